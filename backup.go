@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backupsDirName is the directory under the SQL directory holding the
+// rolling backups of SQL files
+const backupsDirName = ".backups"
+
+// maxBackups is how many backups are kept per SQL file
+const maxBackups = 10
+
+// backupInterval is the least time between two backups of a tab's file, so
+// frequent saves (auto-save, running queries) don't churn through them
+const backupInterval = 5 * time.Minute
+
+// backupsDir returns the backups directory in the SQL directory
+func backupsDir(sqlDir string) string {
+	return filepath.Join(sqlDir, backupsDirName)
+}
+
+// backupPrefix is how the backups of a SQL file are named: its name without
+// the extension, then a timestamp
+func backupPrefix(path string) string {
+	base := filepath.Base(path)
+	return sanitizeFilename(strings.TrimSuffix(base, filepath.Ext(base))) + "."
+}
+
+// writeBackup saves content as a timestamped backup of the SQL file at path
+// and removes all but the newest maxBackups backups of that file
+func writeBackup(dir, path, content string, now time.Time) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	prefix := backupPrefix(path)
+	name := prefix + now.Format("20060102-150405") + ".sql"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	backups, err := listBackups(dir, path)
+	if err != nil {
+		return err
+	}
+	for _, old := range backups[min(len(backups), maxBackups):] {
+		if err := os.Remove(filepath.Join(dir, old)); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// listBackups returns the names of the backups of the SQL file at path,
+// newest first
+func listBackups(dir, path string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+	prefix := backupPrefix(path)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".sql") &&
+			isBackupTimestamp(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".sql")) {
+			names = append(names, name)
+		}
+	}
+	// Timestamps sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// isBackupTimestamp reports whether s is a backup's timestamp, so the backups
+// of orders.sql aren't confused with those of orders.archive.sql
+func isBackupTimestamp(s string) bool {
+	_, err := time.Parse("20060102-150405", s)
+	return err == nil
+}
+
+// writeTabFile writes a tab's editor content to its SQL file. The version
+// it replaces is backed up too, at most once every backupInterval. A backup
+// that fails doesn't stop the save; it's kept in the tab's backupErr.
+func writeTabFile(tab *Tab) error {
+	content := tab.textarea.Value()
+	var previous []byte
+	if content != tab.lastSavedContent && tab.sqlDir != "" && time.Since(tab.lastBackup) >= backupInterval {
+		previous, _ = os.ReadFile(tab.sqlFile)
+	}
+
+	if err := os.WriteFile(tab.sqlFile, []byte(content), 0644); err != nil {
+		return err
+	}
+	tab.lastSavedContent = content
+	tab.diskStat = statFile(tab.sqlFile)
+
+	if len(previous) > 0 {
+		tab.backupErr = writeBackup(backupsDir(tab.sqlDir), tab.sqlFile, string(previous), time.Now())
+		if tab.backupErr == nil {
+			tab.lastBackup = time.Now()
+		}
+	}
+	return nil
+}
+
+// autoSaveMsg is sent every auto-save interval to save edited tabs
+type autoSaveMsg struct{}
+
+// autoSaveTick schedules the next auto-save, if auto-save is on
+func autoSaveTick(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return autoSaveMsg{}
+	})
+}
+
+// autoSave saves every tab with unsaved edits
+func (m *Model) autoSave() {
+	for _, tab := range m.tabs {
+		if tab.sqlFile != "" && tab.textarea.Value() != tab.lastSavedContent {
+			m.saveTab(tab)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestWriteBackup tests keeping the newest backups of each SQL file
+func TestWriteBackup(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range maxBackups + 3 {
+		if err := writeBackup(dir, "/sql/orders.sql", fmt.Sprintf("SELECT %d;", i), start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeBackup(dir, "/sql/orders.archive.sql", "SELECT 'archive';", start); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := listBackups(dir, "/sql/orders.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != maxBackups {
+		t.Fatalf("listBackups() returned %d backups, want %d: %v", len(backups), maxBackups, backups)
+	}
+	if want := "orders.20240301-121200.sql"; backups[0] != want {
+		t.Errorf("newest backup = %q, want %q", backups[0], want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, backups[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != fmt.Sprintf("SELECT %d;", maxBackups+2) {
+		t.Errorf("newest backup content = %q", data)
+	}
+
+	archive, err := listBackups(dir, "/sql/orders.archive.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive) != 1 {
+		t.Errorf("listBackups() for another file = %v, want 1 backup", archive)
+	}
+}
+
+// TestWriteTabFile tests saving a tab and backing up the version it replaces
+func TestWriteTabFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queries.sql")
+	if err := os.WriteFile(path, []byte("SELECT 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tab := NewTab(nil, "sqlite", "", dir, path, "SELECT 1;", "", DefaultTheme)
+	tab.textarea.SetValue("SELECT 2;")
+	if err := writeTabFile(tab); err != nil {
+		t.Fatal(err)
+	}
+	tab.textarea.SetValue("SELECT 3;")
+	if err := writeTabFile(tab); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "SELECT 3;" || tab.lastSavedContent != "SELECT 3;" {
+		t.Errorf("file = %q, lastSavedContent = %q, want both %q", data, tab.lastSavedContent, "SELECT 3;")
+	}
+
+	// The second save was within backupInterval of the first
+	backups, err := listBackups(backupsDir(dir), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1: %v", len(backups), backups)
+	}
+	data, err = os.ReadFile(filepath.Join(backupsDir(dir), backups[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "SELECT 1;" {
+		t.Errorf("backup content = %q, want %q", data, "SELECT 1;")
+	}
+
+	tab.sqlFile = filepath.Join(dir, "missing", "queries.sql")
+	if err := writeTabFile(tab); err == nil {
+		t.Errorf("writeTabFile() to a missing directory succeeded")
+	}
+
+	// A backup that can't be written doesn't stop the save
+	tab.sqlFile = path
+	tab.lastBackup = time.Time{}
+	if err := os.RemoveAll(backupsDir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backupsDir(dir), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tab.textarea.SetValue("SELECT 4;")
+	if err := writeTabFile(tab); err != nil {
+		t.Fatalf("writeTabFile() with a failing backup: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "SELECT 4;" || tab.backupErr == nil {
+		t.Errorf("file = %q, backupErr = %v; want the file saved and the backup's error kept", data, tab.backupErr)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// bookmarkPattern matches a bookmark comment naming the statement below it,
+// e.g. "-- @name: daily-report"
+var bookmarkPattern = regexp.MustCompile(`^\s*--\s*@name:\s*(\S.*?)\s*$`)
+
+// Bookmark is a statement in the editor named by a bookmark comment
+type Bookmark struct {
+	Name string
+	Line int    // first line of the statement after the comment (0-indexed)
+	SQL  string // the statement, for the picker's preview
+}
+
+// findBookmarks returns the bookmarked statements in content, in the order
+// they appear. A bookmark with nothing but blank lines and comments after
+// it is skipped.
+func findBookmarks(content string) []Bookmark {
+	lines := strings.Split(content, "\n")
+	var bookmarks []Bookmark
+	for i, line := range lines {
+		match := bookmarkPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		start := i + 1
+		for start < len(lines) && isBlankOrComment(lines[start]) {
+			start++
+		}
+		if start >= len(lines) {
+			continue
+		}
+		bookmarks = append(bookmarks, Bookmark{
+			Name: match[1],
+			Line: start,
+			SQL:  bookmarkedSQL(lines[start:]),
+		})
+	}
+	return bookmarks
+}
+
+// isBlankOrComment reports whether a line is empty or a -- comment
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "--")
+}
+
+// bookmarkedSQL returns the lines up to the first one ending the statement
+// with a semicolon
+func bookmarkedSQL(lines []string) string {
+	for i, line := range lines {
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			return strings.Join(lines[:i+1], "\n")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BookmarkPicker is the fuzzy-search popup over the active tab's bookmarks
+type BookmarkPicker struct {
+	input     textinput.Model
+	bookmarks []Bookmark
+	matches   []Bookmark
+	selected  int
+}
+
+// newBookmarkPicker creates a picker over bookmarks
+func newBookmarkPicker(bookmarks []Bookmark) *BookmarkPicker {
+	ti := textinput.New()
+	ti.Placeholder = "bookmark name"
+	ti.CharLimit = 128
+	ti.Width = 40
+	ti.Focus()
+
+	p := &BookmarkPicker{input: ti, bookmarks: bookmarks}
+	p.filter()
+	return p
+}
+
+// filter recomputes the matches for the current input, best first. With no
+// input the bookmarks stay in file order.
+func (p *BookmarkPicker) filter() {
+	pattern := strings.TrimSpace(p.input.Value())
+	type scored struct {
+		bookmark Bookmark
+		score    int
+	}
+	var matches []scored
+	for _, b := range p.bookmarks {
+		if score, ok := fuzzyScore(pattern, b.Name); ok {
+			matches = append(matches, scored{b, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	p.matches = p.matches[:0]
+	for _, m := range matches {
+		p.matches = append(p.matches, m.bookmark)
+	}
+	p.selected = 0
+}
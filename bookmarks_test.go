@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFindBookmarks tests finding statements named with "-- @name:" comments
+func TestFindBookmarks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Bookmark
+	}{
+		{
+			name:    "no bookmarks",
+			content: "SELECT 1;\n-- just a comment\nSELECT 2;",
+			want:    nil,
+		},
+		{
+			name:    "statement after the comment",
+			content: "SELECT 1;\n\n-- @name: daily-report\nSELECT count(*)\nFROM orders;\nSELECT 3;",
+			want:    []Bookmark{{Name: "daily-report", Line: 3, SQL: "SELECT count(*)\nFROM orders;"}},
+		},
+		{
+			name:    "blank lines and comments skipped",
+			content: "--@name:  top users  \n\n-- by spend\nSELECT * FROM users;",
+			want:    []Bookmark{{Name: "top users", Line: 3, SQL: "SELECT * FROM users;"}},
+		},
+		{
+			name:    "in file order",
+			content: "-- @name: b\nSELECT 1;\n-- @name: a\nSELECT 2",
+			want: []Bookmark{
+				{Name: "b", Line: 1, SQL: "SELECT 1;"},
+				{Name: "a", Line: 3, SQL: "SELECT 2"},
+			},
+		},
+		{
+			name:    "nothing after the bookmark",
+			content: "SELECT 1;\n-- @name: empty\n\n",
+			want:    nil,
+		},
+		{
+			name:    "not a bookmark",
+			content: "SELECT '-- @name: x';\n-- @name:\nSELECT 2;",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findBookmarks(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findBookmarks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
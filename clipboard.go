@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"golang.org/x/term"
+)
+
+// copyToClipboard copies text to the system clipboard, and also sends it to
+// the terminal as an OSC 52 escape sequence so it reaches the local clipboard
+// over SSH, where there is no system clipboard to write to. It reports false
+// if neither was possible.
+func copyToClipboard(text string) bool {
+	copied := clipboard.WriteAll(text) == nil
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		seq := osc52.New(text)
+		switch {
+		case os.Getenv("TMUX") != "":
+			seq = seq.Tmux()
+		case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+			seq = seq.Screen()
+		}
+		if _, err := seq.WriteTo(os.Stderr); err == nil {
+			copied = true
+		}
+	}
+	return copied
+}
+
+// readClipboard returns the system clipboard's text, or fallback if it is
+// empty or unavailable. Terminals don't reliably answer OSC 52 reads, so
+// over SSH the terminal's own paste (usually Ctrl+Shift+V) is the way in.
+func readClipboard(fallback string) string {
+	text, err := clipboard.ReadAll()
+	if err != nil || text == "" {
+		return fallback
+	}
+	return text
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ColumnPicker is the popup listing the shown result's columns, where they
+// can be hidden from the table and shown again, or pinned
+type ColumnPicker struct {
+	tab      *Tab
+	selected int
+}
+
+// columnLayout is the columns hidden and pinned in a query's results,
+// remembered by name for the rest of the session
+type columnLayout struct {
+	hidden []string
+	pinned []string
+}
+
+// isHidden reports whether result column i is hidden from the table
+func (r *QueryResult) isHidden(i int) bool {
+	return i < len(r.hidden) && r.hidden[i]
+}
+
+// isPinned reports whether result column i stays at the left of the table
+// while the others scroll
+func (r *QueryResult) isPinned(i int) bool {
+	return i < len(r.pinned) && r.pinned[i]
+}
+
+// nextShownColumn returns the first column from i on, stepping by dir,
+// that isn't hidden, or -1 if there's none
+func (r *QueryResult) nextShownColumn(i, dir int) int {
+	for ; i >= 0 && i < len(r.Columns); i += dir {
+		if !r.isHidden(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hiddenCount returns how many of the result's columns are hidden
+func (r *QueryResult) hiddenCount() int {
+	n := 0
+	for _, h := range r.hidden {
+		if h {
+			n++
+		}
+	}
+	return n
+}
+
+// columnLayoutKey is the key under which the layout of a query's result
+// columns is remembered: the query with its spacing evened out, and without
+// the ORDER BY that sorting it in the database wrapped it in
+func columnLayoutKey(query string) string {
+	if inner, _, ok := unwrapSortedQuery(query); ok {
+		query = inner
+	}
+	return strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(query), ";")), " ")
+}
+
+// columnFlags marks the columns named in names
+func columnFlags(columns, names []string) []bool {
+	if len(names) == 0 {
+		return nil
+	}
+	flags := make([]bool, len(columns))
+	for i, col := range columns {
+		flags[i] = slices.Contains(names, col)
+	}
+	return flags
+}
+
+// flaggedColumns returns the names of the columns marked in flags
+func flaggedColumns(columns []string, flags []bool) []string {
+	var names []string
+	for i, col := range columns {
+		if i < len(flags) && flags[i] {
+			names = append(names, col)
+		}
+	}
+	return names
+}
+
+// restoreColumnLayout hides and pins the columns hidden and pinned earlier
+// in the session in the results of the tab's last query
+func (m *Model) restoreColumnLayout(tab *Tab) {
+	result := tab.result
+	layout, ok := m.columnLayouts[columnLayoutKey(tab.lastQuery)]
+	if result == nil || !ok {
+		return
+	}
+	result.hidden = columnFlags(result.Columns, layout.hidden)
+	result.pinned = columnFlags(result.Columns, layout.pinned)
+	if result.hiddenCount() == len(result.Columns) {
+		result.hidden = nil // a different query's columns, all by the same names
+	}
+}
+
+// showSelectedColumn moves the tab's selected column off a hidden column,
+// to the next one shown on the right, or else on the left
+func (t *Tab) showSelectedColumn() {
+	if !t.result.isHidden(t.selectedColumn) {
+		return
+	}
+	if i := t.result.nextShownColumn(t.selectedColumn, 1); i >= 0 {
+		t.selectedColumn = i
+	} else {
+		t.selectedColumn = max(t.result.nextShownColumn(t.selectedColumn, -1), 0)
+	}
+}
+
+// openColumnPicker opens the popup for hiding and showing the shown
+// result's columns
+func (m *Model) openColumnPicker() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.result.Error != nil || len(tab.result.Columns) == 0 {
+		return
+	}
+	m.columnPicker = &ColumnPicker{tab: tab, selected: tab.selectedColumn}
+	m.focus = focusColumnPicker
+}
+
+// toggleHiddenColumn hides the picker's selected column, or shows it again,
+// remembering the columns hidden for the query for the rest of the session
+func (m *Model) toggleHiddenColumn() {
+	p := m.columnPicker
+	result := p.tab.result
+	if result.hidden == nil {
+		result.hidden = make([]bool, len(result.Columns))
+	}
+	if !result.hidden[p.selected] && result.hiddenCount() == len(result.Columns)-1 {
+		m.statusMessage = "The last column shown can't be hidden"
+		return
+	}
+	result.hidden[p.selected] = !result.hidden[p.selected]
+	m.rememberColumnLayout(p.tab)
+	if n := result.hiddenCount(); n > 0 {
+		m.statusMessage = fmt.Sprintf("%d of %d columns hidden", n, len(result.Columns))
+	} else {
+		m.statusMessage = "All columns shown"
+	}
+}
+
+// showAllColumns shows every column of the picker's result again
+func (m *Model) showAllColumns() {
+	m.columnPicker.tab.result.hidden = nil
+	m.rememberColumnLayout(m.columnPicker.tab)
+	m.statusMessage = "All columns shown"
+}
+
+// togglePinnedColumn pins result column i of the tab's result to the left
+// of the table, or unpins it
+func (m *Model) togglePinnedColumn(tab *Tab, i int) {
+	result := tab.result
+	if result == nil || i >= len(result.Columns) {
+		return
+	}
+	if result.pinned == nil {
+		result.pinned = make([]bool, len(result.Columns))
+	}
+	result.pinned[i] = !result.pinned[i]
+	m.rememberColumnLayout(tab)
+	if result.pinned[i] {
+		m.statusMessage = fmt.Sprintf("Pinned %s", result.Columns[i])
+	} else {
+		m.statusMessage = fmt.Sprintf("Unpinned %s", result.Columns[i])
+	}
+}
+
+// rememberColumnLayout saves the columns hidden and pinned in the tab's
+// result under its query, and keeps the selected column one that's shown
+func (m *Model) rememberColumnLayout(tab *Tab) {
+	layout := columnLayout{
+		hidden: flaggedColumns(tab.result.Columns, tab.result.hidden),
+		pinned: flaggedColumns(tab.result.Columns, tab.result.pinned),
+	}
+	key := columnLayoutKey(tab.lastQuery)
+	if len(layout.hidden) == 0 && len(layout.pinned) == 0 {
+		delete(m.columnLayouts, key)
+	} else {
+		if m.columnLayouts == nil {
+			m.columnLayouts = make(map[string]columnLayout)
+		}
+		m.columnLayouts[key] = layout
+	}
+	tab.showSelectedColumn()
+	tab.scrollToSelectedColumn(m.mainWidth())
+}
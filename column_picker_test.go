@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+// TestColumnLayoutKey tests that a query's column layout is remembered
+// under the same key however it's spaced, and when it's sorted in the
+// database
+func TestColumnLayoutKey(t *testing.T) {
+	want := "SELECT * FROM users WHERE id > 1"
+	for _, query := range []string{
+		"SELECT * FROM users WHERE id > 1",
+		"SELECT *\n  FROM users\n  WHERE id > 1;",
+		sortedQuery("SELECT * FROM users WHERE id > 1", "name", "postgres", true),
+	} {
+		if got := columnLayoutKey(query); got != want {
+			t.Errorf("columnLayoutKey(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+// TestNextShownColumn tests finding the next column that isn't hidden in
+// either direction
+func TestNextShownColumn(t *testing.T) {
+	r := &QueryResult{Columns: []string{"a", "b", "c", "d"}, hidden: []bool{true, false, true}}
+	tests := []struct {
+		from, dir, want int
+	}{
+		{0, 1, 1},
+		{1, 1, 1},
+		{2, 1, 3},
+		{2, -1, 1},
+		{0, -1, -1},
+		{4, 1, -1},
+	}
+	for _, tt := range tests {
+		if got := r.nextShownColumn(tt.from, tt.dir); got != tt.want {
+			t.Errorf("nextShownColumn(%d, %d) = %d, want %d", tt.from, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "strings"
+
+// lineCommentPrefix is what commenting out a line inserts
+const lineCommentPrefix = "-- "
+
+// commentEdit records the text inserted (delta > 0) or removed (delta < 0)
+// at a byte column of one line when toggling comments
+type commentEdit struct {
+	col   int
+	delta int
+}
+
+// toggleLineComments comments out lines first to last (inclusive) with "-- ",
+// or uncomments them if every non-blank line in the range is already a
+// comment. Markers are inserted at the smallest indentation of the lines so
+// they stay aligned; blank lines are left alone. It returns the new content
+// and the edit made to each changed line, keyed by line index.
+func toggleLineComments(content string, first, last int) (string, map[int]commentEdit) {
+	lines := strings.Split(content, "\n")
+	last = min(last, len(lines)-1)
+
+	uncomment := true
+	indent := -1
+	for i := first; i <= last; i++ {
+		trimmed := strings.TrimLeft(lines[i], " \t")
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "--") {
+			uncomment = false
+		}
+		lineIndent := len(lines[i]) - len(trimmed)
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+	if indent < 0 {
+		return content, nil // only blank lines
+	}
+
+	edits := make(map[int]commentEdit)
+	for i := first; i <= last; i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if uncomment {
+			col := len(line) - len(trimmed)
+			n := len("--")
+			if strings.HasPrefix(trimmed, lineCommentPrefix) {
+				n = len(lineCommentPrefix)
+			}
+			lines[i] = line[:col] + line[col+n:]
+			edits[i] = commentEdit{col: col, delta: -n}
+		} else {
+			lines[i] = line[:indent] + lineCommentPrefix + line[indent:]
+			edits[i] = commentEdit{col: indent, delta: len(lineCommentPrefix)}
+		}
+	}
+	return strings.Join(lines, "\n"), edits
+}
+
+// adjustOffsetForEdits maps a byte offset in the content before
+// toggleLineComments to the same place in its result
+func adjustOffsetForEdits(oldContent, newContent string, offset int, edits map[int]commentEdit) int {
+	line := strings.Count(oldContent[:offset], "\n")
+	col := offset - (strings.LastIndexByte(oldContent[:offset], '\n') + 1)
+	if e, ok := edits[line]; ok && col >= e.col {
+		col = max(col+e.delta, e.col)
+	}
+
+	newLines := strings.Split(newContent, "\n")
+	pos := 0
+	for _, l := range newLines[:line] {
+		pos += len(l) + 1
+	}
+	return pos + min(col, len(newLines[line]))
+}
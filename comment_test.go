@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+// TestToggleLineComments tests commenting and uncommenting line ranges
+func TestToggleLineComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		first, last int
+		want        string
+	}{
+		{"comment one line", "SELECT 1;\nSELECT 2;", 1, 1, "SELECT 1;\n-- SELECT 2;"},
+		{"uncomment one line", "-- SELECT 1;", 0, 0, "SELECT 1;"},
+		{"uncomment without space", "--SELECT 1;", 0, 0, "SELECT 1;"},
+		{"aligned at smallest indent", "SELECT *\n  FROM t\n    WHERE x", 1, 2, "SELECT *\n  -- FROM t\n  --   WHERE x"},
+		{"blank lines untouched", "SELECT *\n\nFROM t", 0, 2, "-- SELECT *\n\n-- FROM t"},
+		{"mixed range is commented", "-- SELECT *\nFROM t", 0, 1, "-- -- SELECT *\n-- FROM t"},
+		{"uncomment keeps indent", "  -- WHERE x\n  -- AND y", 0, 1, "  WHERE x\n  AND y"},
+		{"only blank lines", "SELECT 1;\n\n", 1, 2, "SELECT 1;\n\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := toggleLineComments(tc.content, tc.first, tc.last)
+			if got != tc.want {
+				t.Errorf("toggleLineComments(%q, %d, %d) = %q, want %q", tc.content, tc.first, tc.last, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestAdjustOffsetForEdits tests keeping the cursor on the same text after toggling comments
+func TestAdjustOffsetForEdits(t *testing.T) {
+	tests := []struct {
+		content string
+		offset  int
+		want    int
+	}{
+		{"SELECT 1;\nSELECT 2;", 3, 3},         // line not edited
+		{"SELECT 1;\n  SELECT 2;", 14, 17},     // after the marker: moves with the text
+		{"SELECT 1;\n  SELECT 2;", 11, 11},     // inside the indent: stays put
+		{"SELECT 1;\n-- SELECT 2;", 16, 13},    // uncommented: moves back
+		{"SELECT 1;\n-- SELECT 2;", 11, 10},    // inside the removed marker: clamped
+		{"SELECT 1;\n-- SELECT 2;\nx", 23, 20}, // later line
+	}
+
+	for _, tc := range tests {
+		updated, edits := toggleLineComments(tc.content, 1, 1)
+		if got := adjustOffsetForEdits(tc.content, updated, tc.offset, edits); got != tc.want {
+			t.Errorf("adjustOffsetForEdits(%q, %d) = %d, want %d (in %q)", tc.content, tc.offset, got, tc.want, updated)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// completionMaxShown caps the number of candidates listed in the status line
+	completionMaxShown = 8
+
+	// popupMinPrefix is how many characters must be typed before the
+	// completion popup opens, so it doesn't flicker on every keystroke
+	popupMinPrefix = 2
+
+	// popupMaxItems is the number of suggestions visible in the popup at once
+	popupMaxItems = 6
+)
+
+// Keywords after which a table name is expected
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "UPDATE": true, "INTO": true, "TABLE": true,
+}
+
+// Keywords after which a column name is expected
+var columnKeywords = map[string]bool{
+	"SELECT": true, "WHERE": true, "AND": true, "OR": true, "NOT": true,
+	"ON": true, "BY": true, "SET": true, "HAVING": true, "DISTINCT": true,
+}
+
+// Completion is the result of completing the identifier before the cursor
+type Completion struct {
+	Prefix     string   // the partial identifier already typed
+	Candidates []string // matching table or column names, sorted, then any keywords
+	Qualified  bool     // completing after "table." or "alias."
+}
+
+// CompletionPopup is the list of suggestions shown under the cursor while typing
+type CompletionPopup struct {
+	Completion
+	selected int
+}
+
+// Selected returns the highlighted suggestion
+func (p *CompletionPopup) Selected() string {
+	return p.Candidates[p.selected]
+}
+
+// Move moves the highlight by delta, wrapping around the list
+func (p *CompletionPopup) Move(delta int) {
+	n := len(p.Candidates)
+	p.selected = ((p.selected+delta)%n + n) % n
+}
+
+// newCompletionPopup returns a popup for the suggestions, or nil if there is
+// nothing worth showing: too short a prefix, no candidates, or only the word
+// already typed
+func newCompletionPopup(c Completion) *CompletionPopup {
+	if len(c.Candidates) == 0 || (!c.Qualified && len([]rune(c.Prefix)) < popupMinPrefix) {
+		return nil
+	}
+	if len(c.Candidates) == 1 && strings.EqualFold(c.Candidates[0], c.Prefix) {
+		return nil
+	}
+	return &CompletionPopup{Completion: c}
+}
+
+// CommonPrefix returns the longest prefix shared by all candidates
+// (case-insensitive), taking its case from the first candidate
+func (c Completion) CommonPrefix() string {
+	if len(c.Candidates) == 0 {
+		return ""
+	}
+	common := []rune(c.Candidates[0])
+	for _, cand := range c.Candidates[1:] {
+		r := []rune(cand)
+		n := 0
+		for n < len(common) && n < len(r) && unicode.ToLower(common[n]) == unicode.ToLower(r[n]) {
+			n++
+		}
+		common = common[:n]
+	}
+	return string(common)
+}
+
+// completeAt works out what is being typed at byte offset pos of content and
+// which schema names could complete it: table names after FROM/JOIN, and
+// column names of the tables the statement uses after SELECT/WHERE.
+// "alias." completes the columns of the aliased table. With keywords set,
+// matching SQL keywords and functions follow the schema names, in any
+// position. It returns false when the cursor isn't at the end of a partial
+// identifier that can be completed.
+func completeAt(content string, pos int, tables []SchemaTable, keywords bool) (Completion, bool) {
+	stmtStart := strings.LastIndexByte(content[:pos], ';') + 1
+	stmtEnd := len(content)
+	if i := strings.IndexByte(content[pos:], ';'); i >= 0 {
+		stmtEnd = pos + i
+	}
+	before := content[stmtStart:pos]
+	stmt := content[stmtStart:stmtEnd]
+
+	prefix := trailingIdentifier(before)
+	if prefix == "" || strings.Count(before, "'")%2 == 1 { // nothing typed, or inside a string
+		return Completion{}, false
+	}
+	rest := before[:len(before)-len(prefix)]
+
+	var names []string
+	qualified := strings.HasSuffix(rest, ".")
+	if qualified {
+		// Qualified column: table.col or alias.col
+		qualifier := trailingIdentifier(rest[:len(rest)-1])
+		if qualifier == "" {
+			return Completion{}, false
+		}
+		if name, ok := referencedTables(stmt)[strings.ToLower(qualifier)]; ok {
+			qualifier = name
+		}
+		if table := findTable(tables, qualifier); table != nil {
+			names = columnNames(table)
+		}
+	} else {
+		switch keyword := previousKeyword(rest); {
+		case tableKeywords[keyword]:
+			for _, t := range tables {
+				names = append(names, t.Name)
+			}
+		case columnKeywords[keyword]:
+			names = statementColumns(stmt, tables)
+		default:
+			if !keywords {
+				return Completion{}, false
+			}
+		}
+	}
+
+	c := Completion{Prefix: prefix, Qualified: qualified}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] || !hasPrefixFold(name, prefix) {
+			continue
+		}
+		seen[name] = true
+		c.Candidates = append(c.Candidates, name)
+	}
+	sort.Strings(c.Candidates)
+	if keywords && !qualified {
+		c.Candidates = append(c.Candidates, keywordCandidates(prefix, seen)...)
+	}
+	return c, true
+}
+
+// keywordCandidates returns the SQL keywords and functions starting with
+// prefix, lowercased if prefix is, skipping names already in seen
+func keywordCandidates(prefix string, seen map[string]bool) []string {
+	lower := prefix == strings.ToLower(prefix)
+	var words []string
+	for _, list := range [][]string{sqlKeywords, sqlFunctions} {
+		for _, word := range list {
+			if lower {
+				word = strings.ToLower(word)
+			}
+			if seen[word] || !hasPrefixFold(word, prefix) {
+				continue
+			}
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// statementColumns returns the columns of the tables stmt reads from,
+// or of every table if it doesn't name any known table yet
+func statementColumns(stmt string, tables []SchemaTable) []string {
+	var names []string
+	for _, name := range referencedTables(stmt) {
+		if table := findTable(tables, name); table != nil {
+			names = append(names, columnNames(table)...)
+		}
+	}
+	if names == nil {
+		for i := range tables {
+			names = append(names, columnNames(&tables[i])...)
+		}
+	}
+	return names
+}
+
+// referencedTables maps the lowercased names and aliases of the tables that
+// follow FROM/JOIN/UPDATE/INTO in stmt to the table names
+func referencedTables(stmt string) map[string]string {
+	words := identifierWords(stmt)
+	refs := make(map[string]string)
+	for i := 0; i+1 < len(words); i++ {
+		if !tableKeywords[strings.ToUpper(words[i])] {
+			continue
+		}
+		table := words[i+1]
+		refs[strings.ToLower(table)] = table
+		j := i + 2
+		if j < len(words) && strings.EqualFold(words[j], "AS") {
+			j++
+		}
+		if j < len(words) && !isSQLKeyword(words[j]) {
+			refs[strings.ToLower(words[j])] = table
+		}
+	}
+	return refs
+}
+
+// previousKeyword returns the last word of text (uppercased) that is a
+// completion keyword, skipping identifiers such as earlier columns in a list
+func previousKeyword(text string) string {
+	words := identifierWords(text)
+	for i := len(words) - 1; i >= 0; i-- {
+		upper := strings.ToUpper(words[i])
+		if tableKeywords[upper] || columnKeywords[upper] {
+			return upper
+		}
+	}
+	return ""
+}
+
+// identifierWords splits text into identifier-like words, dropping quoted
+// strings, comments and punctuation
+func identifierWords(text string) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case ch == '\'':
+			flush()
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return words
+			}
+			i += end + 1
+		case ch == '-' && i+1 < len(text) && text[i+1] == '-':
+			flush()
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return words
+			}
+			i += end
+		case isIdentifierByte(ch):
+			current.WriteByte(ch)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// trailingIdentifier returns the identifier characters at the end of text
+func trailingIdentifier(text string) string {
+	i := len(text)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:i])
+		if r >= utf8.RuneSelf {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+		} else if !isIdentifierByte(byte(r)) {
+			break
+		}
+		i -= size
+	}
+	return text[i:]
+}
+
+// isIdentifierByte reports whether ch can be part of an unquoted identifier;
+// bytes of multi-byte runes are accepted so non-ASCII names stay whole
+func isIdentifierByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= utf8.RuneSelf ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// isSQLKeyword reports whether word ends a table reference rather than
+// naming its alias
+func isSQLKeyword(word string) bool {
+	upper := strings.ToUpper(word)
+	if tableKeywords[upper] || columnKeywords[upper] {
+		return true
+	}
+	switch upper {
+	case "LEFT", "RIGHT", "INNER", "OUTER", "FULL", "CROSS", "NATURAL", "USING",
+		"GROUP", "ORDER", "LIMIT", "OFFSET", "UNION", "VALUES", "RETURNING":
+		return true
+	}
+	return false
+}
+
+// findTable returns the table with the given name (case-insensitive), or nil
+func findTable(tables []SchemaTable, name string) *SchemaTable {
+	for i := range tables {
+		if strings.EqualFold(tables[i].Name, name) {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+// columnNames returns the names of a table's columns
+func columnNames(table *SchemaTable) []string {
+	names := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// hasPrefixFold is strings.HasPrefix ignoring case
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCompleteAt tests choosing table or column candidates from the text before the cursor
+func TestCompleteAt(t *testing.T) {
+	tables := []SchemaTable{
+		{Name: "users", Columns: []SchemaColumn{{Name: "id"}, {Name: "name"}, {Name: "email"}}},
+		{Name: "user_roles", Columns: []SchemaColumn{{Name: "user_id"}, {Name: "role"}}},
+		{Name: "orders", Columns: []SchemaColumn{{Name: "id"}, {Name: "user_id"}, {Name: "total"}}},
+	}
+
+	tests := []struct {
+		name       string
+		content    string // | marks the cursor
+		ok         bool
+		prefix     string
+		candidates []string
+	}{
+		{"table after FROM", "SELECT * FROM us|", true, "us", []string{"user_roles", "users"}},
+		{"table after JOIN", "SELECT * FROM users u JOIN o|", true, "o", []string{"orders"}},
+		{"table case-insensitive", "select * from ORD|", true, "ORD", []string{"orders"}},
+		{"second table in list", "SELECT * FROM users, or|", true, "or", []string{"orders"}},
+		{"column of FROM table", "SELECT na| FROM users", true, "na", []string{"name"}},
+		{"column after earlier columns", "SELECT id, e| FROM users", true, "e", []string{"email"}},
+		{"column in WHERE", "SELECT * FROM orders WHERE t|", true, "t", []string{"total"}},
+		{"columns of joined tables", "SELECT * FROM users JOIN orders ON u|", true, "u", []string{"user_id"}},
+		{"alias qualifier", "SELECT o.t| FROM orders o", true, "t", []string{"total"}},
+		{"AS alias qualifier", "SELECT * FROM users AS u WHERE u.e|", true, "e", []string{"email"}},
+		{"table qualifier", "SELECT user_roles.r| FROM user_roles", true, "r", []string{"role"}},
+		{"no tables yet", "SELECT ro|", true, "ro", []string{"role"}},
+		{"only current statement", "SELECT * FROM orders;\nSELECT n| FROM users", true, "n", []string{"name"}},
+		{"no match", "SELECT * FROM zz|", true, "zz", nil},
+		{"no partial name", "SELECT * FROM |", false, "", nil},
+		{"no keyword context", "CREATE us|", false, "", nil},
+		{"inside string", "SELECT * FROM users WHERE name = 'FROM us|", false, "", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pos := strings.Index(tc.content, "|")
+			content := tc.content[:pos] + tc.content[pos+1:]
+			c, ok := completeAt(content, pos, tables, false)
+			if ok != tc.ok {
+				t.Fatalf("completeAt(%q) ok = %v, want %v", tc.content, ok, tc.ok)
+			}
+			if c.Prefix != tc.prefix {
+				t.Errorf("completeAt(%q) prefix = %q, want %q", tc.content, c.Prefix, tc.prefix)
+			}
+			if !reflect.DeepEqual(c.Candidates, tc.candidates) {
+				t.Errorf("completeAt(%q) candidates = %v, want %v", tc.content, c.Candidates, tc.candidates)
+			}
+		})
+	}
+}
+
+// TestCompletionCommonPrefix tests extending a partial name as far as all candidates agree
+func TestCompletionCommonPrefix(t *testing.T) {
+	tests := []struct {
+		candidates []string
+		want       string
+	}{
+		{nil, ""},
+		{[]string{"users"}, "users"},
+		{[]string{"user_roles", "users"}, "user"},
+		{[]string{"Orders", "order_items"}, "Order"},
+		{[]string{"id", "name"}, ""},
+	}
+
+	for _, tc := range tests {
+		got := Completion{Candidates: tc.candidates}.CommonPrefix()
+		if got != tc.want {
+			t.Errorf("CommonPrefix(%v) = %q, want %q", tc.candidates, got, tc.want)
+		}
+	}
+}
+
+// TestCompleteAtKeywords tests offering SQL keywords and functions after schema names
+func TestCompleteAtKeywords(t *testing.T) {
+	tables := []SchemaTable{
+		{Name: "users", Columns: []SchemaColumn{{Name: "id"}, {Name: "count_visits"}}},
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		candidates []string
+	}{
+		{"keyword anywhere", "sel", []string{"select"}},
+		{"uppercase prefix", "SEL", []string{"SELECT"}},
+		{"schema names first", "SELECT cou", []string{"count_visits", "count"}},
+		{"keywords outside schema context", "CREATE TA", []string{"TABLE"}},
+		{"functions", "SELECT su", []string{"substr", "substring", "sum"}},
+		{"qualified names only", "SELECT u.co FROM users u", []string{"count_visits"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pos := len(tc.content)
+			if i := strings.Index(tc.content, " FROM"); i >= 0 {
+				pos = i
+			}
+			c, ok := completeAt(tc.content, pos, tables, true)
+			if !ok {
+				t.Fatalf("completeAt(%q) ok = false", tc.content)
+			}
+			if !reflect.DeepEqual(c.Candidates, tc.candidates) {
+				t.Errorf("completeAt(%q) candidates = %v, want %v", tc.content, c.Candidates, tc.candidates)
+			}
+		})
+	}
+}
+
+// TestNewCompletionPopup tests when the completion popup is worth opening
+func TestNewCompletionPopup(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Completion
+		open bool
+	}{
+		{"suggestions", Completion{Prefix: "se", Candidates: []string{"select", "set"}}, true},
+		{"prefix too short", Completion{Prefix: "s", Candidates: []string{"select", "set"}}, false},
+		{"qualified short prefix", Completion{Prefix: "", Candidates: []string{"id"}, Qualified: true}, true},
+		{"no candidates", Completion{Prefix: "zz"}, false},
+		{"word already complete", Completion{Prefix: "Select", Candidates: []string{"SELECT"}}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := newCompletionPopup(tc.c) != nil; got != tc.open {
+				t.Errorf("newCompletionPopup(%+v) open = %v, want %v", tc.c, got, tc.open)
+			}
+		})
+	}
+}
+
+// TestCompletionPopupMove tests that the highlight wraps around the list
+func TestCompletionPopupMove(t *testing.T) {
+	p := &CompletionPopup{Completion: Completion{Candidates: []string{"a", "b", "c"}}}
+	p.Move(-1)
+	if p.Selected() != "c" {
+		t.Errorf("Move(-1) from the first item selected %q, want c", p.Selected())
+	}
+	p.Move(1)
+	if p.Selected() != "a" {
+		t.Errorf("Move(1) from the last item selected %q, want a", p.Selected())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +27,24 @@ type Connection struct {
 	DSN          string `yaml:"dsn,omitempty"`           // plaintext DSN for local/dev databases
 	Type         string `yaml:"type,omitempty"`          // mysql, postgres, sqlite (optional, for auto-detection override)
 	Theme        string `yaml:"theme,omitempty"`         // optional theme name for visual distinction
+	Note         string `yaml:"note,omitempty"`          // free-form note shown when the connection is active
+
+	// ColumnWidths overrides the results grid width cap for columns by name
+	ColumnWidths map[string]int `yaml:"column_widths,omitempty"`
+
+	// StatementTimeout overrides the global statement timeout, in seconds
+	StatementTimeout int `yaml:"statement_timeout,omitempty"`
+
+	// DryRun rolls back every change made on this connection
+	DryRun bool `yaml:"dry_run,omitempty"`
+
+	// ReadOnly refuses statements that write, and asks the server for
+	// read-only sessions where the DSN can
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// Session holds session settings (search_path, time_zone, ...) applied
+	// to every connection opened, set from the session settings panel
+	Session map[string]string `yaml:"session,omitempty"`
 }
 
 // IsEncrypted returns true if this connection uses encrypted storage
@@ -46,6 +65,47 @@ type Config struct {
 
 	// SQLDir is the directory for SQL files (defaults to $HOME/sql if empty)
 	SQLDir string `yaml:"sql_dir,omitempty"`
+
+	// WrapPagination makes PgDn on the last page go to the first page (and vice versa)
+	WrapPagination bool `yaml:"wrap_pagination,omitempty"`
+
+	// RowLimit is how many rows a SELECT without a LIMIT fetches at a time
+	// (0 = the default of 500, -1 = all of them)
+	RowLimit int `yaml:"row_limit,omitempty"`
+
+	// DefaultType is the database type used when it can't be detected from the DSN
+	DefaultType string `yaml:"default_type,omitempty"`
+
+	// ExplicitInsertDefaults makes generated INSERTs include a generated key
+	// column as DEFAULT/nextval() rather than leaving it out
+	ExplicitInsertDefaults bool `yaml:"explicit_insert_defaults,omitempty"`
+
+	// ContinueOnError makes running all statements carry on past a failed one
+	ContinueOnError bool `yaml:"continue_on_error,omitempty"`
+
+	// DryRun runs statements that write in a transaction that's rolled back,
+	// on every connection
+	DryRun bool `yaml:"dry_run,omitempty"`
+
+	// ConfirmWrites runs UPDATEs and DELETEs executed from the detail view
+	// in a transaction, committed once the affected row count is confirmed
+	ConfirmWrites bool `yaml:"confirm_writes,omitempty"`
+
+	// AutoSaveInterval saves edited SQL files every this many seconds (0 = off)
+	AutoSaveInterval int `yaml:"auto_save_interval,omitempty"`
+
+	// HealthCheckInterval pings connections every this many seconds
+	// (0 = every 30 seconds, -1 = never)
+	HealthCheckInterval int `yaml:"health_check_interval,omitempty"`
+
+	// StatementTimeout stops statements running longer than this many seconds (0 = no limit)
+	StatementTimeout int `yaml:"statement_timeout,omitempty"`
+
+	// VimMode turns on vim keybindings (normal, insert and visual modes) in the query editor
+	VimMode bool `yaml:"vim_mode,omitempty"`
+
+	// Keys overrides the keys bound to actions, keyed by action name
+	Keys map[string]KeyBinding `yaml:"keys,omitempty"`
 }
 
 // configPath returns the full path to the config file
@@ -386,6 +446,69 @@ func (vm *VaultManager) GetConnection(name string) (dsn string, dbType string, t
 	return dsn, dbType, theme, nil
 }
 
+// GetColumnWidths returns the per-column width overrides for a connection
+func (vm *VaultManager) GetColumnWidths(name string) map[string]int {
+	if vm.config == nil {
+		return nil
+	}
+	conn, ok := vm.config.Connections[name]
+	if !ok {
+		return nil
+	}
+	return conn.ColumnWidths
+}
+
+// GetConnectionNote returns the free-form note for a connection
+func (vm *VaultManager) GetConnectionNote(name string) string {
+	if vm.config == nil {
+		return ""
+	}
+	conn, ok := vm.config.Connections[name]
+	if !ok {
+		return ""
+	}
+	return conn.Note
+}
+
+// SetConnectionNote sets the free-form note for a connection and saves the config
+func (vm *VaultManager) SetConnectionNote(name, note string) error {
+	if vm.config == nil || !vm.config.HasConnection(name) {
+		return ErrConnectionNotFound
+	}
+	vm.config.Connections[name].Note = note
+	return SaveConfig(vm.config)
+}
+
+// GetSessionSettings returns the session settings saved for a connection
+func (vm *VaultManager) GetSessionSettings(name string) map[string]string {
+	if vm.config == nil {
+		return nil
+	}
+	conn, ok := vm.config.Connections[name]
+	if !ok {
+		return nil
+	}
+	return conn.Session
+}
+
+// SetSessionSetting saves a session setting for a connection, or forgets it
+// if value is empty, and saves the config
+func (vm *VaultManager) SetSessionSetting(name, setting, value string) error {
+	if vm.config == nil || !vm.config.HasConnection(name) {
+		return ErrConnectionNotFound
+	}
+	conn := vm.config.Connections[name]
+	if value == "" {
+		delete(conn.Session, setting)
+	} else {
+		if conn.Session == nil {
+			conn.Session = make(map[string]string)
+		}
+		conn.Session[setting] = value
+	}
+	return SaveConfig(vm.config)
+}
+
 // ListConnections returns a list of connection names
 func (vm *VaultManager) ListConnections() []string {
 	if vm.config == nil {
@@ -435,6 +558,118 @@ func (vm *VaultManager) GetSQLDir() string {
 	return filepath.Join(home, "sql")
 }
 
+// WrapPagination returns true if results pagination should wrap around
+func (vm *VaultManager) WrapPagination() bool {
+	return vm.config != nil && vm.config.WrapPagination
+}
+
+// RowLimit returns how many rows a SELECT without a LIMIT fetches at a time,
+// or 0 to fetch them all
+func (vm *VaultManager) RowLimit() int {
+	switch {
+	case vm.config == nil || vm.config.RowLimit == 0:
+		return defaultRowLimit
+	case vm.config.RowLimit < 0:
+		return 0
+	}
+	return vm.config.RowLimit
+}
+
+// ExplicitInsertDefaults returns true if generated INSERTs should name generated key columns
+func (vm *VaultManager) ExplicitInsertDefaults() bool {
+	return vm.config != nil && vm.config.ExplicitInsertDefaults
+}
+
+// ContinueOnError returns true if running all statements should carry on after an error
+func (vm *VaultManager) ContinueOnError() bool {
+	return vm.config != nil && vm.config.ContinueOnError
+}
+
+// ConfirmWrites returns true if UPDATEs and DELETEs executed from the detail
+// view should wait for confirmation before committing
+func (vm *VaultManager) ConfirmWrites() bool {
+	return vm.config != nil && vm.config.ConfirmWrites
+}
+
+// VimMode returns true if the query editor should use vim keybindings
+func (vm *VaultManager) VimMode() bool {
+	return vm.config != nil && vm.config.VimMode
+}
+
+// Keymap returns the default keybindings with the config's overrides applied.
+// The error names any actions in the config that don't exist.
+func (vm *VaultManager) Keymap() (Keymap, error) {
+	keys := DefaultKeymap()
+	if vm.config == nil {
+		return keys, nil
+	}
+	err := keys.Override(vm.config.Keys)
+	return keys, err
+}
+
+// AutoSaveInterval returns how often edited SQL files are saved, or 0 if auto-save is off
+func (vm *VaultManager) AutoSaveInterval() time.Duration {
+	if vm.config == nil || vm.config.AutoSaveInterval <= 0 {
+		return 0
+	}
+	return time.Duration(vm.config.AutoSaveInterval) * time.Second
+}
+
+// HealthCheckInterval returns how often connections are pinged, or 0 if
+// they never are
+func (vm *VaultManager) HealthCheckInterval() time.Duration {
+	switch {
+	case vm.config == nil || vm.config.HealthCheckInterval == 0:
+		return defaultHealthInterval
+	case vm.config.HealthCheckInterval < 0:
+		return 0
+	}
+	return time.Duration(vm.config.HealthCheckInterval) * time.Second
+}
+
+// StatementTimeout returns how long a statement on the named connection may
+// run, or 0 for no limit. The connection's own setting wins over the global one.
+func (vm *VaultManager) StatementTimeout(name string) time.Duration {
+	if vm.config == nil {
+		return 0
+	}
+	seconds := vm.config.StatementTimeout
+	if conn, ok := vm.config.Connections[name]; ok && conn.StatementTimeout > 0 {
+		seconds = conn.StatementTimeout
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// DryRun returns true if statements that write should be rolled back on
+// the named connection, set either globally or for the connection
+func (vm *VaultManager) DryRun(name string) bool {
+	if vm.config == nil {
+		return false
+	}
+	conn, ok := vm.config.Connections[name]
+	return vm.config.DryRun || (ok && conn.DryRun)
+}
+
+// ReadOnly returns true if the named connection only allows reads
+func (vm *VaultManager) ReadOnly(name string) bool {
+	if vm.config == nil {
+		return false
+	}
+	conn, ok := vm.config.Connections[name]
+	return ok && conn.ReadOnly
+}
+
+// DefaultType returns the configured fallback database type, or "" if not set
+func (vm *VaultManager) DefaultType() string {
+	if vm.config == nil {
+		return ""
+	}
+	return vm.config.DefaultType
+}
+
 // SetSQLDir sets the SQL directory in the config and saves it
 func (vm *VaultManager) SetSQLDir(dir string) error {
 	if vm.config == nil {
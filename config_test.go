@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // Helper to set up a temp config file for testing
@@ -456,3 +457,158 @@ func TestMixedConnections(t *testing.T) {
 		t.Error("should have encrypted connections")
 	}
 }
+
+func TestConnectionNote(t *testing.T) {
+	_, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	vm := NewVaultManager()
+	if err := vm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if err := vm.SetConnectionNote("missing", "note"); err != ErrConnectionNotFound {
+		t.Errorf("expected ErrConnectionNotFound, got %v", err)
+	}
+
+	if err := vm.AddConnectionWithEncryption("local", "/tmp/test.db", "sqlite", "", false); err != nil {
+		t.Fatalf("AddConnectionWithEncryption failed: %v", err)
+	}
+	if err := vm.SetConnectionNote("local", "do not run migrations here"); err != nil {
+		t.Fatalf("SetConnectionNote failed: %v", err)
+	}
+
+	// Reload and verify the note was persisted
+	vm2 := NewVaultManager()
+	if err := vm2.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if note := vm2.GetConnectionNote("local"); note != "do not run migrations here" {
+		t.Errorf("GetConnectionNote = %q, want %q", note, "do not run migrations here")
+	}
+}
+
+func TestSessionSettings(t *testing.T) {
+	_, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	vm := NewVaultManager()
+	if err := vm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if err := vm.SetSessionSetting("missing", "time_zone", "+00:00"); err != ErrConnectionNotFound {
+		t.Errorf("expected ErrConnectionNotFound, got %v", err)
+	}
+
+	if err := vm.AddConnectionWithEncryption("local", "/tmp/test.db", "sqlite", "", false); err != nil {
+		t.Fatalf("AddConnectionWithEncryption failed: %v", err)
+	}
+	if err := vm.SetSessionSetting("local", "foreign_keys", "on"); err != nil {
+		t.Fatalf("SetSessionSetting failed: %v", err)
+	}
+	if err := vm.SetSessionSetting("local", "journal", "wal"); err != nil {
+		t.Fatalf("SetSessionSetting failed: %v", err)
+	}
+	if err := vm.SetSessionSetting("local", "journal", ""); err != nil {
+		t.Fatalf("SetSessionSetting failed: %v", err)
+	}
+
+	// Reload and verify only the remaining setting was persisted
+	vm2 := NewVaultManager()
+	if err := vm2.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	got := vm2.GetSessionSettings("local")
+	if len(got) != 1 || got["foreign_keys"] != "on" {
+		t.Errorf("GetSessionSettings = %v, want map[foreign_keys:on]", got)
+	}
+}
+
+func TestStatementTimeout(t *testing.T) {
+	vm := NewVaultManager()
+	if got := vm.StatementTimeout("prod"); got != 0 {
+		t.Errorf("StatementTimeout with no config = %v, want 0", got)
+	}
+
+	vm.config = &Config{
+		StatementTimeout: 30,
+		Connections: map[string]*Connection{
+			"prod":  {DSN: "/tmp/prod.db", StatementTimeout: 5},
+			"local": {DSN: "/tmp/local.db"},
+		},
+	}
+	tests := []struct {
+		name     string
+		expected time.Duration
+	}{
+		{"prod", 5 * time.Second},
+		{"local", 30 * time.Second},
+		{"", 30 * time.Second},
+	}
+	for _, tc := range tests {
+		if got := vm.StatementTimeout(tc.name); got != tc.expected {
+			t.Errorf("StatementTimeout(%q) = %v, want %v", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestRowLimit(t *testing.T) {
+	vm := NewVaultManager()
+	if got := vm.RowLimit(); got != defaultRowLimit {
+		t.Errorf("RowLimit with no config = %d, want %d", got, defaultRowLimit)
+	}
+	tests := []struct {
+		setting  int
+		expected int
+	}{
+		{0, defaultRowLimit},
+		{1000, 1000},
+		{-1, 0},
+	}
+	for _, tc := range tests {
+		vm.config = &Config{RowLimit: tc.setting}
+		if got := vm.RowLimit(); got != tc.expected {
+			t.Errorf("RowLimit with row_limit: %d = %d, want %d", tc.setting, got, tc.expected)
+		}
+	}
+}
+
+func TestHealthCheckInterval(t *testing.T) {
+	vm := NewVaultManager()
+	if got := vm.HealthCheckInterval(); got != defaultHealthInterval {
+		t.Errorf("HealthCheckInterval with no config = %v, want %v", got, defaultHealthInterval)
+	}
+	tests := []struct {
+		setting  int
+		expected time.Duration
+	}{
+		{0, defaultHealthInterval},
+		{5, 5 * time.Second},
+		{-1, 0},
+	}
+	for _, tc := range tests {
+		vm.config = &Config{HealthCheckInterval: tc.setting}
+		if got := vm.HealthCheckInterval(); got != tc.expected {
+			t.Errorf("HealthCheckInterval with health_check_interval: %d = %v, want %v", tc.setting, got, tc.expected)
+		}
+	}
+}
+
+func TestDryRunSetting(t *testing.T) {
+	vm := NewVaultManager()
+	if vm.DryRun("prod") {
+		t.Error("DryRun with no config should be false")
+	}
+	vm.config = &Config{Connections: map[string]*Connection{
+		"prod": {DSN: "postgres://prod", DryRun: true},
+		"dev":  {DSN: "postgres://dev"},
+	}}
+	if !vm.DryRun("prod") || vm.DryRun("dev") {
+		t.Errorf("DryRun per connection: prod = %v, dev = %v, want true, false", vm.DryRun("prod"), vm.DryRun("dev"))
+	}
+	vm.config.DryRun = true
+	if !vm.DryRun("dev") {
+		t.Error("DryRun should apply to every connection when set globally")
+	}
+}
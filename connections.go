@@ -108,7 +108,11 @@ func handleListConnections() {
 		} else {
 			encStatus = " (encrypted)"
 		}
-		fmt.Printf("  - %s%s\n", name, encStatus)
+		noteInfo := ""
+		if note := vm.GetConnectionNote(name); note != "" {
+			noteInfo = " - " + note
+		}
+		fmt.Printf("  - %s%s%s\n", name, encStatus, noteInfo)
 	}
 }
 
@@ -124,7 +128,7 @@ func handleListThemes() {
 }
 
 // handleAddConnection adds a new connection
-func handleAddConnection(name, dsn, dbType, theme string, noEncrypt bool) {
+func handleAddConnection(name, dsn, dbType, theme, note string, noEncrypt bool) {
 	if dsn == "" {
 		fmt.Fprintln(os.Stderr, "Error: -dsn is required when adding a connection")
 		os.Exit(1)
@@ -155,6 +159,7 @@ func handleAddConnection(name, dsn, dbType, theme string, noEncrypt bool) {
 			fmt.Fprintf(os.Stderr, "Failed to add connection: %v\n", err)
 			os.Exit(1)
 		}
+		saveConnectionNote(vm, name, note)
 
 		themeInfo := ""
 		if theme != "" {
@@ -201,6 +206,7 @@ func handleAddConnection(name, dsn, dbType, theme string, noEncrypt bool) {
 		fmt.Fprintf(os.Stderr, "Failed to add connection: %v\n", err)
 		os.Exit(1)
 	}
+	saveConnectionNote(vm, name, note)
 
 	themeInfo := ""
 	if theme != "" {
@@ -209,6 +215,17 @@ func handleAddConnection(name, dsn, dbType, theme string, noEncrypt bool) {
 	fmt.Printf("Connection %q saved (encrypted)%s.\n", name, themeInfo)
 }
 
+// saveConnectionNote stores the note for a newly added connection, if any
+func saveConnectionNote(vm *VaultManager, name, note string) {
+	if note == "" {
+		return
+	}
+	if err := vm.SetConnectionNote(name, note); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save note: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 // handleRemoveConnection removes a connection
 func handleRemoveConnection(name string) {
 	vm := NewVaultManager()
@@ -298,6 +315,48 @@ func handleSetSQLDir(dir string) {
 	fmt.Printf("SQL directory set to: %s\n", absDir)
 }
 
+// handleConfigPath prints the path of the config file
+func handleConfigPath() {
+	path, err := configPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve config path: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(path)
+}
+
+// handleEditConfig opens the config file in $EDITOR, creating it first if needed
+func handleEditConfig() {
+	path, err := configPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve config path: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create an empty config so the file gets restrictive permissions
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := SaveConfig(&Config{Connections: make(map[string]*Connection)}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	c := externalEditorCmd(path)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Editor error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Make sure the edited file still parses
+	if _, err := LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 // handleChangePassword changes the encryption password
 func handleChangePassword() {
 	vm := NewVaultManager()
@@ -412,6 +471,29 @@ func detectDBType(dsn string) string {
 	return ""
 }
 
+// resolveDBType determines the database type for a resolved connection.
+// An explicit type always wins; otherwise the type is detected from the DSN,
+// falling back to defaultType (the config's default_type) if detection fails.
+// If strict is set, a missing type is an error.
+func resolveDBType(info connectionInfo, strict bool, defaultType string) (string, error) {
+	if info.dbType != "" {
+		return info.dbType, nil
+	}
+
+	if strict {
+		return "", errors.New("database type required in strict mode - specify -type or store a type with the connection")
+	}
+
+	detected := detectDBType(info.dsn)
+	if detected != "" {
+		return detected, nil
+	}
+	if defaultType != "" {
+		return defaultType, nil
+	}
+	return "", errors.New("could not auto-detect database type - please specify -type flag or set default_type in config")
+}
+
 // getDriverName returns the SQL driver name for the database type
 func getDriverName(dbType string) string {
 	switch strings.ToLower(dbType) {
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SQLite column types assigned by inferColumnType
+const (
+	csvTypeInteger = "INTEGER"
+	csvTypeReal    = "REAL"
+	csvTypeText    = "TEXT"
+)
+
+// openCSVDatabase creates an in-memory SQLite database and loads the given
+// CSV/TSV file into a table named after the file. Returns the table name.
+func openCSVDatabase(path string) (*sql.DB, string, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, "", err
+	}
+	// Every connection to :memory: gets its own empty database, so pin the pool to one
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	tableName, err := importCSV(db, path)
+	if err != nil {
+		_ = db.Close()
+		return nil, "", err
+	}
+	return db, tableName, nil
+}
+
+// importCSV reads a CSV/TSV file and creates a table containing its rows.
+// The first row is used as the header; column types are inferred from the data.
+func importCSV(db *sql.DB, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = csvDelimiter(path, data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+
+	columns := csvColumnNames(records[0])
+	rows := records[1:]
+	for i, row := range rows {
+		if len(row) > len(columns) {
+			return "", fmt.Errorf("%s: line %d has %d fields, header has %d", path, i+2, len(row), len(columns))
+		}
+	}
+
+	types := make([]string, len(columns))
+	for col := range columns {
+		values := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if col < len(row) {
+				values = append(values, row[col])
+			}
+		}
+		types[col] = inferColumnType(values)
+	}
+
+	tableName := csvTableName(path)
+	q := quoteIdentifier("sqlite")
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = q + col + q + " " + types[i]
+	}
+	createSQL := fmt.Sprintf("CREATE TABLE %s (%s)", q+tableName+q, strings.Join(defs, ", "))
+	if _, err := db.Exec(createSQL); err != nil {
+		return "", fmt.Errorf("failed to create table: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", q+tableName+q, placeholders))
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	args := make([]any, len(columns))
+	for i, row := range rows {
+		for col := range columns {
+			args[col] = nil
+			if col < len(row) {
+				args[col] = csvValue(row[col], types[col])
+			}
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			_ = tx.Rollback()
+			return "", fmt.Errorf("%s: line %d: %w", path, i+2, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return tableName, nil
+}
+
+// csvDelimiter picks the field separator: tab for .tsv/.tab files, comma for
+// .csv files, otherwise whichever of tab or comma is more common in the first line
+func csvDelimiter(path string, data []byte) rune {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return '\t'
+	case ".csv":
+		return ','
+	}
+
+	firstLine := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = data[:idx]
+	}
+	if bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")) {
+		return '\t'
+	}
+	return ','
+}
+
+// csvTableName derives a SQL table name from the file name (without extension)
+func csvTableName(path string) string {
+	base := filepath.Base(path)
+	name := sanitizeIdentifier(strings.TrimSuffix(base, filepath.Ext(base)))
+	if name == "" {
+		return "data"
+	}
+	return name
+}
+
+// csvColumnNames turns header fields into unique column names.
+// Blank headers become column_N and duplicates get a numeric suffix.
+func csvColumnNames(header []string) []string {
+	names := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, field := range header {
+		name := sanitizeIdentifier(field)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		unique := name
+		for n := 2; seen[strings.ToLower(unique)]; n++ {
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		seen[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+	return names
+}
+
+// sanitizeIdentifier replaces anything other than letters, digits and
+// underscores so the name can be used unquoted in queries
+func sanitizeIdentifier(name string) string {
+	name = strings.TrimSpace(name)
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	result := b.String()
+	if result != "" && result[0] >= '0' && result[0] <= '9' {
+		result = "_" + result
+	}
+	return result
+}
+
+// inferColumnType returns INTEGER if every non-empty value is an integer,
+// REAL if every non-empty value is numeric, and TEXT otherwise.
+// Integers with leading zeros (zip codes, IDs) are kept as TEXT.
+func inferColumnType(values []string) string {
+	result := csvTypeInteger
+	sawValue := false
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		sawValue = true
+		if !isNumericLiteral(v) {
+			return csvTypeText
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if hasLeadingZero(v) {
+				return csvTypeText
+			}
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			result = csvTypeReal
+			continue
+		}
+		return csvTypeText
+	}
+	if !sawValue {
+		return csvTypeText
+	}
+	return result
+}
+
+// isNumericLiteral rejects strings that strconv accepts but that aren't
+// plain decimal numbers, such as "Inf", "NaN", hex floats and underscores
+func isNumericLiteral(v string) bool {
+	hasDigit := false
+	for _, r := range v {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E':
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
+
+// hasLeadingZero reports whether an integer string has a significant leading zero
+func hasLeadingZero(v string) bool {
+	v = strings.TrimLeft(v, "+-")
+	return len(v) > 1 && v[0] == '0'
+}
+
+// csvValue converts a raw field to the Go value stored for the column type.
+// Empty fields become NULL.
+func csvValue(raw string, colType string) any {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil
+	}
+	switch colType {
+	case csvTypeInteger:
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return n
+		}
+	case csvTypeReal:
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return f
+		}
+	}
+	return raw
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestInferColumnType tests column type inference from CSV values
+func TestInferColumnType(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"integers", []string{"1", "-2", "300"}, csvTypeInteger},
+		{"integers with blanks", []string{"1", "", " 2 "}, csvTypeInteger},
+		{"mixed int and float", []string{"1", "2.5", "3e2"}, csvTypeReal},
+		{"text", []string{"1", "two"}, csvTypeText},
+		{"leading zeros kept as text", []string{"02134", "90210"}, csvTypeText},
+		{"single zero is integer", []string{"0", "10"}, csvTypeInteger},
+		{"inf and nan are text", []string{"Inf", "NaN"}, csvTypeText},
+		{"all empty", []string{"", ""}, csvTypeText},
+		{"no values", nil, csvTypeText},
+		{"sign only", []string{"-"}, csvTypeText},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := inferColumnType(tc.values)
+			if result != tc.expected {
+				t.Errorf("inferColumnType(%q) = %q, want %q", tc.values, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestCSVTableName tests table names derived from file paths
+func TestCSVTableName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"data.csv", "data"},
+		{"/tmp/sales-2024.tsv", "sales_2024"},
+		{"2024 report.csv", "_2024_report"},
+		{"noext", "noext"},
+		{".csv", "data"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			result := csvTableName(tc.path)
+			if result != tc.expected {
+				t.Errorf("csvTableName(%q) = %q, want %q", tc.path, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestCSVColumnNames tests header sanitizing and de-duplication
+func TestCSVColumnNames(t *testing.T) {
+	header := []string{"id", "First Name", "", "ID", "id"}
+	expected := []string{"id", "First_Name", "column_3", "ID_2", "id_3"}
+
+	result := csvColumnNames(header)
+	if len(result) != len(expected) {
+		t.Fatalf("csvColumnNames() returned %d names, want %d", len(result), len(expected))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("column %d = %q, want %q", i, result[i], expected[i])
+		}
+	}
+}
+
+// TestCSVDelimiter tests delimiter selection by extension and sniffing
+func TestCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		data     string
+		expected rune
+	}{
+		{"csv extension", "a.csv", "a\tb\tc\n", ','},
+		{"tsv extension", "a.tsv", "a,b,c\n", '\t'},
+		{"sniff tabs", "a.txt", "a\tb\tc\n1,2\t3\t4\n", '\t'},
+		{"sniff commas", "a.txt", "a,b,c\n", ','},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := csvDelimiter(tc.path, []byte(tc.data))
+			if result != tc.expected {
+				t.Errorf("csvDelimiter(%q) = %q, want %q", tc.path, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestOpenCSVDatabase tests loading a file and querying it
+func TestOpenCSVDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.tsv")
+	content := "\xef\xbb\xbfid\tname\tscore\n1\tAlice\t9.5\n2\tBob\t\n3\tCarol\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, table, err := openCSVDatabase(path)
+	if err != nil {
+		t.Fatalf("openCSVDatabase() error: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if table != "people" {
+		t.Errorf("table = %q, want %q", table, "people")
+	}
+
+	var count, nullScores int
+	if err := db.QueryRow("SELECT COUNT(*), SUM(score IS NULL) FROM people").Scan(&count, &nullScores); err != nil {
+		t.Fatalf("query error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+	if nullScores != 2 {
+		t.Errorf("null scores = %d, want 2", nullScores)
+	}
+
+	var idType, scoreType string
+	if err := db.QueryRow("SELECT typeof(id), typeof(score) FROM people WHERE name = 'Alice'").Scan(&idType, &scoreType); err != nil {
+		t.Fatalf("query error: %v", err)
+	}
+	if idType != "integer" || scoreType != "real" {
+		t.Errorf("types = (%s, %s), want (integer, real)", idType, scoreType)
+	}
+}
+
+// TestOpenCSVDatabaseErrors tests files that cannot be loaded
+func TestOpenCSVDatabaseErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"too many fields", "a,b\n1,2,3\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, "bad.csv")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if db, _, err := openCSVDatabase(path); err == nil {
+				_ = db.Close()
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, _, err := openCSVDatabase(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("expected error for missing file, got nil")
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DDLView holds the state of the read-only CREATE statement viewer
+type DDLView struct {
+	table       string
+	ddl         string
+	scroll      int
+	returnFocus focusState // focus to restore when the viewer closes
+}
+
+// tableRefPattern finds the first table a statement reads from or writes to
+var tableRefPattern = regexp.MustCompile("(?i)\\b(?:FROM|JOIN|UPDATE|INTO|TABLE)\\s+([`\"\\w.$]+)")
+
+// referencedTable returns the first table named in a query, or "" if none
+func referencedTable(query string) string {
+	match := tableRefPattern.FindStringSubmatch(query)
+	if match == nil {
+		return ""
+	}
+	return strings.NewReplacer("`", "", `"`, "").Replace(match[1])
+}
+
+// tableDDL returns the CREATE statement(s) for a table or view
+func tableDDL(db *sql.DB, dbType string, table string) (string, error) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return mysqlTableDDL(db, table)
+	case "postgres", "postgresql", "pg":
+		return postgresTableDDL(db, table)
+	default:
+		return sqliteTableDDL(db, table)
+	}
+}
+
+// schemaDDL returns the CREATE statements of all tables, followed by all
+// views (which may depend on them), separated by blank lines
+func schemaDDL(db *sql.DB, dbType string, tables []SchemaTable) (string, error) {
+	var tableDDLs, viewDDLs []string
+	for _, table := range tables {
+		ddl, err := tableDDL(db, dbType, table.Name)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", table.Name, err)
+		}
+		if table.IsView {
+			viewDDLs = append(viewDDLs, ddl)
+		} else {
+			tableDDLs = append(tableDDLs, ddl)
+		}
+	}
+	return strings.Join(append(tableDDLs, viewDDLs...), "\n\n"), nil
+}
+
+// exportSchemaDDL writes the CREATE statements of every table and view to
+// a new timestamped file in sqlDir, e.g. shop_schema_20240102_150405.sql,
+// and returns its path
+func exportSchemaDDL(db *sql.DB, dbType string, tables []SchemaTable, sqlDir string, dbName string) (string, error) {
+	ddl, err := schemaDDL(db, dbType, tables)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(sqlDir, 0755); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	path := filepath.Join(sqlDir, sanitizeFilename(dbName)+"_schema_"+now.Format("20060102_150405")+".sql")
+	header := fmt.Sprintf("-- Schema of %s (%s), exported %s\n\n", dbName, dbType, now.Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(header+ddl+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// objectDDL returns the definition of a trigger, procedure or function
+func objectDDL(db *sql.DB, dbType string, obj SchemaObject) (string, error) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		// SHOW CREATE PROCEDURE/FUNCTION/TRIGGER all put the statement third
+		return mysqlShowCreate(db, "SHOW CREATE "+strings.ToUpper(obj.Kind)+" "+quoteNameIfNeeded(obj.Name, "mysql"), 2, obj.Kind+" "+obj.Name)
+	case "postgres", "postgresql", "pg":
+		if obj.Kind == "trigger" {
+			var def string
+			err := db.QueryRow(`SELECT pg_get_triggerdef(t.oid, true) FROM pg_trigger t
+				JOIN pg_class c ON c.oid = t.tgrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE n.nspname = current_schema() AND t.tgname = $1 AND c.relname = $2`, obj.Name, obj.Table).Scan(&def)
+			if err != nil {
+				return "", err
+			}
+			return def + ";", nil
+		}
+		// One definition per overload
+		defs, err := queryStrings(db, `SELECT pg_get_functiondef(p.oid) FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = current_schema() AND p.proname = '`+escapeSQLString(obj.Name)+`'
+			ORDER BY p.oid`)
+		if err != nil {
+			return "", err
+		}
+		if len(defs) == 0 {
+			return "", fmt.Errorf("%s %s not found", obj.Kind, obj.Name)
+		}
+		for i, def := range defs {
+			defs[i] = strings.TrimSpace(def) + ";"
+		}
+		return strings.Join(defs, "\n\n"), nil
+	default:
+		var def string
+		err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'trigger' AND name = ?", obj.Name).Scan(&def)
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%s %s not found", obj.Kind, obj.Name)
+		}
+		if err != nil {
+			return "", err
+		}
+		return def + ";", nil
+	}
+}
+
+// mysqlTableDDL uses SHOW CREATE TABLE, which also works for views
+func mysqlTableDDL(db *sql.DB, table string) (string, error) {
+	// Tables return (Table, Create Table); views return four columns
+	return mysqlShowCreate(db, "SHOW CREATE TABLE "+quoteNameIfNeeded(table, "mysql"), 1, "table "+table)
+}
+
+// mysqlShowCreate runs a SHOW CREATE statement and returns the given column
+// of its single row, which holds the CREATE statement. what names the object
+// in the error when there is no row.
+func mysqlShowCreate(db *sql.DB, stmt string, column int, what string) (string, error) {
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s not found", what)
+	}
+	values := make([]sql.RawBytes, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return "", err
+	}
+	if len(values) <= column {
+		return "", fmt.Errorf("unexpected %s result", stmt)
+	}
+	return string(values[column]) + ";", nil
+}
+
+// sqliteTableDDL reads the stored CREATE statements for a table and its
+// indexes and triggers from sqlite_master
+func sqliteTableDDL(db *sql.DB, table string) (string, error) {
+	rows, err := db.Query(`SELECT sql FROM sqlite_master
+		WHERE tbl_name = ? COLLATE NOCASE AND sql IS NOT NULL
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 0 WHEN 'index' THEN 1 ELSE 2 END, name`, table)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var statements []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return "", err
+		}
+		statements = append(statements, stmt+";")
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(statements) == 0 {
+		return "", fmt.Errorf("table %s not found", table)
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
+
+// postgresTableDDL reconstructs a CREATE statement from pg_catalog, since
+// PostgreSQL has no SHOW CREATE TABLE
+func postgresTableDDL(db *sql.DB, table string) (string, error) {
+	name := quoteNameIfNeeded(table, "postgres")
+
+	var relkind string
+	if err := db.QueryRow("SELECT relkind::text FROM pg_class WHERE oid = $1::regclass", name).Scan(&relkind); err != nil {
+		return "", err
+	}
+	if relkind == "v" || relkind == "m" {
+		var def string
+		if err := db.QueryRow("SELECT pg_get_viewdef($1::regclass, true)", name).Scan(&def); err != nil {
+			return "", err
+		}
+		kind := "VIEW"
+		if relkind == "m" {
+			kind = "MATERIALIZED VIEW"
+		}
+		return fmt.Sprintf("CREATE %s %s AS\n%s", kind, name, strings.TrimSpace(def)), nil
+	}
+
+	var columns []string
+	rows, err := db.Query(`SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, name)
+	if err != nil {
+		return "", err
+	}
+	for rows.Next() {
+		var colName, colType string
+		var notNull bool
+		var def sql.NullString
+		if err := rows.Scan(&colName, &colType, &notNull, &def); err != nil {
+			_ = rows.Close()
+			return "", err
+		}
+		columns = append(columns, columnDefinition(SchemaColumn{
+			Name:     quoteNameIfNeeded(colName, "postgres"),
+			Type:     colType,
+			Nullable: !notNull,
+			Default:  def,
+		}))
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var constraints []string
+	rows, err = db.Query(`SELECT conname, pg_get_constraintdef(oid) FROM pg_constraint
+		WHERE conrelid = $1::regclass
+		ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'f' THEN 2 ELSE 3 END, conname`, name)
+	if err != nil {
+		return "", err
+	}
+	for rows.Next() {
+		var conName, def string
+		if err := rows.Scan(&conName, &def); err != nil {
+			_ = rows.Close()
+			return "", err
+		}
+		constraints = append(constraints, "CONSTRAINT "+quoteNameIfNeeded(conName, "postgres")+" "+def)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	// Indexes not already created by a constraint
+	var indexes []string
+	rows, err = db.Query(`SELECT pg_get_indexdef(i.indexrelid) FROM pg_index i
+		WHERE i.indrelid = $1::regclass
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid)
+		ORDER BY i.indexrelid`, name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var def string
+		if err := rows.Scan(&def); err != nil {
+			return "", err
+		}
+		indexes = append(indexes, def+";")
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	ddl := buildCreateTable(name, columns, constraints)
+	if len(indexes) > 0 {
+		ddl += "\n\n" + strings.Join(indexes, "\n")
+	}
+	return ddl, nil
+}
+
+// columnDefinition renders a column as it appears in a CREATE TABLE statement
+func columnDefinition(col SchemaColumn) string {
+	def := col.Name + " " + col.Type
+	if !col.Nullable {
+		def += " NOT NULL"
+	}
+	if col.Default.Valid {
+		def += " DEFAULT " + col.Default.String
+	}
+	return def
+}
+
+// buildCreateTable assembles a CREATE TABLE statement with one column or
+// constraint per line
+func buildCreateTable(name string, columns []string, constraints []string) string {
+	parts := append(append([]string{}, columns...), constraints...)
+	return fmt.Sprintf("CREATE TABLE %s (\n    %s\n);", name, strings.Join(parts, ",\n    "))
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReferencedTable tests finding the table named in a query
+func TestReferencedTable(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM users WHERE id = 1", "users"},
+		{"select id from `orders` o", "orders"},
+		{`SELECT * FROM "public"."Users"`, "public.Users"},
+		{"UPDATE accounts SET x = 1", "accounts"},
+		{"INSERT INTO logs (msg) VALUES ('a')", "logs"},
+		{"SELECT 1", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			result := referencedTable(tc.query)
+			if result != tc.expected {
+				t.Errorf("referencedTable(%q) = %q, want %q", tc.query, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestSQLiteTableDDL tests reading the CREATE statements for a SQLite table
+func TestSQLiteTableDDL(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("CREATE INDEX idx_users_email ON users (email)"); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	ddl, err := tableDDL(db, "sqlite", "USERS")
+	if err != nil {
+		t.Fatalf("tableDDL() error: %v", err)
+	}
+	if !strings.HasPrefix(ddl, "CREATE TABLE users") {
+		t.Errorf("DDL should start with the CREATE TABLE, got:\n%s", ddl)
+	}
+	if !strings.Contains(ddl, "CREATE INDEX idx_users_email ON users (email);") {
+		t.Errorf("DDL should include the index, got:\n%s", ddl)
+	}
+
+	if _, err := tableDDL(db, "sqlite", "missing"); err == nil {
+		t.Error("expected error for missing table, got nil")
+	}
+}
+
+// TestExportSchemaDDL tests writing a schema snapshot with tables before views
+func TestExportSchemaDDL(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE VIEW active_users AS SELECT * FROM users WHERE is_active = 1",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id))",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up schema: %v", err)
+		}
+	}
+	tables, err := NewSchemaCache(db, "sqlite").Tables()
+	if err != nil {
+		t.Fatalf("Tables() error: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "sql")
+	path, err := exportSchemaDDL(db, "sqlite", tables, dir, "shop")
+	if err != nil {
+		t.Fatalf("exportSchemaDDL() error: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "shop_schema_") {
+		t.Errorf("exportSchemaDDL() path = %s, want shop_schema_*.sql in %s", path, dir)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	dump := string(data)
+	orders := strings.Index(dump, "CREATE TABLE orders")
+	users := strings.Index(dump, "CREATE TABLE users")
+	view := strings.Index(dump, "CREATE VIEW active_users")
+	if orders < 0 || users < 0 || view < 0 {
+		t.Fatalf("export is missing statements:\n%s", dump)
+	}
+	if view < orders || view < users {
+		t.Errorf("views should follow tables:\n%s", dump)
+	}
+	if !strings.HasPrefix(dump, "-- Schema of shop (sqlite)") {
+		t.Errorf("export should start with a header comment, got:\n%s", dump)
+	}
+}
+
+// TestBuildCreateTable tests assembling a reconstructed CREATE TABLE statement
+func TestBuildCreateTable(t *testing.T) {
+	columns := []string{
+		columnDefinition(SchemaColumn{Name: "id", Type: "integer", Default: sql.NullString{String: "nextval('t_id_seq'::regclass)", Valid: true}}),
+		columnDefinition(SchemaColumn{Name: "note", Type: "text", Nullable: true}),
+	}
+	constraints := []string{"CONSTRAINT t_pkey PRIMARY KEY (id)"}
+
+	expected := "CREATE TABLE t (\n" +
+		"    id integer NOT NULL DEFAULT nextval('t_id_seq'::regclass),\n" +
+		"    note text,\n" +
+		"    CONSTRAINT t_pkey PRIMARY KEY (id)\n" +
+		");"
+	if result := buildCreateTable("t", columns, constraints); result != expected {
+		t.Errorf("buildCreateTable() =\n%s\nwant\n%s", result, expected)
+	}
+}
+
+// TestQuoteNameIfNeeded tests quoting table names for the DDL queries,
+// each part of a schema-qualified name on its own
+func TestQuoteNameIfNeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		expected string
+	}{
+		{"users", "postgres", "users"},
+		{"Users", "postgres", `"Users"`},
+		{"public.Users", "postgres", `public."Users"`},
+		{"my schema.users", "postgres", `"my schema".users`},
+		{"app.Users", "mysql", "app.Users"},
+		{"order items", "mysql", "`order items`"},
+		{`a"b`, "sqlite", `"a""b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteNameIfNeeded(tt.name, tt.dbType); got != tt.expected {
+				t.Errorf("quoteNameIfNeeded(%q, %q) = %q, want %q", tt.name, tt.dbType, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// writesData reports whether a statement may change data or schema:
+// anything but a read, and reads that write through a data-modifying CTE
+// (WITH d AS (DELETE ... RETURNING *) SELECT ...) or run one with EXPLAIN
+// ANALYZE
+func writesData(stmt string) bool {
+	if !IsSelectStatement(stmt) {
+		return true
+	}
+	var words []string
+	for _, tok := range tokenizeForFormat(stmt) {
+		if tok.kind == fmtWord {
+			words = append(words, strings.ToUpper(tok.text))
+		}
+	}
+	if len(words) == 0 || (words[0] != "WITH" && words[0] != "EXPLAIN") {
+		return false
+	}
+	if words[0] == "EXPLAIN" && !slices.Contains(words, "ANALYZE") {
+		return false // only planned, not run
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "INSERT", "UPDATE", "DELETE", "MERGE":
+			return true
+		}
+	}
+	return false
+}
+
+// commitsImplicitly reports whether MySQL commits the transaction when it
+// runs stmt, so a dry run couldn't roll it back
+func commitsImplicitly(stmt, dbType string) bool {
+	if getDriverName(dbType) != "mysql" {
+		return false
+	}
+	fields := strings.Fields(skipLeadingComments(stmt, true))
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CREATE", "ALTER", "DROP", "RENAME", "TRUNCATE", "GRANT", "REVOKE", "LOCK", "UNLOCK":
+		return true
+	}
+	return false
+}
+
+// dryRunSavepoint marks where a dry run inside an open transaction starts,
+// so its changes can be rolled back without ending the transaction
+const dryRunSavepoint = "dibber_dry_run"
+
+// dryRun runs a statement that writes in a transaction that's always rolled
+// back, to report what it would do without changing anything. With tx, the
+// tab's open transaction, it runs in that under a savepoint instead, so it
+// sees the transaction's changes and isn't blocked by its locks. Transaction
+// statements are skipped, and statements MySQL would commit aren't run.
+func dryRun(ctx context.Context, db *sql.DB, tx *sql.Tx, dbType, stmt string, args ...any) *QueryResult {
+	if transactionCommand(stmt) != "" {
+		return &QueryResult{Executed: true, RowsAffected: -1, DryRun: true}
+	}
+	if commitsImplicitly(stmt, dbType) {
+		return &QueryResult{Error: errors.New("dry run: MySQL commits this statement as it runs, so it wasn't run"), DryRun: true}
+	}
+
+	if tx != nil {
+		return dryRunInTx(ctx, tx, stmt, args...)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return &QueryResult{Error: err, DryRun: true}
+	}
+	defer func() { _ = tx.Rollback() }()
+	return dryRunStatement(ctx, tx, stmt, args...)
+}
+
+// dryRunInTx runs a statement in an open transaction, rolling back to a
+// savepoint taken before it so the transaction's earlier changes stay
+func dryRunInTx(ctx context.Context, tx *sql.Tx, stmt string, args ...any) *QueryResult {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+dryRunSavepoint); err != nil {
+		return &QueryResult{Error: err, DryRun: true}
+	}
+	result := dryRunStatement(ctx, tx, stmt, args...)
+	// Even once the statement's been cancelled or timed out
+	if _, err := tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+dryRunSavepoint); err != nil {
+		return &QueryResult{Error: fmt.Errorf("dry run: rolling back its changes failed: %w", err), DryRun: true}
+	}
+	_, _ = tx.ExecContext(context.Background(), "RELEASE SAVEPOINT "+dryRunSavepoint)
+	return result
+}
+
+// dryRunStatement runs a statement for a dry run in tx
+func dryRunStatement(ctx context.Context, tx *sql.Tx, stmt string, args ...any) *QueryResult {
+	var result *QueryResult
+	if ReturnsRows(stmt) {
+		result = executeQuery(ctx, tx, stmt, args...)
+	} else {
+		result = executeStatement(ctx, tx, stmt, args...)
+	}
+	result.DryRun = true
+	return result
+}
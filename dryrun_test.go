@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// TestWritesData tests telling statements that may write from reads
+func TestWritesData(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want bool
+	}{
+		{"SELECT * FROM users", false},
+		{"UPDATE users SET age = 31", true},
+		{"DELETE FROM users WHERE id = 1", true},
+		{"CREATE TABLE t (id INT)", true},
+		{"BEGIN", true},
+		{"WITH recent AS (SELECT * FROM users) SELECT * FROM recent", false},
+		{"WITH gone AS (DELETE FROM users RETURNING *) SELECT count(*) FROM gone", true},
+		{"EXPLAIN DELETE FROM users", false},
+		{"EXPLAIN ANALYZE DELETE FROM users", true},
+		{"EXPLAIN ANALYZE SELECT * FROM users", false},
+		{"SELECT 'DELETE' FROM users", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			if got := writesData(tt.stmt); got != tt.want {
+				t.Errorf("writesData(%q) = %v, want %v", tt.stmt, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCommitsImplicitly tests spotting MySQL statements that can't be rolled back
+func TestCommitsImplicitly(t *testing.T) {
+	tests := []struct {
+		stmt   string
+		dbType string
+		want   bool
+	}{
+		{"CREATE TABLE t (id INT)", "mysql", true},
+		{"# setup\nTRUNCATE t", "mysql", true},
+		{"UPDATE t SET id = 1", "mysql", false},
+		{"CREATE TABLE t (id INT)", "postgres", false},
+		{"DROP TABLE t", "sqlite", false},
+	}
+
+	for _, tt := range tests {
+		if got := commitsImplicitly(tt.stmt, tt.dbType); got != tt.want {
+			t.Errorf("commitsImplicitly(%q, %q) = %v, want %v", tt.stmt, tt.dbType, got, tt.want)
+		}
+	}
+}
+
+// TestDryRun tests that a dry run counts the affected rows and changes nothing
+func TestDryRun(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "dry.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2), (3)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	ctx := context.Background()
+
+	result := dryRun(ctx, db, nil, "sqlite", "DELETE FROM t WHERE id > 1")
+	if result.Error != nil {
+		t.Fatalf("dryRun() error = %v", result.Error)
+	}
+	if !result.DryRun || result.RowsAffected != 2 {
+		t.Errorf("dryRun() = %d rows affected (dry run %v), want 2", result.RowsAffected, result.DryRun)
+	}
+	if got := resultOutcome(result); got != "Dry run: 2 row(s) would be affected" {
+		t.Errorf("resultOutcome() = %q", got)
+	}
+
+	result = dryRun(ctx, db, nil, "sqlite", "DROP TABLE t")
+	if result.Error != nil {
+		t.Fatalf("dryRun() error = %v", result.Error)
+	}
+	if rows := executeQuery(ctx, db, "SELECT * FROM t"); rows.Error != nil || len(rows.Rows) != 3 {
+		t.Errorf("after dry runs: %d rows (error %v), want 3", len(rows.Rows), rows.Error)
+	}
+
+	// Transaction statements are skipped, not run
+	if result := dryRun(ctx, db, nil, "sqlite", "COMMIT"); result.Error != nil || !result.Executed {
+		t.Errorf("dryRun(COMMIT) = %+v, want a skipped statement", result)
+	}
+}
+
+// TestDryRunInTransaction tests that a dry run with a transaction open runs
+// in it, seeing its changes, and rolls back only its own
+func TestDryRunInTransaction(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "dry.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2), (3)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	ctx := context.Background()
+	tx, err := beginTx(db)
+	if err != nil {
+		t.Fatalf("beginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("INSERT INTO t VALUES (4)"); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	// The transaction holds the write lock, which a transaction of the dry
+	// run's own would wait on
+	result := dryRun(ctx, db, tx, "sqlite", "DELETE FROM t WHERE id > 1")
+	if result.Error != nil {
+		t.Fatalf("dryRun() error = %v", result.Error)
+	}
+	if !result.DryRun || result.RowsAffected != 3 {
+		t.Errorf("dryRun() = %d rows affected (dry run %v), want 3 with the uncommitted row", result.RowsAffected, result.DryRun)
+	}
+	if rows := executeQuery(ctx, tx, "SELECT * FROM t"); rows.Error != nil || len(rows.Rows) != 4 {
+		t.Errorf("in the transaction after the dry run: %d rows (error %v), want 4", len(rows.Rows), rows.Error)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if rows := executeQuery(ctx, db, "SELECT * FROM t"); rows.Error != nil || len(rows.Rows) != 4 {
+		t.Errorf("after committing: %d rows (error %v), want 4", len(rows.Rows), rows.Error)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dsnDatabaseName returns the raw database name component of a DSN, or "" if there is none
+func dsnDatabaseName(dsn, dbType string) string {
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		if isPostgresURL(dsn) {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return ""
+			}
+			return strings.TrimPrefix(u.Path, "/")
+		}
+		for _, part := range strings.Fields(dsn) {
+			if strings.HasPrefix(part, "dbname=") {
+				return strings.TrimPrefix(part, "dbname=")
+			}
+		}
+		return ""
+
+	case "mysql":
+		_, dbName, _, ok := splitMySQLDSN(dsn)
+		if !ok {
+			return ""
+		}
+		return dbName
+	}
+
+	return ""
+}
+
+// replaceDatabaseInDSN rewrites only the database component of a DSN,
+// keeping the server, credentials and parameters intact
+func replaceDatabaseInDSN(dsn, dbType, database string) (string, error) {
+	if database == "" {
+		return "", errors.New("database name is required")
+	}
+
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		if isPostgresURL(dsn) {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse DSN: %w", err)
+			}
+			u.Path = "/" + database
+			u.RawPath = ""
+			return u.String(), nil
+		}
+
+		// Key=value format - replace dbname or append it
+		parts := strings.Fields(dsn)
+		replaced := false
+		for i, part := range parts {
+			if strings.HasPrefix(part, "dbname=") {
+				parts[i] = "dbname=" + database
+				replaced = true
+			}
+		}
+		if !replaced {
+			parts = append(parts, "dbname="+database)
+		}
+		return strings.Join(parts, " "), nil
+
+	case "mysql":
+		prefix, _, params, ok := splitMySQLDSN(dsn)
+		if !ok {
+			return "", errors.New("could not find database name in MySQL DSN")
+		}
+		return prefix + "/" + database + params, nil
+
+	case "sqlite", "sqlite3":
+		return "", errors.New("sqlite has no server - open a different database file instead")
+	}
+
+	return "", fmt.Errorf("unsupported database type %q", dbType)
+}
+
+// replaceSearchPathInDSN sets the search_path run-time parameter of a
+// PostgreSQL DSN, so every pooled connection uses the given schema first
+func replaceSearchPathInDSN(dsn, schema string) (string, error) {
+	if schema == "" {
+		return "", errors.New("schema name is required")
+	}
+
+	if isPostgresURL(dsn) {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse DSN: %w", err)
+		}
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	// Key=value format - replace search_path or append it
+	parts := strings.Fields(dsn)
+	replaced := false
+	for i, part := range parts {
+		if strings.HasPrefix(part, "search_path=") {
+			parts[i] = "search_path=" + schema
+			replaced = true
+		}
+	}
+	if !replaced {
+		parts = append(parts, "search_path="+schema)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// withStatementTimeout adds a server-side statement timeout to a DSN, so the
+// database stops a runaway statement itself rather than only dibber giving up
+// on it. PostgreSQL gets statement_timeout; MySQL gets max_execution_time,
+// which only limits SELECTs. A timeout the DSN already sets is kept, and
+// DSNs for other databases are returned unchanged.
+func withStatementTimeout(dsn, dbType string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return dsn
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		if isPostgresURL(dsn) {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return dsn
+			}
+			q := u.Query()
+			if q.Has("statement_timeout") {
+				return dsn
+			}
+			q.Set("statement_timeout", ms)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+		for _, part := range strings.Fields(dsn) {
+			if strings.HasPrefix(part, "statement_timeout=") {
+				return dsn
+			}
+		}
+		return dsn + " statement_timeout=" + ms
+
+	case "mysql":
+		prefix, dbName, params, ok := splitMySQLDSN(dsn)
+		if !ok || strings.Contains(params, "max_execution_time=") {
+			return dsn
+		}
+		if params == "" {
+			params = "?max_execution_time=" + ms
+		} else {
+			params += "&max_execution_time=" + ms
+		}
+		return prefix + "/" + dbName + params
+	}
+
+	return dsn
+}
+
+// withReadOnly makes a read-only connection's sessions read-only on the
+// server too, where the DSN can say so: PostgreSQL gets
+// default_transaction_read_only and SQLite the query_only pragma. MySQL and
+// MariaDB name the setting differently, and an unknown one fails to
+// connect, so MySQL DSNs are returned unchanged.
+func withReadOnly(dsn, dbType string, readOnly bool) string {
+	if !readOnly {
+		return dsn
+	}
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		if isPostgresURL(dsn) {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return dsn
+			}
+			q := u.Query()
+			q.Set("default_transaction_read_only", "on")
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+		var parts []string
+		for _, part := range strings.Fields(dsn) {
+			if !strings.HasPrefix(part, "default_transaction_read_only=") {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(append(parts, "default_transaction_read_only=on"), " ")
+
+	case "sqlite", "sqlite3":
+		path, params, _ := strings.Cut(dsn, "?")
+		q, err := url.ParseQuery(params)
+		if err != nil {
+			return dsn
+		}
+		q.Set("_query_only", "true")
+		return path + "?" + q.Encode()
+	}
+
+	return dsn
+}
+
+// withSessionSettings adds a connection's saved session settings to a DSN,
+// so every connection the pool opens starts with them. Settings the
+// database type doesn't offer are ignored, and ones the DSN already sets are
+// replaced.
+func withSessionSettings(dsn, dbType string, settings map[string]string) string {
+	for _, s := range sessionSettings(dbType) {
+		value, ok := settings[s.name]
+		if !ok {
+			continue
+		}
+		switch getDriverName(dbType) {
+		case "pgx":
+			if isPostgresURL(dsn) {
+				u, err := url.Parse(dsn)
+				if err != nil {
+					return dsn
+				}
+				q := u.Query()
+				q.Set(s.param, value)
+				u.RawQuery = q.Encode()
+				dsn = u.String()
+				continue
+			}
+			// Replace only the setting, leaving the rest as written: quoted
+			// values such as password='a  b' may hold runs of spaces
+			for start, end := keywordValueSpan(dsn, s.param); start >= 0; start, end = keywordValueSpan(dsn, s.param) {
+				dsn = dsn[:start] + strings.TrimLeft(dsn[end:], " \t\n\r")
+			}
+			// Quote the value in case it has spaces: search_path='app, public'
+			quoted := "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+			if dsn = strings.TrimSpace(dsn); dsn != "" {
+				dsn += " "
+			}
+			dsn += s.param + "=" + quoted
+
+		case "mysql":
+			// The driver runs SET <param>=<value> as given, so the value is
+			// quoted as an SQL string
+			prefix, dbName, params, ok := splitMySQLDSN(dsn)
+			if !ok {
+				return dsn
+			}
+			var kept []string
+			for _, param := range strings.Split(strings.TrimPrefix(params, "?"), "&") {
+				if param != "" && !strings.HasPrefix(param, s.param+"=") {
+					kept = append(kept, param)
+				}
+			}
+			quoted := "'" + strings.ReplaceAll(value, "'", "''") + "'"
+			kept = append(kept, s.param+"="+url.QueryEscape(quoted))
+			dsn = prefix + "/" + dbName + "?" + strings.Join(kept, "&")
+
+		case "sqlite3":
+			path, params, _ := strings.Cut(dsn, "?")
+			q, err := url.ParseQuery(params)
+			if err != nil {
+				return dsn
+			}
+			q.Set(s.param, value)
+			dsn = path + "?" + q.Encode()
+		}
+	}
+	return dsn
+}
+
+// keywordValueSpan returns where param's key=value pair starts and ends in
+// a PostgreSQL keyword/value DSN, reading a single-quoted value (with \' and
+// \\ escapes) as one even if it has spaces, or -1, -1 if it isn't set
+func keywordValueSpan(dsn, param string) (start, end int) {
+	isSpace := func(i int) bool { return i < len(dsn) && strings.ContainsRune(" \t\n\r", rune(dsn[i])) }
+	skipSpace := func(i int) int {
+		for isSpace(i) {
+			i++
+		}
+		return i
+	}
+	for i := skipSpace(0); i < len(dsn); i = skipSpace(i) {
+		start := i
+		for i < len(dsn) && dsn[i] != '=' && !isSpace(i) {
+			i++
+		}
+		key := dsn[start:i]
+		// libpq allows spaces around the =
+		if j := skipSpace(i); j < len(dsn) && dsn[j] == '=' {
+			i = skipSpace(j + 1)
+			if i < len(dsn) && dsn[i] == '\'' {
+				for i++; i < len(dsn) && dsn[i] != '\''; i++ {
+					if dsn[i] == '\\' {
+						i++
+					}
+				}
+				i = min(i+1, len(dsn))
+			} else {
+				for i < len(dsn) && !isSpace(i) {
+					i++
+				}
+			}
+		}
+		if key == param {
+			return start, i
+		}
+	}
+	return -1, -1
+}
+
+// isPostgresURL returns true if the DSN is in postgres:// URL form
+func isPostgresURL(dsn string) bool {
+	lower := strings.ToLower(dsn)
+	return strings.HasPrefix(lower, "postgres://") || strings.HasPrefix(lower, "postgresql://")
+}
+
+// splitMySQLDSN splits a MySQL DSN (user:pass@tcp(host)/dbname?params) into
+// the part before the database, the database name, and the "?params" suffix
+func splitMySQLDSN(dsn string) (prefix, dbName, params string, ok bool) {
+	rest := dsn
+	if idx := strings.Index(rest, "?"); idx != -1 {
+		params = rest[idx:]
+		rest = rest[:idx]
+	}
+	idx := strings.LastIndex(rest, "/")
+	if idx == -1 {
+		return "", "", "", false
+	}
+	return rest[:idx], rest[idx+1:], params, true
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ERView holds the state of the entity-relationship overview
+type ERView struct {
+	lines  []string
+	scroll int
+}
+
+// erDiagramLines renders tables and their foreign key relationships as an
+// indented tree: each related table lists its outgoing references (──▶) and
+// the columns of other tables that reference it (◀──). Tables without any
+// relationships are listed together at the end.
+func erDiagramLines(tables []SchemaTable, fks map[string][]SchemaForeignKey) []string {
+	type edge struct{ from, to string }
+	outgoing := make(map[string][]edge)
+	incoming := make(map[string][]edge)
+	for _, table := range tables {
+		for _, fk := range fks[strings.ToLower(table.Name)] {
+			from := table.Name + "(" + strings.Join(fk.Columns, ", ") + ")"
+			to := fk.RefTable + "(" + strings.Join(fk.RefColumns, ", ") + ")"
+			key := strings.ToLower(table.Name)
+			outgoing[key] = append(outgoing[key], edge{from, to})
+			refKey := strings.ToLower(strings.ReplaceAll(fk.RefTable, `"`, ""))
+			incoming[refKey] = append(incoming[refKey], edge{from, to})
+		}
+	}
+
+	var lines, unrelated []string
+	for _, table := range tables {
+		key := strings.ToLower(table.Name)
+		out, in := outgoing[key], incoming[key]
+		if len(out) == 0 && len(in) == 0 {
+			unrelated = append(unrelated, table.Name)
+			continue
+		}
+		lines = append(lines, "▪ "+table.Name)
+		for _, e := range out {
+			lines = append(lines, "    "+strings.TrimPrefix(e.from, table.Name)+" ──▶ "+e.to)
+		}
+		sort.Slice(in, func(i, j int) bool { return in[i].from < in[j].from })
+		for _, e := range in {
+			lines = append(lines, "    ◀── "+e.from)
+		}
+		lines = append(lines, "")
+	}
+
+	if len(unrelated) > 0 {
+		lines = append(lines, "Tables without relationships:")
+		for _, name := range unrelated {
+			lines = append(lines, "    "+name)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No tables")
+	}
+	return lines
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestERDiagramLines tests the text rendering of tables and their relationships
+func TestERDiagramLines(t *testing.T) {
+	tables := []SchemaTable{
+		{Name: "orders"},
+		{Name: "settings"},
+		{Name: "users"},
+	}
+	fks := map[string][]SchemaForeignKey{
+		"orders": {{Name: "fk_0", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+	}
+
+	expected := []string{
+		"▪ orders",
+		"    (user_id) ──▶ users(id)",
+		"",
+		"▪ users",
+		"    ◀── orders(user_id)",
+		"",
+		"Tables without relationships:",
+		"    settings",
+	}
+	result := erDiagramLines(tables, fks)
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("erDiagramLines() =\n%s\nwant\n%s", strings.Join(result, "\n"), strings.Join(expected, "\n"))
+	}
+
+	if result := erDiagramLines(nil, nil); len(result) != 1 || result[0] != "No tables" {
+		t.Errorf("erDiagramLines(nil) = %q, want [\"No tables\"]", result)
+	}
+}
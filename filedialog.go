@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // openFileDialog opens the file selection dialog
@@ -71,10 +74,11 @@ func (m *Model) loadDirectoryIntoDialog(dir string) {
 	m.statusMessage = "Select a file or directory"
 }
 
-// loadFile loads the selected SQL file into the textarea
+// loadFile loads the selected SQL file into the textarea, saving the file
+// it replaces first. A file already open in another tab is switched to.
 func (m *Model) loadFile(filename string) {
 	tab := m.activeTabPtr()
-	if tab == nil {
+	if tab == nil || m.switchToFileTab(filename) {
 		return
 	}
 
@@ -83,11 +87,13 @@ func (m *Model) loadFile(filename string) {
 		m.statusMessage = fmt.Sprintf("Error reading file: %v", err)
 		return
 	}
+	m.saveToFile()
 
 	content := string(data)
 	tab.textarea.SetValue(content)
 	tab.sqlFile = filename
 	tab.lastSavedContent = content
+	tab.diskStat = statFile(filename)
 	m.statusMessage = fmt.Sprintf("Opened %s", filename)
 
 	// Clear any existing results
@@ -95,26 +101,99 @@ func (m *Model) loadFile(filename string) {
 	tab.queryMeta = nil
 }
 
+// openFileInNewTab opens a SQL file in a new tab on the active tab's
+// connection. A file already open in another tab is switched to.
+func (m *Model) openFileInNewTab(filename string) {
+	tab := m.activeTabPtr()
+	if tab == nil || m.switchToFileTab(filename) {
+		return
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error reading file: %v", err)
+		return
+	}
+	m.saveToFile()
+
+	// The new tab shares the connection and its schema cache
+	newTab := NewTab(tab.db, tab.dbType, tab.dsn, tab.sqlDir, filename, string(data), tab.connectionName, tab.theme)
+	loadConnectionSettings(newTab, m.vaultManager)
+	newTab.schema = tab.schema
+	newTab.textarea.SetHeight(tab.textarea.Height())
+	newTab.textarea.SetWidth(tab.textarea.Width())
+
+	m.tabs = append(m.tabs, newTab)
+	m.activeTab = len(m.tabs) - 1
+	m.statusMessage = fmt.Sprintf("Opened %s in tab %d", filename, m.activeTab+1)
+}
+
+// switchToFileTab switches to the tab editing filename, if there is one
+func (m *Model) switchToFileTab(filename string) bool {
+	for i, t := range m.tabs {
+		if t.sqlFile == "" || !sameFile(t.sqlFile, filename) {
+			continue
+		}
+		if i != m.activeTab {
+			m.saveToFile()
+			m.activeTab = i
+		}
+		m.statusMessage = fmt.Sprintf("%s is open in tab %d", filepath.Base(filename), i+1)
+		return true
+	}
+	return false
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
 // saveToFile saves the current textarea content to the SQL file
 func (m *Model) saveToFile() {
-	tab := m.activeTabPtr()
-	if tab == nil || tab.sqlFile == "" {
+	if tab := m.activeTabPtr(); tab != nil {
+		m.saveTab(tab)
+	}
+}
+
+// saveTab saves a tab's editor content to its SQL file. If the file was
+// changed by something else since it was loaded, it asks whether to reload
+// it instead of overwriting those changes. A failed write is kept on the tab
+// and shown in the status bar until a save succeeds.
+func (m *Model) saveTab(tab *Tab) {
+	if tab.sqlFile == "" {
 		return
 	}
-	content := tab.textarea.Value()
-	// Write file, ignoring errors (we don't want to crash on save failure)
-	if err := os.WriteFile(tab.sqlFile, []byte(content), 0644); err == nil {
-		tab.lastSavedContent = content
+	if changedOnDisk(tab) {
+		m.promptReload(tab)
+		return
 	}
+	tab.saveErr = writeTabFile(tab)
 }
 
-// reloadFileFromDisk reloads the SQL file from disk into the textarea
+// reloadFileFromDisk reloads the SQL file from disk into the textarea. With
+// unsaved edits, it asks first - and only if the file actually changed.
 func (m *Model) reloadFileFromDisk() {
 	tab := m.activeTabPtr()
 	if tab == nil || tab.sqlFile == "" {
 		return
 	}
+	if m.hasUnsavedChanges() {
+		if changedOnDisk(tab) {
+			m.promptReload(tab)
+		}
+		return
+	}
+	m.reloadTab(tab)
+}
 
+// reloadTab replaces a tab's editor content with its SQL file
+func (m *Model) reloadTab(tab *Tab) {
 	data, err := os.ReadFile(tab.sqlFile)
 	if err != nil {
 		m.statusMessage = fmt.Sprintf("Error reloading file: %v", err)
@@ -124,9 +203,90 @@ func (m *Model) reloadFileFromDisk() {
 	content := string(data)
 	tab.textarea.SetValue(content)
 	tab.lastSavedContent = content
+	tab.diskStat = statFile(tab.sqlFile)
 	m.statusMessage = fmt.Sprintf("Reloaded %s", tab.sqlFile)
 }
 
+// fileStat identifies a version of a SQL file on disk
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the current fileStat of path, or the zero fileStat if it
+// doesn't exist
+func statFile(path string) fileStat {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}
+}
+
+// changedOnDisk reports whether a tab's SQL file was changed by something
+// else (another editor, git) since the tab last loaded or saved it
+func changedOnDisk(tab *Tab) bool {
+	if tab.sqlFile == "" {
+		return false
+	}
+	stat := statFile(tab.sqlFile)
+	if stat.size == tab.diskStat.size && stat.modTime.Equal(tab.diskStat.modTime) {
+		return false
+	}
+	data, err := os.ReadFile(tab.sqlFile)
+	if err != nil {
+		return false // deleted or unreadable; saving writes it again
+	}
+	if string(data) != tab.lastSavedContent {
+		return true
+	}
+	tab.diskStat = stat // touched, but the content is what we have
+	return false
+}
+
+// promptReload asks whether to reload a tab whose SQL file changed on disk
+func (m *Model) promptReload(tab *Tab) {
+	m.reloadPrompt = tab
+}
+
+// reloadPromptText is the question shown in the status bar while asking
+// whether to reload a changed SQL file
+func (m Model) reloadPromptText() string {
+	tab := m.reloadPrompt
+	name := filepath.Base(tab.sqlFile)
+	if tab.textarea.Value() != tab.lastSavedContent {
+		return fmt.Sprintf("%s changed on disk. Reload it and lose your unsaved edits? (y/n)", name)
+	}
+	return fmt.Sprintf("%s changed on disk. Reload it? (y/n)", name)
+}
+
+// fileCheckInterval is how often the active tab's SQL file is checked for
+// changes made outside dibber. A stat every couple of seconds is cheap, and
+// unlike a file watch it keeps working when an editor saves by replacing the
+// file, and on network filesystems.
+const fileCheckInterval = 2 * time.Second
+
+// fileCheckMsg is sent every fileCheckInterval to check the SQL file
+type fileCheckMsg struct{}
+
+// checkFileTick schedules the next check of the SQL file
+func checkFileTick() tea.Cmd {
+	return tea.Tick(fileCheckInterval, func(time.Time) tea.Msg {
+		return fileCheckMsg{}
+	})
+}
+
+// checkFile starts checking the active tab's SQL file for outside changes,
+// if it has one and the checks stopped for want of a file
+func (m *Model) checkFile() tea.Cmd {
+	tab := m.activeTabPtr()
+	if m.checkingFile || tab == nil || tab.sqlFile == "" {
+		return nil
+	}
+	m.checkingFile = true
+	return checkFileTick()
+}
+
 // hasUnsavedChanges returns true if the active tab's textarea content differs from the last saved content
 func (m Model) hasUnsavedChanges() bool {
 	tab := m.tab()
@@ -190,3 +350,16 @@ func (m *Model) navigateFileDialog(selected FileDialogEntry) {
 		m.fileDialog = nil
 	}
 }
+
+// openFileDialogEntryInNewTab opens the selected file in a new tab
+func (m *Model) openFileDialogEntryInNewTab(selected FileDialogEntry) {
+	if selected.isDir {
+		return
+	}
+	m.openFileInNewTab(filepath.Join(m.fileDialog.directory, selected.name))
+	m.focus = focusQuery
+	m.fileDialog = nil
+	if tab := m.activeTabPtr(); tab != nil {
+		tab.textarea.Focus()
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestChangedOnDisk tests noticing edits made to a tab's SQL file elsewhere
+func TestChangedOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.sql")
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("SELECT 1;", start)
+
+	tab := &Tab{sqlFile: path, lastSavedContent: "SELECT 1;", diskStat: statFile(path)}
+	if changedOnDisk(tab) {
+		t.Errorf("changedOnDisk() = true for an untouched file")
+	}
+
+	// Touched but with the same content
+	write("SELECT 1;", start.Add(time.Minute))
+	if changedOnDisk(tab) {
+		t.Errorf("changedOnDisk() = true for a file touched without changes")
+	}
+	if !tab.diskStat.modTime.Equal(start.Add(time.Minute)) {
+		t.Errorf("changedOnDisk() didn't record the touched file's time")
+	}
+
+	write("SELECT 2;", start.Add(2*time.Minute))
+	if !changedOnDisk(tab) {
+		t.Errorf("changedOnDisk() = false for a file edited elsewhere")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if changedOnDisk(tab) {
+		t.Errorf("changedOnDisk() = true for a deleted file")
+	}
+}
+
+// TestSameFile tests recognising two paths to one file
+func TestSameFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.sql")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.sql")
+	if err := os.Symlink(path, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{path, path, true},
+		{path, filepath.Join(dir, ".", "a.sql"), true},
+		{path, link, true},
+		{path, filepath.Join(dir, "b.sql"), false},
+	}
+	for _, tc := range tests {
+		if got := sameFile(tc.a, tc.b); got != tc.want {
+			t.Errorf("sameFile(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+// TestCheckFile tests that checking for outside changes stops while the
+// active tab has no SQL file, and starts again when it has one
+func TestCheckFile(t *testing.T) {
+	tab := &Tab{}
+	m := Model{tabs: []*Tab{tab}, checkingFile: true}
+
+	next, cmd := m.update(fileCheckMsg{})
+	m = next.(Model)
+	if cmd != nil || m.checkingFile {
+		t.Fatal("the checks should stop with no SQL file open")
+	}
+
+	tab.sqlFile = filepath.Join(t.TempDir(), "queries.sql")
+	if m.checkFile() == nil || !m.checkingFile {
+		t.Fatal("checkFile() should start the checks once a file is open")
+	}
+	if m.checkFile() != nil {
+		t.Error("checkFile() shouldn't schedule a second check while one is scheduled")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// finderMaxResults caps the number of matches kept for display
+const finderMaxResults = 100
+
+// FinderItem is a table or a column of a table that can be picked in the finder
+type FinderItem struct {
+	Table  string
+	Column string // empty for the table itself
+	IsView bool
+	score  int
+}
+
+// Label returns the text the item is matched and displayed as
+func (it FinderItem) Label() string {
+	if it.Column == "" {
+		return it.Table
+	}
+	return it.Table + "." + it.Column
+}
+
+// SchemaFinder is the fuzzy-search popup over table and column names
+type SchemaFinder struct {
+	input    textinput.Model
+	items    []FinderItem
+	matches  []FinderItem
+	selected int
+}
+
+// newSchemaFinder creates a finder over every table and column in tables
+func newSchemaFinder(tables []SchemaTable) *SchemaFinder {
+	ti := textinput.New()
+	ti.Placeholder = "table or column name"
+	ti.CharLimit = 128
+	ti.Width = 40
+	ti.Focus()
+
+	var items []FinderItem
+	for _, table := range tables {
+		items = append(items, FinderItem{Table: table.Name, IsView: table.IsView})
+		for _, col := range table.Columns {
+			items = append(items, FinderItem{Table: table.Name, Column: col.Name, IsView: table.IsView})
+		}
+	}
+
+	f := &SchemaFinder{input: ti, items: items}
+	f.filter()
+	return f
+}
+
+// filter recomputes the matches for the current input, best first
+func (f *SchemaFinder) filter() {
+	pattern := strings.TrimSpace(f.input.Value())
+	f.matches = f.matches[:0]
+	for _, item := range f.items {
+		score, ok := fuzzyScore(pattern, item.Label())
+		if !ok {
+			continue
+		}
+		item.score = score
+		f.matches = append(f.matches, item)
+	}
+	sort.SliceStable(f.matches, func(i, j int) bool {
+		return f.matches[i].score > f.matches[j].score
+	})
+	if len(f.matches) > finderMaxResults {
+		f.matches = f.matches[:finderMaxResults]
+	}
+	f.selected = 0
+}
+
+// fuzzyScore reports whether every character of pattern appears in candidate
+// in order (case-insensitive), and scores the match: consecutive characters
+// and characters at the start of a word score higher, and shorter candidates
+// win ties. An empty pattern matches everything.
+func fuzzyScore(pattern, candidate string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	c := []rune(candidate)
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	pi := 0
+	prevMatch := -2
+	for ci := 0; ci < len(c) && pi < len(p); ci++ {
+		if unicode.ToLower(c[ci]) != p[pi] {
+			continue
+		}
+		score++
+		if ci == prevMatch+1 {
+			score += 5 // consecutive
+		}
+		if ci == 0 || c[ci-1] == '_' || c[ci-1] == '.' || (unicode.IsUpper(c[ci]) && unicode.IsLower(c[ci-1])) {
+			score += 10 // word start
+		}
+		prevMatch = ci
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score*100 - len(c), true
+}
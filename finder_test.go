@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestFuzzyScore tests subsequence matching of finder patterns
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		candidate string
+		match     bool
+	}{
+		{"", "users", true},
+		{"usr", "users", true},
+		{"USR", "users", true},
+		{"uem", "users.email", true},
+		{"sru", "users", false},
+		{"usersx", "users", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern+"/"+tc.candidate, func(t *testing.T) {
+			_, ok := fuzzyScore(tc.pattern, tc.candidate)
+			if ok != tc.match {
+				t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tc.pattern, tc.candidate, ok, tc.match)
+			}
+		})
+	}
+}
+
+// TestFuzzyScoreRanking tests that tighter matches rank higher
+func TestFuzzyScoreRanking(t *testing.T) {
+	tests := []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"email", "users.email", "users.email_verified_at"},
+		{"ord", "orders", "users.word_count"},
+		{"ui", "users.id", "quirks"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			better, _ := fuzzyScore(tc.pattern, tc.better)
+			worse, _ := fuzzyScore(tc.pattern, tc.worse)
+			if better <= worse {
+				t.Errorf("score(%q) = %d, want greater than score(%q) = %d", tc.better, better, tc.worse, worse)
+			}
+		})
+	}
+}
+
+// TestSchemaFinderFilter tests that the finder lists tables and columns and filters them
+func TestSchemaFinderFilter(t *testing.T) {
+	tables := []SchemaTable{
+		{Name: "users", Columns: []SchemaColumn{{Name: "id"}, {Name: "email"}}},
+		{Name: "orders", Columns: []SchemaColumn{{Name: "id"}, {Name: "user_id"}}},
+	}
+
+	f := newSchemaFinder(tables)
+	if len(f.matches) != 6 {
+		t.Fatalf("empty pattern matched %d items, want 6", len(f.matches))
+	}
+	if f.matches[0].Label() != "users" {
+		t.Errorf("first item = %q, want schema order", f.matches[0].Label())
+	}
+
+	f.input.SetValue("ema")
+	f.filter()
+	if len(f.matches) != 1 || f.matches[0].Label() != "users.email" {
+		t.Errorf("matches for %q = %+v, want only users.email", "ema", f.matches)
+	}
+}
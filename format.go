@@ -0,0 +1,366 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fmtTokenKind classifies the tokens the SQL formatter works with
+type fmtTokenKind int
+
+const (
+	fmtWord fmtTokenKind = iota // keywords, identifiers, numbers, placeholders
+	fmtQuoted
+	fmtPunct // ( ) , ; .
+	fmtOperator
+	fmtLineComment
+	fmtBlockComment
+)
+
+type fmtToken struct {
+	kind fmtTokenKind
+	text string
+}
+
+// fmtOperators are the multi-character operators kept together, longest first
+var fmtOperators = []string{"->>", "#>>", "<=>", "::", "<=", ">=", "<>", "!=", "||", "->", "#>", "@>", "<@", "&&", "<<", ">>"}
+
+// Keywords that start a clause on a new line
+var fmtClauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "VALUES": true, "SET": true, "UPDATE": true, "INSERT": true,
+	"DELETE": true, "RETURNING": true, "JOIN": true, "LEFT": true, "RIGHT": true,
+	"INNER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+}
+
+// Keywords after which a clause keyword continues the same clause
+// (LEFT JOIN, DELETE FROM, FOR UPDATE, ON DUPLICATE KEY UPDATE)
+var fmtClauseJoiners = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "OUTER": true, "FULL": true,
+	"CROSS": true, "NATURAL": true, "DELETE": true, "FOR": true, "KEY": true,
+}
+
+// Clauses whose top-level commas put each item on its own line
+var fmtListClauses = map[string]bool{
+	"SELECT": true, "FROM": true, "GROUP": true, "ORDER": true, "SET": true,
+	"VALUES": true, "RETURNING": true,
+}
+
+// Clauses whose top-level AND/OR put each condition on its own line
+var fmtConditionClauses = map[string]bool{
+	"WHERE": true, "HAVING": true, "JOIN": true,
+}
+
+// formatSQL reformats a single SQL statement: keywords uppercased, each
+// clause on its own line, list items and AND/OR conditions on continuation
+// lines, and subqueries indented. Strings, quoted identifiers and comments
+// are kept as they are.
+func formatSQL(sql string) string {
+	tokens := tokenizeForFormat(sql)
+	keywords, functions := keywordSets()
+
+	// One context per open parenthesis; subqueries get their own clauses
+	type context struct {
+		subquery   bool
+		base       int    // indent level of clause lines
+		clause     string // current clause keyword
+		openIndent int    // indent level of the line the parenthesis opened on
+	}
+	stack := []context{{subquery: true}}
+
+	var b strings.Builder
+	lineIndent := 0
+	lineStart := true
+	between := false
+	var prev fmtToken
+	prevWord, prevWord2 := "", ""
+
+	newline := func(level int) {
+		if b.Len() == 0 {
+			return
+		}
+		if lineStart {
+			// Already on a fresh line (after a comment); just re-indent it
+			out := b.String()
+			b.Reset()
+			b.WriteString(out[:strings.LastIndexByte(out, '\n')])
+		}
+		b.WriteString("\n" + strings.Repeat("  ", level))
+		lineIndent = level
+		lineStart = true
+	}
+	nextSignificant := func(i int) fmtToken {
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].kind != fmtLineComment && tokens[j].kind != fmtBlockComment {
+				return tokens[j]
+			}
+		}
+		return fmtToken{}
+	}
+
+	for i, tok := range tokens {
+		ctx := &stack[len(stack)-1]
+		text := tok.text
+		upper := strings.ToUpper(text)
+		next := nextSignificant(i)
+
+		if tok.kind == fmtWord {
+			isCall := next.text == "("
+			if keywords[upper] || (isCall && functions[upper]) {
+				text = upper
+			}
+
+			if ctx.subquery {
+				switch {
+				case fmtClauseKeywords[upper] && !fmtClauseJoiners[prevWord] && !(isCall && (upper == "LEFT" || upper == "RIGHT")):
+					newline(ctx.base)
+					ctx.clause = upper
+					if strings.HasSuffix(upper, "JOIN") || upper == "LEFT" || upper == "RIGHT" || upper == "INNER" || upper == "FULL" || upper == "CROSS" || upper == "NATURAL" {
+						ctx.clause = "JOIN"
+					}
+				case (upper == "AND" || upper == "OR") && fmtConditionClauses[ctx.clause] && !between:
+					newline(ctx.base + 1)
+				}
+			}
+			if upper == "BETWEEN" {
+				between = true
+			} else if upper == "AND" {
+				between = false
+			}
+		}
+
+		// A subquery's closing parenthesis goes on its own line
+		if text == ")" && len(stack) > 1 {
+			closed := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if closed.subquery {
+				newline(closed.openIndent)
+			}
+		}
+
+		// Spacing before the token
+		switch {
+		case lineStart:
+		case tok.kind == fmtPunct && text != "(":
+		case prev.text == "(" || prev.text == "." || prev.text == "::":
+		case text == "::":
+		case text == "(" && prev.kind == fmtWord && isFunctionName(prevWord, keywords) && prevWord2 != "INTO" && prevWord2 != "TABLE":
+			// function call, but not INSERT INTO t (cols)
+		case prev.kind == fmtOperator && (prev.text == "-" || prev.text == "+") && isUnaryPosition(tokens, i-1):
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		lineStart = false
+
+		switch {
+		case tok.kind == fmtLineComment:
+			newline(lineIndent)
+		case text == "(":
+			if next.kind == fmtWord && (strings.EqualFold(next.text, "SELECT") || strings.EqualFold(next.text, "WITH")) {
+				stack = append(stack, context{subquery: true, base: lineIndent + 1, openIndent: lineIndent})
+				newline(lineIndent + 1)
+			} else {
+				stack = append(stack, context{base: ctx.base, clause: ctx.clause, openIndent: lineIndent})
+			}
+		case text == "," && ctx.subquery && fmtListClauses[ctx.clause]:
+			newline(ctx.base + 1)
+		}
+
+		prev = tok
+		if tok.kind != fmtLineComment && tok.kind != fmtBlockComment {
+			prevWord2 = prevWord
+			prevWord = ""
+			if tok.kind == fmtWord {
+				prevWord = strings.ToUpper(tok.text)
+			}
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// keywordSets returns the SQL keywords and function names as sets
+func keywordSets() (keywords, functions map[string]bool) {
+	keywords = make(map[string]bool, len(sqlKeywords))
+	for _, kw := range sqlKeywords {
+		keywords[kw] = true
+	}
+	functions = make(map[string]bool, len(sqlFunctions))
+	for _, fn := range sqlFunctions {
+		functions[fn] = true
+	}
+	return keywords, functions
+}
+
+// uppercaseKeywords uppercases the keywords and function names in a
+// statement, keeping its layout. Strings, quoted identifiers, comments,
+// dollar-quoted bodies and names after a dot (t.key) are left as they are.
+func uppercaseKeywords(sql string, dbType string) string {
+	var b strings.Builder
+	pos := 0
+	for _, r := range dollarQuoteRanges(sql, dbType) {
+		b.WriteString(uppercaseKeywordsIn(sql[pos:r[0]]))
+		b.WriteString(sql[r[0]:r[1]])
+		pos = r[1]
+	}
+	b.WriteString(uppercaseKeywordsIn(sql[pos:]))
+	return b.String()
+}
+
+// uppercaseKeywordsIn uppercases the keywords in SQL with no dollar quotes,
+// copying the whitespace between tokens through unchanged
+func uppercaseKeywordsIn(sql string) string {
+	keywords, functions := keywordSets()
+	tokens := tokenizeForFormat(sql)
+	var b strings.Builder
+	pos := 0
+	for i, tok := range tokens {
+		at := pos + strings.Index(sql[pos:], tok.text)
+		b.WriteString(sql[pos:at])
+		pos = at + len(tok.text)
+
+		text := tok.text
+		if tok.kind == fmtWord && (i == 0 || tokens[i-1].text != ".") {
+			upper := strings.ToUpper(text)
+			isCall := i+1 < len(tokens) && tokens[i+1].text == "("
+			if keywords[upper] || (isCall && functions[upper]) {
+				text = upper
+			}
+		}
+		b.WriteString(text)
+	}
+	b.WriteString(sql[pos:])
+	return b.String()
+}
+
+// isFunctionName reports whether an (uppercased) word directly before an
+// opening parenthesis is a function being called rather than a keyword
+func isFunctionName(word string, keywords map[string]bool) bool {
+	if !keywords[word] {
+		return true
+	}
+	for _, fn := range sqlFunctions {
+		if fn == word {
+			return true
+		}
+	}
+	return word == "LEFT" || word == "RIGHT"
+}
+
+// isUnaryPosition reports whether the + or - at index i is a sign rather
+// than an operator: it follows another operator, an opening parenthesis, a
+// comma, a keyword or nothing
+func isUnaryPosition(tokens []fmtToken, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := tokens[i-1]
+	switch prev.kind {
+	case fmtOperator:
+		return true
+	case fmtPunct:
+		return prev.text == "(" || prev.text == ","
+	case fmtWord:
+		return fmtClauseKeywords[strings.ToUpper(prev.text)] || columnKeywords[strings.ToUpper(prev.text)] ||
+			strings.EqualFold(prev.text, "THEN") || strings.EqualFold(prev.text, "ELSE") || strings.EqualFold(prev.text, "WHEN")
+	}
+	return false
+}
+
+// tokenizeForFormat splits SQL into formatter tokens, dropping whitespace
+func tokenizeForFormat(sql string) []fmtToken {
+	var tokens []fmtToken
+	n := len(sql)
+	i := 0
+	for i < n {
+		ch := sql[i]
+		switch {
+		case unicode.IsSpace(rune(ch)):
+			i++
+		case ch == '-' && i+1 < n && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = n - i
+			}
+			tokens = append(tokens, fmtToken{fmtLineComment, strings.TrimRight(sql[i:i+end], " \t\r")})
+			i += end
+		case ch == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = n - i - 2
+			} else {
+				end += 2
+			}
+			tokens = append(tokens, fmtToken{fmtBlockComment, sql[i : i+2+end]})
+			i += 2 + end
+		case ch == '\'' || ch == '"' || ch == '`':
+			j := i + 1
+			for j < n {
+				if sql[j] == '\\' && ch == '\'' && j+1 < n {
+					j += 2
+					continue
+				}
+				if sql[j] == ch {
+					if j+1 < n && sql[j+1] == ch { // doubled quote
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			j = min(j, n)
+			tokens = append(tokens, fmtToken{fmtQuoted, sql[i:j]})
+			i = j
+		case ch == '{' && strings.HasPrefix(sql[i:], "{{") && strings.Contains(sql[i:], "}}"):
+			end := strings.Index(sql[i:], "}}") + 2
+			tokens = append(tokens, fmtToken{fmtWord, sql[i : i+end]})
+			i += end
+		case ch == ':' && i+1 < n && isIdentifierByte(sql[i+1]) && sql[i+1] != ':' && (i == 0 || !isIdentifierByte(sql[i-1])):
+			j := i + 1
+			for j < n && isIdentifierByte(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, fmtToken{fmtWord, sql[i:j]})
+			i = j
+		case isIdentifierByte(ch) || ch == '@':
+			j := i + 1
+			for j < n && (isIdentifierByte(sql[j]) || (sql[j] == '.' && isNumberPrefix(sql[i:j]))) {
+				j++
+			}
+			tokens = append(tokens, fmtToken{fmtWord, sql[i:j]})
+			i = j
+		case strings.IndexByte("(),;.", ch) >= 0:
+			tokens = append(tokens, fmtToken{fmtPunct, string(ch)})
+			i++
+		default:
+			op := string(ch)
+			for _, candidate := range fmtOperators {
+				if strings.HasPrefix(sql[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			tokens = append(tokens, fmtToken{fmtOperator, op})
+			i += len(op)
+		}
+	}
+	return tokens
+}
+
+// isNumberPrefix reports whether s is all digits, so a following '.' is a decimal point
+func isNumberPrefix(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+// TestFormatSQL tests clause-per-line layout, keyword casing and indentation
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"select with join",
+			"select id, count(*) as n from users u left join orders o on o.user_id = u.id and o.total > -5 where u.age between 18 and 65 and u.name like 'a%' group by id order by n desc limit 10",
+			"SELECT id,\n  COUNT(*) AS n\nFROM users u\nLEFT JOIN orders o ON o.user_id = u.id\n  AND o.total > -5\nWHERE u.age BETWEEN 18 AND 65\n  AND u.name LIKE 'a%'\nGROUP BY id\nORDER BY n DESC\nLIMIT 10",
+		},
+		{
+			"subquery",
+			"select * from users where id in (select user_id from orders) or (a = 1 and b = 2)",
+			"SELECT *\nFROM users\nWHERE id IN (\n  SELECT user_id\n  FROM orders\n)\n  OR (a = 1 AND b = 2)",
+		},
+		{
+			"insert",
+			"insert into users (id, name) values (1, 'it''s'), (2, 'b')",
+			"INSERT INTO users (id, name)\nVALUES (1, 'it''s'),\n  (2, 'b')",
+		},
+		{
+			"update with comment",
+			"update users set name = 'x', age = age + 1 -- bump\nwhere id = :id",
+			"UPDATE users\nSET name = 'x',\n  age = age + 1 -- bump\nWHERE id = :id",
+		},
+		{
+			"union",
+			"select a from t union all select b from u",
+			"SELECT a\nFROM t\nUNION ALL\nSELECT b\nFROM u",
+		},
+		{
+			"literals and casts kept",
+			`SELECT "Select From", created_at::date, {{ day }}, left(name, 2) FROM t`,
+			"SELECT \"Select From\",\n  created_at::DATE,\n  {{ day }},\n  LEFT(name, 2)\nFROM t",
+		},
+		{
+			"delete",
+			"delete from users where id = 1",
+			"DELETE FROM users\nWHERE id = 1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := formatSQL(tc.input)
+			if got != tc.want {
+				t.Errorf("formatSQL() =\n%s\nwant:\n%s", got, tc.want)
+			}
+			if again := formatSQL(got); again != got {
+				t.Errorf("formatSQL() is not stable:\n%s\nthen:\n%s", got, again)
+			}
+		})
+	}
+}
+
+// TestUppercaseKeywords tests uppercasing keywords without changing the layout
+func TestUppercaseKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbType string
+		input  string
+		want   string
+	}{
+		{
+			"layout kept",
+			"postgres",
+			"select id,\n       count(*)\n  from users  -- where from\n where id in (1, 2)",
+			"SELECT id,\n       COUNT(*)\n  FROM users  -- where from\n WHERE id IN (1, 2)",
+		},
+		{
+			"literals kept",
+			"mysql",
+			"select 'select', \"from\", `order` from t where x = /* and */ 1",
+			"SELECT 'select', \"from\", `order` FROM t WHERE x = /* and */ 1",
+		},
+		{
+			"names after a dot kept",
+			"postgres",
+			"select t.key, left(t.name, 2) from t",
+			"SELECT t.key, LEFT(t.name, 2) FROM t",
+		},
+		{
+			"dollar-quoted body kept",
+			"postgres",
+			"select $$ select 1 $$ as body from t",
+			"SELECT $$ select 1 $$ AS body FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uppercaseKeywords(tt.input, tt.dbType); got != tt.want {
+				t.Errorf("uppercaseKeywords() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"slices"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,6 +23,12 @@ func (m Model) handleFileDialogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.navigateFileDialog(selected)
 		}
 		return m, nil
+	case "t":
+		// Open the selected file in a new tab on the same connection
+		if len(m.fileDialog.entries) > 0 {
+			m.openFileDialogEntryInNewTab(m.fileDialog.entries[m.fileDialog.selectedIdx])
+		}
+		return m, nil
 	case "up", "k":
 		if m.fileDialog.selectedIdx > 0 {
 			m.fileDialog.selectedIdx--
@@ -51,14 +59,51 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	switch msg.String() {
-	case "esc":
+	// Confirm or cancel a pending direct execution
+	if tab.detailView.pendingSQL != "" {
+		stmt := tab.detailView.pendingSQL
+		switch msg.String() {
+		case "y", "Y", "enter":
+			tab.detailView.pendingSQL = ""
+			return m, m.executeDetailSQL(stmt)
+		case "n", "N", "esc":
+			tab.detailView.pendingSQL = ""
+			m.statusMessage = "Execution cancelled"
+		}
+		return m, nil
+	}
+
+	switch key := msg.String(); {
+	case key == "esc":
 		// Close detail view, go back to results
 		m.focus = focusResults
 		tab.detailView = nil
 		return m, nil
 
-	case "f5", "ctrl+u":
+	case m.keys.CopyField.Matches(key):
+		m.copyField()
+		return m, nil
+
+	case m.keys.FollowForeignKey.Matches(key):
+		// Follow the focused column's foreign key to the referenced row
+		i := tab.detailView.focusedField
+		if i >= len(tab.detailView.references) || tab.detailView.references[i] == "" {
+			m.statusMessage = "Field is not a foreign key"
+			return m, nil
+		}
+		value := tab.detailView.originalValues[i]
+		if value.IsNull {
+			m.statusMessage = "Foreign key is NULL - no referenced row"
+			return m, nil
+		}
+		m.appendQueryToTextarea(followForeignKeySQL(tab.detailView.references[i], value.Value, tab.detailView.columnTypes[i], tab.dbType))
+		m.focus = focusQuery
+		tab.textarea.Focus()
+		tab.detailView = nil
+		m.statusMessage = "Query for referenced row appended. Press Ctrl+R to execute."
+		return m, nil
+
+	case m.keys.AppendUpdate.Matches(key):
 		// Generate UPDATE and append to query window
 		if tab.queryMeta != nil && tab.queryMeta.IsEditable {
 			updateSQL := m.generateUpdateSQL()
@@ -74,7 +119,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "f6", "ctrl+d":
+	case m.keys.AppendDelete.Matches(key):
 		// Generate DELETE and append to query window
 		if tab.queryMeta != nil && tab.queryMeta.IsEditable {
 			deleteSQL := m.generateDeleteSQL()
@@ -89,10 +134,14 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "f7", "ctrl+i":
+	case m.keys.AppendInsert.Matches(key):
 		// Generate INSERT and append to query window
 		if tab.queryMeta != nil && tab.queryMeta.IsEditable {
-			insertSQL := m.generateInsertSQL()
+			insertSQL, err := m.generateInsertSQL()
+			if err != nil {
+				m.statusMessage = "Can't generate INSERT: " + err.Error()
+				return m, nil
+			}
 			if insertSQL != "" {
 				m.appendQueryToTextarea(insertSQL)
 				m.focus = focusQuery
@@ -104,7 +153,41 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "ctrl+n":
+	case m.keys.ExecuteUpdate.Matches(key), m.keys.ExecuteDelete.Matches(key), m.keys.ExecuteInsert.Matches(key):
+		// Generate and execute immediately, instead of appending for review
+		if tab.queryMeta == nil || !tab.queryMeta.IsEditable {
+			return m, nil
+		}
+		var stmt string
+		switch {
+		case m.keys.ExecuteUpdate.Matches(key):
+			stmt = m.generateUpdateSQL()
+			if stmt == "" {
+				m.statusMessage = "No changes to update."
+				return m, nil
+			}
+		case m.keys.ExecuteDelete.Matches(key):
+			stmt = m.generateDeleteSQL()
+		case m.keys.ExecuteInsert.Matches(key):
+			var err error
+			if stmt, err = m.generateInsertSQL(); err != nil {
+				m.statusMessage = "Can't generate INSERT: " + err.Error()
+				return m, nil
+			}
+		}
+		if stmt == "" {
+			return m, nil
+		}
+		// Show the statement before it runs, unless it'll wait in a
+		// transaction for confirmation anyway or is only a dry run
+		if !m.confirmsWrite(tab, stmt) && !tab.dryRun {
+			tab.detailView.pendingSQL = stmt
+			m.statusMessage = ""
+			return m, nil
+		}
+		return m, m.executeDetailSQL(stmt)
+
+	case m.keys.ToggleNull.Matches(key):
 		// Toggle NULL state for focused field
 		if tab.queryMeta != nil && tab.queryMeta.IsEditable {
 			idx := tab.detailView.focusedField
@@ -119,7 +202,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "up", "shift+tab":
+	case key == "up", key == "shift+tab":
 		if tab.detailView.focusedField > 0 {
 			tab.detailView.inputs[tab.detailView.focusedField].Blur()
 			tab.detailView.focusedField--
@@ -132,7 +215,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "down", "tab":
+	case key == "down", key == "tab":
 		if tab.detailView.focusedField < len(tab.detailView.inputs)-1 {
 			tab.detailView.inputs[tab.detailView.focusedField].Blur()
 			tab.detailView.focusedField++
@@ -145,7 +228,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "pgdown":
+	case key == "pgdown":
 		// Scroll down within multi-line content
 		origVal := tab.detailView.originalValues[tab.detailView.focusedField]
 		if !origVal.IsNull && strings.Contains(origVal.Value, "\n") {
@@ -161,7 +244,7 @@ func (m Model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "pgup":
+	case key == "pgup":
 		// Scroll up within multi-line content
 		tab.detailView.contentScrollOffset -= 10
 		if tab.detailView.contentScrollOffset < 0 {
@@ -193,47 +276,149 @@ func (m Model) handleResultsNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	switch msg.String() {
-	case "up", "k":
+	switch key := msg.String(); {
+	case tab.result.Error != nil && m.keys.RowUp.Matches(key):
+		// Scroll the error panel
+		m.scrollErrorPanel(-1)
+		return m, nil
+
+	case tab.result.Error != nil && m.keys.RowDown.Matches(key):
+		m.scrollErrorPanel(1)
+		return m, nil
+
+	case m.keys.RowUp.Matches(key):
 		if tab.selectedRow > 0 {
 			tab.selectedRow--
 			// Check if we need to go to previous page
-			if tab.selectedRow < tab.currentPage*pageSize {
+			if tab.selectedRow < tab.currentPage*tab.rowsPerPage() {
 				tab.currentPage--
 			}
 		}
 		return m, nil
 
-	case "down", "j":
+	case m.keys.RowDown.Matches(key):
 		if tab.selectedRow < len(tab.result.Rows)-1 {
 			tab.selectedRow++
 			// Check if we need to go to next page
-			if tab.selectedRow >= (tab.currentPage+1)*pageSize {
+			if tab.selectedRow >= (tab.currentPage+1)*tab.rowsPerPage() {
 				tab.currentPage++
 			}
 		}
 		return m, nil
 
-	case "pgup", "ctrl+u":
+	case m.keys.PageUp.Matches(key):
 		if tab.currentPage > 0 {
 			tab.currentPage--
-			tab.selectedRow = tab.currentPage * pageSize
+			tab.selectedRow = tab.currentPage * tab.rowsPerPage()
+		} else if m.wrapPagination && tab.totalPages > 1 {
+			// Wrap around to the last page
+			tab.currentPage = tab.totalPages - 1
+			tab.selectedRow = tab.currentPage * tab.rowsPerPage()
 		}
 		return m, nil
 
-	case "pgdown", "ctrl+d":
+	case m.keys.PageDown.Matches(key):
 		if tab.currentPage < tab.totalPages-1 {
 			tab.currentPage++
-			tab.selectedRow = tab.currentPage * pageSize
+			tab.selectedRow = tab.currentPage * tab.rowsPerPage()
+		} else if tab.result.HasMoreRows() {
+			// Past the rows fetched so far: fetch the next batch and page into it
+			return m, m.fetchRows(true)
+		} else if m.wrapPagination && tab.totalPages > 1 {
+			// Wrap around to the first page
+			tab.currentPage = 0
+			tab.selectedRow = 0
 		}
 		return m, nil
 
-	case "home", "g":
+	case m.keys.PrevResult.Matches(key):
+		m.flipResult(-1)
+		return m, nil
+
+	case len(key) == 1 && key >= "1" && key <= "9" && tab.scriptResultsStart() >= 0:
+		// Pick one of the last script's results
+		m.showScriptResult(int(key[0] - '0'))
+		return m, nil
+
+	case m.keys.NextResult.Matches(key):
+		m.flipResult(1)
+		return m, nil
+
+	case m.keys.LoadMore.Matches(key):
+		return m, m.fetchMoreRows()
+
+	case m.keys.RerunDiff.Matches(key):
+		return m, m.rerunDiff()
+
+	case m.keys.ColumnLeft.Matches(key):
+		tab.stepColumn(-1)
+		tab.scrollToSelectedColumn(m.mainWidth())
+		return m, nil
+
+	case m.keys.ColumnRight.Matches(key):
+		tab.stepColumn(1)
+		tab.scrollToSelectedColumn(m.mainWidth())
+		return m, nil
+
+	case m.keys.PinColumn.Matches(key):
+		m.togglePinnedColumn(tab, tab.selectedColumn)
+		return m, nil
+
+	case m.keys.CopyCell.Matches(key):
+		m.copyCell()
+		return m, nil
+
+	case m.keys.CopyRowJSON.Matches(key):
+		m.copyRowJSON()
+		return m, nil
+
+	case m.keys.CopyRowInsert.Matches(key):
+		m.copyRowInsert()
+		return m, nil
+
+	case m.keys.NarrowColumn.Matches(key):
+		m.statusMessage = tab.resizeSelectedColumn(-1)
+		tab.scrollToSelectedColumn(m.mainWidth())
+		return m, nil
+
+	case m.keys.WidenColumn.Matches(key):
+		m.statusMessage = tab.resizeSelectedColumn(1)
+		tab.scrollToSelectedColumn(m.mainWidth())
+		return m, nil
+
+	case m.keys.ColumnPicker.Matches(key):
+		m.openColumnPicker()
+		return m, nil
+
+	case m.keys.SortColumn.Matches(key):
+		m.sortByColumn()
+		return m, nil
+
+	case m.keys.SortInDatabase.Matches(key):
+		return m, m.sortInDatabase()
+
+	case m.keys.Filter.Matches(key):
+		m.openFilter()
+		return m, nil
+
+	case m.keys.Search.Matches(key):
+		m.openResultSearch()
+		return m, nil
+
+	case tab.resultSearch != nil && tab.resultSearch.re != nil && m.keys.NextMatch.Matches(key):
+		m.stepResultSearch(1)
+		return m, nil
+
+	case tab.resultSearch != nil && tab.resultSearch.re != nil && m.keys.PrevMatch.Matches(key):
+		m.stepResultSearch(-1)
+		return m, nil
+
+	case m.keys.FirstRow.Matches(key):
 		tab.currentPage = 0
 		tab.selectedRow = 0
 		return m, nil
 
-	case "end", "G":
+	case m.keys.LastRow.Matches(key):
 		tab.currentPage = tab.totalPages - 1
 		tab.selectedRow = len(tab.result.Rows) - 1
 		return m, nil
@@ -265,10 +450,14 @@ func (m Model) handleConnectionPickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleAddTypeMode(msg)
 	case PickerModeAddTheme:
 		return m.handleAddThemeMode(msg)
+	case PickerModeAddNote:
+		return m.handleAddNoteMode(msg)
 	case PickerModeAddEncrypt:
 		return m.handleAddEncryptMode(msg)
 	case PickerModeConfirmDelete:
 		return m.handleConfirmDeleteMode(msg)
+	case PickerModeEditNote:
+		return m.handleEditNoteMode(msg)
 	}
 	return m, nil
 }
@@ -401,18 +590,24 @@ func (m Model) handleListMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 				m.statusMessage = "New tab created: " + selectedName
 			} else {
-				// Switch current tab's connection
-				if err := m.switchConnection(selectedName); err != nil {
-					m.connectionPicker.errorMessage = err.Error()
-					return m, nil
+				// Switch current tab's connection, once its transaction's ended
+				switchTo := func(m *Model) tea.Cmd {
+					if err := m.switchConnection(selectedName); err != nil {
+						m.statusMessage = "Switch failed: " + err.Error()
+						return nil
+					}
+					m.statusMessage = "Switched to: " + selectedName
+					return nil
 				}
+				tab := m.activeTabPtr()
 				m.focus = focusQuery
 				m.connectionPicker = nil
-				m.statusMessage = "Switched to: " + selectedName
-				tab := m.activeTabPtr()
 				if tab != nil {
 					tab.textarea.Focus()
 				}
+				if !m.endTransactionFirst(tab, "switching to "+selectedName, switchTo) {
+					switchTo(&m)
+				}
 			}
 		}
 		return m, nil
@@ -423,6 +618,7 @@ func (m Model) handleListMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.connectionPicker.newConnDSN = ""
 		m.connectionPicker.newConnType = ""
 		m.connectionPicker.newConnTheme = ""
+		m.connectionPicker.newConnNote = ""
 		m.connectionPicker.themeIdx = 0
 		m.connectionPicker.errorMessage = ""
 		return m, nil
@@ -433,6 +629,15 @@ func (m Model) handleListMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.connectionPicker.errorMessage = ""
 		}
 		return m, nil
+	case "e":
+		// Edit the selected connection's note
+		if len(m.connectionPicker.connections) > 0 {
+			name := m.connectionPicker.connections[m.connectionPicker.selectedIdx]
+			m.connectionPicker.newConnNote = m.vaultManager.GetConnectionNote(name)
+			m.connectionPicker.mode = PickerModeEditNote
+			m.connectionPicker.errorMessage = ""
+		}
+		return m, nil
 	case "up", "k":
 		if m.connectionPicker.selectedIdx > 0 {
 			m.connectionPicker.selectedIdx--
@@ -571,14 +776,13 @@ func (m Model) handleAddThemeMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.connectionPicker.errorMessage = ""
 		return m, nil
 	case "enter":
-		// Store theme and move to encryption choice
+		// Store theme and move to the (optional) note
 		theme := themes[m.connectionPicker.themeIdx]
 		if theme == "default" {
 			theme = ""
 		}
 		m.connectionPicker.newConnTheme = theme
-		m.connectionPicker.mode = PickerModeAddEncrypt
-		m.connectionPicker.encryptOptIdx = 0 // default to encrypted
+		m.connectionPicker.mode = PickerModeAddNote
 		m.connectionPicker.errorMessage = ""
 		return m, nil
 	case "up", "k":
@@ -595,13 +799,77 @@ func (m Model) handleAddThemeMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleAddNoteMode handles entering an optional free-form note
+func (m Model) handleAddNoteMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.connectionPicker.mode = PickerModeAddTheme
+		m.connectionPicker.errorMessage = ""
+		return m, nil
+	case "enter":
+		m.connectionPicker.newConnNote = strings.TrimSpace(m.connectionPicker.newConnNote)
+		m.connectionPicker.mode = PickerModeAddEncrypt
+		m.connectionPicker.encryptOptIdx = 0 // default to encrypted
+		m.connectionPicker.errorMessage = ""
+		return m, nil
+	case "backspace":
+		if len(m.connectionPicker.newConnNote) > 0 {
+			m.connectionPicker.newConnNote = m.connectionPicker.newConnNote[:len(m.connectionPicker.newConnNote)-1]
+		}
+		return m, nil
+	default:
+		if len(msg.String()) == 1 {
+			m.connectionPicker.newConnNote += msg.String()
+		}
+		return m, nil
+	}
+}
+
+// handleEditNoteMode handles editing the selected connection's note
+func (m Model) handleEditNoteMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.connectionPicker.mode = PickerModeList
+		m.connectionPicker.newConnNote = ""
+		m.connectionPicker.errorMessage = ""
+		return m, nil
+	case "enter":
+		name := m.connectionPicker.connections[m.connectionPicker.selectedIdx]
+		note := strings.TrimSpace(m.connectionPicker.newConnNote)
+		if err := m.vaultManager.SetConnectionNote(name, note); err != nil {
+			m.connectionPicker.errorMessage = "Failed to save note: " + err.Error()
+			return m, nil
+		}
+		// Tabs on the connection show the new note straight away
+		for _, tab := range m.tabs {
+			if tab.connectionName == name {
+				tab.note = note
+			}
+		}
+		m.connectionPicker.mode = PickerModeList
+		m.connectionPicker.newConnNote = ""
+		m.connectionPicker.errorMessage = ""
+		return m, nil
+	case "backspace":
+		if len(m.connectionPicker.newConnNote) > 0 {
+			m.connectionPicker.newConnNote = m.connectionPicker.newConnNote[:len(m.connectionPicker.newConnNote)-1]
+		}
+		return m, nil
+	default:
+		if len(msg.String()) == 1 {
+			m.connectionPicker.newConnNote += msg.String()
+		}
+		return m, nil
+	}
+}
+
 // handleAddEncryptMode handles choosing whether to encrypt the DSN
 func (m Model) handleAddEncryptMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	options := []string{"Encrypted (requires password)", "Plaintext (no password needed)"}
 
 	switch msg.String() {
 	case "esc":
-		m.connectionPicker.mode = PickerModeAddTheme
+		m.connectionPicker.mode = PickerModeAddNote
 		m.connectionPicker.errorMessage = ""
 		return m, nil
 	case "enter":
@@ -647,6 +915,13 @@ func (m Model) handleAddEncryptMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.connectionPicker.newConnNote != "" {
+			if err := m.vaultManager.SetConnectionNote(m.connectionPicker.newConnName, m.connectionPicker.newConnNote); err != nil {
+				m.connectionPicker.errorMessage = "Failed to save note: " + err.Error()
+				return m, nil
+			}
+		}
+
 		// Refresh and go back to list
 		m.connectionPicker.connections = m.vaultManager.ListConnections()
 		m.connectionPicker.mode = PickerModeList
@@ -656,6 +931,7 @@ func (m Model) handleAddEncryptMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.connectionPicker.newConnDSN = ""
 		m.connectionPicker.newConnType = ""
 		m.connectionPicker.newConnTheme = ""
+		m.connectionPicker.newConnNote = ""
 		m.connectionPicker.noEncrypt = false
 		// Select the new connection
 		for i, name := range m.connectionPicker.connections {
@@ -714,6 +990,69 @@ func (m Model) handleConfirmDeleteMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleDatabasePromptKeys handles key events in the database switch prompt
+func (m Model) handleDatabasePromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.focus = focusQuery
+		m.statusMessage = "Database switch cancelled"
+		if tab := m.activeTabPtr(); tab != nil {
+			tab.textarea.Focus()
+		}
+		return m, nil
+	case "up":
+		if m.databaseSelected > 0 {
+			m.databaseSelected--
+		}
+		return m, nil
+	case "down":
+		if m.databaseSelected < len(m.filteredDatabaseChoices())-1 {
+			m.databaseSelected++
+		}
+		return m, nil
+	case "enter":
+		// Pick the highlighted entry, or connect to whatever name was typed
+		choice := DatabaseChoice{Name: strings.TrimSpace(m.databaseInput.Value())}
+		if matches := m.filteredDatabaseChoices(); m.databaseSelected < len(matches) {
+			choice = matches[m.databaseSelected]
+		}
+		switchTo := func(m *Model) tea.Cmd {
+			var err error
+			if choice.Schema {
+				err = m.switchSchema(choice.Name)
+			} else {
+				err = m.switchDatabase(choice.Name)
+			}
+			switch {
+			case err != nil:
+				m.statusMessage = "Switch failed: " + err.Error()
+			case choice.Schema:
+				m.statusMessage = "Switched to schema: " + choice.Name
+			default:
+				m.statusMessage = "Switched to database: " + choice.Name
+			}
+			return nil
+		}
+		m.focus = focusQuery
+		tab := m.activeTabPtr()
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+		if !m.endTransactionFirst(tab, "switching to "+choice.Name, switchTo) {
+			switchTo(&m)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := m.databaseInput.Value()
+	m.databaseInput, cmd = m.databaseInput.Update(msg)
+	if m.databaseInput.Value() != before {
+		m.databaseSelected = 0
+	}
+	return m, cmd
+}
+
 // closeConnectionPicker closes the picker and returns to query view
 func (m Model) closeConnectionPicker(message string) (tea.Model, tea.Cmd) {
 	m.focus = focusQuery
@@ -726,3 +1065,741 @@ func (m Model) closeConnectionPicker(message string) (tea.Model, tea.Cmd) {
 	}
 	return m, nil
 }
+
+// handleGotoLineKeys handles key events in the go-to-line prompt
+func (m Model) handleGotoLineKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+
+	switch msg.String() {
+	case "esc":
+		m.gotoLine = nil
+		m.focus = focusQuery
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.gotoLine.Value())
+		m.gotoLine = nil
+		m.focus = focusQuery
+		if text == "" || tab == nil {
+			return m, nil
+		}
+		line, err := strconv.Atoi(text)
+		if err != nil || line < 1 {
+			m.statusMessage = "Invalid line number: " + text
+			return m, nil
+		}
+		content := tab.textarea.Value()
+		moveCursorTo(&tab.textarea, lineOffset(content, line-1))
+		m.statusMessage = ""
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	*m.gotoLine, cmd = m.gotoLine.Update(msg)
+	return m, cmd
+}
+
+// handleWatchPromptKeys handles key events in the watch interval prompt
+func (m Model) handleWatchPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.watchPrompt = nil
+		m.focus = focusQuery
+		return m, nil
+	case "enter":
+		text := m.watchPrompt.Value()
+		m.watchPrompt = nil
+		m.focus = focusQuery
+		interval, err := parseWatchInterval(text)
+		if err != nil {
+			m.statusMessage = "Error: " + err.Error()
+			return m, nil
+		}
+		return m, m.startWatch(interval)
+	}
+
+	var cmd tea.Cmd
+	*m.watchPrompt, cmd = m.watchPrompt.Update(msg)
+	return m, cmd
+}
+
+// handleFilterPromptKeys handles key events in the results filter prompt,
+// which filters the rows as the text is typed
+func (m Model) handleFilterPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	switch msg.String() {
+	case "esc":
+		m.filterPrompt = nil
+		m.focus = focusResults
+		if tab != nil {
+			tab.setFilter("")
+		}
+		return m, nil
+	case "enter":
+		m.filterPrompt = nil
+		m.focus = focusResults
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	*m.filterPrompt, cmd = m.filterPrompt.Update(msg)
+	if tab != nil {
+		tab.setFilter(m.filterPrompt.Value())
+	}
+	return m, cmd
+}
+
+// handleResultSearchKeys handles key events in the results search prompt:
+// typing selects the first matching cell from where the search started,
+// Enter keeps the search for n/N to step through, and Esc clears it
+func (m Model) handleResultSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.resultSearch == nil || tab.result == nil {
+		m.focus = focusResults
+		return m, nil
+	}
+	s := tab.resultSearch
+
+	switch msg.String() {
+	case "esc":
+		tab.resultSearch = nil
+		m.focus = focusResults
+		return m, nil
+	case "enter":
+		if s.err != nil {
+			return m, nil // fix the pattern first
+		}
+		if s.re == nil {
+			tab.resultSearch = nil
+		}
+		m.focus = focusResults
+		m.statusMessage = s.status()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := s.input.Value()
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() == before {
+		return m, cmd
+	}
+	s.compile()
+	s.find(tab.result.Rows, tab.result.hidden, s.originRow, s.originCol, 1, true)
+	if s.current < 0 {
+		// No match: back to where the search started
+		tab.selectedRow, tab.selectedColumn = s.originRow, s.originCol
+		tab.currentPage = tab.selectedRow / tab.rowsPerPage()
+		tab.scrollToSelectedColumn(m.mainWidth())
+	} else {
+		m.showSearchMatch(tab)
+	}
+	return m, cmd
+}
+
+// handleEditorSearchKeys handles key events in the editor search bar:
+// typing moves the cursor to the first match, Enter/↓ and ↑ step through
+// the matches, and Esc leaves the cursor at the current one
+func (m Model) handleEditorSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	s := m.editorSearch
+
+	switch key := msg.String(); {
+	case key == "esc":
+		m.editorSearch = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+		return m, nil
+	case key == "enter", key == "down", m.keys.Search.Matches(key):
+		s.step(1)
+	case key == "up":
+		s.step(-1)
+	default:
+		var cmd tea.Cmd
+		before := s.input.Value()
+		s.input, cmd = s.input.Update(msg)
+		if s.input.Value() == before || tab == nil {
+			return m, cmd
+		}
+		s.update(tab.textarea.Value())
+		if s.current < 0 {
+			moveCursorTo(&tab.textarea, s.origin) // no match: back to where the search started
+		} else {
+			moveCursorTo(&tab.textarea, s.matches[s.current].start)
+		}
+		return m, cmd
+	}
+	if tab != nil && s.current >= 0 {
+		moveCursorTo(&tab.textarea, s.matches[s.current].start)
+	}
+	return m, nil
+}
+
+// handleFinderKeys handles key events in the table/column fuzzy finder
+func (m Model) handleFinderKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	f := m.finder
+
+	closeFinder := func() {
+		m.finder = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+	}
+
+	switch msg.String() {
+	case "esc":
+		closeFinder()
+		return m, nil
+	case "up", "ctrl+k":
+		if f.selected > 0 {
+			f.selected--
+		}
+		return m, nil
+	case "down", "ctrl+j":
+		if f.selected < len(f.matches)-1 {
+			f.selected++
+		}
+		return m, nil
+	case "enter":
+		if f.selected >= len(f.matches) || tab == nil {
+			return m, nil
+		}
+		item := f.matches[f.selected]
+		name := item.Column
+		if name == "" {
+			name = item.Table
+		}
+		closeFinder()
+		tab.textarea.InsertString(quoteNameIfNeeded(name, tab.dbType))
+		m.statusMessage = "Inserted " + item.Label()
+		return m, nil
+	case "tab":
+		if f.selected >= len(f.matches) {
+			return m, nil
+		}
+		item := f.matches[f.selected]
+		m.finder = nil
+		m.revealInSidebar(item)
+		return m, nil
+	case " ":
+		// Names don't contain spaces, so space peeks at the table instead
+		if f.selected < len(f.matches) {
+			m.openPreview(f.matches[f.selected].Table)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := f.input.Value()
+	f.input, cmd = f.input.Update(msg)
+	if f.input.Value() != before {
+		f.filter()
+	}
+	return m, cmd
+}
+
+// handleVariablePromptKeys handles key events in the query variable prompt
+func (m Model) handleVariablePromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	p := m.variablePrompt
+
+	closePrompt := func() {
+		m.variablePrompt = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+	}
+
+	switch msg.String() {
+	case "esc":
+		closePrompt()
+		m.statusMessage = "Query cancelled"
+		return m, nil
+	case "up", "shift+tab":
+		p.focus((p.focused - 1 + len(p.inputs)) % len(p.inputs))
+		return m, nil
+	case "down", "tab":
+		p.focus((p.focused + 1) % len(p.inputs))
+		return m, nil
+	case "enter":
+		// Enter moves through the fields and runs the query from the last one
+		if p.focused < len(p.inputs)-1 {
+			p.focus(p.focused + 1)
+			return m, nil
+		}
+		values := p.values()
+		if m.variableValues == nil {
+			m.variableValues = make(map[string]string)
+		}
+		for name, value := range values {
+			m.variableValues[name] = value
+		}
+		closePrompt()
+		if p.all {
+			return m, m.runStatements(p.query, values)
+		}
+		query, args := bindVariables(p.query, p.vars, values, tab.dbType)
+		return m, m.runQuery(query, args...)
+	}
+
+	var cmd tea.Cmd
+	p.inputs[p.focused], cmd = p.inputs[p.focused].Update(msg)
+	return m, cmd
+}
+
+// handleWriteConfirmKeys handles key events in the confirmation of an
+// UPDATE or DELETE without WHERE
+func (m Model) handleWriteConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.writeConfirm
+
+	closeConfirm := func() {
+		m.writeConfirm = nil
+		m.focus = c.returnFocus
+		if tab := m.activeTabPtr(); tab != nil && m.focus == focusQuery {
+			tab.textarea.Focus()
+		}
+	}
+
+	switch msg.String() {
+	case "esc":
+		closeConfirm()
+		c.tab.script = nil // the rest of a script doesn't run either
+		m.statusMessage = c.verb + " cancelled"
+		return m, nil
+	case "enter":
+		if c.input.Value() != "YES" {
+			c.mismatch = true
+			return m, nil
+		}
+		closeConfirm()
+		if !slices.Contains(m.tabs, c.tab) || c.tab.running != "" {
+			return m, nil
+		}
+		return m, m.launchQuery(c.tab, c.query, c.args...)
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	c.mismatch = false
+	return m, cmd
+}
+
+// handleColumnPickerKeys handles key events in the column picker
+func (m Model) handleColumnPickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.columnPicker
+
+	switch key := msg.String(); {
+	case key == "esc", key == "q", m.keys.ColumnPicker.Matches(key):
+		m.columnPicker = nil
+		m.focus = focusResults
+	case key == "up", key == "k":
+		p.selected = max(p.selected-1, 0)
+	case key == "down", key == "j":
+		p.selected = min(p.selected+1, len(p.tab.result.Columns)-1)
+	case key == " ", key == "enter":
+		m.toggleHiddenColumn()
+	case key == "a":
+		m.showAllColumns()
+	case m.keys.PinColumn.Matches(key):
+		m.togglePinnedColumn(p.tab, p.selected)
+	}
+	return m, nil
+}
+
+// handleSessionPanelKeys handles key events in the session settings panel
+func (m Model) handleSessionPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.sessionPanel
+
+	if p.editing {
+		switch msg.String() {
+		case "esc":
+			p.editing = false
+			p.input.Blur()
+			p.err = ""
+			return m, nil
+		case "enter":
+			m.applySessionSetting(strings.TrimSpace(p.input.Value()))
+			return m, nil
+		}
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.sessionPanel = nil
+		m.focus = focusQuery
+		if tab := m.activeTabPtr(); tab != nil {
+			tab.textarea.Focus()
+		}
+	case "up", "k":
+		p.selected = max(p.selected-1, 0)
+	case "down", "j":
+		p.selected = min(p.selected+1, len(p.settings)-1)
+	case "enter":
+		p.edit()
+	case "d", "delete":
+		if _, saved := p.tab.session[p.settings[p.selected].name]; saved {
+			m.applySessionSetting("")
+		}
+	}
+	return m, nil
+}
+
+// handleSnippetKeys handles key events in the snippet picker
+func (m Model) handleSnippetKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	p := m.snippets
+
+	closeSnippets := func() {
+		m.snippets = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+	}
+
+	switch msg.String() {
+	case "esc":
+		closeSnippets()
+		return m, nil
+	case "up", "ctrl+k":
+		if p.selected > 0 {
+			p.selected--
+		}
+		return m, nil
+	case "down", "ctrl+j":
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+		return m, nil
+	case "enter":
+		if p.selected >= len(p.matches) {
+			return m, nil
+		}
+		snippet := p.matches[p.selected]
+		closeSnippets()
+		m.insertSnippet(snippet)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return m, cmd
+}
+
+// handleBookmarkKeys handles key events in the bookmark picker
+func (m Model) handleBookmarkKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	p := m.bookmarks
+
+	closeBookmarks := func() {
+		m.bookmarks = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+	}
+
+	if m.keys.Run.Matches(msg.String()) {
+		// Run the bookmarked statement where it is in the editor
+		if p.selected >= len(p.matches) || tab == nil {
+			return m, nil
+		}
+		bookmark := p.matches[p.selected]
+		closeBookmarks()
+		m.jumpToBookmark(bookmark)
+		return m, m.runQueryUnderCursor()
+	}
+
+	switch msg.String() {
+	case "esc":
+		closeBookmarks()
+		return m, nil
+	case "up", "ctrl+k":
+		if p.selected > 0 {
+			p.selected--
+		}
+		return m, nil
+	case "down", "ctrl+j":
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+		return m, nil
+	case "enter":
+		if p.selected >= len(p.matches) || tab == nil {
+			return m, nil
+		}
+		bookmark := p.matches[p.selected]
+		closeBookmarks()
+		m.jumpToBookmark(bookmark)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return m, cmd
+}
+
+// handleHistoryKeys handles key events in the query history browser
+func (m Model) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	h := m.history
+
+	closeHistory := func() {
+		m.history = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+	}
+
+	if m.keys.History.Matches(msg.String()) {
+		closeHistory()
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		closeHistory()
+		return m, nil
+	case "up", "ctrl+k":
+		if h.selected > 0 {
+			h.selected--
+		}
+		return m, nil
+	case "down", "ctrl+j":
+		if h.selected < len(h.matches)-1 {
+			h.selected++
+		}
+		return m, nil
+	case "enter":
+		if h.selected >= len(h.matches) || tab == nil {
+			return m, nil
+		}
+		stmt := h.matches[h.selected].Statement
+		closeHistory()
+		// Start a new line unless the cursor is already at the start of one
+		content := tab.textarea.Value()
+		before := content[:textareaCursorOffset(tab.textarea)]
+		if line := before[strings.LastIndexByte(before, '\n')+1:]; strings.TrimSpace(line) != "" {
+			stmt = "\n" + stmt
+		}
+		tab.textarea.InsertString(stmt + ";")
+		m.statusMessage = "Inserted statement from history"
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	before := h.input.Value()
+	h.input, cmd = h.input.Update(msg)
+	if h.input.Value() != before {
+		h.filter()
+	}
+	return m, cmd
+}
+
+// handleDDLViewKeys handles key events in the read-only DDL viewer
+func (m Model) handleDDLViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	v := m.ddlView
+	lineCount := strings.Count(v.ddl, "\n") + 1
+	page := max(m.height-6, 1)
+	maxScroll := max(lineCount-page, 0)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.focus = v.returnFocus
+		m.ddlView = nil
+		if m.focus == focusQuery && tab != nil {
+			tab.textarea.Focus()
+		}
+	case "up", "k":
+		v.scroll = max(v.scroll-1, 0)
+	case "down", "j":
+		v.scroll = min(v.scroll+1, maxScroll)
+	case "pgup":
+		v.scroll = max(v.scroll-page, 0)
+	case "pgdown", " ":
+		v.scroll = min(v.scroll+page, maxScroll)
+	case "home", "g":
+		v.scroll = 0
+	case "end", "G":
+		v.scroll = maxScroll
+	case "c", "enter":
+		// appendQueryToTextarea adds the final semicolon itself
+		m.appendQueryToTextarea(strings.TrimSuffix(strings.TrimSpace(v.ddl), ";"))
+		m.ddlView = nil
+		m.focus = focusQuery
+		if tab != nil {
+			tab.textarea.Focus()
+		}
+		m.statusMessage = "DDL for " + v.table + " copied to editor"
+	}
+	return m, nil
+}
+
+// handlePlanViewKeys handles key events in the EXPLAIN ANALYZE tree viewer
+func (m Model) handlePlanViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.planView
+	count := len(v.rows())
+	page := max(m.height-10-planDetailLines-len(v.plan.Summary), 3)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.focus = v.returnFocus
+		m.planView = nil
+	case "up", "k":
+		v.selected = max(v.selected-1, 0)
+	case "down", "j":
+		v.selected = min(v.selected+1, count-1)
+	case "pgup":
+		v.selected = max(v.selected-page, 0)
+	case "pgdown":
+		v.selected = min(v.selected+page, count-1)
+	case "home", "g":
+		v.selected = 0
+	case "end", "G":
+		v.selected = count - 1
+	case "enter", " ":
+		v.toggle()
+	case "left", "h":
+		v.collapseOrParent()
+	case "right", "l":
+		if rows := v.rows(); v.collapsed[rows[v.selected].node] {
+			v.toggle()
+		}
+	}
+	return m, nil
+}
+
+// handlePreviewKeys handles key events in the table preview popup
+func (m Model) handlePreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if stmt := m.preview.proceed; stmt != "" {
+		// Previewing an UPDATE or DELETE: Enter runs it, Esc doesn't
+		switch msg.String() {
+		case "enter":
+			m.focus = m.preview.returnFocus
+			m.preview = nil
+			if tab := m.activeTabPtr(); tab != nil && m.focus == focusQuery {
+				tab.textarea.Focus()
+			}
+			return m, m.runQuery(stmt)
+		case "esc", "q":
+			m.focus = m.preview.returnFocus
+			m.preview = nil
+			if tab := m.activeTabPtr(); tab != nil && m.focus == focusQuery {
+				tab.textarea.Focus()
+			}
+			m.statusMessage = "Not run"
+		}
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "q", " ", "enter":
+		m.focus = m.preview.returnFocus
+		m.preview = nil
+	}
+	return m, nil
+}
+
+// handleERViewKeys handles key events in the entity-relationship overview
+func (m Model) handleERViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.erView
+	page := max(m.height-5, 1)
+	maxScroll := max(len(v.lines)-page, 0)
+
+	switch msg.String() {
+	case "esc", "q":
+		m.erView = nil
+		m.focus = focusQuery
+		if tab := m.activeTabPtr(); tab != nil {
+			tab.textarea.Focus()
+		}
+	case "up", "k":
+		v.scroll = max(v.scroll-1, 0)
+	case "down", "j":
+		v.scroll = min(v.scroll+1, maxScroll)
+	case "pgup":
+		v.scroll = max(v.scroll-page, 0)
+	case "pgdown", " ":
+		v.scroll = min(v.scroll+page, maxScroll)
+	case "home", "g":
+		v.scroll = 0
+	case "end", "G":
+		v.scroll = maxScroll
+	}
+	return m, nil
+}
+
+// handleSidebarKeys handles key events in the schema browser sidebar
+func (m Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return m, nil
+	}
+	tables := tab.schema.tables
+
+	switch msg.String() {
+	case "esc", "tab":
+		m.focus = focusQuery
+		tab.textarea.Focus()
+	case "up", "k":
+		if tab.sidebarSelected > 0 {
+			tab.sidebarSelected--
+		}
+	case "down", "j":
+		if tab.sidebarSelected < sidebarItemCount(tab)-1 {
+			tab.sidebarSelected++
+		}
+	case "home", "g":
+		tab.sidebarSelected = 0
+	case "end", "G":
+		tab.sidebarSelected = max(sidebarItemCount(tab)-1, 0)
+	case "right", "l", "left", "h":
+		if tab.sidebarSelected >= len(tables) {
+			return m, nil
+		}
+		name := tables[tab.sidebarSelected].Name
+		if msg.String() == "right" || msg.String() == "l" {
+			m.expandSidebarTable(name)
+		} else {
+			delete(tab.sidebarExpanded, name)
+		}
+	case " ":
+		if tab.sidebarSelected < len(tables) {
+			m.openPreview(tables[tab.sidebarSelected].Name)
+		}
+	case "p":
+		if tab.sidebarSelected < len(tables) {
+			m.openGrants(tables[tab.sidebarSelected].Name)
+		}
+	case "enter":
+		// Triggers and routines open their definition read-only
+		if selectedSidebarObject(tab) != nil {
+			m.openDDLView()
+			return m, nil
+		}
+		if tab.sidebarSelected >= len(tables) {
+			return m, nil
+		}
+		table := tables[tab.sidebarSelected]
+		m.appendQueryToTextarea(selectTableSQL(table.Name, tab.dbType, 100))
+		m.focus = focusQuery
+		tab.textarea.Focus()
+		m.statusMessage = "Query for " + table.Name + " appended. Press Ctrl+R to execute."
+	}
+	return m, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultHealthInterval is how often connections are pinged unless
+// health_check_interval says otherwise
+const defaultHealthInterval = 30 * time.Second
+
+// slowPing is the round trip above which the title bar shows a connection
+// as slow
+const slowPing = 150 * time.Millisecond
+
+// connHealth is what the last ping of a tab's connection found
+type connHealth struct {
+	db      *sql.DB // the connection pinged, in case the tab has switched since
+	latency time.Duration
+	err     error
+	version string // the server's version, read with the first ping
+}
+
+// healthCheckMsg is sent every health check interval to ping the tabs'
+// connections
+type healthCheckMsg struct{}
+
+// healthResultMsg is sent when a ping started by checkHealth finishes
+type healthResultMsg struct {
+	tab    *Tab
+	health connHealth
+}
+
+// healthCheckTick schedules the next ping of the tabs' connections, if
+// health checks are on
+func healthCheckTick(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return healthCheckMsg{}
+	})
+}
+
+// serverVersionQuery returns the query reading a database's server version
+func serverVersionQuery(dbType string) string {
+	switch getDriverName(dbType) {
+	case "pgx":
+		return "SHOW server_version"
+	case "mysql":
+		return "SELECT VERSION()"
+	case "sqlite3":
+		return "SELECT sqlite_version()"
+	}
+	return ""
+}
+
+// checkHealth pings every tab's connection in the background, which also
+// keeps idle connections from timing out. Single-connection pools (CSV
+// files) are skipped, since a result being fetched holds their connection.
+func (m *Model) checkHealth() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, tab := range m.tabs {
+		if tab.db == nil || tab.db.Stats().MaxOpenConnections == 1 {
+			continue
+		}
+		db, dbType := tab.db, tab.dbType
+		version := ""
+		if tab.health.db == db {
+			version = tab.health.version
+		}
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			start := time.Now()
+			health := connHealth{db: db, version: version}
+			health.err = db.PingContext(ctx)
+			health.latency = time.Since(start)
+			if health.err == nil && health.version == "" && serverVersionQuery(dbType) != "" {
+				_ = db.QueryRowContext(ctx, serverVersionQuery(dbType)).Scan(&health.version)
+			}
+			return healthResultMsg{tab: tab, health: health}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderHealth renders the active tab's connection health for the title
+// bar: a green dot with the ping time and server version, yellow when the
+// ping is slow, or red when the server didn't answer
+func (m Model) renderHealth() string {
+	tab := m.tab()
+	if tab == nil || tab.health.db == nil || tab.health.db != tab.db {
+		return ""
+	}
+	h := tab.health
+	color, text := tab.theme.Success, fmt.Sprintf("● %s", formatDuration(h.latency))
+	switch {
+	case h.err != nil:
+		color, text = tab.theme.Danger, fmt.Sprintf("● unreachable: %s", truncateString(h.err.Error(), 60))
+	case h.latency > slowPing:
+		color = tab.theme.Warning
+		text += " (slow)"
+	}
+	if h.version != "" && h.err == nil {
+		text += " · " + h.version
+	}
+	return " " + lipgloss.NewStyle().Foreground(color).Render(text)
+}
@@ -7,6 +7,43 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// sqlKeywords are highlighted (case-insensitively) and offered as completions
+var sqlKeywords = []string{
+	// DML
+	"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "LIKE", "BETWEEN",
+	"IS", "NULL", "AS", "ON", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER",
+	"CROSS", "FULL", "NATURAL", "USING", "ORDER", "BY", "ASC", "DESC",
+	"LIMIT", "OFFSET", "GROUP", "HAVING", "DISTINCT", "ALL", "UNION",
+	"INTERSECT", "EXCEPT", "INTO", "VALUES", "SET", "UPDATE", "DELETE",
+	"INSERT", "REPLACE", "TRUNCATE", "CREATE", "ALTER", "DROP", "TABLE",
+	"INDEX", "VIEW", "DATABASE", "SCHEMA", "IF", "EXISTS", "CASCADE",
+	"CONSTRAINT", "PRIMARY", "KEY", "FOREIGN", "REFERENCES", "UNIQUE",
+	"CHECK", "DEFAULT", "AUTO_INCREMENT", "AUTOINCREMENT",
+	// Data types
+	"INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "FLOAT", "DOUBLE",
+	"DECIMAL", "NUMERIC", "REAL", "BOOLEAN", "BOOL", "CHAR", "VARCHAR",
+	"TEXT", "BLOB", "DATE", "TIME", "DATETIME", "TIMESTAMP", "SERIAL",
+	// Transaction
+	"BEGIN", "COMMIT", "ROLLBACK", "TRANSACTION", "SAVEPOINT",
+	// Other
+	"CASE", "WHEN", "THEN", "ELSE", "END", "CAST", "CONVERT", "COALESCE",
+	"NULLIF", "TRUE", "FALSE", "WITH", "RECURSIVE", "EXPLAIN", "ANALYZE",
+}
+
+// sqlFunctions are the aggregate and common functions highlighted before
+// an opening parenthesis and offered as completions
+var sqlFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "ROUND", "FLOOR", "CEIL", "ABS",
+	"UPPER", "LOWER", "TRIM", "LTRIM", "RTRIM", "LENGTH", "SUBSTR", "SUBSTRING",
+	"REPLACE", "CONCAT", "CONCAT_WS", "COALESCE", "IFNULL", "NULLIF", "IIF",
+	"NOW", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "DATE",
+	"YEAR", "MONTH", "DAY", "HOUR", "MINUTE", "SECOND", "STRFTIME",
+	"PRINTF", "TYPEOF", "INSTR", "GROUP_CONCAT", "RANDOM", "HEX", "QUOTE",
+}
+
+// maxHighlightCache caps how many highlighted lines a highlighter keeps
+const maxHighlightCache = 4096
+
 // SQLHighlighter provides SQL syntax highlighting for the query window
 type SQLHighlighter struct {
 	theme Theme
@@ -14,61 +51,49 @@ type SQLHighlighter struct {
 	// Compiled patterns
 	keywordPattern  *regexp.Regexp
 	functionPattern *regexp.Regexp
-	stringPattern   *regexp.Regexp
 	numberPattern   *regexp.Regexp
-	commentPattern  *regexp.Regexp
 	operatorPattern *regexp.Regexp
+
+	// Strings and comments are matched together so whichever starts first wins
+	// (a quote inside a comment, or a comment marker inside a string)
+	literalPattern *regexp.Regexp
+
+	// dollarQuotes highlights $$...$$ and $tag$...$tag$ as strings
+	dollarQuotes bool
+
+	// cache maps lines to their highlighted text, so redrawing the editor
+	// only tokenizes lines that have changed
+	cache map[string]string
 }
 
 // NewSQLHighlighter creates a new SQL highlighter with the given theme
 func NewSQLHighlighter(theme Theme) *SQLHighlighter {
-	// SQL keywords (case-insensitive matching)
-	keywords := []string{
-		// DML
-		"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "LIKE", "BETWEEN",
-		"IS", "NULL", "AS", "ON", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER",
-		"CROSS", "FULL", "NATURAL", "USING", "ORDER", "BY", "ASC", "DESC",
-		"LIMIT", "OFFSET", "GROUP", "HAVING", "DISTINCT", "ALL", "UNION",
-		"INTERSECT", "EXCEPT", "INTO", "VALUES", "SET", "UPDATE", "DELETE",
-		"INSERT", "REPLACE", "TRUNCATE", "CREATE", "ALTER", "DROP", "TABLE",
-		"INDEX", "VIEW", "DATABASE", "SCHEMA", "IF", "EXISTS", "CASCADE",
-		"CONSTRAINT", "PRIMARY", "KEY", "FOREIGN", "REFERENCES", "UNIQUE",
-		"CHECK", "DEFAULT", "AUTO_INCREMENT", "AUTOINCREMENT",
-		// Data types
-		"INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "FLOAT", "DOUBLE",
-		"DECIMAL", "NUMERIC", "REAL", "BOOLEAN", "BOOL", "CHAR", "VARCHAR",
-		"TEXT", "BLOB", "DATE", "TIME", "DATETIME", "TIMESTAMP", "SERIAL",
-		// Transaction
-		"BEGIN", "COMMIT", "ROLLBACK", "TRANSACTION", "SAVEPOINT",
-		// Other
-		"CASE", "WHEN", "THEN", "ELSE", "END", "CAST", "CONVERT", "COALESCE",
-		"NULLIF", "TRUE", "FALSE", "WITH", "RECURSIVE", "EXPLAIN", "ANALYZE",
-	}
-
-	// SQL aggregate and common functions
-	functions := []string{
-		"COUNT", "SUM", "AVG", "MIN", "MAX", "ROUND", "FLOOR", "CEIL", "ABS",
-		"UPPER", "LOWER", "TRIM", "LTRIM", "RTRIM", "LENGTH", "SUBSTR", "SUBSTRING",
-		"REPLACE", "CONCAT", "CONCAT_WS", "COALESCE", "IFNULL", "NULLIF", "IIF",
-		"NOW", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "DATE",
-		"YEAR", "MONTH", "DAY", "HOUR", "MINUTE", "SECOND", "STRFTIME",
-		"PRINTF", "TYPEOF", "INSTR", "GROUP_CONCAT", "RANDOM", "HEX", "QUOTE",
-	}
+	return NewSQLHighlighterForDB(theme, "")
+}
 
+// NewSQLHighlighterForDB creates a SQL highlighter that also understands
+// database-specific syntax, such as MySQL's # line comments
+func NewSQLHighlighterForDB(theme Theme, dbType string) *SQLHighlighter {
 	// Build keyword pattern (word boundaries, case-insensitive)
-	keywordStr := `(?i)\b(` + strings.Join(keywords, "|") + `)\b`
+	keywordStr := `(?i)\b(` + strings.Join(sqlKeywords, "|") + `)\b`
 
 	// Build function pattern (word followed by open paren)
-	funcStr := `(?i)\b(` + strings.Join(functions, "|") + `)\s*\(`
+	funcStr := `(?i)\b(` + strings.Join(sqlFunctions, "|") + `)\s*\(`
+
+	stringStr := `'[^']*'|"[^"]*"|` + "`[^`]*`"
+	commentStr := `--.*$|/\*[\s\S]*?\*/`
+	if hashCommentsAllowed(dbType) {
+		commentStr = `--.*$|#.*$|/\*[\s\S]*?\*/`
+	}
 
 	return &SQLHighlighter{
 		theme:           theme,
 		keywordPattern:  regexp.MustCompile(keywordStr),
 		functionPattern: regexp.MustCompile(funcStr),
-		stringPattern:   regexp.MustCompile(`'[^']*'|"[^"]*"`),
 		numberPattern:   regexp.MustCompile(`\b-?\d+\.?\d*\b`),
-		commentPattern:  regexp.MustCompile(`--.*$|/\*[\s\S]*?\*/`),
 		operatorPattern: regexp.MustCompile(`[<>=!]+|[+\-*/%]|\|\||&&`),
+		literalPattern:  regexp.MustCompile(stringStr + "|" + commentStr),
+		dollarQuotes:    dollarQuotesAllowed(dbType),
 	}
 }
 
@@ -130,25 +155,21 @@ func (h *SQLHighlighter) tokenize(sql string) []token {
 	var tokens []token
 	covered := make([]bool, len(sql))
 
-	// Find comments first (highest priority - they can contain anything)
-	for _, match := range h.commentPattern.FindAllStringIndex(sql, -1) {
-		if !h.isOverlapping(covered, match[0], match[1]) {
-			tokens = append(tokens, token{
-				text:  sql[match[0]:match[1]],
-				typ:   tokenComment,
-				start: match[0],
-				end:   match[1],
-			})
-			h.markCovered(covered, match[0], match[1])
-		}
-	}
-
-	// Find strings (second priority)
-	for _, match := range h.stringPattern.FindAllStringIndex(sql, -1) {
+	// Find comments and strings first (highest priority - they can contain anything)
+	for _, match := range h.literalMatches(sql) {
 		if !h.isOverlapping(covered, match[0], match[1]) {
+			typ := tokenComment
+			switch sql[match[0]] {
+			case '\'', '"', '$':
+				typ = tokenString
+			case '`':
+				// A quoted identifier: left unstyled, but keywords inside it
+				// aren't highlighted
+				typ = tokenText
+			}
 			tokens = append(tokens, token{
 				text:  sql[match[0]:match[1]],
-				typ:   tokenString,
+				typ:   typ,
 				start: match[0],
 				end:   match[1],
 			})
@@ -221,6 +242,41 @@ func (h *SQLHighlighter) tokenize(sql string) []token {
 	return tokens
 }
 
+// literalMatches finds the strings and comments in sql, in order. Dollar-quoted
+// strings are found by hand, since a regexp can't require the closing tag to
+// repeat the opening one; one left open on this line runs to the end of it.
+func (h *SQLHighlighter) literalMatches(sql string) [][2]int {
+	var matches [][2]int
+	pos := 0
+	for pos < len(sql) {
+		next := len(sql)
+		loc := h.literalPattern.FindStringIndex(sql[pos:])
+		if loc != nil {
+			next = pos + loc[0]
+		}
+		dollar := -1
+		if h.dollarQuotes {
+			for i := pos; i < next; i++ {
+				if tag := dollarQuoteTag(sql, i); tag != "" {
+					dollar = i
+					matches = append(matches, [2]int{i, dollarQuoteEnd(sql, i, tag)})
+					pos = matches[len(matches)-1][1]
+					break
+				}
+			}
+		}
+		if dollar >= 0 {
+			continue
+		}
+		if loc == nil {
+			break
+		}
+		matches = append(matches, [2]int{pos + loc[0], pos + loc[1]})
+		pos += loc[1]
+	}
+	return matches
+}
+
 func (h *SQLHighlighter) isOverlapping(covered []bool, start, end int) bool {
 	for i := start; i < end && i < len(covered); i++ {
 		if covered[i] {
@@ -268,7 +324,17 @@ func (h *SQLHighlighter) styleToken(tok token) string {
 	return style.Render(tok.text)
 }
 
-// HighlightLine highlights a single line of SQL
+// HighlightLine highlights a single line of SQL, reusing the result from the
+// last time the same text was highlighted
 func (h *SQLHighlighter) HighlightLine(line string) string {
-	return h.Highlight(line)
+	if highlighted, ok := h.cache[line]; ok {
+		return highlighted
+	}
+	highlighted := h.Highlight(line)
+	if h.cache == nil || len(h.cache) >= maxHighlightCache {
+		// Start over rather than track which lines are stale
+		h.cache = make(map[string]string)
+	}
+	h.cache[line] = highlighted
+	return highlighted
 }
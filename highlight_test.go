@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -255,3 +257,116 @@ func TestSQLHighlighter_TokenizePriority(t *testing.T) {
 		t.Errorf("string token = %q, want %q", stringTokens[0].text, "'SELECT * FROM fake'")
 	}
 }
+
+func TestSQLHighlighter_HashComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		dbType      string
+		input       string
+		wantComment string // expected comment token text, "" for none
+		wantString  string // expected string token text, "" for none
+	}{
+		{"mysql hash comment", "mysql", "SELECT 1; # done; really", "# done; really", ""},
+		{"mysql hash inside string", "mysql", "SELECT '#fff' FROM colors", "", "'#fff'"},
+		{"mysql comment after string", "mysql", "SELECT 'a' # note", "# note", "'a'"},
+		{"postgres hash is an operator", "postgres", "SELECT data #> '{a}' FROM t", "", "'{a}'"},
+		{"dash comment inside string", "", "SELECT '-- not a comment'", "", "'-- not a comment'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSQLHighlighterForDB(DefaultTheme, tt.dbType)
+			var comment, str string
+			for _, tok := range h.tokenize(tt.input) {
+				switch tok.typ {
+				case tokenComment:
+					comment = tok.text
+				case tokenString:
+					str = tok.text
+				}
+			}
+			if comment != tt.wantComment {
+				t.Errorf("comment token = %q, want %q", comment, tt.wantComment)
+			}
+			if str != tt.wantString {
+				t.Errorf("string token = %q, want %q", str, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestSQLHighlighter_DollarQuotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		dbType      string
+		input       string
+		wantStrings []string
+	}{
+		{"anonymous dollar quote", "postgres", "SELECT $$it's; -- fine$$ AS s", []string{"$$it's; -- fine$$"}},
+		{"tagged dollar quote", "postgres", "AS $fn$ SELECT $$x$$ $fn$ LANGUAGE sql", []string{"$fn$ SELECT $$x$$ $fn$"}},
+		{"opened on this line", "postgres", "CREATE FUNCTION f() RETURNS int AS $$", []string{"$$"}},
+		{"dollar quote inside string", "postgres", "SELECT '$$', 1", []string{"'$$'"}},
+		{"dollar quote inside comment", "postgres", "SELECT 1 -- $$ note", nil},
+		{"positional parameter", "postgres", "SELECT $1, $2", nil},
+		{"mysql delimiter", "mysql", "DELIMITER $$", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSQLHighlighterForDB(DefaultTheme, tt.dbType)
+			var strs []string
+			for _, tok := range h.tokenize(tt.input) {
+				if tok.typ == tokenString {
+					strs = append(strs, tok.text)
+				}
+			}
+			if !reflect.DeepEqual(strs, tt.wantStrings) {
+				t.Errorf("string tokens = %q, want %q", strs, tt.wantStrings)
+			}
+		})
+	}
+}
+
+func TestSQLHighlighter_BacktickIdentifiers(t *testing.T) {
+	h := NewSQLHighlighterForDB(DefaultTheme, "mysql")
+	tokens := h.tokenize("SELECT `select`, 'a' FROM `from; -- x` WHERE 1")
+
+	var keywords, texts []string
+	for _, tok := range tokens {
+		switch tok.typ {
+		case tokenKeyword:
+			keywords = append(keywords, tok.text)
+		case tokenText:
+			texts = append(texts, tok.text)
+		}
+	}
+	if want := []string{"SELECT", "FROM", "WHERE"}; !reflect.DeepEqual(keywords, want) {
+		t.Errorf("keywords = %q, want %q", keywords, want)
+	}
+	if want := []string{"`select`", "`from; -- x`"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("identifier tokens = %q, want %q", texts, want)
+	}
+}
+
+func TestSQLHighlighter_LineCache(t *testing.T) {
+	h := NewSQLHighlighter(DefaultTheme)
+	line := "SELECT id FROM users WHERE name = 'x'"
+
+	first := h.HighlightLine(line)
+	if first != h.Highlight(line) {
+		t.Errorf("HighlightLine() = %q, want the same as Highlight()", first)
+	}
+	if _, ok := h.cache[line]; !ok {
+		t.Fatal("HighlightLine() didn't cache the line")
+	}
+	if again := h.HighlightLine(line); again != first {
+		t.Errorf("cached HighlightLine() = %q, want %q", again, first)
+	}
+
+	for i := range maxHighlightCache + 10 {
+		h.HighlightLine(fmt.Sprintf("SELECT %d", i))
+	}
+	if len(h.cache) > maxHighlightCache {
+		t.Errorf("cache holds %d lines, want at most %d", len(h.cache), maxHighlightCache)
+	}
+}
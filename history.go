@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// historyDirName is the directory under the SQL directory holding one
+// history file per connection
+const historyDirName = ".history"
+
+// HistoryEntry is one executed statement in a connection's history file
+type HistoryEntry struct {
+	Time       time.Time `json:"time"`
+	Connection string    `json:"connection"`
+	Statement  string    `json:"statement"`
+	DurationMs int64     `json:"duration_ms"`
+	Rows       int64     `json:"rows"` // rows returned, or rows affected
+	Error      string    `json:"error,omitempty"`
+}
+
+// newHistoryEntry describes a statement and its result for the history file
+func newHistoryEntry(connection, stmt string, result *QueryResult, duration time.Duration) HistoryEntry {
+	entry := HistoryEntry{
+		Time:       time.Now(),
+		Connection: connection,
+		Statement:  stmt,
+		DurationMs: duration.Milliseconds(),
+		Rows:       result.RowsAffected,
+	}
+	if !result.Executed {
+		entry.Rows = int64(len(result.Rows))
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	return entry
+}
+
+// historyPath returns the history file for a connection in the SQL directory
+func historyPath(sqlDir, connection string) string {
+	return filepath.Join(sqlDir, historyDirName, sanitizeFilename(connection)+".jsonl")
+}
+
+// historyConnectionName names the tab's connection for its history file:
+// the saved connection name, or else the database name
+func historyConnectionName(tab *Tab) string {
+	if tab.connectionName != "" {
+		return tab.connectionName
+	}
+	if name := extractDatabaseName(tab.dsn, tab.dbType); name != "" {
+		return name
+	}
+	return tab.dbType
+}
+
+// appendHistory adds an entry to the end of a history file, one JSON object per line
+func appendHistory(path string, entry HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return f.Close()
+}
+
+// loadHistory reads a history file, newest entry first. A missing file is an
+// empty history, and lines that can't be parsed are skipped.
+func loadHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // statements can be long
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// filterHistory returns the entries whose statement contains every word of
+// search (case-insensitive), keeping their order
+func filterHistory(entries []HistoryEntry, search string) []HistoryEntry {
+	words := strings.Fields(strings.ToLower(search))
+	var matches []HistoryEntry
+	for _, entry := range entries {
+		stmt := strings.ToLower(entry.Statement)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(stmt, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// HistoryBrowser is the searchable list of a connection's past statements
+type HistoryBrowser struct {
+	connection string
+	input      textinput.Model
+	entries    []HistoryEntry
+	matches    []HistoryEntry
+	selected   int
+}
+
+// newHistoryBrowser creates a browser over entries, newest first
+func newHistoryBrowser(connection string, entries []HistoryEntry) *HistoryBrowser {
+	ti := textinput.New()
+	ti.Placeholder = "search statements"
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Focus()
+
+	h := &HistoryBrowser{connection: connection, input: ti, entries: entries}
+	h.filter()
+	return h
+}
+
+// filter recomputes the matches for the current search
+func (h *HistoryBrowser) filter() {
+	h.matches = filterHistory(h.entries, h.input.Value())
+	h.selected = 0
+}
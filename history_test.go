@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHistoryRoundTrip tests appending entries to a history file and reading them back newest first
+func TestHistoryRoundTrip(t *testing.T) {
+	path := historyPath(t.TempDir(), "prod db")
+	if filepath.Base(path) != "prod_db.jsonl" {
+		t.Errorf("historyPath() = %s, want a prod_db.jsonl file", path)
+	}
+
+	entries, err := loadHistory(path)
+	if err != nil || entries != nil {
+		t.Fatalf("loadHistory() of a missing file = %v, %v; want no entries and no error", entries, err)
+	}
+
+	first := HistoryEntry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Connection: "prod db", Statement: "SELECT 1", Rows: 1}
+	second := HistoryEntry{Time: first.Time.Add(time.Minute), Connection: "prod db", Statement: "DELETE FROM users\nWHERE id = 3", Rows: 1, DurationMs: 12}
+	for _, entry := range []HistoryEntry{first, second} {
+		if err := appendHistory(path, entry); err != nil {
+			t.Fatalf("appendHistory() error: %v", err)
+		}
+	}
+
+	// A corrupt line (e.g. from an interrupted write) is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	_, _ = f.WriteString("{not json\n")
+	_ = f.Close()
+
+	entries, err = loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory() error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("loadHistory() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Statement != second.Statement || !entries[0].Time.Equal(second.Time) || entries[0].DurationMs != 12 {
+		t.Errorf("newest entry = %+v, want %+v", entries[0], second)
+	}
+	if entries[1].Statement != first.Statement {
+		t.Errorf("oldest entry = %+v, want %+v", entries[1], first)
+	}
+}
+
+// TestNewHistoryEntry tests recording row counts and errors from a query result
+func TestNewHistoryEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *QueryResult
+		rows   int64
+		errMsg string
+	}{
+		{"rows returned", &QueryResult{Rows: [][]CellValue{{}, {}}}, 2, ""},
+		{"rows affected", &QueryResult{Executed: true, RowsAffected: 5}, 5, ""},
+		{"error", &QueryResult{Error: errors.New("no such table: nope")}, 0, "no such table: nope"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := newHistoryEntry("local", "SELECT 1", tc.result, 1500*time.Millisecond)
+			if entry.Rows != tc.rows || entry.Error != tc.errMsg || entry.DurationMs != 1500 {
+				t.Errorf("newHistoryEntry() = %+v, want rows %d, error %q and 1500ms", entry, tc.rows, tc.errMsg)
+			}
+		})
+	}
+}
+
+// TestFilterHistory tests searching statements by every word, ignoring case
+func TestFilterHistory(t *testing.T) {
+	entries := []HistoryEntry{
+		{Statement: "SELECT * FROM orders WHERE total > 100"},
+		{Statement: "select name from users"},
+		{Statement: "UPDATE users SET name = 'x'"},
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"", []string{entries[0].Statement, entries[1].Statement, entries[2].Statement}},
+		{"USERS", []string{entries[1].Statement, entries[2].Statement}},
+		{"users update", []string{entries[2].Statement}},
+		{"invoices", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.search, func(t *testing.T) {
+			var got []string
+			for _, entry := range filterHistory(entries, tc.search) {
+				got = append(got, entry.Statement)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("filterHistory(%q) = %v, want %v", tc.search, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("filterHistory(%q)[%d] = %q, want %q", tc.search, i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyBinding is the keys that trigger an action, as Bubble Tea names them
+// (e.g. "ctrl+r", "alt+s", "f5", "pgdown")
+type KeyBinding []string
+
+// Matches reports whether key is one of the binding's keys
+func (b KeyBinding) Matches(key string) bool {
+	return slices.Contains(b, key)
+}
+
+// UnmarshalYAML lets a binding be written as a single key or a list of keys
+func (b *KeyBinding) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*b = KeyBinding{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*b = keys
+	return nil
+}
+
+// Keymap is the keys bound to each of dibber's actions. Keys that only mean
+// something inside a dialog (Esc, Enter, y/n, arrows in lists) aren't in it.
+type Keymap struct {
+	// Global
+	Quit             KeyBinding
+	Save             KeyBinding
+	OpenFile         KeyBinding
+	ExternalEditor   KeyBinding
+	NewTab           KeyBinding
+	NextTab          KeyBinding
+	PrevTab          KeyBinding
+	CloseTab         KeyBinding
+	SwitchConnection KeyBinding
+	SwitchDatabase   KeyBinding
+	ReloadSchema     KeyBinding
+	Messages         KeyBinding
+	MessagesUp       KeyBinding
+	MessagesDown     KeyBinding
+	Sidebar          KeyBinding
+	ShowDDL          KeyBinding
+	EROverview       KeyBinding
+	ExportSchema     KeyBinding
+	Snippets         KeyBinding
+	Bookmarks        KeyBinding
+	History          KeyBinding
+	Finder           KeyBinding
+	SessionSettings  KeyBinding
+
+	// Query editor
+	Run               KeyBinding
+	CancelQuery       KeyBinding
+	RunAll            KeyBinding
+	RunNext           KeyBinding
+	RunPrev           KeyBinding
+	Explain           KeyBinding
+	PreviewWrite      KeyBinding
+	Watch             KeyBinding
+	Transaction       KeyBinding
+	Format            KeyBinding
+	UppercaseKeywords KeyBinding
+	ToggleComment     KeyBinding
+	NextStatement     KeyBinding
+	PrevStatement     KeyBinding
+	GotoLine          KeyBinding
+	Search            KeyBinding
+	Select            KeyBinding
+	Paste             KeyBinding
+	CopyStatement     KeyBinding
+	Undo              KeyBinding
+	Redo              KeyBinding
+
+	// Results
+	RowUp          KeyBinding
+	RowDown        KeyBinding
+	PageUp         KeyBinding
+	PageDown       KeyBinding
+	FirstRow       KeyBinding
+	LastRow        KeyBinding
+	ColumnLeft     KeyBinding
+	ColumnRight    KeyBinding
+	SortColumn     KeyBinding
+	SortInDatabase KeyBinding
+	Filter         KeyBinding
+	NextMatch      KeyBinding
+	PrevMatch      KeyBinding
+	ColumnPicker   KeyBinding
+	PinColumn      KeyBinding
+	NarrowColumn   KeyBinding
+	WidenColumn    KeyBinding
+	CopyCell       KeyBinding
+	CopyRowJSON    KeyBinding
+	CopyRowInsert  KeyBinding
+	PrevResult     KeyBinding
+	NextResult     KeyBinding
+	LoadMore       KeyBinding
+	RerunDiff      KeyBinding
+	ShrinkEditor   KeyBinding
+	GrowEditor     KeyBinding
+
+	// Row detail view
+	FollowForeignKey KeyBinding
+	AppendUpdate     KeyBinding
+	AppendDelete     KeyBinding
+	AppendInsert     KeyBinding
+	ExecuteUpdate    KeyBinding
+	ExecuteDelete    KeyBinding
+	ExecuteInsert    KeyBinding
+	ToggleNull       KeyBinding
+	CopyField        KeyBinding
+}
+
+// DefaultKeymap returns dibber's built-in keybindings
+func DefaultKeymap() Keymap {
+	return Keymap{
+		Quit:             KeyBinding{"ctrl+q", "ctrl+c"},
+		Save:             KeyBinding{"ctrl+s"},
+		OpenFile:         KeyBinding{"ctrl+o"},
+		ExternalEditor:   KeyBinding{"ctrl+e"},
+		NewTab:           KeyBinding{"ctrl+t"},
+		NextTab:          KeyBinding{"ctrl+tab", "ctrl+pgdown"},
+		PrevTab:          KeyBinding{"ctrl+shift+tab", "ctrl+pgup"},
+		CloseTab:         KeyBinding{"ctrl+w"},
+		SwitchConnection: KeyBinding{"ctrl+p"},
+		SwitchDatabase:   KeyBinding{"ctrl+b"},
+		ReloadSchema:     KeyBinding{"alt+r", "ctrl+shift+r"},
+		Messages:         KeyBinding{"alt+m"},
+		MessagesUp:       KeyBinding{"alt+up"},
+		MessagesDown:     KeyBinding{"alt+down"},
+		Sidebar:          KeyBinding{"alt+s"},
+		ShowDDL:          KeyBinding{"alt+t"},
+		EROverview:       KeyBinding{"alt+e"},
+		ExportSchema:     KeyBinding{"alt+x"},
+		Snippets:         KeyBinding{"alt+n"},
+		Bookmarks:        KeyBinding{"alt+g"},
+		History:          KeyBinding{"ctrl+h"},
+		Finder:           KeyBinding{"alt+o"},
+		SessionSettings:  KeyBinding{"alt+,"},
+
+		Run:               KeyBinding{"ctrl+r", "f5"},
+		CancelQuery:       KeyBinding{"esc", "ctrl+c"},
+		RunAll:            KeyBinding{"alt+R"},
+		RunNext:           KeyBinding{"alt+j"},
+		RunPrev:           KeyBinding{"alt+k"},
+		Explain:           KeyBinding{"ctrl+x"},
+		PreviewWrite:      KeyBinding{"alt+p"},
+		Watch:             KeyBinding{"alt+w"},
+		Transaction:       KeyBinding{"alt+T"},
+		Format:            KeyBinding{"alt+F"},
+		UppercaseKeywords: KeyBinding{"alt+U"},
+		ToggleComment:     KeyBinding{"ctrl+_", "ctrl+/"}, // terminals send Ctrl+/ as Ctrl+_
+		NextStatement:     KeyBinding{"ctrl+down"},
+		PrevStatement:     KeyBinding{"ctrl+up"},
+		GotoLine:          KeyBinding{"ctrl+g"},
+		Search:            KeyBinding{"ctrl+f"},
+		Select:            KeyBinding{"alt+v"},
+		Paste:             KeyBinding{"ctrl+v"},
+		CopyStatement:     KeyBinding{"alt+c"},
+		Undo:              KeyBinding{"ctrl+z"},
+		Redo:              KeyBinding{"ctrl+y"},
+
+		RowUp:          KeyBinding{"up", "k"},
+		RowDown:        KeyBinding{"down", "j"},
+		PageUp:         KeyBinding{"pgup", "ctrl+u"},
+		PageDown:       KeyBinding{"pgdown", "ctrl+d"},
+		FirstRow:       KeyBinding{"home", "g"},
+		LastRow:        KeyBinding{"end", "G"},
+		ColumnLeft:     KeyBinding{"left", "h"},
+		ColumnRight:    KeyBinding{"right", "l"},
+		SortColumn:     KeyBinding{"s"},
+		SortInDatabase: KeyBinding{"S"},
+		Filter:         KeyBinding{"/"},
+		NextMatch:      KeyBinding{"n"},
+		PrevMatch:      KeyBinding{"N"},
+		ColumnPicker:   KeyBinding{"c"},
+		PinColumn:      KeyBinding{"p"},
+		NarrowColumn:   KeyBinding{"<"},
+		WidenColumn:    KeyBinding{">"},
+		CopyCell:       KeyBinding{"y"},
+		CopyRowJSON:    KeyBinding{"Y"},
+		CopyRowInsert:  KeyBinding{"I"},
+		PrevResult:     KeyBinding{"alt+left"},
+		NextResult:     KeyBinding{"alt+right"},
+		LoadMore:       KeyBinding{"alt+l"},
+		RerunDiff:      KeyBinding{"r"},
+		ShrinkEditor:   KeyBinding{"-"},
+		GrowEditor:     KeyBinding{"+", "="},
+
+		FollowForeignKey: KeyBinding{"ctrl+g"},
+		AppendUpdate:     KeyBinding{"f5", "ctrl+u"},
+		AppendDelete:     KeyBinding{"f6", "ctrl+d"},
+		AppendInsert:     KeyBinding{"f7", "ctrl+i"},
+		ExecuteUpdate:    KeyBinding{"alt+u"},
+		ExecuteDelete:    KeyBinding{"alt+d"},
+		ExecuteInsert:    KeyBinding{"alt+i"},
+		ToggleNull:       KeyBinding{"ctrl+n"},
+		CopyField:        KeyBinding{"alt+y"},
+	}
+}
+
+// bindings maps the action names used in ~/.dibber.yaml to the keymap's bindings
+func (k *Keymap) bindings() map[string]*KeyBinding {
+	return map[string]*KeyBinding{
+		"quit":              &k.Quit,
+		"save":              &k.Save,
+		"open_file":         &k.OpenFile,
+		"external_editor":   &k.ExternalEditor,
+		"new_tab":           &k.NewTab,
+		"next_tab":          &k.NextTab,
+		"prev_tab":          &k.PrevTab,
+		"close_tab":         &k.CloseTab,
+		"switch_connection": &k.SwitchConnection,
+		"switch_database":   &k.SwitchDatabase,
+		"reload_schema":     &k.ReloadSchema,
+		"messages":          &k.Messages,
+		"messages_up":       &k.MessagesUp,
+		"messages_down":     &k.MessagesDown,
+		"sidebar":           &k.Sidebar,
+		"show_ddl":          &k.ShowDDL,
+		"er_overview":       &k.EROverview,
+		"export_schema":     &k.ExportSchema,
+		"snippets":          &k.Snippets,
+		"bookmarks":         &k.Bookmarks,
+		"history":           &k.History,
+		"finder":            &k.Finder,
+		"session_settings":  &k.SessionSettings,
+
+		"run":                &k.Run,
+		"cancel_query":       &k.CancelQuery,
+		"run_all":            &k.RunAll,
+		"run_next":           &k.RunNext,
+		"run_prev":           &k.RunPrev,
+		"explain":            &k.Explain,
+		"preview_write":      &k.PreviewWrite,
+		"watch":              &k.Watch,
+		"transaction":        &k.Transaction,
+		"format":             &k.Format,
+		"uppercase_keywords": &k.UppercaseKeywords,
+		"toggle_comment":     &k.ToggleComment,
+		"next_statement":     &k.NextStatement,
+		"prev_statement":     &k.PrevStatement,
+		"goto_line":          &k.GotoLine,
+		"search":             &k.Search,
+		"select":             &k.Select,
+		"paste":              &k.Paste,
+		"copy_statement":     &k.CopyStatement,
+		"undo":               &k.Undo,
+		"redo":               &k.Redo,
+
+		"row_up":           &k.RowUp,
+		"row_down":         &k.RowDown,
+		"page_up":          &k.PageUp,
+		"page_down":        &k.PageDown,
+		"first_row":        &k.FirstRow,
+		"last_row":         &k.LastRow,
+		"column_left":      &k.ColumnLeft,
+		"column_right":     &k.ColumnRight,
+		"sort_column":      &k.SortColumn,
+		"sort_in_database": &k.SortInDatabase,
+		"filter":           &k.Filter,
+		"next_match":       &k.NextMatch,
+		"prev_match":       &k.PrevMatch,
+		"column_picker":    &k.ColumnPicker,
+		"pin_column":       &k.PinColumn,
+		"narrow_column":    &k.NarrowColumn,
+		"widen_column":     &k.WidenColumn,
+		"copy_cell":        &k.CopyCell,
+		"copy_row_json":    &k.CopyRowJSON,
+		"copy_row_insert":  &k.CopyRowInsert,
+		"prev_result":      &k.PrevResult,
+		"next_result":      &k.NextResult,
+		"load_more":        &k.LoadMore,
+		"rerun_diff":       &k.RerunDiff,
+		"shrink_editor":    &k.ShrinkEditor,
+		"grow_editor":      &k.GrowEditor,
+
+		"follow_foreign_key": &k.FollowForeignKey,
+		"append_update":      &k.AppendUpdate,
+		"append_delete":      &k.AppendDelete,
+		"append_insert":      &k.AppendInsert,
+		"execute_update":     &k.ExecuteUpdate,
+		"execute_delete":     &k.ExecuteDelete,
+		"execute_insert":     &k.ExecuteInsert,
+		"toggle_null":        &k.ToggleNull,
+		"copy_field":         &k.CopyField,
+	}
+}
+
+// Override replaces the bindings of the named actions. Unknown action names
+// are skipped and reported in the error, so one typo doesn't lose the rest.
+func (k *Keymap) Override(keys map[string]KeyBinding) error {
+	bindings := k.bindings()
+	var unknown []string
+	for action, binding := range keys {
+		b, ok := bindings[action]
+		if !ok {
+			unknown = append(unknown, action)
+			continue
+		}
+		*b = binding
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown key actions in config: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
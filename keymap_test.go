@@ -0,0 +1,112 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// TestKeymapOverride tests remapping actions from the config's keys section
+func TestKeymapOverride(t *testing.T) {
+	var cfg Config
+	data := `
+keys:
+  page_down: pgdown
+  append_delete: [f6, ctrl+x]
+  paeg_up: ctrl+b
+`
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := DefaultKeymap()
+	err := keys.Override(cfg.Keys)
+	if err == nil || err.Error() != "unknown key actions in config: paeg_up" {
+		t.Errorf("Override() error = %v, want the unknown action reported", err)
+	}
+
+	tests := []struct {
+		name    string
+		binding KeyBinding
+		key     string
+		want    bool
+	}{
+		{"remapped single key", keys.PageDown, "pgdown", true},
+		{"old key unbound", keys.PageDown, "ctrl+d", false},
+		{"remapped list", keys.AppendDelete, "ctrl+x", true},
+		{"list keeps listed key", keys.AppendDelete, "f6", true},
+		{"untouched action keeps defaults", keys.PageUp, "ctrl+u", true},
+		{"unknown action ignored", keys.SwitchDatabase, "ctrl+b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.binding.Matches(tt.key); got != tt.want {
+				t.Errorf("%v.Matches(%q) = %v, want %v", tt.binding, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKeymapBindings tests that every action can be named in the config
+func TestKeymapBindings(t *testing.T) {
+	keys := DefaultKeymap()
+	bindings := keys.bindings()
+	for name, b := range bindings {
+		if len(*b) == 0 {
+			t.Errorf("action %q has no default keys", name)
+		}
+	}
+
+	// No two names share a binding
+	var seen []*KeyBinding
+	for _, b := range bindings {
+		if slices.Contains(seen, b) {
+			t.Errorf("two action names share a binding")
+		}
+		seen = append(seen, b)
+	}
+}
+
+// TestDialogsTakeGlobalKeys tests that a dialog with a text input gets keys
+// that are global shortcuts elsewhere, rather than them acting behind it
+func TestDialogsTakeGlobalKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		focus focusState
+		open  func(m *Model)
+	}{
+		{"write confirmation", focusConfirmWrite, func(m *Model) {
+			m.writeConfirm = newWriteConfirm(m.tabs[0], "DELETE FROM t", nil, "DELETE", focusQuery)
+		}},
+		{"variable prompt", focusVariables, func(m *Model) {
+			query := "SELECT * FROM t WHERE id = :id"
+			m.variablePrompt = newVariablePrompt(query, findQueryVariables(query, "sqlite"), nil)
+		}},
+		{"history", focusHistory, func(m *Model) {
+			m.history = newHistoryBrowser("", nil)
+		}},
+		{"results filter", focusFilter, func(m *Model) {
+			ti := textinput.New()
+			m.filterPrompt = &ti
+		}},
+		{"session settings", focusSession, func(m *Model) {
+			m.sessionPanel = &SessionPanel{tab: m.tabs[0], input: textinput.New()}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{tabs: []*Tab{{}, {}}, keys: DefaultKeymap(), focus: tt.focus}
+			tt.open(&m)
+			for _, key := range []tea.KeyMsg{{Type: tea.KeyCtrlW}, {Type: tea.KeyCtrlT}, {Type: tea.KeyCtrlO}} {
+				next, _ := m.update(key)
+				if got := next.(Model); len(got.tabs) != 2 || got.focus != tt.focus {
+					t.Errorf("%s acted behind the dialog: %d tabs, focus %v", key, len(got.tabs), got.focus)
+				}
+			}
+		})
+	}
+}
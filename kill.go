@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// killTimeout bounds how long the statement killing a cancelled query may take
+const killTimeout = 5 * time.Second
+
+// serverQuery is where a running query runs on the server, so cancelling
+// it can also stop it there: cancelling the client's context alone leaves
+// MySQL working on it after the client has hung up
+type serverQuery struct {
+	db     *sql.DB
+	dbType string
+	connID atomic.Int64 // the connection's id on the server; 0 until known
+}
+
+// connectionIDQuery returns the statement reading the current connection's
+// id on the server, or "" if the database has no way to kill a query
+// (SQLite, which stops when its context is cancelled)
+func connectionIDQuery(dbType string) string {
+	switch getDriverName(dbType) {
+	case "mysql":
+		return "SELECT CONNECTION_ID()"
+	case "pgx":
+		return "SELECT pg_backend_pid()"
+	}
+	return ""
+}
+
+// killQueryStatement returns the statement that stops the query running on
+// the server connection id, leaving the connection open
+func killQueryStatement(dbType string, id int64) string {
+	if getDriverName(dbType) == "mysql" {
+		return fmt.Sprintf("KILL QUERY %d", id)
+	}
+	return fmt.Sprintf("SELECT pg_cancel_backend(%d)", id)
+}
+
+// newServerQuery returns the server-side handle for a query about to run
+// on db, or nil if the database can't kill queries
+func newServerQuery(db *sql.DB, dbType string) *serverQuery {
+	if db == nil || connectionIDQuery(dbType) == "" {
+		return nil
+	}
+	return &serverQuery{db: db, dbType: dbType}
+}
+
+// pin readies runner for a query that can be killed, reading the id of the
+// connection it'll run on. A transaction has its connection already; from
+// the pool, one is taken for the query to run on, and release gives it
+// back once the query's rows are closed - or closes it if the query was
+// cancelled, so a late kill can't stop the next query to use it. If the id
+// can't be read, the query runs on runner as it is.
+func (q *serverQuery) pin(ctx context.Context, runner sqlRunner) (pinned sqlRunner, release func()) {
+	release = func() {}
+	if q == nil {
+		return runner, release
+	}
+	if pool, ok := runner.(*sql.DB); ok {
+		if pool.Stats().MaxOpenConnections == 1 {
+			return runner, release // a second connection couldn't kill it
+		}
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			return runner, release
+		}
+		// Close waits for the rows to be closed before giving the
+		// connection back, so it mustn't hold up the query's result
+		runner, release = conn, func() {
+			cancelled := ctx.Err() != nil
+			go func() {
+				if cancelled {
+					_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+				}
+				_ = conn.Close()
+			}()
+		}
+	}
+
+	rows, err := runner.QueryContext(ctx, connectionIDQuery(q.dbType))
+	if err != nil {
+		return runner, release
+	}
+	defer rows.Close()
+	var id int64
+	if rows.Next() && rows.Scan(&id) == nil {
+		q.connID.Store(id)
+	}
+	return runner, release
+}
+
+// serverKillMsg reports how killing a cancelled query on the server went
+type serverKillMsg struct {
+	stmt     string
+	err      error
+	duration time.Duration
+}
+
+// kill stops the query on the server from another of the pool's
+// connections, once its connection's id is known
+func (q *serverQuery) kill() tea.Cmd {
+	if q == nil {
+		return nil
+	}
+	id := q.connID.Load()
+	if id == 0 {
+		return nil
+	}
+	stmt := killQueryStatement(q.dbType, id)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		start := time.Now()
+		_, err := q.db.ExecContext(ctx, stmt)
+		return serverKillMsg{stmt: stmt, err: err, duration: time.Since(start)}
+	}
+}
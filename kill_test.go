@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+// TestKillQueryStatement tests the statements that read a connection's id and kill its query
+func TestKillQueryStatement(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		idQuery  string
+		killStmt string
+	}{
+		{"mysql", "SELECT CONNECTION_ID()", "KILL QUERY 42"},
+		{"postgres", "SELECT pg_backend_pid()", "SELECT pg_cancel_backend(42)"},
+		{"pg", "SELECT pg_backend_pid()", "SELECT pg_cancel_backend(42)"},
+		{"sqlite", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			if got := connectionIDQuery(tt.dbType); got != tt.idQuery {
+				t.Errorf("connectionIDQuery(%q) = %q, want %q", tt.dbType, got, tt.idQuery)
+			}
+			if tt.idQuery == "" {
+				if newServerQuery(setupTestDB(t), tt.dbType) != nil {
+					t.Errorf("newServerQuery(%q) should be nil", tt.dbType)
+				}
+				return
+			}
+			if got := killQueryStatement(tt.dbType, 42); got != tt.killStmt {
+				t.Errorf("killQueryStatement(%q, 42) = %q, want %q", tt.dbType, got, tt.killStmt)
+			}
+		})
+	}
+}
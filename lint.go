@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// hugeTableRows is the row count from which reading a whole table with
+// SELECT * is flagged
+const hugeTableRows = 1_000_000
+
+// lintStatement returns warnings about a statement, to show before it runs.
+// rowCount looks up a table's row count if it's already known; nothing is
+// queried to lint.
+func lintStatement(stmt string, rowCount func(table string) (RowCount, bool)) []string {
+	warnings := unbalancedWarnings(stmt)
+	words := topLevelWords(stmt)
+	if len(words) == 0 {
+		return warnings
+	}
+	has := func(word string) bool {
+		for _, w := range words {
+			if w == word {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch words[0] {
+	case "DELETE":
+		if !has("WHERE") {
+			warnings = append(warnings, "DELETE without WHERE removes every row")
+		}
+	case "UPDATE":
+		if !has("WHERE") {
+			warnings = append(warnings, "UPDATE without WHERE changes every row")
+		}
+	case "SELECT":
+		if len(words) < 2 || words[1] != "*" || has("WHERE") || has("LIMIT") || has("FETCH") || rowCount == nil {
+			break
+		}
+		seen := make(map[string]bool)
+		var tables []string
+		for _, table := range referencedTables(stmt) {
+			if !seen[strings.ToLower(table)] {
+				seen[strings.ToLower(table)] = true
+				tables = append(tables, table)
+			}
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			if count, ok := rowCount(table); ok && count.Rows >= hugeTableRows {
+				warnings = append(warnings, fmt.Sprintf("SELECT * reads all of %s (%s rows)", table, formatRowCount(count)))
+			}
+		}
+	}
+	return warnings
+}
+
+// topLevelWords returns a statement's words (uppercased) and operators
+// outside parentheses, so a WHERE in a subquery doesn't count for the
+// statement
+func topLevelWords(stmt string) []string {
+	var words []string
+	depth := 0
+	for _, tok := range tokenizeForFormat(stmt) {
+		switch {
+		case tok.kind == fmtPunct && tok.text == "(":
+			depth++
+		case tok.kind == fmtPunct && tok.text == ")":
+			depth--
+		case tok.kind == fmtWord && depth == 0:
+			words = append(words, strings.ToUpper(tok.text))
+		case tok.kind == fmtOperator && depth == 0:
+			words = append(words, tok.text)
+		}
+	}
+	return words
+}
+
+// unscopedWrite returns "UPDATE" or "DELETE" if stmt is one without a
+// WHERE clause, which changes every row of its table, or "" otherwise
+func unscopedWrite(stmt string) string {
+	words := topLevelWords(stmt)
+	if len(words) == 0 || (words[0] != "UPDATE" && words[0] != "DELETE") || slices.Contains(words, "WHERE") {
+		return ""
+	}
+	return words[0]
+}
+
+// unbalancedWarnings flags unterminated strings, quoted identifiers and
+// comments, and unmatched parentheses
+func unbalancedWarnings(stmt string) []string {
+	var warnings []string
+	depth := 0
+	n := len(stmt)
+	for i := 0; i < n; i++ {
+		ch := stmt[i]
+		switch {
+		case ch == '-' && i+1 < n && stmt[i+1] == '-':
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				i = n
+			} else {
+				i += end
+			}
+		case ch == '/' && i+1 < n && stmt[i+1] == '*':
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return append(warnings, "Unterminated /* comment")
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			j := i + 1
+			closed := false
+			for j < n {
+				if stmt[j] == '\\' && ch == '\'' && j+1 < n {
+					j += 2
+					continue
+				}
+				if stmt[j] == ch {
+					if j+1 < n && stmt[j+1] == ch { // doubled quote
+						j += 2
+						continue
+					}
+					closed = true
+					break
+				}
+				j++
+			}
+			if !closed {
+				if ch == '\'' {
+					return append(warnings, "Unterminated string")
+				}
+				return append(warnings, "Unterminated quoted identifier "+string(ch))
+			}
+			i = j
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth < 0 {
+				warnings = append(warnings, "Unmatched )")
+				depth = 0
+			}
+		}
+	}
+	if depth > 0 {
+		warnings = append(warnings, "Unclosed (")
+	}
+	return warnings
+}
+
+// cursorLintWarnings lints the statement Ctrl+R would run from the cursor.
+// Selected text isn't linted.
+func (m Model) cursorLintWarnings() []string {
+	tab := m.tab()
+	if tab == nil || tab.selection != nil {
+		return nil
+	}
+	content := tab.textarea.Value()
+	start, end := statementRangeAtLine(content, tab.textarea.Line(), tab.dbType)
+	if start < 0 {
+		return nil
+	}
+	return lintStatement(content[start:end], tab.schema.CachedRowCount)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLintStatement tests the warnings shown for the statement under the cursor
+func TestLintStatement(t *testing.T) {
+	rowCount := func(table string) (RowCount, bool) {
+		switch strings.ToLower(table) {
+		case "events":
+			return RowCount{Rows: 12_000_000, Estimate: true}, true
+		case "users":
+			return RowCount{Rows: 500}, true
+		}
+		return RowCount{}, false
+	}
+
+	tests := []struct {
+		name string
+		stmt string
+		want []string
+	}{
+		{"clean select", "SELECT id FROM users WHERE id = 1;", nil},
+		{"delete without where", "DELETE FROM users;", []string{"DELETE without WHERE removes every row"}},
+		{"delete with where", "delete from users where id = 1;", nil},
+		{"update without where", "-- reset\nUPDATE users SET active = 0;", []string{"UPDATE without WHERE changes every row"}},
+		{"where only in subquery", "UPDATE users SET n = (SELECT count(*) FROM events WHERE user_id = 1);", []string{"UPDATE without WHERE changes every row"}},
+		{"where in a comment", "DELETE FROM users -- WHERE id = 1\n;", []string{"DELETE without WHERE removes every row"}},
+		{"select star on huge table", "SELECT * FROM events;", []string{"SELECT * reads all of events (~12M rows)"}},
+		{"select star with limit", "SELECT * FROM events LIMIT 10;", nil},
+		{"select star with where", "SELECT * FROM events e WHERE e.id = 1;", nil},
+		{"select star on small table", "SELECT * FROM users;", nil},
+		{"select star on unknown table", "SELECT * FROM orders;", nil},
+		{"unterminated string", "SELECT 'abc FROM users;", []string{"Unterminated string"}},
+		{"doubled quote", "SELECT 'it''s' FROM users WHERE id = 1;", nil},
+		{"quote in comment", "SELECT 1 -- it's fine\n;", nil},
+		{"unterminated identifier", `SELECT "name FROM users;`, []string{`Unterminated quoted identifier "`}},
+		{"unterminated comment", "SELECT 1 /* note;", []string{"Unterminated /* comment"}},
+		{"unclosed paren", "SELECT count(id FROM users WHERE id = 1;", []string{"Unclosed ("}},
+		{"unmatched paren", "SELECT id) FROM users WHERE id = 1;", []string{"Unmatched )"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintStatement(tt.stmt, rowCount)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintStatement(%q) = %q, want %q", tt.stmt, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnscopedWrite tests spotting UPDATEs and DELETEs that change every row
+func TestUnscopedWrite(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{"DELETE FROM users;", "DELETE"},
+		{"-- reset\nupdate users set active = 0;", "UPDATE"},
+		{"UPDATE users SET n = (SELECT count(*) FROM events WHERE user_id = 1);", "UPDATE"},
+		{"DELETE FROM users WHERE id = 1;", ""},
+		{"UPDATE users SET active = 0 WHERE id IN (1, 2);", ""},
+		{"SELECT * FROM users;", ""},
+		{"INSERT INTO users (id) VALUES (1);", ""},
+	}
+
+	for _, tt := range tests {
+		if got := unscopedWrite(tt.stmt); got != tt.want {
+			t.Errorf("unscopedWrite(%q) = %q, want %q", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+// TestWriteConfirmTable tests naming the table in the confirmation
+func TestWriteConfirmTable(t *testing.T) {
+	tests := []struct {
+		stmt string
+		verb string
+		want string
+	}{
+		{"DELETE FROM users;", "DELETE", "users"},
+		{"-- reset\nUPDATE app.users SET active = 0;", "UPDATE", "app.users"},
+		{"UPDATE users u SET n = 1 FROM events e;", "UPDATE", "users"},
+	}
+
+	for _, tt := range tests {
+		c := &WriteConfirm{tab: &Tab{dbType: "postgres"}, query: tt.stmt, verb: tt.verb}
+		if got := c.table(); got != tt.want {
+			t.Errorf("table() for %q = %q, want %q", tt.stmt, got, tt.want)
+		}
+	}
+}
@@ -19,6 +19,7 @@ func main() {
 	dsn := flag.String("dsn", "", "Database connection string (use this OR -conn)")
 	connectionName := flag.String("conn", "", "Named connection from ~/.dibber.yaml")
 	dbType := flag.String("type", "", "Database type: mysql, postgres, sqlite (auto-detected if not specified)")
+	strictType := flag.Bool("strict-type", false, "Disable type auto-detection (requires -type or a stored connection type)")
 
 	// Connection management flags
 	addConnection := flag.String("add-conn", "", "Add a new named connection (requires -dsn)")
@@ -27,13 +28,20 @@ func main() {
 	listThemes := flag.Bool("list-themes", false, "List all available themes")
 	changePassword := flag.Bool("change-password", false, "Change the encryption password")
 	themeName := flag.String("theme", "", "Theme for the connection (use with -add-conn)")
+	note := flag.String("note", "", "Free-form note for the connection (use with -add-conn)")
 	noEncrypt := flag.Bool("no-encrypt", false, "Store DSN in plaintext (use with -add-conn for local databases)")
 
 	// Other flags
 	sqlDir := flag.String("sql-dir", "", "Directory for SQL files (overrides config, default: $HOME/sql)")
 	setSQLDir := flag.String("set-sql-dir", "", "Set the SQL directory in config")
+	showConfigPath := flag.Bool("config-path", false, "Print the config file path and exit")
+	editConfig := flag.Bool("edit-config", false, "Open the config file in $EDITOR")
 	sqlFile := flag.String("sql-file", "", "SQL file to sync with the query window (default: derived from database name)")
 	outputFormat := flag.String("format", "table", "Output format for piped queries: table, csv, tsv")
+	csvFile := flag.String("csv", "", "Load a CSV/TSV file into an in-memory SQLite table named after the file")
+	execSQL := flag.String("e", "", "Execute the given SQL, print results and exit")
+	echo := flag.Bool("echo", false, "Print each statement to stdout before its result (pipe mode and -e)")
+	dumpSchema := flag.Bool("dump-schema", false, "Write CREATE statements for all tables to a file in the SQL directory and exit")
 	flag.Parse()
 
 	// Handle connection management commands
@@ -53,7 +61,7 @@ func main() {
 	}
 
 	if *addConnection != "" {
-		handleAddConnection(*addConnection, *dsn, *dbType, *themeName, *noEncrypt)
+		handleAddConnection(*addConnection, *dsn, *dbType, *themeName, *note, *noEncrypt)
 		return
 	}
 
@@ -67,36 +75,68 @@ func main() {
 		return
 	}
 
-	// Determine DSN from either -dsn or -conn
-	connInfo, err := resolveDSN(*dsn, *connectionName, *dbType)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		printUsage()
-		os.Exit(1)
+	if *showConfigPath {
+		handleConfigPath()
+		return
 	}
 
-	// Auto-detect database type if not specified
-	detectedType := connInfo.dbType
-	if detectedType == "" {
-		detectedType = detectDBType(connInfo.dsn)
+	if *editConfig {
+		handleEditConfig()
+		return
 	}
 
-	if detectedType == "" {
-		fmt.Fprintln(os.Stderr, "Error: Could not auto-detect database type. Please specify -type flag.")
-		os.Exit(1)
-	}
+	// Create vault manager for connection switching and config
+	vm := NewVaultManager()
+	_ = vm.LoadConfig() // Ignore error - might not have a config yet
 
-	// Map type to driver name
-	driverName := getDriverName(detectedType)
-	if driverName == "" {
-		fmt.Fprintf(os.Stderr, "Error: Unknown database type '%s'. Use mysql, postgres, or sqlite.\n", detectedType)
-		os.Exit(1)
-	}
+	var db *sql.DB
+	var connInfo connectionInfo
+	var detectedType string
+	var csvTable string
 
-	db, err := sql.Open(driverName, connInfo.dsn)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
-		os.Exit(1)
+	if *csvFile != "" {
+		// CSV mode: query the file through an in-memory SQLite database
+		var err error
+		db, csvTable, err = openCSVDatabase(*csvFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load %s: %v\n", *csvFile, err)
+			os.Exit(1)
+		}
+		connInfo = connectionInfo{dsn: ":memory:", dbType: "sqlite"}
+		detectedType = "sqlite"
+	} else {
+		// Determine DSN from either -dsn or -conn
+		var err error
+		connInfo, err = resolveDSN(*dsn, *connectionName, *dbType)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			printUsage()
+			os.Exit(1)
+		}
+
+		// Auto-detect database type if not specified
+		detectedType, err = resolveDBType(connInfo, *strictType, vm.DefaultType())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		// Map type to driver name
+		driverName := getDriverName(detectedType)
+		if driverName == "" {
+			fmt.Fprintf(os.Stderr, "Error: Unknown database type '%s'. Use mysql, postgres, or sqlite.\n", detectedType)
+			os.Exit(1)
+		}
+
+		// The DSN opened carries the server-side timeout, read-only and
+		// session settings; connInfo.dsn stays as configured, for naming the SQL file
+		openDSN := withStatementTimeout(connInfo.dsn, detectedType, vm.StatementTimeout(*connectionName))
+		openDSN = withReadOnly(openDSN, detectedType, vm.ReadOnly(*connectionName))
+		db, err = sql.Open(driverName, withSessionSettings(openDSN, detectedType, vm.GetSessionSettings(*connectionName)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+			os.Exit(1)
+		}
 	}
 	defer func() { _ = db.Close() }()
 
@@ -106,25 +146,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Determine SQL directory: flag overrides config, config overrides default
+	resolvedSQLDir := vm.GetSQLDir() // Gets from config or default
+	if *sqlDir != "" {
+		resolvedSQLDir = *sqlDir // Flag overrides
+	}
+
+	dbName := extractDatabaseName(connInfo.dsn, detectedType)
+	if csvTable != "" {
+		dbName = csvTable
+	}
+
+	// -dump-schema writes a schema snapshot and prints its path
+	if *dumpSchema {
+		tables, err := NewSchemaCache(db, detectedType).Tables()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load schema: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := exportSchemaDDL(db, detectedType, tables, resolvedSQLDir, dbName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Schema export failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(path)
+		return
+	}
+
+	pipeOpts := pipeOptions{format: *outputFormat, echo: *echo, dbType: detectedType, timeout: vm.StatementTimeout(*connectionName), dryRun: vm.DryRun(*connectionName), readOnly: vm.ReadOnly(*connectionName)}
+
+	// -e runs the given SQL non-interactively, like pipe mode
+	if *execSQL != "" {
+		runStatements(db, *execSQL, pipeOpts)
+		return
+	}
+
 	// Check if stdin is a pipe (not a terminal)
 	if isPiped() {
 		// Pipe mode: read query from stdin, execute, output to stdout
-		runPipeMode(db, *outputFormat)
+		runPipeMode(db, pipeOpts)
 		return
 	}
 
 	// Interactive mode: start the Bubble Tea UI
 
-	// Create vault manager for connection switching and config
-	vm := NewVaultManager()
-	_ = vm.LoadConfig() // Ignore error - might not have a config yet
-
-	// Determine SQL directory: flag overrides config, config overrides default
-	resolvedSQLDir := vm.GetSQLDir() // Gets from config or default
-	if *sqlDir != "" {
-		resolvedSQLDir = *sqlDir // Flag overrides
-	}
-
 	// Ensure SQL directory exists
 	if err := os.MkdirAll(resolvedSQLDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create SQL directory %s: %v\n", resolvedSQLDir, err)
@@ -135,7 +200,6 @@ func main() {
 	// If not specified, derive from database name
 	resolvedSQLFile := *sqlFile
 	if resolvedSQLFile == "" {
-		dbName := extractDatabaseName(connInfo.dsn, detectedType)
 		resolvedSQLFile = dbName + ".sql"
 	}
 	if !filepath.IsAbs(resolvedSQLFile) {
@@ -151,7 +215,7 @@ func main() {
 	// Get the theme
 	theme := GetTheme(connInfo.theme)
 
-	p := tea.NewProgram(NewModel(db, detectedType, resolvedSQLDir, resolvedSQLFile, initialSQL, vm, *connectionName, theme), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	p := tea.NewProgram(NewModel(db, detectedType, connInfo.dsn, resolvedSQLDir, resolvedSQLFile, initialSQL, vm, *connectionName, theme), tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
@@ -165,10 +229,12 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "  dibber -conn 'name'       (use a saved connection)")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Connection Management:")
-	fmt.Fprintln(os.Stderr, "  dibber -add-conn 'name' -dsn 'connection_string' [-type db_type] [-no-encrypt]")
+	fmt.Fprintln(os.Stderr, "  dibber -add-conn 'name' -dsn 'connection_string' [-type db_type] [-note 'text'] [-no-encrypt]")
 	fmt.Fprintln(os.Stderr, "  dibber -remove-conn 'name'")
 	fmt.Fprintln(os.Stderr, "  dibber -list-conns")
 	fmt.Fprintln(os.Stderr, "  dibber -change-password")
+	fmt.Fprintln(os.Stderr, "  dibber -config-path")
+	fmt.Fprintln(os.Stderr, "  dibber -edit-config")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Interactive mode:")
 	fmt.Fprintln(os.Stderr, "  dibber -dsn 'user:password@tcp(localhost:3306)/dbname'")
@@ -177,16 +243,29 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "Pipe mode (query via stdin):")
 	fmt.Fprintln(os.Stderr, "  echo 'SELECT * FROM users' | dibber -dsn '...'")
 	fmt.Fprintln(os.Stderr, "  cat query.sql | dibber -conn prod -format csv")
+	fmt.Fprintln(os.Stderr, "  dibber -conn prod -e 'SELECT COUNT(*) FROM users'")
+	fmt.Fprintln(os.Stderr, "  dibber -conn prod -dump-schema   (schema snapshot in the SQL directory)")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "CSV/TSV files (loaded into an in-memory SQLite table named after the file):")
+	fmt.Fprintln(os.Stderr, "  dibber -csv data.csv -e 'SELECT * FROM data WHERE amount > 100'")
+	fmt.Fprintln(os.Stderr, "  dibber -csv data.tsv")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Options:")
 	fmt.Fprintln(os.Stderr, "  -dsn             Database connection string")
 	fmt.Fprintln(os.Stderr, "  -conn            Named connection from ~/.dibber.yaml")
 	fmt.Fprintln(os.Stderr, "  -type            Database type: mysql, postgres, sqlite (auto-detected)")
+	fmt.Fprintln(os.Stderr, "  -strict-type     Disable type auto-detection (requires -type or a stored type)")
 	fmt.Fprintln(os.Stderr, "  -no-encrypt      Store DSN in plaintext (for local databases, no password needed)")
 	fmt.Fprintln(os.Stderr, "  -sql-dir         Directory for SQL files (overrides config)")
 	fmt.Fprintln(os.Stderr, "  -set-sql-dir     Set the SQL directory in config")
+	fmt.Fprintln(os.Stderr, "  -config-path     Print the config file path")
+	fmt.Fprintln(os.Stderr, "  -edit-config     Open the config file in $EDITOR")
 	fmt.Fprintln(os.Stderr, "  -sql-file        SQL file to sync queries (default: [database_name].sql)")
 	fmt.Fprintln(os.Stderr, "  -format          Output format for pipe mode: table, csv, tsv (default: table)")
+	fmt.Fprintln(os.Stderr, "  -e               Execute the given SQL and exit")
+	fmt.Fprintln(os.Stderr, "  -echo            Print each statement before its result in pipe mode")
+	fmt.Fprintln(os.Stderr, "  -csv             Query a CSV/TSV file via an in-memory SQLite table")
+	fmt.Fprintln(os.Stderr, "  -dump-schema     Write CREATE statements for all tables to the SQL directory")
 }
 
 // sanitizeFilename removes or replaces characters that are problematic in filenames
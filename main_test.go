@@ -30,6 +30,40 @@ func TestDetectDBType(t *testing.T) {
 	}
 }
 
+// TestResolveDBType tests explicit, detected and strict type resolution
+func TestResolveDBType(t *testing.T) {
+	tests := []struct {
+		name        string
+		info        connectionInfo
+		strict      bool
+		defaultType string
+		expected    string
+		wantErr     bool
+	}{
+		{"explicit type", connectionInfo{dsn: "/tmp/x.db", dbType: "mysql"}, false, "", "mysql", false},
+		{"detected type", connectionInfo{dsn: "/tmp/x.db"}, false, "", "sqlite", false},
+		{"undetectable", connectionInfo{dsn: "something_unknown"}, false, "", "", true},
+		{"strict with explicit type", connectionInfo{dsn: "/tmp/x.db", dbType: "sqlite"}, true, "", "sqlite", false},
+		{"strict without type", connectionInfo{dsn: "/tmp/x.db"}, true, "", "", true},
+		{"default used when undetectable", connectionInfo{dsn: "something_unknown"}, false, "sqlite", "sqlite", false},
+		{"default does not override detection", connectionInfo{dsn: "postgres://localhost/db"}, false, "sqlite", "postgres", false},
+		{"default does not override explicit type", connectionInfo{dsn: "something_unknown", dbType: "mysql"}, false, "sqlite", "mysql", false},
+		{"default ignored in strict mode", connectionInfo{dsn: "something_unknown"}, true, "sqlite", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := resolveDBType(tc.info, tc.strict, tc.defaultType)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("resolveDBType() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if result != tc.expected {
+				t.Errorf("resolveDBType() = %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
 // TestGetDriverName tests driver name mapping
 func TestGetDriverName(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isMetaCommand reports whether a statement is a psql-style backslash
+// command such as \dt or \d users
+func isMetaCommand(stmt string) bool {
+	return strings.HasPrefix(strings.TrimSpace(stmt), `\`)
+}
+
+// parseMetaCommand splits a backslash command into its name and argument,
+// ignoring a trailing semicolon
+func parseMetaCommand(stmt string) (string, string) {
+	stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+	name, arg, _ := strings.Cut(stmt, " ")
+	return name, strings.TrimSpace(arg)
+}
+
+// expandedSetting applies the argument of \x (on, off, or empty to toggle)
+func expandedSetting(arg string, current bool) (bool, error) {
+	switch strings.ToLower(arg) {
+	case "":
+		return !current, nil
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return current, fmt.Errorf(`\x: unrecognized value "%s": expected on or off`, arg)
+}
+
+// expandedStatus is the message shown after \x, matching psql's wording
+func expandedStatus(expanded bool) string {
+	if expanded {
+		return "Expanded display is on."
+	}
+	return "Expanded display is off."
+}
+
+// metaCommandQuery translates a backslash command (other than \x) into the
+// catalog query for the database type
+func metaCommandQuery(name, arg, dbType string) (string, error) {
+	switch name {
+	case `\dt`:
+		return listRelationsQuery(dbType, false), nil
+	case `\d`:
+		if arg == "" {
+			return listRelationsQuery(dbType, true), nil
+		}
+		return describeTableQuery(arg, dbType), nil
+	case `\l`:
+		return listDatabasesQuery(dbType), nil
+	case `\dp`:
+		return grantsQuery(arg, dbType), nil
+	case `\search`:
+		if arg == "" {
+			return "", fmt.Errorf(`\search: missing search text`)
+		}
+		return schemaSearchQuery(arg, dbType), nil
+	}
+	return "", fmt.Errorf(`invalid command %s: supported commands are \dt, \d [table], \dp [table], \l, \search text and \x`, name)
+}
+
+// listRelationsQuery lists the tables (and views, if requested) in the
+// current database or schema search path
+func listRelationsQuery(dbType string, includeViews bool) string {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		types := "'BASE TABLE'"
+		if includeViews {
+			types += ", 'VIEW'"
+		}
+		return `SELECT table_name AS Name,
+	CASE table_type WHEN 'BASE TABLE' THEN 'table' ELSE LOWER(table_type) END AS Type
+FROM information_schema.tables
+WHERE table_schema = DATABASE() AND table_type IN (` + types + `)
+ORDER BY table_name`
+	case "postgres", "postgresql", "pg":
+		types := "'BASE TABLE'"
+		if includeViews {
+			types += ", 'VIEW'"
+		}
+		return `SELECT table_schema AS "Schema", table_name AS "Name",
+	CASE table_type WHEN 'BASE TABLE' THEN 'table' ELSE lower(table_type) END AS "Type"
+FROM information_schema.tables
+WHERE table_schema = ANY (current_schemas(false)) AND table_type IN (` + types + `)
+ORDER BY table_schema, table_name`
+	default:
+		types := "'table'"
+		if includeViews {
+			types += ", 'view'"
+		}
+		return `SELECT name AS Name, type AS Type
+FROM sqlite_master
+WHERE type IN (` + types + `) AND name NOT LIKE 'sqlite_%'
+ORDER BY name`
+	}
+}
+
+// describeTableQuery lists a table's columns, like psql's \d table.
+// The table may be schema-qualified and quoted.
+func describeTableQuery(table, dbType string) string {
+	table = strings.NewReplacer("`", "", `"`, "").Replace(table)
+	schema, name, qualified := strings.Cut(table, ".")
+	if !qualified {
+		schema, name = "", table
+	}
+
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		schemaCond := "DATABASE()"
+		if schema != "" {
+			schemaCond = "'" + escapeSQLString(schema) + "'"
+		}
+		return `SELECT column_name AS ` + "`Column`" + `, column_type AS Type,
+	CASE is_nullable WHEN 'NO' THEN 'not null' ELSE '' END AS Nullable,
+	COALESCE(column_default, '') AS ` + "`Default`" + `
+FROM information_schema.columns
+WHERE table_schema = ` + schemaCond + ` AND table_name = '` + escapeSQLString(name) + `'
+ORDER BY ordinal_position`
+	case "postgres", "postgresql", "pg":
+		schemaCond := "= ANY (current_schemas(false))"
+		if schema != "" {
+			schemaCond = "= '" + escapeSQLString(schema) + "'"
+		}
+		return `SELECT column_name AS "Column", data_type AS "Type",
+	CASE is_nullable WHEN 'NO' THEN 'not null' ELSE '' END AS "Nullable",
+	COALESCE(column_default, '') AS "Default"
+FROM information_schema.columns
+WHERE table_schema ` + schemaCond + ` AND table_name = '` + escapeSQLString(name) + `'
+ORDER BY ordinal_position`
+	default:
+		args := "'" + escapeSQLString(name) + "'"
+		if schema != "" {
+			args += ", '" + escapeSQLString(schema) + "'"
+		}
+		return `SELECT name AS "Column", type AS "Type",
+	CASE WHEN "notnull" THEN 'not null' ELSE '' END AS "Nullable",
+	COALESCE(dflt_value, '') AS "Default"
+FROM pragma_table_info(` + args + `)
+ORDER BY cid`
+	}
+}
+
+// listDatabasesQuery lists the databases on the server, or the attached
+// databases for SQLite
+func listDatabasesQuery(dbType string) string {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return "SHOW DATABASES"
+	case "postgres", "postgresql", "pg":
+		return `SELECT datname AS "Name", pg_get_userbyid(datdba) AS "Owner",
+	pg_encoding_to_char(encoding) AS "Encoding"
+FROM pg_database
+WHERE NOT datistemplate
+ORDER BY datname`
+	default:
+		return `SELECT name AS "Name", file AS "File" FROM pragma_database_list ORDER BY seq`
+	}
+}
+
+// schemaSearchQuery finds table, view and column names containing text
+// (case-insensitively), along with view definitions and routine bodies
+// (triggers for SQLite) that mention it. Each row is the kind of match, the
+// object it was found in and, for columns and definitions, the detail.
+func schemaSearchQuery(text, dbType string) string {
+	// ! escapes LIKE wildcards; backslash would need doubling in MySQL
+	pattern := "'%" + escapeSQLString(strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(text)) + "%' ESCAPE '!'"
+	literal := "'" + escapeSQLString(text) + "'"
+
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		snippet := func(expr string) string {
+			return "REPLACE(SUBSTRING(" + expr + ", GREATEST(LOCATE(LOWER(" + literal + "), LOWER(" + expr + ")) - 30, 1), " +
+				fmt.Sprint(len(text)+60) + "), CHAR(10), ' ')"
+		}
+		return `SELECT CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END AS Type, table_name AS Object, '' AS Detail
+FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name LIKE ` + pattern + `
+UNION ALL
+SELECT 'column', table_name, column_name
+FROM information_schema.columns WHERE table_schema = DATABASE() AND column_name LIKE ` + pattern + `
+UNION ALL
+SELECT 'view definition', table_name, ` + snippet("view_definition") + `
+FROM information_schema.views WHERE table_schema = DATABASE() AND view_definition LIKE ` + pattern + `
+UNION ALL
+SELECT 'routine body', routine_name, ` + snippet("routine_definition") + `
+FROM information_schema.routines WHERE routine_schema = DATABASE() AND routine_definition LIKE ` + pattern + `
+ORDER BY 1, 2, 3`
+	case "postgres", "postgresql", "pg":
+		snippet := func(expr string) string {
+			return "replace(substr(" + expr + ", greatest(strpos(lower(" + expr + "), lower(" + literal + ")) - 30, 1), " +
+				fmt.Sprint(len(text)+60) + "), chr(10), ' ')"
+		}
+		const userSchemas = "NOT IN ('pg_catalog', 'information_schema')"
+		return `SELECT CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END AS "Type",
+	table_schema || '.' || table_name AS "Object", '' AS "Detail"
+FROM information_schema.tables WHERE table_schema ` + userSchemas + ` AND table_name ILIKE ` + pattern + `
+UNION ALL
+SELECT 'column', table_schema || '.' || table_name, column_name
+FROM information_schema.columns WHERE table_schema ` + userSchemas + ` AND column_name ILIKE ` + pattern + `
+UNION ALL
+SELECT 'view definition', schemaname || '.' || viewname, ` + snippet("definition") + `
+FROM pg_views WHERE schemaname ` + userSchemas + ` AND definition ILIKE ` + pattern + `
+UNION ALL
+SELECT 'routine body', n.nspname || '.' || r.proname, ` + snippet("r.prosrc") + `
+FROM pg_proc r JOIN pg_namespace n ON n.oid = r.pronamespace
+WHERE n.nspname ` + userSchemas + ` AND r.prosrc ILIKE ` + pattern + `
+ORDER BY 1, 2, 3`
+	default:
+		snippet := func(expr string) string {
+			return "replace(substr(" + expr + ", max(instr(lower(" + expr + "), lower(" + literal + ")) - 30, 1), " +
+				fmt.Sprint(len(text)+60) + "), char(10), ' ')"
+		}
+		return `SELECT type AS "Type", name AS "Object", '' AS "Detail"
+FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite!_%' ESCAPE '!' AND name LIKE ` + pattern + `
+UNION ALL
+SELECT 'column', m.name, c.name
+FROM sqlite_master m JOIN pragma_table_info(m.name) c
+WHERE m.type IN ('table', 'view') AND c.name LIKE ` + pattern + `
+UNION ALL
+SELECT m.type || ' definition', m.name, ` + snippet("m.sql") + `
+FROM sqlite_master m WHERE m.type IN ('view', 'trigger') AND m.sql LIKE ` + pattern + `
+ORDER BY 1, 2, 3`
+	}
+}
+
+// tablePrivileges are the privileges checked per table on PostgreSQL
+var tablePrivileges = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}
+
+// grantsQuery lists the current user's privileges on a table, or on the
+// database if table is "", like psql's \dp
+func grantsQuery(table, dbType string) string {
+	table = strings.NewReplacer("`", "", `"`, "").Replace(table)
+
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		if table == "" {
+			return "SHOW GRANTS"
+		}
+		// information_schema lists privileges granted directly, at each level
+		// that can apply to the table; grantee is quoted as 'user'@'host'
+		grantee := `CONCAT('''', SUBSTRING_INDEX(CURRENT_USER(), '@', 1), '''@''', SUBSTRING_INDEX(CURRENT_USER(), '@', -1), '''')`
+		name := "'" + escapeSQLString(table) + "'"
+		return `SELECT 'global' AS Level, privilege_type AS Privilege, is_grantable AS Grantable
+FROM information_schema.user_privileges WHERE grantee = ` + grantee + `
+UNION ALL
+SELECT 'database', privilege_type, is_grantable
+FROM information_schema.schema_privileges WHERE grantee = ` + grantee + ` AND table_schema = DATABASE()
+UNION ALL
+SELECT 'table', privilege_type, is_grantable
+FROM information_schema.table_privileges
+WHERE grantee = ` + grantee + ` AND table_schema = DATABASE() AND table_name = ` + name + `
+UNION ALL
+SELECT CONCAT('column ', column_name), privilege_type, is_grantable
+FROM information_schema.column_privileges
+WHERE grantee = ` + grantee + ` AND table_schema = DATABASE() AND table_name = ` + name + `
+ORDER BY 1, 2`
+	case "postgres", "postgresql", "pg":
+		// has_table_privilege accounts for roles, ownership and superusers
+		if table == "" {
+			var checks []string
+			for _, priv := range tablePrivileges {
+				checks = append(checks, "CASE WHEN has_table_privilege(c.oid, '"+priv+"') THEN '"+priv+"' END")
+			}
+			return `SELECT c.relname AS "Table", concat_ws(', ', ` + strings.Join(checks, ", ") + `) AS "Privileges"
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'v', 'm', 'p', 'f')
+ORDER BY c.relname`
+		}
+		return `SELECT p.privilege AS "Privilege",
+	CASE WHEN has_table_privilege('` + escapeSQLString(quoteNameIfNeeded(table, "postgres")) + `', p.privilege) THEN 'YES' ELSE 'NO' END AS "Granted"
+FROM unnest(ARRAY['` + strings.Join(tablePrivileges, "', '") + `']) WITH ORDINALITY AS p(privilege, ord)
+ORDER BY p.ord`
+	default:
+		return `SELECT CASE WHEN query_only THEN 'read-only' ELSE 'read/write' END AS "Access",
+	'SQLite has no user privileges; access follows the database file permissions' AS "Note"
+FROM pragma_query_only`
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestParseMetaCommand tests splitting backslash commands into name and argument
+func TestParseMetaCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantName string
+		wantArg  string
+	}{
+		{`\dt`, `\dt`, ""},
+		{`  \d users  `, `\d`, "users"},
+		{`\d public.users;`, `\d`, "public.users"},
+		{`\x on`, `\x`, "on"},
+	}
+
+	for _, tc := range tests {
+		name, arg := parseMetaCommand(tc.input)
+		if name != tc.wantName || arg != tc.wantArg {
+			t.Errorf("parseMetaCommand(%q) = (%q, %q), want (%q, %q)", tc.input, name, arg, tc.wantName, tc.wantArg)
+		}
+	}
+}
+
+// TestExpandedSetting tests the on/off/toggle forms of \x
+func TestExpandedSetting(t *testing.T) {
+	tests := []struct {
+		arg     string
+		current bool
+		want    bool
+		wantErr bool
+	}{
+		{"", false, true, false},
+		{"", true, false, false},
+		{"on", false, true, false},
+		{"OFF", true, false, false},
+		{"auto", true, true, true},
+	}
+
+	for _, tc := range tests {
+		got, err := expandedSetting(tc.arg, tc.current)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("expandedSetting(%q, %v) = (%v, %v), want (%v, error %v)", tc.arg, tc.current, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+// TestMetaCommandQuerySQLite tests that translated commands run against SQLite
+func TestMetaCommandQuerySQLite(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec("CREATE VIEW adults AS SELECT * FROM users WHERE age >= 18"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		arg      string
+		wantRows []string // first column of each row
+	}{
+		{`\dt`, "", []string{"users"}},
+		{`\d`, "", []string{"adults", "users"}},
+		{`\d`, "users", []string{"id", "name", "email", "age", "salary", "is_active", "notes"}},
+		{`\d`, `main."users"`, []string{"id", "name", "email", "age", "salary", "is_active", "notes"}},
+		{`\l`, "", []string{"main"}},
+		{`\dp`, "users", []string{"read/write"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(strings.TrimSpace(tc.name+" "+tc.arg), func(t *testing.T) {
+			query, err := metaCommandQuery(tc.name, tc.arg, "sqlite")
+			if err != nil {
+				t.Fatalf("metaCommandQuery() error: %v", err)
+			}
+			result := executeQuery(context.Background(), db, query)
+			if result.Error != nil {
+				t.Fatalf("executeQuery(%q) error: %v", query, result.Error)
+			}
+			var got []string
+			for _, row := range result.Rows {
+				got = append(got, row[0].Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.wantRows, ",") {
+				t.Errorf("rows = %v, want %v", got, tc.wantRows)
+			}
+		})
+	}
+
+	if _, err := metaCommandQuery(`\q`, "", "sqlite"); err == nil {
+		t.Error(`metaCommandQuery(\q) should return an error`)
+	}
+}
+
+// TestDescribeTableQuery tests schema qualification and escaping per database type
+func TestDescribeTableQuery(t *testing.T) {
+	tests := []struct {
+		table    string
+		dbType   string
+		contains string
+	}{
+		{"users", "postgres", "table_schema = ANY (current_schemas(false)) AND table_name = 'users'"},
+		{`"app"."users"`, "postgres", "table_schema = 'app' AND table_name = 'users'"},
+		{"users", "mysql", "table_schema = DATABASE() AND table_name = 'users'"},
+		{"shop.orders", "mysql", "table_schema = 'shop' AND table_name = 'orders'"},
+		{"o'brien", "sqlite", "pragma_table_info('o''brien')"},
+	}
+
+	for _, tc := range tests {
+		if got := describeTableQuery(tc.table, tc.dbType); !strings.Contains(got, tc.contains) {
+			t.Errorf("describeTableQuery(%q, %q) = %q, want it to contain %q", tc.table, tc.dbType, got, tc.contains)
+		}
+	}
+}
+
+// TestGrantsQuery tests the privilege queries for a table and for the whole database
+func TestGrantsQuery(t *testing.T) {
+	tests := []struct {
+		table    string
+		dbType   string
+		contains string
+	}{
+		{"", "mysql", "SHOW GRANTS"},
+		{"`orders`", "mysql", "table_schema = DATABASE() AND table_name = 'orders'"},
+		{"orders", "postgres", "has_table_privilege('orders', p.privilege)"},
+		{`"Order Items"`, "postgres", `has_table_privilege('"Order Items"', p.privilege)`},
+		{"", "postgres", "has_table_privilege(c.oid, 'UPDATE')"},
+		{"orders", "sqlite", "pragma_query_only"},
+	}
+
+	for _, tc := range tests {
+		if got := grantsQuery(tc.table, tc.dbType); !strings.Contains(got, tc.contains) {
+			t.Errorf("grantsQuery(%q, %q) = %q, want it to contain %q", tc.table, tc.dbType, got, tc.contains)
+		}
+	}
+}
+
+// TestSchemaSearchQuerySQLite tests searching names and definitions in SQLite
+func TestSchemaSearchQuerySQLite(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+	for _, stmt := range []string{
+		"CREATE TABLE user_emails (id INTEGER PRIMARY KEY, address TEXT)",
+		"CREATE VIEW adults AS SELECT name FROM users WHERE age >= 18",
+		"CREATE TRIGGER touch AFTER UPDATE ON users BEGIN UPDATE users SET notes = 'touched' WHERE id = NEW.id; END",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want []string // "Type Object Detail" rows
+	}{
+		{"EMAIL", []string{"column users email", "table user_emails "}},
+		{"age >=", []string{"view definition adults  SELECT name FROM users WHERE age >= 18"}},
+		{"touched", []string{"trigger definition touch GIN UPDATE users SET notes = 'touched' WHERE id = NEW.id; END"}},
+		{"user_", []string{"table user_emails "}},
+		{"%", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			result := executeQuery(context.Background(), db, schemaSearchQuery(tc.text, "sqlite"))
+			if result.Error != nil {
+				t.Fatalf("executeQuery() error: %v", result.Error)
+			}
+			var got []string
+			for _, row := range result.Rows {
+				got = append(got, row[0].Value+" "+row[1].Value+" "+row[2].Value)
+			}
+			if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+				t.Errorf("rows = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,20 +1,35 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
 )
 
 const (
-	pageSize = 20
+	// defaultPageSize is the rows per page of results until the window's
+	// size is known
+	defaultPageSize = 20
+
+	// sidebarWidth is the width of the schema browser sidebar, including its border
+	sidebarWidth = 32
 )
 
 // Model is the main Bubble Tea model
@@ -25,25 +40,139 @@ type Model struct {
 
 	// Global UI state
 	confirmingQuit bool
-	viewport       viewport.Model
-	focus          focusState
-	width          int
-	height         int
-	ready          bool
-	statusMessage  string
-	fileDialog     *FileDialog
+
+	// Tab whose SQL file changed on disk, while asking whether to reload it,
+	// and whether the active tab's file is being checked for such changes
+	reloadPrompt *Tab
+	checkingFile bool
+	txPrompt     *Tab                 // asking whether to commit or roll back
+	txPromptNote string               // what's waiting to be committed, for confirm_writes
+	txThen       func(*Model) tea.Cmd // done once the transaction's ended
+	txThenDoing  string               // what txThen does, e.g. "closing the tab"
+
+	// Statement whose connection dropped, while asking whether to reconnect
+	reconnectPrompt *lostStatement
+
+	viewport      viewport.Model
+	focus         focusState
+	width         int
+	height        int
+	ready         bool
+	statusMessage string
+	fileDialog    *FileDialog
+
+	// Shown in the status bar while the active tab's query runs
+	spinner spinner.Model
 
 	// Connection management
 	vaultManager     *VaultManager
 	connectionPicker *ConnectionPicker // for interactive connection switching
 	creatingNewTab   bool              // true when connection picker is for new tab
 
+	// Database switch prompt (same server, different database or schema)
+	databaseInput    textinput.Model
+	databaseChoices  []DatabaseChoice
+	databaseSelected int // index into filteredDatabaseChoices()
+
+	// Fuzzy finder over table and column names
+	finder *SchemaFinder
+
+	// Incremental search over the query editor (Ctrl+F)
+	editorSearch *EditorSearch
+
+	// Line number prompt for jumping to a line (Ctrl+G)
+	gotoLine *textinput.Model
+
+	// Interval prompt for watching a query (Alt+W)
+	watchPrompt *textinput.Model
+
+	// Prompt narrowing the shown result's rows (/)
+	filterPrompt *textinput.Model
+
+	// Popup hiding and showing the result's columns (c), and the columns
+	// hidden and pinned in each query's results this session, by
+	// columnLayoutKey
+	columnPicker  *ColumnPicker
+	columnLayouts map[string]columnLayout
+
+	// Read-only CREATE statement viewer
+	ddlView *DDLView
+
+	// EXPLAIN ANALYZE tree viewer
+	planView *PlanView
+
+	// Confirmation before an UPDATE or DELETE without WHERE runs
+	writeConfirm *WriteConfirm
+
+	// Session settings of the active tab's connection
+	sessionPanel *SessionPanel
+
+	// Entity-relationship overview
+	erView *ERView
+
+	// Quick peek at a table's first rows
+	preview *TablePreview
+
+	// Searchable history of the current connection's statements
+	history *HistoryBrowser
+
+	// Picker over the snippet library
+	snippets *SnippetPicker
+
+	// Picker over the active tab's bookmarked statements
+	bookmarks *BookmarkPicker
+
+	// Prompt for {{name}}/:name query variables, and the values last used
+	variablePrompt *VariablePrompt
+	variableValues map[string]string
+
+	// Last text copied or cut from the query editor, used when the system
+	// clipboard isn't available
+	clipboard string
+
 	// SQL directory (global default)
 	sqlDir string
+
+	// Results navigation options (from config)
+	wrapPagination bool
+
+	// Rows a SELECT without a LIMIT fetches at a time (0 = all of them)
+	rowLimit int
+
+	// Name generated key columns in INSERTs (as DEFAULT/nextval()) instead of omitting them
+	explicitInsertDefaults bool
+
+	// Keep running all statements after one fails
+	continueOnError bool
+
+	// Hold UPDATEs and DELETEs from the detail view in a transaction until
+	// their row count is confirmed
+	confirmWrites bool
+
+	// Save edited tabs this often (0 = only when running queries or saving)
+	autoSaveInterval time.Duration
+
+	// Ping connections this often (0 = never)
+	healthInterval time.Duration
+
+	// Vim emulation in the query editor (from config)
+	vimMode bool
+	vim     VimState
+
+	// Keys bound to actions (defaults, overridden from config)
+	keys Keymap
+
+	// Session log of executed statements
+	messages       []MessageEntry
+	showMessages   bool
+	messagesScroll int // entries scrolled up from the newest
+
+	// Schema browser sidebar
+	showSidebar bool
 }
 
 // NewTab creates a new Tab with the given connection
-func NewTab(db *sql.DB, dbType string, sqlDir string, sqlFile string, initialSQL string, connectionName string, theme Theme) *Tab {
+func NewTab(db *sql.DB, dbType string, dsn string, sqlDir string, sqlFile string, initialSQL string, connectionName string, theme Theme) *Tab {
 	ta := textarea.New()
 	ta.Placeholder = "Enter SQL query..."
 	ta.Focus()
@@ -60,27 +189,66 @@ func NewTab(db *sql.DB, dbType string, sqlDir string, sqlFile string, initialSQL
 	return &Tab{
 		db:               db,
 		dbType:           dbType,
+		dsn:              dsn,
 		sqlDir:           sqlDir,
 		sqlFile:          sqlFile,
 		lastSavedContent: initialSQL,
+		diskStat:         statFile(sqlFile),
 		textarea:         ta,
 		connectionName:   connectionName,
 		theme:            theme,
-		highlighter:      NewSQLHighlighter(theme),
+		highlighter:      NewSQLHighlighterForDB(theme, dbType),
+		schema:           NewSchemaCache(db, dbType),
+	}
+}
+
+// loadConnectionSettings copies per-connection settings from the config onto the tab
+func loadConnectionSettings(tab *Tab, vm *VaultManager) {
+	if vm == nil {
+		return
 	}
+	tab.columnWidths = vm.GetColumnWidths(tab.connectionName)
+	tab.note = vm.GetConnectionNote(tab.connectionName)
+	tab.statementTimeout = vm.StatementTimeout(tab.connectionName)
+	tab.dryRun = vm.DryRun(tab.connectionName)
+	tab.readOnly = vm.ReadOnly(tab.connectionName)
+	tab.session = maps.Clone(vm.GetSessionSettings(tab.connectionName))
 }
 
 // NewModel creates a new Model with a single initial tab
-func NewModel(db *sql.DB, dbType string, sqlDir string, sqlFile string, initialSQL string, vm *VaultManager, connectionName string, theme Theme) Model {
-	tab := NewTab(db, dbType, sqlDir, sqlFile, initialSQL, connectionName, theme)
+func NewModel(db *sql.DB, dbType string, dsn string, sqlDir string, sqlFile string, initialSQL string, vm *VaultManager, connectionName string, theme Theme) Model {
+	tab := NewTab(db, dbType, dsn, sqlDir, sqlFile, initialSQL, connectionName, theme)
+	loadConnectionSettings(tab, vm)
 
-	return Model{
+	m := Model{
 		tabs:         []*Tab{tab},
 		activeTab:    0,
 		focus:        focusQuery,
 		vaultManager: vm,
 		sqlDir:       sqlDir,
+		keys:         DefaultKeymap(),
+		spinner:      spinner.New(spinner.WithSpinner(spinner.Dot)),
+		rowLimit:     defaultRowLimit,
+
+		healthInterval: defaultHealthInterval,
+		checkingFile:   sqlFile != "",
 	}
+	if vm != nil {
+		m.wrapPagination = vm.WrapPagination()
+		m.rowLimit = vm.RowLimit()
+		m.explicitInsertDefaults = vm.ExplicitInsertDefaults()
+		m.continueOnError = vm.ContinueOnError()
+		m.confirmWrites = vm.ConfirmWrites()
+		m.autoSaveInterval = vm.AutoSaveInterval()
+		m.healthInterval = vm.HealthCheckInterval()
+		m.vimMode = vm.VimMode()
+		keys, err := vm.Keymap()
+		m.keys = keys
+		if err != nil {
+			m.statusMessage = err.Error()
+		}
+	}
+	return m
 }
 
 // activeTabPtr returns a pointer to the active tab
@@ -101,15 +269,33 @@ func (m Model) tab() *Tab {
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return textarea.Blink
+	firstCheck := func() tea.Msg { return healthCheckMsg{} }
+	if m.healthInterval <= 0 {
+		firstCheck = nil
+	}
+	var fileCheck tea.Cmd
+	if m.checkingFile {
+		fileCheck = checkFileTick()
+	}
+	return tea.Batch(textarea.Blink, fileCheck, autoSaveTick(m.autoSaveInterval), firstCheck)
 }
 
 // editorFinishedMsg is sent when the external editor exits
 type editorFinishedMsg struct {
-	err error
+	err       error
+	statement *statementEdit // nil when the whole file was edited
 }
 
-// openInExternalEditor opens the SQL file in the user's $EDITOR
+// statementEdit is a single statement being edited in a temporary file
+type statementEdit struct {
+	path     string
+	start    int // byte range of the statement in the textarea content
+	end      int
+	original string // textarea content when the editor was opened
+}
+
+// openInExternalEditor opens the statement under the cursor in the user's
+// $EDITOR, or the whole SQL file when the cursor isn't on a statement
 func (m *Model) openInExternalEditor() tea.Cmd {
 	tab := m.activeTabPtr()
 	if tab == nil {
@@ -118,33 +304,170 @@ func (m *Model) openInExternalEditor() tea.Cmd {
 
 	// Save current content before opening editor
 	m.saveToFile()
+	if m.reloadPrompt != nil {
+		return nil // the file changed on disk; settle that first
+	}
+
+	content := tab.textarea.Value()
+	start, end := statementRangeAtLine(content, tab.textarea.Line(), tab.dbType)
+	if start < 0 {
+		c := externalEditorCmd(tab.sqlFile)
+		return tea.ExecProcess(c, func(err error) tea.Msg {
+			return editorFinishedMsg{err: err}
+		})
+	}
 
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi" // fallback to vi if EDITOR not set
+	f, err := os.CreateTemp("", "dibber-*.sql")
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to create temporary file: %v", err)
+		return nil
+	}
+	_, err = f.WriteString(content[start:end] + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		m.statusMessage = fmt.Sprintf("Failed to write temporary file: %v", err)
+		return nil
 	}
 
-	c := exec.Command(editor, tab.sqlFile)
+	edit := &statementEdit{path: f.Name(), start: start, end: end, original: content}
+	c := externalEditorCmd(edit.path)
 	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return editorFinishedMsg{err: err}
+		return editorFinishedMsg{err: err, statement: edit}
 	})
 }
 
+// applyStatementEdit reads back a statement edited in the external editor
+// and replaces the original statement with it
+func (m *Model) applyStatementEdit(edit *statementEdit) {
+	defer func() { _ = os.Remove(edit.path) }()
+
+	tab := m.activeTabPtr()
+	if tab == nil || tab.textarea.Value() != edit.original {
+		m.statusMessage = "Content changed while editing - statement not updated"
+		return
+	}
+	data, err := os.ReadFile(edit.path)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error reading edited statement: %v", err)
+		return
+	}
+
+	edited := strings.TrimSpace(string(data))
+	if edited != "" && !strings.HasSuffix(edited, ";") && !isMetaCommand(edited) &&
+		strings.HasSuffix(edit.original[edit.start:edit.end], ";") {
+		edited += ";" // keep the statement terminated (a custom DELIMITER follows the range)
+	}
+	if edited == edit.original[edit.start:edit.end] {
+		m.statusMessage = "Statement unchanged"
+		return
+	}
+	tab.textarea.SetValue(edit.original[:edit.start] + edited + edit.original[edit.end:])
+	moveCursorTo(&tab.textarea, edit.start)
+	m.saveToFile()
+	m.statusMessage = "Updated statement from editor"
+}
+
+// externalEditorCmd builds the command that opens path in the user's
+// $EDITOR, which may include arguments (e.g. "code --wait")
+func externalEditorCmd(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"} // fallback to vi if EDITOR not set
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.recordEdit(msg)
+	if next, ok := updated.(Model); ok {
+		// The window, the editor or the panels may have changed size
+		next.fitPages()
+		// A file may have been opened, or a tab with one switched to
+		if check := next.checkFile(); check != nil {
+			cmd = tea.Batch(cmd, check)
+		}
+		return next, cmd
+	}
+	return updated, cmd
+}
+
+// update handles a message; Update wraps it to record editor changes for undo
+// and to fit the pages of results to the space left for them
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	tab := m.activeTabPtr()
 
 	switch msg := msg.(type) {
 	case editorFinishedMsg:
-		// External editor closed - reload the file
-		if msg.err != nil {
+		// External editor closed - reload the file or the edited statement
+		switch {
+		case msg.err != nil:
 			m.statusMessage = fmt.Sprintf("Editor error: %v", msg.err)
-		} else {
+			if msg.statement != nil {
+				_ = os.Remove(msg.statement.path)
+			}
+		case msg.statement != nil:
+			m.applyStatementEdit(msg.statement)
+		default:
+			m.reloadPrompt = nil // the check may have noticed the editor's save
 			m.reloadFileFromDisk()
 		}
 		return m, nil
 
+	case fileCheckMsg:
+		// Notice edits made to the SQL file outside dibber, until there's
+		// no file to check
+		if tab == nil || tab.sqlFile == "" {
+			m.checkingFile = false
+			return m, nil
+		}
+		if m.reloadPrompt == nil && !m.confirmingQuit && changedOnDisk(tab) {
+			m.promptReload(tab)
+		}
+		return m, checkFileTick()
+
+	case autoSaveMsg:
+		if m.reloadPrompt == nil && !m.confirmingQuit {
+			m.autoSave()
+		}
+		return m, autoSaveTick(m.autoSaveInterval)
+
+	case healthCheckMsg:
+		return m, tea.Batch(m.checkHealth(), healthCheckTick(m.healthInterval))
+
+	case healthResultMsg:
+		if slices.Contains(m.tabs, msg.tab) && msg.tab.db == msg.health.db {
+			msg.tab.health = msg.health
+		}
+		return m, nil
+
+	case queryResultMsg:
+		return m, m.finishQuery(msg)
+
+	case serverKillMsg:
+		m.logMessage(msg.stmt, msg.err, -1, msg.duration)
+		return m, nil
+
+	case watchTickMsg:
+		return m, m.runWatched(msg)
+
+	case rowsFetchedMsg:
+		m.finishFetch(msg)
+		return m, nil
+
+	case spinner.TickMsg:
+		// Keep spinning only while a query is running
+		if !slices.ContainsFunc(m.tabs, func(t *Tab) bool { return t.running != "" }) {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
 		// Handle confirm quit dialog
 		if m.confirmingQuit {
@@ -164,33 +487,184 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Global quit - works from any view
-		if msg.String() == "ctrl+q" || msg.String() == "ctrl+c" {
-			if m.hasUnsavedChangesAnyTab() {
-				m.confirmingQuit = true
-				m.statusMessage = "You have unsaved changes. Save before quitting? (y/n, Esc to cancel)"
+		// Handle the prompt to reload a SQL file changed on disk
+		if m.reloadPrompt != nil {
+			changed := m.reloadPrompt
+			switch msg.String() {
+			case "y", "Y":
+				m.reloadTab(changed)
+			case "n", "N", "esc":
+				// Keep the editor's version; the next save overwrites the file
+				changed.diskStat = statFile(changed.sqlFile)
+				m.statusMessage = "Kept your version - saving will overwrite the file"
+			default:
+				return m, nil
+			}
+			m.reloadPrompt = nil
+			return m, nil
+		}
+
+		// Handle the prompt to commit or roll back a transaction
+		if m.txPrompt != nil {
+			tab, then := m.txPrompt, m.txThen
+			var cmd tea.Cmd
+			var err error
+			switch msg.String() {
+			case "c", "C", "y", "Y":
+				cmd, err = m.endTransaction(tab, true)
+			case "r", "R", "n", "N":
+				cmd, err = m.endTransaction(tab, false)
+			case "esc":
+				m.statusMessage = "Transaction still open"
+				then = nil
+			default:
+				return m, nil
+			}
+			m.txPrompt = nil
+			m.txPromptNote = ""
+			m.txThen, m.txThenDoing = nil, ""
+			if then != nil && err == nil {
+				return m, tea.Batch(cmd, then(&m))
+			}
+			return m, cmd
+		}
+
+		// Handle the prompt to reconnect after a dropped connection
+		if m.reconnectPrompt != nil {
+			lost := m.reconnectPrompt
+			switch msg.String() {
+			case "r", "R", "y", "Y":
+				m.reconnectPrompt = nil
+				return m, m.reconnectLost(lost)
+			case "n", "N", "esc":
+				m.statusMessage = "Not reconnected"
+			default:
+				return m, nil
+			}
+			m.reconnectPrompt = nil
+			return m, nil
+		}
+
+		// The dialogs typed into get every key, so Ctrl+W deletes a word
+		// rather than closing the tab behind them
+
+		// Handle the confirmation of an UPDATE or DELETE without WHERE
+		if m.focus == focusConfirmWrite && m.writeConfirm != nil {
+			return m.handleWriteConfirmKeys(msg)
+		}
+
+		// Handle results search prompt keys
+		if m.focus == focusResultSearch {
+			return m.handleResultSearchKeys(msg)
+		}
+
+		// Handle results filter prompt keys
+		if m.focus == focusFilter && m.filterPrompt != nil {
+			return m.handleFilterPromptKeys(msg)
+		}
+
+		// Handle editor search keys
+		if m.focus == focusSearch && m.editorSearch != nil {
+			return m.handleEditorSearchKeys(msg)
+		}
+
+		// Handle query history browser keys
+		if m.focus == focusHistory && m.history != nil {
+			return m.handleHistoryKeys(msg)
+		}
+
+		// Handle query variable prompt keys
+		if m.focus == focusVariables && m.variablePrompt != nil {
+			return m.handleVariablePromptKeys(msg)
+		}
+
+		// Handle session settings panel keys
+		if m.focus == focusSession && m.sessionPanel != nil {
+			return m.handleSessionPanelKeys(msg)
+		}
+
+		// The completion popup takes Tab, ↑/↓ and Esc while it's open
+		if m.focus == focusQuery && tab != nil && tab.completion != nil {
+			switch msg.String() {
+			case "tab":
+				m.acceptCompletion()
+				return m, nil
+			case "up":
+				tab.completion.Move(-1)
+				return m, nil
+			case "down":
+				tab.completion.Move(1)
 				return m, nil
 			}
-			return m, tea.Quit
+			// Any other key closes it; typing reopens it once the editor has the key
+			tab.completion = nil
+			if msg.String() == "esc" && !m.vimMode {
+				return m, nil // in vim mode Esc also leaves insert mode
+			}
+		}
+
+		// Cancel the running query - Esc or Ctrl+C. In vim insert mode Esc
+		// still leaves insert mode.
+		if tab != nil && tab.running != "" && (m.focus == focusQuery || m.focus == focusResults) &&
+			m.keys.CancelQuery.Matches(msg.String()) && !(msg.String() == "esc" && m.vimMode && m.vim.mode == vimInsert) {
+			return m, m.cancelQuery()
+		}
+
+		// Global quit - works from any view
+		if m.keys.Quit.Matches(msg.String()) {
+			return m, m.quit()
 		}
 
 		// Global save - Ctrl+S
-		if msg.String() == "ctrl+s" {
+		if m.keys.Save.Matches(msg.String()) {
 			m.saveToFile()
-			if tab != nil {
+			if tab != nil && m.reloadPrompt == nil && tab.saveErr == nil {
 				m.statusMessage = fmt.Sprintf("Saved to %s", tab.sqlFile)
 			}
 			return m, nil
 		}
 
+		// Reload schema cache - Alt+R or Ctrl+Shift+R
+		if m.keys.ReloadSchema.Matches(msg.String()) {
+			if tab == nil {
+				return m, nil
+			}
+			if err := tab.schema.Reload(); err != nil {
+				m.statusMessage = fmt.Sprintf("Schema reload failed: %v", err)
+			} else {
+				m.statusMessage = fmt.Sprintf("Schema reloaded: %d tables", len(tab.schema.tables))
+				if m.showSidebar {
+					m.loadSidebarSchema()
+				}
+			}
+			return m, nil
+		}
+
+		// Toggle messages panel - Alt+M
+		if m.keys.Messages.Matches(msg.String()) {
+			m.showMessages = !m.showMessages
+			m.messagesScroll = 0
+			return m, nil
+		}
+
+		// Scroll messages panel - Alt+Up/Alt+Down
+		if m.showMessages && (m.keys.MessagesUp.Matches(msg.String()) || m.keys.MessagesDown.Matches(msg.String())) {
+			if m.keys.MessagesUp.Matches(msg.String()) && m.messagesScroll < len(m.messages)-1 {
+				m.messagesScroll++
+			} else if m.keys.MessagesDown.Matches(msg.String()) && m.messagesScroll > 0 {
+				m.messagesScroll--
+			}
+			return m, nil
+		}
+
 		// Global open - Ctrl+O
-		if msg.String() == "ctrl+o" {
+		if m.keys.OpenFile.Matches(msg.String()) {
 			m.openFileDialog()
 			return m, nil
 		}
 
 		// Open in external editor - Ctrl+E
-		if msg.String() == "ctrl+e" {
+		if m.keys.ExternalEditor.Matches(msg.String()) {
 			if tab == nil || tab.sqlFile == "" {
 				m.statusMessage = "No SQL file to edit"
 				return m, nil
@@ -199,7 +673,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// New tab - Ctrl+T
-		if msg.String() == "ctrl+t" {
+		if m.keys.NewTab.Matches(msg.String()) {
 			if m.vaultManager != nil {
 				m.creatingNewTab = true
 				m.openConnectionPicker()
@@ -211,8 +685,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Switch tabs - Ctrl+Tab or Ctrl+PageDown (next), Ctrl+Shift+Tab or Ctrl+PageUp (prev)
 		// Also support Shift+Tab for switching when in query/results view (not in detail view where it navigates fields)
-		nextTabKeys := msg.String() == "ctrl+tab" || msg.String() == "ctrl+pgdown"
-		prevTabKeys := msg.String() == "ctrl+shift+tab" || msg.String() == "ctrl+pgup"
+		nextTabKeys := m.keys.NextTab.Matches(msg.String())
+		prevTabKeys := m.keys.PrevTab.Matches(msg.String())
 
 		// Allow shift+tab for tab switching only when not in detail view or other dialogs
 		if msg.String() == "shift+tab" && (m.focus == focusQuery || m.focus == focusResults) && len(m.tabs) > 1 {
@@ -239,9 +713,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Close tab - Ctrl+W
-		if msg.String() == "ctrl+w" {
+		if m.keys.CloseTab.Matches(msg.String()) {
 			if len(m.tabs) > 1 {
-				m.closeCurrentTab()
+				closeTab := func(m *Model) tea.Cmd {
+					return m.closeCurrentTab()
+				}
+				if !m.endTransactionFirst(tab, "closing the tab", closeTab) {
+					return m, closeTab(&m)
+				}
 			} else {
 				m.statusMessage = "Cannot close the last tab"
 			}
@@ -259,7 +738,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Open connection picker - Ctrl+P (switch connection for current tab)
-		if msg.String() == "ctrl+p" {
+		if m.keys.SwitchConnection.Matches(msg.String()) {
 			if m.vaultManager != nil {
 				m.creatingNewTab = false
 				m.openConnectionPicker()
@@ -269,10 +748,214 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Handle database switch prompt keys
+		if m.focus == focusDatabasePrompt {
+			return m.handleDatabasePromptKeys(msg)
+		}
+
+		// Switch database on the same server - Ctrl+B
+		if m.keys.SwitchDatabase.Matches(msg.String()) {
+			m.openDatabasePrompt()
+			return m, nil
+		}
+
+		// Toggle/focus schema browser sidebar - Alt+S
+		if m.keys.Sidebar.Matches(msg.String()) {
+			m.toggleSidebar()
+			return m, nil
+		}
+
+		// Show CREATE statement for the selected or referenced table - Alt+T
+		if m.keys.ShowDDL.Matches(msg.String()) {
+			m.openDDLView()
+			return m, nil
+		}
+
+		// Show entity-relationship overview - Alt+E
+		if m.keys.EROverview.Matches(msg.String()) {
+			m.openERView()
+			return m, nil
+		}
+
+		// Export the CREATE statements of all tables to the SQL directory - Alt+X
+		if m.keys.ExportSchema.Matches(msg.String()) {
+			m.exportSchema()
+			return m, nil
+		}
+
+		// Handle ER overview keys
+		if m.focus == focusER && m.erView != nil {
+			return m.handleERViewKeys(msg)
+		}
+
+		// Handle DDL viewer keys
+		if m.focus == focusDDL && m.ddlView != nil {
+			return m.handleDDLViewKeys(msg)
+		}
+
+		// Handle table preview keys
+		if m.focus == focusPreview && m.preview != nil {
+			return m.handlePreviewKeys(msg)
+		}
+
+		// Handle query plan viewer keys
+		if m.focus == focusPlan && m.planView != nil {
+			return m.handlePlanViewKeys(msg)
+		}
+
+		// Handle schema browser sidebar keys
+		if m.focus == focusSidebar {
+			return m.handleSidebarKeys(msg)
+		}
+
+		// Handle fuzzy finder keys
+		if m.focus == focusFinder && m.finder != nil {
+			return m.handleFinderKeys(msg)
+		}
+
+		// Handle go-to-line prompt keys
+		if m.focus == focusGotoLine && m.gotoLine != nil {
+			return m.handleGotoLineKeys(msg)
+		}
+
+		// Handle watch interval prompt keys
+		if m.focus == focusWatch && m.watchPrompt != nil {
+			return m.handleWatchPromptKeys(msg)
+		}
+
+		// Handle column picker keys
+		if m.focus == focusColumnPicker && m.columnPicker != nil {
+			return m.handleColumnPickerKeys(msg)
+		}
+
+		// Handle snippet picker keys
+		if m.focus == focusSnippets && m.snippets != nil {
+			return m.handleSnippetKeys(msg)
+		}
+
+		// Handle bookmark picker keys
+		if m.focus == focusBookmarks && m.bookmarks != nil {
+			return m.handleBookmarkKeys(msg)
+		}
+
+		// Format the statement under the cursor - Alt+Shift+F
+		if m.keys.Format.Matches(msg.String()) {
+			m.formatQuery()
+			return m, nil
+		}
+
+		// Uppercase the keywords in the statement under the cursor - Alt+Shift+U
+		if m.keys.UppercaseKeywords.Matches(msg.String()) {
+			m.uppercaseQueryKeywords()
+			return m, nil
+		}
+
+		// Comment or uncomment the current or selected lines - Ctrl+/
+		if m.focus == focusQuery && m.keys.ToggleComment.Matches(msg.String()) {
+			m.toggleComment()
+			return m, nil
+		}
+
+		// Jump to the next/previous statement - Ctrl+↓/Ctrl+↑
+		if m.focus == focusQuery && m.keys.NextStatement.Matches(msg.String()) {
+			m.jumpToStatement(1)
+			return m, nil
+		}
+		if m.focus == focusQuery && m.keys.PrevStatement.Matches(msg.String()) {
+			m.jumpToStatement(-1)
+			return m, nil
+		}
+
+		// Run the next/previous statement - Alt+J/Alt+K
+		if m.focus == focusQuery || m.focus == focusResults {
+			if m.keys.RunNext.Matches(msg.String()) {
+				return m, m.runAdjacentStatement(1)
+			}
+			if m.keys.RunPrev.Matches(msg.String()) {
+				return m, m.runAdjacentStatement(-1)
+			}
+		}
+
+		// Show the plan of the statement under the cursor - Ctrl+X
+		if (m.focus == focusQuery || m.focus == focusResults) && m.keys.Explain.Matches(msg.String()) {
+			return m, m.explainQueryUnderCursor()
+		}
+
+		// Preview the rows an UPDATE or DELETE would change - Alt+P
+		if m.focus == focusQuery && m.keys.PreviewWrite.Matches(msg.String()) {
+			return m, m.previewWrite()
+		}
+
+		// Watch the statement under the cursor, or stop watching - Alt+W
+		if (m.focus == focusQuery || m.focus == focusResults) && m.keys.Watch.Matches(msg.String()) {
+			m.toggleWatch()
+			return m, nil
+		}
+
+		// Start a transaction, or commit or roll back the open one - Alt+Shift+T
+		if (m.focus == focusQuery || m.focus == focusResults) && m.keys.Transaction.Matches(msg.String()) {
+			m.toggleTransaction()
+			return m, nil
+		}
+
+		// Paste from the clipboard, or the last copy if it's unavailable - Ctrl+V
+		if m.focus == focusQuery && m.keys.Paste.Matches(msg.String()) {
+			m.pasteIntoEditor(readClipboard(m.clipboard))
+			return m, nil
+		}
+
+		// Copy the statement under the cursor - Alt+C
+		if m.focus == focusQuery && m.keys.CopyStatement.Matches(msg.String()) {
+			m.copyStatement()
+			return m, nil
+		}
+
+		// Go to a line - Ctrl+G
+		if m.focus == focusQuery && m.keys.GotoLine.Matches(msg.String()) {
+			m.openGotoLine()
+			return m, nil
+		}
+
+		// Insert a snippet - Alt+N
+		if m.keys.Snippets.Matches(msg.String()) {
+			m.openSnippets()
+			return m, nil
+		}
+
+		// Jump to or run a bookmarked statement - Alt+G
+		if m.keys.Bookmarks.Matches(msg.String()) {
+			m.openBookmarks()
+			return m, nil
+		}
+
+		// Browse query history - Ctrl+H
+		if m.keys.History.Matches(msg.String()) {
+			m.openHistory()
+			return m, nil
+		}
+
+		// Search the query editor - Ctrl+F
+		if m.keys.Search.Matches(msg.String()) && m.focus == focusQuery {
+			m.openEditorSearch()
+			return m, nil
+		}
+
+		// Find tables and columns - Alt+O
+		if m.keys.Finder.Matches(msg.String()) {
+			m.openFinder()
+			return m, nil
+		}
+
+		// View and change session settings - Alt+,
+		if m.keys.SessionSettings.Matches(msg.String()) {
+			m.openSessionPanel()
+			return m, nil
+		}
+
 		// Resize query window - works in results/banner view (not when typing in query)
 		if m.focus == focusResults && tab != nil {
-			switch msg.String() {
-			case "-":
+			switch {
+			case m.keys.ShrinkEditor.Matches(msg.String()):
 				// Shrink query window
 				h := tab.textarea.Height()
 				if h > 3 {
@@ -280,7 +963,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.statusMessage = fmt.Sprintf("Query window: %d lines", h-1)
 				}
 				return m, nil
-			case "+", "=":
+			case m.keys.GrowEditor.Matches(msg.String()):
 				// Grow query window
 				h := tab.textarea.Height()
 				maxHeight := m.height / 2 // Max half the screen
@@ -296,7 +979,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Also handle Ctrl+R from results/banner view (execute query)
-		if m.focus == focusResults && (msg.String() == "ctrl+r" || msg.String() == "f5") {
+		if m.focus == focusResults && m.keys.Run.Matches(msg.String()) {
 			// Switch to query, execute, handled below
 			m.focus = focusQuery
 			if tab != nil {
@@ -310,9 +993,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleDetailViewKeys(msg)
 		}
 
-		switch msg.String() {
-		case "esc":
+		// Bracketed paste: insert the text in one go, never as keybindings
+		if msg.Paste && m.focus == focusQuery && tab != nil {
+			m.pasteIntoEditor(string(msg.Runes))
+			return m, nil
+		}
+
+		// Vim emulation: in normal mode keys move and edit rather than type
+		if m.vimMode && m.focus == focusQuery && tab != nil && m.handleVimKey(msg) {
+			return m, nil
+		}
+
+		// Select text in the query editor - Shift+arrows, or Alt+V then arrows
+		if m.focus == focusQuery && tab != nil && m.updateSelection(msg) {
+			return m, nil
+		}
+
+		switch key := msg.String(); {
+		case key == "esc":
 			// Esc goes back one level, doesn't quit
+			if m.focus == focusQuery && tab != nil {
+				tab.placeholders = false // stop jumping between snippet placeholders
+			}
 			if m.focus == focusResults {
 				m.focus = focusQuery
 				if tab != nil {
@@ -321,10 +1023,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "tab":
+		case key == "tab":
 			// Tab toggles between query and results/banner pane
+			// unless there's a snippet placeholder to jump to or a table or
+			// column name to complete
 			switch m.focus {
 			case focusQuery:
+				if m.jumpToPlaceholder() || m.completeQuery() {
+					return m, nil
+				}
 				m.focus = focusResults
 				if tab != nil {
 					tab.textarea.Blur()
@@ -337,7 +1044,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "enter":
+		case key == "enter":
 			// Open detail view when in results (not banner)
 			if m.focus == focusResults && tab != nil && tab.result != nil && len(tab.result.Rows) > 0 {
 				m.openDetailView()
@@ -348,37 +1055,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case "ctrl+r", "f5":
-			if tab == nil {
-				return m, nil
-			}
-			// Execute the query under the cursor
-			query := m.getQueryUnderCursor()
-			if query == "" {
-				m.statusMessage = "No query under cursor. Queries must end with ';'"
-				return m, nil
-			}
-			tab.lastQuery = query
-			tab.result = executeQuery(tab.db, query)
-			tab.queryMeta = parseQueryMeta(query, tab.result)
-			tab.selectedRow = 0
-			tab.currentPage = 0
-			// Save the SQL file after executing
-			m.saveToFile()
-			if tab.result.Error != nil {
-				m.statusMessage = fmt.Sprintf("Error: %v", tab.result.Error)
-			} else {
-				tab.totalPages = (len(tab.result.Rows) + pageSize - 1) / pageSize
-				if tab.totalPages == 0 {
-					tab.totalPages = 1
-				}
-				m.statusMessage = fmt.Sprintf("Query returned %d rows", len(tab.result.Rows))
-				if len(tab.result.Rows) > 0 {
-					m.focus = focusResults
-					tab.textarea.Blur()
-				}
-			}
-			return m, nil
+		case m.keys.Run.Matches(key):
+			return m, m.runQueryUnderCursor()
+		}
+
+		// Run every statement in the editor - Alt+Shift+R
+		if m.keys.RunAll.Matches(msg.String()) {
+			return m, m.runAll()
 		}
 
 		// Handle navigation in results view
@@ -391,9 +1074,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 		// Adjust textarea width for all tabs
-		for _, t := range m.tabs {
-			t.textarea.SetWidth(msg.Width - 4)
-		}
+		m.resizeTextareas()
 
 		// On first window size, set textarea to 50% of height for all tabs
 		if !m.ready {
@@ -425,40 +1106,1440 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport = viewport.New(msg.Width, msg.Height-headerHeight-footerHeight-textareaHeight)
 		m.viewport.YPosition = headerHeight
 
-		// Update detail view visible fields if open
-		if tab != nil && tab.detailView != nil {
-			tab.detailView.visibleFields = (msg.Height - 12) / 2
-			if tab.detailView.visibleFields < 3 {
-				tab.detailView.visibleFields = 3
-			}
+		// Update detail view visible fields if open
+		if tab != nil && tab.detailView != nil {
+			tab.detailView.visibleFields = (msg.Height - 12) / 2
+			if tab.detailView.visibleFields < 3 {
+				tab.detailView.visibleFields = 3
+			}
+		}
+
+	case tea.MouseMsg:
+		// Handle mouse clicks on tabs
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			// Check if click is on the tab bar (row 2, after title)
+			// Title is row 0-1, tab bar is row 2
+			if msg.Y == 2 && len(m.tabs) > 1 {
+				clickedTab := m.getTabAtPosition(msg.X)
+				if clickedTab >= 0 && clickedTab != m.activeTab && clickedTab < len(m.tabs) {
+					m.saveToFile() // Save current tab before switching
+					m.activeTab = clickedTab
+					m.reloadFileFromDisk() // Reload the new tab's file
+					m.statusMessage = fmt.Sprintf("Tab %d: %s", m.activeTab+1, m.tabDisplayName(m.activeTab))
+				}
+				return m, nil
+			}
+		}
+	}
+
+	// Update textarea if focused
+	if m.focus == focusQuery && tab != nil {
+		if key, ok := msg.(tea.KeyMsg); ok && m.vimNormal() && !visualMoves[key.String()] {
+			return m, tea.Batch(cmds...) // only movement keys reach the editor in vim normal mode
+		}
+		var cmd tea.Cmd
+		tab.textarea, cmd = tab.textarea.Update(msg)
+		cmds = append(cmds, cmd)
+		if key, ok := msg.(tea.KeyMsg); ok {
+			m.updateCompletionPopup(key)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// updateSelection starts, extends, copies, deletes or clears the query
+// editor's text selection for a key, and reports whether the key was used up.
+// In a visual selection letters are commands and keys that would type are
+// ignored; typing over a Shift selection replaces it.
+func (m *Model) updateSelection(msg tea.KeyMsg) bool {
+	tab := m.activeTabPtr()
+	key := msg.String()
+
+	if m.keys.Select.Matches(key) {
+		if tab.selection != nil {
+			tab.selection = nil
+			m.statusMessage = "Selection cleared"
+			return true
+		}
+		tab.selection = &Selection{anchor: textareaCursorOffset(tab.textarea), visual: true}
+		m.statusMessage = "Visual selection: move to extend, Ctrl+R runs it, Esc cancels"
+		return true
+	}
+	if move, ok := selectionMoves[key]; ok {
+		if tab.selection == nil {
+			tab.selection = &Selection{anchor: textareaCursorOffset(tab.textarea)}
+		}
+		tab.textarea, _ = tab.textarea.Update(tea.KeyMsg{Type: move})
+		return true
+	}
+	if tab.selection == nil {
+		return false
+	}
+	if m.keys.Run.Matches(key) || m.keys.Explain.Matches(key) {
+		return false // runs (or explains) the selection
+	}
+
+	switch key {
+	case "esc":
+		tab.selection = nil
+		return true
+	case "backspace", "delete":
+		m.deleteSelection()
+		return true
+	}
+	if tab.selection.visual {
+		switch key {
+		case "c", "y":
+			m.copySelection()
+			tab.selection = nil
+			return true
+		case "x", "d":
+			m.copySelection()
+			m.deleteSelection()
+			return true
+		case "v":
+			m.deleteSelection()
+			tab.textarea.InsertString(pastedText(readClipboard(m.clipboard)))
+			return true
+		}
+	}
+	if visualMoves[key] {
+		if !tab.selection.visual {
+			tab.selection = nil // plain movement ends a Shift selection
+		}
+		return false // the editor moves the cursor
+	}
+	if (msg.Type == tea.KeyRunes && !msg.Alt) || msg.Type == tea.KeySpace || key == "enter" {
+		if tab.selection.visual {
+			return true // typing is blocked in a visual selection
+		}
+		m.deleteSelection()
+		return false // the editor types the key in place of the selected text
+	}
+	if key == "tab" && tab.selection.visual {
+		return true
+	}
+	tab.selection = nil
+	return false
+}
+
+// copySelection copies the selected text to the clipboard
+func (m *Model) copySelection() {
+	tab := m.activeTabPtr()
+	start, end := tab.selection.Range(textareaCursorOffset(tab.textarea))
+	m.clipboard = tab.textarea.Value()[start:end]
+	if !copyToClipboard(m.clipboard) {
+		m.statusMessage = "Copied (system clipboard unavailable)"
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Copied %d characters", utf8.RuneCountInString(m.clipboard))
+}
+
+// copyStatement copies the statement under the cursor to the clipboard,
+// without its terminating semicolon, or the selected text if there is some
+func (m *Model) copyStatement() {
+	if m.activeTabPtr().selection != nil {
+		m.copySelection()
+		return
+	}
+	stmt := m.getQueryUnderCursor()
+	if stmt == "" {
+		m.statusMessage = "No query under cursor. Queries must end with ';'"
+		return
+	}
+	m.clipboard = stmt
+	if !copyToClipboard(stmt) {
+		m.statusMessage = "Copied statement (system clipboard unavailable)"
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Copied statement (%d characters)", utf8.RuneCountInString(stmt))
+}
+
+// copyCell copies the whole value of the selected cell of the results to
+// the clipboard, however much of it the table shows
+func (m *Model) copyCell() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.selectedRow >= len(tab.result.Rows) || tab.selectedColumn >= len(tab.result.Columns) {
+		return
+	}
+	m.copyValue(tab.result.Columns[tab.selectedColumn], tab.result.Rows[tab.selectedRow][tab.selectedColumn])
+}
+
+// copyRowInsert copies the selected row of the results to the clipboard as
+// an INSERT into the table it was selected from
+func (m *Model) copyRowInsert() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.selectedRow >= len(tab.result.Rows) {
+		return
+	}
+	if tab.queryMeta == nil || tab.queryMeta.TableName == "" {
+		m.statusMessage = "Only a row selected from a single table can be copied as an INSERT"
+		return
+	}
+	stmt := tab.result.rowInsertSQL(tab.selectedRow, tab.queryMeta.TableName, tab.dbType)
+	m.clipboard = stmt
+	if !copyToClipboard(stmt) {
+		m.statusMessage = fmt.Sprintf("Copied row %d as an INSERT (system clipboard unavailable)", tab.selectedRow+1)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Copied row %d as an INSERT into %s", tab.selectedRow+1, tab.queryMeta.TableName)
+}
+
+// copyField copies the value of the detail view's focused field to the
+// clipboard
+func (m *Model) copyField() {
+	tab := m.activeTabPtr()
+	i := tab.detailView.focusedField
+	m.copyValue(tab.result.Columns[i], tab.detailView.fieldValue(i))
+}
+
+// copyValue copies a value of the named column to the clipboard
+func (m *Model) copyValue(column string, cell CellValue) {
+	if cell.IsNull {
+		m.statusMessage = fmt.Sprintf("%s is NULL - nothing to copy", column)
+		return
+	}
+	m.clipboard = cell.Value
+	if !copyToClipboard(cell.Value) {
+		m.statusMessage = fmt.Sprintf("Copied %s (system clipboard unavailable)", column)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Copied %s (%d characters)", column, utf8.RuneCountInString(cell.Value))
+}
+
+// toggleComment comments out the cursor line, or every line the selection
+// touches, or uncomments them if they're all comments already
+func (m *Model) toggleComment() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	content := tab.textarea.Value()
+	cursor := textareaCursorOffset(tab.textarea)
+	first, last := tab.textarea.Line(), tab.textarea.Line()
+	if tab.selection != nil {
+		start, end := tab.selection.Range(cursor)
+		first = strings.Count(content[:start], "\n")
+		last = strings.Count(content[:end], "\n")
+		if end > start && content[end-1] == '\n' {
+			last-- // selection ends at the start of a line it doesn't cover
+		}
+	}
+
+	updated, edits := toggleLineComments(content, first, last)
+	if updated == content {
+		return
+	}
+	if tab.selection != nil {
+		tab.selection.anchor = adjustOffsetForEdits(content, updated, tab.selection.anchor, edits)
+	}
+	tab.textarea.SetValue(updated)
+	moveCursorTo(&tab.textarea, adjustOffsetForEdits(content, updated, cursor, edits))
+}
+
+// deleteSelection removes the selected text
+func (m *Model) deleteSelection() {
+	tab := m.activeTabPtr()
+	content := tab.textarea.Value()
+	start, end := tab.selection.Range(textareaCursorOffset(tab.textarea))
+	tab.selection = nil
+	moveCursorTo(&tab.textarea, end)
+	replaceBeforeCursor(&tab.textarea, utf8.RuneCountInString(content[start:end]), "")
+}
+
+// pasteIntoEditor inserts pasted text at the query editor's cursor as a
+// single edit, replacing any selected text
+func (m *Model) pasteIntoEditor(text string) {
+	tab := m.activeTabPtr()
+	if tab.selection != nil {
+		m.deleteSelection()
+	}
+	tab.completion = nil
+	tab.textarea.InsertString(pastedText(text))
+}
+
+// translateMetaCommand returns the catalog query a psql-style backslash
+// command runs in tab, or the query itself if it isn't one. \x only toggles
+// expanded display, and returns "".
+func (m *Model) translateMetaCommand(tab *Tab, query string) (string, error) {
+	if !isMetaCommand(query) {
+		return query, nil
+	}
+	name, arg := parseMetaCommand(query)
+	if name == `\x` {
+		expanded, err := expandedSetting(arg, tab.expanded)
+		if err != nil {
+			return "", err
+		}
+		tab.expanded = expanded
+		m.statusMessage = expandedStatus(expanded)
+		return "", nil
+	}
+	return metaCommandQuery(name, arg, tab.dbType)
+}
+
+// runAll runs every statement in the editor in order, first asking for the
+// values of any {{name}}/:name variables
+func (m *Model) runAll() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	script := tab.textarea.Value()
+	if vars := scriptVariables(script, tab.dbType); len(vars) > 0 {
+		m.variablePrompt = newVariablePrompt(script, vars, m.variableValues)
+		m.variablePrompt.all = true
+		m.focus = focusVariables
+		tab.textarea.Blur()
+		m.statusMessage = ""
+		return nil
+	}
+	return m.runStatements(script, nil)
+}
+
+// runStatements runs each statement of a script in turn, stopping at the
+// first failure unless continue_on_error is set. Every statement is logged
+// to the messages panel, which is opened to show the per-statement outcomes.
+// values fills in each statement's placeholders.
+func (m *Model) runStatements(script string, values map[string]string) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+	var statements []string
+	for _, stmt := range SplitStatementsForDB(script, tab.dbType) {
+		if !isCommentOnly(stmt, tab.dbType) {
+			statements = append(statements, stmt)
+		}
+	}
+	if len(statements) == 0 {
+		m.statusMessage = "No statements to run"
+		return nil
+	}
+	tab.script = &scriptRun{statements: statements, values: values}
+	return m.continueScript(tab)
+}
+
+// scriptRun is a script being run a statement at a time by runStatements
+type scriptRun struct {
+	statements []string
+	values     map[string]string // for the statements' placeholders
+	next       int               // index of the next statement to start
+	failed     int
+}
+
+// continueScript starts the next statement of the tab's script, or reports
+// how the run went once every statement has been run
+func (m *Model) continueScript(tab *Tab) tea.Cmd {
+	run := tab.script
+	for run.next < len(run.statements) {
+		stmt := run.statements[run.next]
+		run.next++
+		query, err := m.translateMetaCommand(tab, stmt)
+		if err != nil {
+			m.logTabResult(tab, stmt, &QueryResult{Error: err}, 0)
+			if m.scriptFailed(tab, err) {
+				return nil
+			}
+			continue
+		}
+		if query != "" && run.values != nil && !isMetaCommand(stmt) {
+			query, args := bindVariables(query, findQueryVariables(query, tab.dbType), run.values, tab.dbType)
+			return m.startQuery(tab, query, args...)
+		}
+		if query != "" {
+			return m.startQuery(tab, query)
+		}
+	}
+
+	tab.script = nil
+	m.showMessages = true
+	m.statusMessage = fmt.Sprintf("Ran %d statements", len(run.statements))
+	if run.failed > 0 {
+		m.statusMessage += fmt.Sprintf(", %d failed", run.failed)
+	}
+	return nil
+}
+
+// scriptFailed counts a failed statement of the tab's script, reporting true
+// if the run stops there
+func (m *Model) scriptFailed(tab *Tab, err error) bool {
+	run := tab.script
+	run.failed++
+	if m.continueOnError {
+		return false
+	}
+	tab.script = nil
+	m.showMessages = true
+	m.statusMessage = fmt.Sprintf("Stopped at statement %d of %d: %v", run.next, len(run.statements), err)
+	return true
+}
+
+// queryResultMsg is sent when a statement started by startQuery finishes
+type queryResultMsg struct {
+	tab       *Tab
+	db        *sql.DB // the connection it ran on, in case the tab has switched since
+	query     string
+	args      []any // bound to the query's placeholders
+	result    *QueryResult
+	duration  time.Duration
+	cancelled bool // stopped by cancelQuery
+
+	// Set when the query was a BEGIN, COMMIT or ROLLBACK that started or
+	// ended the tab's transaction
+	txAction string
+	tx       *sql.Tx // the transaction a BEGIN started
+
+	// Set when the query was a change made from the detail view; result
+	// is then the tab's last query, re-run to show the change
+	write *detailWrite
+
+	reload  bool   // re-ran the tab's last query to show a change (see reloadResult)
+	preview string // the UPDATE or DELETE whose rows the query read (see previewWrite)
+}
+
+// runQuery executes a query in the active tab, with args bound to its
+// placeholders, and shows its result once it finishes
+func (m *Model) runQuery(query string, args ...any) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+	return m.startQuery(tab, query, args...)
+}
+
+// startQuery runs a query on the tab's connection in the background, so the
+// UI stays responsive while it runs; the spinner ticks until it finishes.
+// A read-only tab refuses statements that write, and an UPDATE or DELETE
+// without WHERE waits for confirmation first.
+func (m *Model) startQuery(tab *Tab, query string, args ...any) tea.Cmd {
+	if tab.readOnly {
+		if err := readOnlyError(query); err != nil {
+			m.logTabResult(tab, query, &QueryResult{Error: err}, 0)
+			tab.script = nil
+			m.statusMessage = fmt.Sprintf("Error: %v", err)
+			return nil
+		}
+	}
+	if m.confirmWrite(tab, query, args) {
+		return nil
+	}
+	return m.launchQuery(tab, query, args...)
+}
+
+// launchQuery starts running a query in the background
+func (m *Model) launchQuery(tab *Tab, query string, args ...any) tea.Cmd {
+	timeout := tab.statementTimeout
+	ctx, cancel := statementContext(timeout)
+	tab.closeStreams()
+	limit := m.queryRowLimit(query)
+	if tab.diffBase != nil && limit > 0 {
+		// Fetch as many rows as the result being compared with
+		limit = max(limit, len(tab.diffBase.Rows))
+	}
+	tab.running = query
+	tab.runningSince = time.Now()
+	tab.cancelQuery = cancel
+	db, tx, runner := tab.db, tab.tx, tab.runner()
+	action := transactionCommand(query)
+	dryRunning := tab.dryRun && writesData(query)
+	dbType := tab.dbType
+	server := newServerQuery(db, dbType)
+	if dryRunning || action != "" {
+		server = nil // run in a transaction of their own, or instantly
+	}
+	tab.serverQuery = server
+	run := func() tea.Msg {
+		start := time.Now()
+		msg := queryResultMsg{tab: tab, db: db, query: query, args: args}
+		// Typed BEGIN/COMMIT/ROLLBACK go through database/sql's transaction,
+		// so the statements between them share its connection
+		switch {
+		case dryRunning:
+			msg.result = dryRun(ctx, db, tx, dbType, query, args...)
+		case action == txBegin && tx != nil:
+			msg.result = &QueryResult{Error: errors.New("a transaction is already open")}
+		case action == txBegin:
+			var err error
+			msg.tx, err = beginTx(db)
+			msg.result = &QueryResult{Executed: true, RowsAffected: -1, Error: err}
+			if err == nil {
+				msg.txAction = action
+			}
+		case action == txCommit && tx != nil:
+			msg.result = &QueryResult{Executed: true, RowsAffected: -1, Error: tx.Commit()}
+			msg.txAction = action
+		case action == txRollback && tx != nil:
+			msg.result = &QueryResult{Executed: true, RowsAffected: -1, Error: tx.Rollback()}
+			msg.txAction = action
+		default:
+			pinned, release := server.pin(ctx, runner)
+			if ReturnsRows(query) {
+				msg.result = openQuery(ctx, pinned, query, limit, args...)
+			} else {
+				msg.result = executeStatement(ctx, pinned, query, args...)
+			}
+			release()
+		}
+		if msg.result.Error != nil {
+			msg.result.Error = timeoutError(ctx, msg.result.Error, timeout)
+		}
+		msg.duration = time.Since(start)
+		msg.cancelled = errors.Is(ctx.Err(), context.Canceled)
+		return msg
+	}
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// cancelQuery stops the query running in the active tab, and the rest of
+// its script if it's running one, killing it on the server too where the
+// database allows. The database reports the cancellation when the query
+// finishes.
+func (m *Model) cancelQuery() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.cancelQuery == nil {
+		return nil
+	}
+	tab.cancelQuery()
+	m.statusMessage = "Cancelling query..."
+	return tab.serverQuery.kill()
+}
+
+// finishQuery shows a finished query's result in the tab it ran in, and
+// starts the next statement if the tab is running a script
+func (m *Model) finishQuery(msg queryResultMsg) tea.Cmd {
+	tab := msg.tab
+	idx := slices.Index(m.tabs, tab)
+	if idx < 0 || msg.db != tab.db {
+		if msg.tx != nil {
+			_ = msg.tx.Rollback() // nothing is left to run in it
+		}
+	}
+	if idx < 0 {
+		return nil // the tab was closed while the query ran
+	}
+	tab.running = ""
+	tab.cancelQuery() // release the query's context
+	tab.cancelQuery = nil
+	tab.serverQuery = nil
+	diffBase := tab.diffBase
+	tab.diffBase = nil
+	if msg.db != tab.db {
+		// The tab switched connection; the result belongs to the old one
+		tab.script = nil
+		tab.watch = nil
+		return nil
+	}
+	switch msg.txAction {
+	case txBegin:
+		tab.tx = msg.tx
+		tab.txSince = time.Now()
+	case txCommit, txRollback:
+		// Over even if COMMIT failed; the database rolls it back
+		tab.tx = nil
+	}
+	active := idx == m.activeTab
+	prefix := ""
+	if !active {
+		prefix = m.tabDisplayName(idx) + ": "
+	}
+	if msg.write != nil {
+		return m.finishDetailWrite(tab, msg, prefix)
+	}
+	if msg.preview != "" {
+		m.finishPreviewWrite(tab, msg, prefix)
+		return nil
+	}
+	if msg.reload {
+		// The status still reports the change the results are refreshed for
+		if msg.cancelled {
+			m.statusMessage += " (refresh cancelled)"
+			return nil
+		}
+		m.showReloaded(tab, msg.result)
+		if tab.result.Error != nil {
+			m.statusMessage += fmt.Sprintf(" (refresh failed: %v)", tab.result.Error)
+		}
+		return nil
+	}
+	if msg.cancelled {
+		// Keep showing the previous result; a cancelled script stops here
+		m.logTabResult(tab, msg.query, &QueryResult{Error: errors.New("cancelled")}, msg.duration)
+		tab.script = nil
+		m.statusMessage = prefix + "Query cancelled"
+		if tab.watch != nil {
+			tab.watch = nil
+			m.statusMessage += " - stopped watching"
+		}
+		return nil
+	}
+
+	query := msg.query
+	tab.lastQuery = query
+	tab.lastArgs = msg.args
+	previous := tab.result
+	tab.result = msg.result
+	tab.parseResultError()
+	m.logTabResult(tab, query, tab.result, msg.duration)
+	tab.queryMeta = parseQueryMeta(query, tab.result, tab.schema.PrimaryKey)
+	// A watched query's later runs replace its result rather than add to
+	// the history; running anything else stops the watch
+	watched := tab.watch != nil && query == tab.watch.query
+	if !watched {
+		tab.watch = nil
+	}
+	inPlace := watched && tab.watch.runs > 0
+	tab.diff = nil
+	diffed := false
+	if diffBase != nil && tab.result.Error == nil && !tab.result.Executed {
+		var keys []int
+		if tab.queryMeta != nil {
+			keys = tab.queryMeta.KeyIndexes
+		}
+		tab.diff, diffed = diffResults(diffBase, tab.result, keys)
+	}
+	m.restoreColumnLayout(tab)
+	if inPlace {
+		tab.replaceLatestResult()
+	} else {
+		tab.pushResult()
+	}
+	if IsDDLStatement(query) {
+		tab.schema.Invalidate() // table/column metadata may have changed
+		if m.showSidebar && active {
+			m.loadSidebarSchema()
+		}
+	}
+	tab.errorScroll = 0
+	tab.resultSearch = nil // its matches were for the previous result
+	if inPlace {
+		// Keep the selection where it was, if the row's still there
+		tab.selectedRow = min(tab.selectedRow, max(len(tab.result.Rows)-1, 0))
+		tab.currentPage = tab.selectedRow / tab.rowsPerPage()
+	} else {
+		tab.selectedRow = 0
+		tab.currentPage = 0
+		if previous == nil || !slices.Equal(previous.Columns, tab.result.Columns) {
+			// Stay on the same column when re-running or sorting a query
+			tab.firstColumn = 0
+			tab.selectedColumn = 0
+		}
+	}
+	tab.showSelectedColumn()
+	// Save the SQL file after executing
+	m.saveTab(tab)
+	if tab.result.Error != nil {
+		m.statusMessage = fmt.Sprintf("%sError: %v", prefix, tab.result.Error)
+	} else if tab.result.Executed {
+		tab.totalPages = 1
+		m.statusMessage = fmt.Sprintf("%s%s in %s", prefix, resultOutcome(tab.result), formatDuration(msg.duration))
+		switch msg.txAction {
+		case txBegin:
+			m.statusMessage = prefix + "Transaction started - statements run in it until you commit or roll back"
+		case txCommit:
+			m.statusMessage = prefix + "Committed"
+		case txRollback:
+			m.statusMessage = prefix + "Rolled back"
+		}
+	} else {
+		tab.totalPages = tab.pageCount(len(tab.result.Rows))
+		m.statusMessage = fmt.Sprintf("%sQuery returned %d rows in %s (run %s + fetch %s)", prefix, len(tab.result.Rows),
+			formatDuration(msg.duration), formatDuration(tab.result.ExecTime), formatDuration(tab.result.FetchTime))
+		if tab.result.HasMoreRows() {
+			m.statusMessage = fmt.Sprintf("%sShowing first %d rows in %s (run %s + fetch %s), Alt+L for more", prefix, len(tab.result.Rows),
+				formatDuration(msg.duration), formatDuration(tab.result.ExecTime), formatDuration(tab.result.FetchTime))
+		}
+		if tab.result.DryRun {
+			m.statusMessage = fmt.Sprintf("%s%s in %s", prefix, resultOutcome(tab.result), formatDuration(msg.duration))
+		}
+		if diffed {
+			m.statusMessage = prefix + tab.diff.summary()
+		} else if diffBase != nil {
+			m.statusMessage = prefix + "Re-run: the columns changed, so the rows can't be compared"
+		}
+		// Show the rows, unless another view was opened while the query ran
+		// or they're a watched query's, already shown
+		if len(tab.result.Rows) > 0 && active && !inPlace && (m.focus == focusQuery || m.focus == focusResults) {
+			m.focus = focusResults
+			tab.textarea.Blur()
+			// Expanded display (\x) shows one record at a time
+			if tab.expanded {
+				m.openDetailView()
+			} else if plan, ok := resultPlan(tab.result); ok && tab.script == nil {
+				// EXPLAIN ANALYZE output opens as a tree; Esc shows the raw rows
+				m.planView = newPlanView(plan, focusResults)
+				m.focus = focusPlan
+			}
+		}
+	}
+
+	if isConnectionLost(tab.result.Error) {
+		// Offer to reconnect; a script stops here either way
+		tab.script = nil
+		m.reconnectPrompt = &lostStatement{tab: tab, query: query, args: msg.args, err: tab.result.Error, txLost: tab.tx != nil}
+		tab.watch = nil
+		return nil
+	}
+
+	if watched {
+		if tab.result.Error != nil {
+			tab.watch = nil
+			m.statusMessage += " - stopped watching"
+			return nil
+		}
+		tab.watch.runs++
+		m.statusMessage += " - " + tab.watch.status()
+		return tab.nextWatchTick()
+	}
+
+	if tab.script != nil {
+		if tab.result.Error != nil && m.scriptFailed(tab, tab.result.Error) {
+			return nil
+		}
+		return m.continueScript(tab)
+	}
+	return nil
+}
+
+// mainWidth returns the width available to the main view (excluding the sidebar)
+func (m Model) mainWidth() int {
+	if m.showSidebar {
+		return max(m.width-sidebarWidth, 20)
+	}
+	return m.width
+}
+
+// resizeTextareas fits every tab's query editor to the main view width
+func (m *Model) resizeTextareas() {
+	for _, t := range m.tabs {
+		t.textarea.SetWidth(m.mainWidth() - 4)
+	}
+}
+
+// toggleSidebar opens and focuses the schema browser, focuses it if it is
+// open but unfocused, or closes it if it already has focus
+func (m *Model) toggleSidebar() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+
+	if m.showSidebar && m.focus == focusSidebar {
+		m.showSidebar = false
+		m.focus = focusQuery
+		tab.textarea.Focus()
+		m.resizeTextareas()
+		return
+	}
+
+	if !m.showSidebar {
+		m.showSidebar = true
+		m.resizeTextareas()
+	}
+	m.loadSidebarSchema()
+	m.focus = focusSidebar
+	tab.textarea.Blur()
+}
+
+// loadSidebarSchema loads the tables and row counts shown in the schema
+// browser, if they aren't already cached
+func (m *Model) loadSidebarSchema() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	if _, err := tab.schema.Tables(); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load schema: %v", err)
+		return
+	}
+	if _, err := tab.schema.RowCounts(); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load row counts: %v", err)
+	}
+	if _, err := tab.schema.Objects(); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load triggers and routines: %v", err)
+	}
+	// Tables left expanded keep showing their indexes after a reload
+	for name, expanded := range tab.sidebarExpanded {
+		if expanded {
+			m.expandSidebarTable(name)
+		}
+	}
+}
+
+// sidebarItemCount returns the number of selectable sidebar entries: the
+// tables, followed by the triggers and routines
+func sidebarItemCount(tab *Tab) int {
+	return len(tab.schema.tables) + len(tab.schema.objects)
+}
+
+// selectedSidebarObject returns the trigger or routine selected in the
+// sidebar, or nil if a table (or nothing) is selected
+func selectedSidebarObject(tab *Tab) *SchemaObject {
+	i := tab.sidebarSelected - len(tab.schema.tables)
+	if i < 0 || i >= len(tab.schema.objects) {
+		return nil
+	}
+	return &tab.schema.objects[i]
+}
+
+// openFinder opens the fuzzy finder over the current connection's tables and columns
+func (m *Model) openFinder() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	tables, err := tab.schema.Tables()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load schema: %v", err)
+		return
+	}
+	m.finder = newSchemaFinder(tables)
+	m.focus = focusFinder
+	tab.textarea.Blur()
+	m.statusMessage = ""
+}
+
+// openEditorSearch starts an incremental search of the query editor from
+// the cursor
+func (m *Model) openEditorSearch() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	m.editorSearch = newEditorSearch(textareaCursorOffset(tab.textarea))
+	m.focus = focusSearch
+	tab.selection = nil
+	tab.completion = nil
+	m.statusMessage = ""
+}
+
+// openGotoLine asks for a line number to move the editor cursor to
+func (m *Model) openGotoLine() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	ti := textinput.New()
+	ti.Prompt = "Go to line: "
+	ti.Placeholder = fmt.Sprintf("1-%d", tab.textarea.LineCount())
+	ti.CharLimit = 7
+	ti.Width = 10
+	ti.Focus()
+	m.gotoLine = &ti
+	m.focus = focusGotoLine
+	tab.completion = nil
+	m.statusMessage = ""
+}
+
+// runQueryUnderCursor runs the selection or the statement under the cursor,
+// prompting for its variables first if it has any
+func (m *Model) runQueryUnderCursor() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	query := m.getQueryUnderCursor()
+	if query == "" && tab.selection != nil {
+		m.statusMessage = "Nothing selected"
+		return nil
+	}
+	if query == "" {
+		m.statusMessage = "No query under cursor. Queries must end with ';'"
+		return nil
+	}
+	// psql-style backslash commands run as catalog queries
+	meta := isMetaCommand(query)
+	query, err := m.translateMetaCommand(tab, query)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+	if query == "" {
+		return nil // \x only changes the display
+	}
+	// {{name}} and :name variables are filled in before running
+	if vars := findQueryVariables(query, tab.dbType); len(vars) > 0 && !meta {
+		m.variablePrompt = newVariablePrompt(query, vars, m.variableValues)
+		m.focus = focusVariables
+		tab.textarea.Blur()
+		m.statusMessage = ""
+		return nil
+	}
+	return m.runQuery(query)
+}
+
+// explainQueryUnderCursor shows the plan of the selected text or the
+// statement under the cursor in the results, leaving the editor as it is
+func (m *Model) explainQueryUnderCursor() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	query := m.getQueryUnderCursor()
+	if query == "" {
+		m.statusMessage = "No query under cursor. Queries must end with ';'"
+		return nil
+	}
+	if isMetaCommand(query) {
+		m.statusMessage = "Backslash commands have no query plan"
+		return nil
+	}
+	query = explainSQL(query, tab.dbType)
+	if vars := findQueryVariables(query, tab.dbType); len(vars) > 0 {
+		m.variablePrompt = newVariablePrompt(query, vars, m.variableValues)
+		m.focus = focusVariables
+		tab.textarea.Blur()
+		m.statusMessage = ""
+		return nil
+	}
+	return m.runQuery(query)
+}
+
+// runAdjacentStatement moves the editor cursor to the statement after (dir 1)
+// or before (dir -1) the one under it, which is the one last run, and runs it
+func (m *Model) runAdjacentStatement(dir int) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	var ranges []stmtRange
+	content := tab.textarea.Value()
+	for _, r := range splitStatementRanges(content, tab.dbType) {
+		if !isCommentOnly(content[r.start:r.end], tab.dbType) {
+			ranges = append(ranges, r)
+		}
+	}
+	pos := adjacentStatementStart(ranges, textareaCursorOffset(tab.textarea), dir)
+	if pos < 0 {
+		m.statusMessage = "No more statements"
+		return nil
+	}
+	moveCursorTo(&tab.textarea, pos)
+	tab.selection = nil
+	tab.completion = nil
+	m.focus = focusQuery
+	tab.textarea.Focus()
+	return m.runQueryUnderCursor()
+}
+
+// jumpToStatement moves the editor cursor to the start of the next (dir 1)
+// or previous (dir -1) statement, as the statement splitter sees them
+func (m *Model) jumpToStatement(dir int) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	ranges := splitStatementRanges(tab.textarea.Value(), tab.dbType)
+	cursor := textareaCursorOffset(tab.textarea)
+	pos := nextStatementStart(ranges, cursor)
+	if dir < 0 {
+		pos = prevStatementStart(ranges, cursor)
+	}
+	if pos < 0 {
+		m.statusMessage = "No more statements"
+		return
+	}
+	moveCursorTo(&tab.textarea, pos)
+	tab.completion = nil
+	m.statusMessage = ""
+}
+
+// openDDLView shows the CREATE statement for the table, trigger or routine
+// selected in the sidebar, or else the table referenced by the query under
+// the cursor
+func (m *Model) openDDLView() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+
+	if obj := selectedSidebarObject(tab); m.focus == focusSidebar && obj != nil {
+		ddl, err := objectDDL(tab.db, tab.dbType, *obj)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to get definition of %s: %v", obj.Name, err)
+			return
+		}
+		m.ddlView = &DDLView{table: obj.Name, ddl: ddl, returnFocus: m.focus}
+		m.focus = focusDDL
+		m.statusMessage = ""
+		return
+	}
+
+	table := ""
+	if m.focus == focusSidebar && tab.sidebarSelected < len(tab.schema.tables) {
+		table = tab.schema.tables[tab.sidebarSelected].Name
+	} else {
+		table = referencedTable(m.getQueryUnderCursor())
+		if table == "" {
+			table = referencedTable(tab.lastQuery)
+		}
+	}
+	if table == "" {
+		m.statusMessage = "No table referenced by the current query"
+		return
+	}
+
+	ddl, err := tableDDL(tab.db, tab.dbType, table)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to get DDL for %s: %v", table, err)
+		return
+	}
+	m.ddlView = &DDLView{table: table, ddl: ddl, returnFocus: m.focus}
+	m.focus = focusDDL
+	tab.textarea.Blur()
+	m.statusMessage = ""
+}
+
+// previewRows is the number of rows shown by the table preview popup
+const previewRows = 10
+
+// openPreview shows the first rows of a table in a popup, leaving the
+// editor and the current result untouched
+func (m *Model) openPreview(table string) {
+	tab := m.activeTabPtr()
+	if tab == nil || table == "" {
+		return
+	}
+	query := selectTableSQL(table, tab.dbType, previewRows)
+	if err := m.openPopup("👀 "+table, fmt.Sprintf("First %d rows", previewRows), query); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to preview %s: %v", table, err)
+	}
+}
+
+// openGrants shows the current user's privileges on a table in a popup
+func (m *Model) openGrants(table string) {
+	tab := m.activeTabPtr()
+	if tab == nil || table == "" {
+		return
+	}
+	if err := m.openPopup("🔑 "+table, "Privileges of the current user", grantsQuery(table, tab.dbType)); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load privileges for %s: %v", table, err)
+	}
+}
+
+// openPopup runs a query on the active tab's connection and shows the
+// result in a popup
+func (m *Model) openPopup(title, note, query string) error {
+	result := executeQuery(context.Background(), m.activeTabPtr().db, query)
+	if result.Error != nil {
+		return result.Error
+	}
+	m.preview = &TablePreview{title: title, note: note, result: result, returnFocus: m.focus}
+	m.focus = focusPreview
+	return nil
+}
+
+// openHistory opens the browser over the current connection's statement history
+func (m *Model) openHistory() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	name := historyConnectionName(tab)
+	entries, err := loadHistory(historyPath(tab.sqlDir, name))
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load history: %v", err)
+		return
+	}
+	m.history = newHistoryBrowser(name, entries)
+	m.focus = focusHistory
+	tab.textarea.Blur()
+	m.statusMessage = ""
+}
+
+// formatQuery reformats the statement under the cursor in place and saves the file
+func (m *Model) formatQuery() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	content := tab.textarea.Value()
+	start, end := statementRangeAtLine(content, tab.textarea.Line(), tab.dbType)
+	if start < 0 {
+		m.statusMessage = "No query under cursor. Queries must end with ';'"
+		return
+	}
+	stmt := strings.TrimSuffix(content[start:end], ";")
+	if isMetaCommand(stmt) {
+		m.statusMessage = "Meta-commands aren't formatted"
+		return
+	}
+	formatted := formatSQL(stmt) + content[start+len(stmt):end]
+	if formatted == content[start:end] {
+		m.statusMessage = "Statement is already formatted"
+		return
+	}
+	tab.textarea.SetValue(content[:start] + formatted + content[end:])
+	moveCursorTo(&tab.textarea, start)
+	m.saveToFile()
+	m.statusMessage = "Formatted statement"
+}
+
+// uppercaseQueryKeywords uppercases the keywords in the statement under the
+// cursor, leaving its layout alone, and saves the file
+func (m *Model) uppercaseQueryKeywords() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	content := tab.textarea.Value()
+	start, end := statementRangeAtLine(content, tab.textarea.Line(), tab.dbType)
+	if start < 0 {
+		m.statusMessage = "No query under cursor. Queries must end with ';'"
+		return
+	}
+	stmt := content[start:end]
+	if isMetaCommand(stmt) {
+		m.statusMessage = "Meta-commands aren't changed"
+		return
+	}
+	upper := uppercaseKeywords(stmt, tab.dbType)
+	if upper == stmt {
+		m.statusMessage = "Keywords are already uppercase"
+		return
+	}
+	cursor := textareaCursorOffset(tab.textarea)
+	tab.textarea.SetValue(content[:start] + upper + content[end:])
+	moveCursorTo(&tab.textarea, cursor) // same length, so the cursor stays put
+	m.saveToFile()
+	m.statusMessage = "Uppercased keywords"
+}
+
+// openSnippets opens the picker over the snippet library in the SQL directory
+func (m *Model) openSnippets() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	dir := snippetsDir(tab.sqlDir)
+	snippets, err := loadSnippets(dir)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load snippets: %v", err)
+		return
+	}
+	if len(snippets) == 0 {
+		m.statusMessage = fmt.Sprintf("No snippets yet - add .sql files to %s", dir)
+		return
+	}
+	m.snippets = newSnippetPicker(dir, snippets)
+	m.focus = focusSnippets
+	tab.textarea.Blur()
+	m.statusMessage = ""
+}
+
+// openBookmarks opens the picker over the statements in the active tab
+// named with "-- @name:" comments
+func (m *Model) openBookmarks() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	bookmarks := findBookmarks(tab.textarea.Value())
+	if len(bookmarks) == 0 {
+		m.statusMessage = "No bookmarks - name a statement with a \"-- @name: report\" comment above it"
+		return
+	}
+	m.bookmarks = newBookmarkPicker(bookmarks)
+	m.focus = focusBookmarks
+	tab.textarea.Blur()
+	tab.completion = nil
+	m.statusMessage = ""
+}
+
+// jumpToBookmark moves the editor cursor to the start of a bookmarked statement
+func (m *Model) jumpToBookmark(b Bookmark) {
+	tab := m.activeTabPtr()
+	moveCursorTo(&tab.textarea, lineOffset(tab.textarea.Value(), b.Line))
+	tab.selection = nil
+	m.statusMessage = "Bookmark " + b.Name
+}
+
+// insertSnippet inserts a snippet at the cursor and moves to its first
+// placeholder, if it has any
+func (m *Model) insertSnippet(snippet Snippet) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	start := textareaCursorOffset(tab.textarea)
+	tab.textarea.InsertString(snippet.Body)
+	m.statusMessage = "Inserted snippet " + snippet.Name
+
+	// The placeholders are searched for from where the snippet went in
+	end := textareaCursorOffset(tab.textarea)
+	if first, _ := nextPlaceholder(tab.textarea.Value()[:end], start); first >= 0 {
+		moveCursorTo(&tab.textarea, first)
+		tab.placeholders = true
+		m.jumpToPlaceholder()
+		m.statusMessage += " - Tab: next placeholder"
+	}
+}
+
+// jumpToPlaceholder removes the next ${name} placeholder after the cursor of
+// an inserted snippet and leaves the cursor in its place, ready to type the
+// value. It returns false once there are no placeholders left.
+func (m *Model) jumpToPlaceholder() bool {
+	tab := m.activeTabPtr()
+	if tab == nil || !tab.placeholders {
+		return false
+	}
+	start, end := nextPlaceholder(tab.textarea.Value(), textareaCursorOffset(tab.textarea))
+	if start < 0 {
+		tab.placeholders = false
+		return false
+	}
+	// Delete backwards from its end: the textarea joins the next line when
+	// deleting forwards removes the last character of a line
+	content := tab.textarea.Value()
+	moveCursorTo(&tab.textarea, end)
+	replaceBeforeCursor(&tab.textarea, utf8.RuneCountInString(content[start:end]), "")
+	if next, _ := nextPlaceholder(tab.textarea.Value(), start); next < 0 {
+		tab.placeholders = false
+	}
+	return true
+}
+
+// moveCursorTo moves the cursor of ta to byte offset pos of its value. It
+// moves a whole line at a time, so even a long file doesn't take a key per
+// character.
+func moveCursorTo(ta *textarea.Model, pos int) {
+	content := ta.Value()
+	pos = max(min(pos, len(content)), 0)
+	row := strings.Count(content[:pos], "\n")
+	col := utf8.RuneCountInString(content[strings.LastIndexByte(content[:pos], '\n')+1 : pos])
+
+	// From the start or end of a line, CursorUp/CursorDown go to the
+	// neighbouring line however it wraps
+	for ta.Line() > row {
+		ta.CursorStart()
+		ta.CursorUp()
+	}
+	for ta.Line() < row {
+		ta.CursorEnd()
+		ta.CursorDown()
+	}
+	ta.SetCursor(col)
+}
+
+// exportSchema writes the CREATE statements of every table in the current
+// database to a snapshot file in the SQL directory
+func (m *Model) exportSchema() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	tables, err := tab.schema.Tables()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load schema: %v", err)
+		return
+	}
+	path, err := exportSchemaDDL(tab.db, tab.dbType, tables, tab.sqlDir, extractDatabaseName(tab.dsn, tab.dbType))
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Schema export failed: %v", err)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Exported %d tables/views to %s", len(tables), path)
+}
+
+// completeQuery completes the table or column name being typed at the cursor
+// in the query editor. It returns false if the cursor isn't at a partial
+// name where a table or column is expected, or nothing completes it, so Tab
+// isn't swallowed and falls back to switching panes.
+func (m *Model) completeQuery() bool {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return false
+	}
+	tables, err := tab.schema.Tables()
+	if err != nil {
+		return false
+	}
+	c, ok := completeAt(tab.textarea.Value(), textareaCursorOffset(tab.textarea), tables, false)
+	if !ok {
+		return false
+	}
+
+	switch len(c.Candidates) {
+	case 0:
+		m.statusMessage = fmt.Sprintf("No completions for %q", c.Prefix)
+		return false
+	case 1:
+		replaceBeforeCursor(&tab.textarea, len([]rune(c.Prefix)), quoteNameIfNeeded(c.Candidates[0], tab.dbType))
+		m.statusMessage = ""
+	default:
+		replaceBeforeCursor(&tab.textarea, len([]rune(c.Prefix)), c.CommonPrefix())
+		shown := c.Candidates
+		if len(shown) > completionMaxShown {
+			shown = shown[:completionMaxShown]
+		}
+		m.statusMessage = strings.Join(shown, " · ")
+		if len(c.Candidates) > len(shown) {
+			m.statusMessage += fmt.Sprintf(" · … (%d more)", len(c.Candidates)-len(shown))
+		}
+	}
+	return true
+}
+
+// updateCompletionPopup refreshes the suggestions under the cursor after a
+// key reached the editor. Typing or deleting opens or narrows the popup;
+// any other key, such as moving the cursor, closes it.
+func (m *Model) updateCompletionPopup(key tea.KeyMsg) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	tab.completion = nil
+	if key.Type != tea.KeyRunes && key.Type != tea.KeyBackspace {
+		return
+	}
+	// Only names that are already cached, so typing never waits on the catalog
+	tables, _ := tab.schema.CachedTables()
+	if c, ok := completeAt(tab.textarea.Value(), textareaCursorOffset(tab.textarea), tables, true); ok {
+		tab.completion = newCompletionPopup(c)
+	}
+}
+
+// acceptCompletion replaces the partial word at the cursor with the
+// suggestion highlighted in the completion popup
+func (m *Model) acceptCompletion() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.completion == nil {
+		return
+	}
+	p := tab.completion
+	tab.completion = nil
+	replaceBeforeCursor(&tab.textarea, len([]rune(p.Prefix)), quoteNameIfNeeded(p.Selected(), tab.dbType))
+}
+
+// textareaCursorOffset returns the byte offset of the cursor in ta's value
+func textareaCursorOffset(ta textarea.Model) int {
+	lines := strings.Split(ta.Value(), "\n")
+	row := ta.Line()
+	if row >= len(lines) {
+		return len(ta.Value())
+	}
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+	info := ta.LineInfo()
+	runes := []rune(lines[row])
+	col := min(info.StartColumn+info.ColumnOffset, len(runes))
+	return offset + len(string(runes[:col]))
+}
+
+// replaceBeforeCursor deletes n characters before the cursor and inserts s
+func replaceBeforeCursor(ta *textarea.Model, n int, s string) {
+	for i := 0; i < n; i++ {
+		*ta, _ = ta.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	ta.InsertString(s)
+}
+
+// openERView loads the foreign keys of every table and opens the
+// entity-relationship overview
+func (m *Model) openERView() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	tables, err := tab.schema.Tables()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load schema: %v", err)
+		return
+	}
+
+	fks := make(map[string][]SchemaForeignKey)
+	for _, table := range tables {
+		if table.IsView {
+			continue
+		}
+		tableFKs, err := tab.schema.ForeignKeys(table.Name)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Failed to load foreign keys for %s: %v", table.Name, err)
+			return
+		}
+		fks[strings.ToLower(table.Name)] = tableFKs
+	}
+
+	m.erView = &ERView{lines: erDiagramLines(tables, fks)}
+	m.focus = focusER
+	tab.textarea.Blur()
+	m.statusMessage = ""
+}
+
+// expandSidebarTable expands a table in the schema sidebar, loading its indexes
+func (m *Model) expandSidebarTable(name string) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	if tab.sidebarExpanded == nil {
+		tab.sidebarExpanded = make(map[string]bool)
+	}
+	tab.sidebarExpanded[name] = true
+	if _, err := tab.schema.Indexes(name); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load indexes for %s: %v", name, err)
+	}
+	if _, err := tab.schema.Sequence(name); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load key sequence for %s: %v", name, err)
+	}
+	if _, _, err := tab.schema.TableRowCount(name); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to count rows of %s: %v", name, err)
+	}
+}
+
+// revealInSidebar opens the schema sidebar with the given table selected,
+// expanding it when a column was picked
+func (m *Model) revealInSidebar(item FinderItem) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	for i, table := range tab.schema.tables {
+		if table.Name == item.Table {
+			tab.sidebarSelected = i
 		}
+	}
+	if item.Column != "" {
+		m.expandSidebarTable(item.Table)
+	}
+	if !m.showSidebar {
+		m.showSidebar = true
+		m.resizeTextareas()
+	}
+	m.focus = focusSidebar
+	tab.textarea.Blur()
+}
 
-	case tea.MouseMsg:
-		// Handle mouse clicks on tabs
-		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
-			// Check if click is on the tab bar (row 2, after title)
-			// Title is row 0-1, tab bar is row 2
-			if msg.Y == 2 && len(m.tabs) > 1 {
-				clickedTab := m.getTabAtPosition(msg.X)
-				if clickedTab >= 0 && clickedTab != m.activeTab && clickedTab < len(m.tabs) {
-					m.saveToFile() // Save current tab before switching
-					m.activeTab = clickedTab
-					m.reloadFileFromDisk() // Reload the new tab's file
-					m.statusMessage = fmt.Sprintf("Tab %d: %s", m.activeTab+1, m.tabDisplayName(m.activeTab))
-				}
-				return m, nil
+// tabLabel returns the label shown for a tab in the tab bar: its display
+// name, the SQL file when it isn't the connection's default file, and a
+// marker when the file has unsaved changes
+func (m Model) tabLabel(idx int) string {
+	label := m.tabDisplayName(idx)
+	if len(label) > 15 {
+		label = label[:12] + "..."
+	}
+	tab := m.tabs[idx]
+	if tab.sqlFile != "" {
+		file := strings.TrimSuffix(filepath.Base(tab.sqlFile), filepath.Ext(tab.sqlFile))
+		if file != extractDatabaseName(tab.dsn, tab.dbType) && file != tab.connectionName {
+			if len(file) > 15 {
+				file = file[:12] + "..."
 			}
+			label += " · " + file
 		}
 	}
-
-	// Update textarea if focused
-	if m.focus == focusQuery && tab != nil {
-		var cmd tea.Cmd
-		tab.textarea, cmd = tab.textarea.Update(msg)
-		cmds = append(cmds, cmd)
+	if tab.textarea.Value() != tab.lastSavedContent {
+		label += " *"
 	}
-
-	return m, tea.Batch(cmds...)
+	return label
 }
 
 // tabDisplayName returns a display name for a tab
@@ -484,15 +2565,9 @@ func (m Model) getTabAtPosition(x int) int {
 
 	currentX := 0
 	for i := range m.tabs {
-		// Calculate tab label (same logic as renderTabBar)
-		label := m.tabDisplayName(i)
-		if len(label) > 15 {
-			label = label[:12] + "..."
-		}
-
 		// Tab label format: "N: label" with padding (0, 1) = 2 extra chars
-		tabLabel := fmt.Sprintf("%d: %s", i+1, label)
-		tabWidth := len(tabLabel) + 2 // +2 for padding
+		tabLabel := fmt.Sprintf("%d: %s", i+1, m.tabLabel(i))
+		tabWidth := uniseg.StringWidth(tabLabel) + 2 // +2 for padding
 
 		if x >= currentX && x < currentX+tabWidth {
 			return i
@@ -504,20 +2579,38 @@ func (m Model) getTabAtPosition(x int) int {
 	return -1
 }
 
+// quit quits, first asking whether to commit or roll back each open
+// transaction, and then whether to save unsaved changes
+func (m *Model) quit() tea.Cmd {
+	for i, t := range m.tabs {
+		if t.tx != nil {
+			m.activeTab = i
+			m.endTransactionFirst(t, "quitting", (*Model).quit)
+			return nil
+		}
+	}
+	if m.hasUnsavedChangesAnyTab() {
+		m.confirmingQuit = true
+		m.statusMessage = "You have unsaved changes. Save before quitting? (y/n, Esc to cancel)"
+		return nil
+	}
+	return tea.Quit
+}
+
 // closeCurrentTab closes the active tab
-func (m *Model) closeCurrentTab() {
+func (m *Model) closeCurrentTab() tea.Cmd {
 	if len(m.tabs) <= 1 {
-		return
+		return nil
 	}
 
+	var release tea.Cmd
 	tab := m.activeTabPtr()
 	if tab != nil {
 		// Save before closing
 		m.saveToFile()
-		// Close the database connection
-		if tab.db != nil {
-			_ = tab.db.Close()
-		}
+		// Stop its query and close the database connection, unless
+		// another tab uses it
+		release = m.releaseDB(tab)
 	}
 
 	// Remove the tab
@@ -529,16 +2622,51 @@ func (m *Model) closeCurrentTab() {
 	}
 
 	m.statusMessage = fmt.Sprintf("Tab closed. %d tab(s) open.", len(m.tabs))
+	return release
+}
+
+// releaseDB closes a tab's database connection unless another tab shares it
+// (tabs opened on another file of the same connection). A transaction still
+// open is rolled back; the actions the user starts ask about it first, with
+// endTransactionFirst. A query still running is cancelled, and the command
+// returned kills it on the server and only then releases the transaction
+// and connection, which it holds.
+func (m *Model) releaseDB(tab *Tab) tea.Cmd {
+	tab.closeStreams()
+	tx, db := tab.tx, tab.db
+	tab.tx = nil
+	if slices.ContainsFunc(m.tabs, func(other *Tab) bool { return other != tab && other.db == db }) {
+		db = nil
+	}
+	release := func() {
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+		if db != nil {
+			_ = db.Close()
+		}
+	}
+	if tab.cancelQuery == nil {
+		release()
+		return nil
+	}
+	tab.cancelQuery()
+	kill := tab.serverQuery.kill()
+	return func() tea.Msg {
+		var msg tea.Msg
+		if kill != nil {
+			msg = kill()
+		}
+		release()
+		return msg
+	}
 }
 
 // saveAllTabs saves all tabs' SQL files
 func (m *Model) saveAllTabs() {
 	for _, tab := range m.tabs {
 		if tab.sqlFile != "" {
-			content := tab.textarea.Value()
-			if err := os.WriteFile(tab.sqlFile, []byte(content), 0644); err == nil {
-				tab.lastSavedContent = content
-			}
+			tab.saveErr = writeTabFile(tab)
 		}
 	}
 }
@@ -606,10 +2734,402 @@ func (m *Model) openDetailView() {
 		focusedField:   0,
 		scrollOffset:   0,
 		visibleFields:  visibleFields,
+		references:     m.columnReferences(tab.result.Columns),
+		comments:       m.columnComments(tab.result.Columns),
 	}
 	m.focus = focusDetail
 }
 
+// resultTable returns the table the current results were selected from, or "" if unknown
+func (m Model) resultTable() string {
+	tab := m.tab()
+	if tab == nil {
+		return ""
+	}
+	if tab.queryMeta != nil && tab.queryMeta.TableName != "" {
+		return tab.queryMeta.TableName
+	}
+	return referencedTable(tab.lastQuery)
+}
+
+// columnComments returns the catalog comment of each result column of the result table, or ""
+func (m *Model) columnComments(columns []string) []string {
+	comments := make([]string, len(columns))
+	tab := m.activeTabPtr()
+	table := m.resultTable()
+	if tab == nil || table == "" {
+		return comments
+	}
+	if dot := strings.LastIndex(table, "."); dot >= 0 {
+		table = table[dot+1:]
+	}
+	info := tab.schema.Table(table)
+	if info == nil {
+		return comments
+	}
+	for i, col := range columns {
+		for _, schemaCol := range info.Columns {
+			if strings.EqualFold(schemaCol.Name, col) {
+				comments[i] = schemaCol.Comment
+			}
+		}
+	}
+	return comments
+}
+
+// columnReferences returns, for each result column, the "table.column" it
+// references through a foreign key of the result table, or ""
+func (m *Model) columnReferences(columns []string) []string {
+	refs := make([]string, len(columns))
+	tab := m.activeTabPtr()
+	table := m.resultTable()
+	if tab == nil || table == "" {
+		return refs
+	}
+	fks, err := tab.schema.ForeignKeys(table)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to load foreign keys for %s: %v", table, err)
+		return refs
+	}
+	for i, col := range columns {
+		if fk, pos := foreignKeyForColumn(fks, col); fk != nil {
+			refs[i] = fk.RefTable + "." + fk.RefColumns[pos]
+		}
+	}
+	return refs
+}
+
+// executeDetailSQL runs a statement generated from the detail view in the
+// background, then re-runs the last query so the results reflect the change
+func (m *Model) executeDetailSQL(stmt string) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "Wait for the running query to finish"
+		return nil
+	}
+	if err := readOnlyError(stmt); tab.readOnly && err != nil {
+		m.logMessage(stmt, err, -1, 0)
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+	// With confirm_writes, run the change in a transaction and ask before
+	// committing it
+	return m.launchDetailWrite(tab, stmt, !tab.dryRun && m.confirmsWrite(tab, stmt))
+}
+
+// detailWrite is a change made from the detail view, and how it went
+type detailWrite struct {
+	stmt    string
+	confirm bool         // run in a transaction begun for it, to ask before committing
+	begin   *QueryResult // the BEGIN's outcome, with confirm
+	result  *QueryResult // the statement's outcome; nil if BEGIN failed
+}
+
+// launchDetailWrite starts running a statement generated from the detail
+// view, followed by the tab's last query on the same connection so the
+// refreshed results show the change. With confirm, both run in a
+// transaction begun for them, left open for finishQuery to ask about.
+func (m *Model) launchDetailWrite(tab *Tab, stmt string, confirm bool) tea.Cmd {
+	timeout := tab.statementTimeout
+	ctx, cancel := statementContext(timeout)
+	tab.closeStreams()
+	refetch := m.refetchLimit(tab)
+	tab.running = stmt
+	tab.runningSince = time.Now()
+	tab.cancelQuery = cancel
+	db, tx, runner, dbType := tab.db, tab.tx, tab.runner(), tab.dbType
+	query, args := tab.lastQuery, tab.lastArgs
+	dryRunning := tab.dryRun
+	server := newServerQuery(db, dbType)
+	if dryRunning {
+		server = nil // runs in a transaction of its own
+	}
+	tab.serverQuery = server
+	write := func(msg *queryResultMsg) {
+		w := msg.write
+		if dryRunning {
+			w.result = dryRun(ctx, db, tx, dbType, stmt)
+			return
+		}
+		if confirm {
+			start := time.Now()
+			tx, err := beginTx(db)
+			w.begin = &QueryResult{Executed: true, RowsAffected: -1, Error: err, ExecTime: time.Since(start)}
+			if err != nil {
+				return
+			}
+			msg.tx, runner = tx, tx
+		}
+		pinned, release := server.pin(ctx, runner)
+		defer release()
+		if w.result = executeStatement(ctx, pinned, stmt); w.result.Error != nil {
+			if msg.tx != nil {
+				_ = msg.tx.Rollback()
+				msg.tx = nil
+			}
+			return
+		}
+		// Inside the transaction the refreshed results show the change
+		if query != "" {
+			msg.result = openQuery(ctx, pinned, query, refetch, args...)
+		}
+	}
+	run := func() tea.Msg {
+		start := time.Now()
+		msg := queryResultMsg{tab: tab, db: db, query: query, args: args, write: &detailWrite{stmt: stmt, confirm: confirm}}
+		write(&msg)
+		for _, result := range []*QueryResult{msg.write.result, msg.result} {
+			if result != nil && result.Error != nil {
+				result.Error = timeoutError(ctx, result.Error, timeout)
+			}
+		}
+		msg.duration = time.Since(start)
+		msg.cancelled = errors.Is(ctx.Err(), context.Canceled)
+		return msg
+	}
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// finishDetailWrite reports how a change made from the detail view went,
+// showing the refreshed results and, with confirm_writes, asking whether
+// to commit it
+func (m *Model) finishDetailWrite(tab *Tab, msg queryResultMsg, prefix string) tea.Cmd {
+	w := msg.write
+	if w.begin != nil {
+		m.logTabResult(tab, "BEGIN", w.begin, w.begin.ExecTime)
+		if w.begin.Error != nil {
+			m.statusMessage = fmt.Sprintf("%sError: %v", prefix, w.begin.Error)
+			return nil
+		}
+	}
+	if msg.cancelled && (w.result == nil || w.result.Error != nil || msg.tx != nil) {
+		if msg.tx != nil {
+			_ = msg.tx.Rollback() // its refresh was cancelled, so it's unconfirmed
+		}
+		m.logTabResult(tab, w.stmt, &QueryResult{Error: errors.New("cancelled")}, msg.duration)
+		m.statusMessage = prefix + "Query cancelled"
+		return nil
+	}
+	m.logTabResult(tab, w.stmt, w.result, msg.duration)
+	if w.result.Error != nil {
+		m.statusMessage = fmt.Sprintf("%sError: %v", prefix, w.result.Error)
+		return nil
+	}
+	if w.result.DryRun {
+		tab.detailView = nil
+		m.focus = focusResults
+		m.statusMessage = fmt.Sprintf("%s%s - nothing was changed", prefix, resultOutcome(w.result))
+		return nil
+	}
+
+	if msg.result != nil {
+		m.showReloaded(tab, msg.result)
+	}
+	tab.detailView = nil
+	m.focus = focusResults
+	verb := strings.ToUpper(strings.Fields(w.stmt)[0])
+	if msg.tx != nil {
+		tab.tx = msg.tx
+		tab.txSince = time.Now()
+		m.txPrompt = tab
+		m.txPromptNote = fmt.Sprintf("%s%s changed %d row(s)", prefix, verb, w.result.RowsAffected)
+		return nil
+	}
+	m.statusMessage = fmt.Sprintf("%s%s executed: %d row(s) affected", prefix, verb, w.result.RowsAffected)
+	if tab.result != nil && tab.result.Error != nil {
+		m.statusMessage += fmt.Sprintf(" (refresh failed: %v)", tab.result.Error)
+	}
+	return nil
+}
+
+// confirmsWrite reports whether stmt, executed from the detail view, should
+// wait in a transaction for confirmation. It doesn't when a transaction is
+// already open; committing that is up to the user. Nor does it on a
+// single-connection database (a CSV file), which can't hold a transaction
+// open (see beginTx); the statement is confirmed before it runs instead, as
+// without confirm_writes.
+func (m Model) confirmsWrite(tab *Tab, stmt string) bool {
+	if !m.confirmWrites || tab.tx != nil || tab.db.Stats().MaxOpenConnections == 1 {
+		return false
+	}
+	verb := strings.ToUpper(strings.Fields(stmt)[0])
+	return verb == "UPDATE" || verb == "DELETE"
+}
+
+// reloadResult re-runs the tab's last query in the background so the
+// results reflect a change, fetching as many rows as are shown now
+func (m *Model) reloadResult(tab *Tab) tea.Cmd {
+	if tab.lastQuery == "" || tab.running != "" {
+		return nil
+	}
+	refetch := m.refetchLimit(tab)
+	return m.launchRead(queryResultMsg{tab: tab, query: tab.lastQuery, args: tab.lastArgs, reload: true}, refetch)
+}
+
+// launchRead starts running a query that reads rows for msg's tab in the
+// background, in its transaction if it has one, without showing them as a
+// new result, as reloadResult and previewWrite do. msg says what to do with
+// them once the query's finished, when it's sent with its result filled in.
+func (m *Model) launchRead(msg queryResultMsg, limit int) tea.Cmd {
+	tab := msg.tab
+	timeout := tab.statementTimeout
+	ctx, cancel := statementContext(timeout)
+	tab.closeStreams()
+	tab.running = msg.query
+	tab.runningSince = time.Now()
+	tab.cancelQuery = cancel
+	msg.db = tab.db
+	runner := tab.runner()
+	server := newServerQuery(tab.db, tab.dbType)
+	tab.serverQuery = server
+	run := func() tea.Msg {
+		start := time.Now()
+		pinned, release := server.pin(ctx, runner)
+		msg.result = openQuery(ctx, pinned, msg.query, limit, msg.args...)
+		release()
+		if msg.result.Error != nil {
+			msg.result.Error = timeoutError(ctx, msg.result.Error, timeout)
+		}
+		msg.duration = time.Since(start)
+		msg.cancelled = errors.Is(ctx.Err(), context.Canceled)
+		return msg
+	}
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// refetchLimit returns how many rows re-running the tab's last query
+// fetches: as many as are shown now, if that's more than the usual limit
+func (m Model) refetchLimit(tab *Tab) int {
+	refetch := m.queryRowLimit(tab.lastQuery)
+	if refetch > 0 && tab.result != nil {
+		refetch = max(refetch, len(tab.result.Rows))
+	}
+	return refetch
+}
+
+// showReloaded shows the result of re-running the tab's last query in place
+// of the one shown, keeping the selection in range
+func (m *Model) showReloaded(tab *Tab, result *QueryResult) {
+	tab.result = result
+	tab.parseResultError()
+	tab.queryMeta = parseQueryMeta(tab.lastQuery, tab.result, tab.schema.PrimaryKey)
+	tab.diff = nil
+	tab.refreshResult()
+	if tab.result.Error == nil {
+		tab.totalPages = tab.pageCount(len(tab.result.Rows))
+		if tab.selectedRow >= len(tab.result.Rows) {
+			tab.selectedRow = max(len(tab.result.Rows)-1, 0)
+		}
+		tab.currentPage = tab.selectedRow / tab.rowsPerPage()
+	}
+}
+
+// logResult records an executed statement and its result in the session log
+func (m *Model) logResult(stmt string, result *QueryResult, duration time.Duration) {
+	m.logTabResult(m.activeTabPtr(), stmt, result, duration)
+}
+
+// logTabResult records a statement executed in tab and its result in the
+// session log
+func (m *Model) logTabResult(tab *Tab, stmt string, result *QueryResult, duration time.Duration) {
+	entry := MessageEntry{
+		time:      time.Now(),
+		tabName:   m.tabDisplayName(slices.Index(m.tabs, tab)),
+		statement: stmt,
+		outcome:   resultOutcome(result),
+		duration:  duration,
+		isError:   result.Error != nil,
+	}
+	m.messages = append(m.messages, entry)
+	m.messagesScroll = 0 // jump to the newest entry
+
+	// Keep a persistent per-connection history too, ignoring errors
+	// (we don't want a failed write to interrupt the session)
+	if tab != nil && tab.sqlDir != "" {
+		name := historyConnectionName(tab)
+		_ = appendHistory(historyPath(tab.sqlDir, name), newHistoryEntry(name, stmt, result, duration))
+	}
+}
+
+// logMessage records a statement executed via Exec in the session log
+func (m *Model) logMessage(stmt string, err error, affected int64, duration time.Duration) {
+	result := &QueryResult{Error: err, Executed: true, RowsAffected: affected}
+	m.logResult(stmt, result, duration)
+}
+
+// openDatabasePrompt opens the prompt for switching to another database on the same server
+func (m *Model) openDatabasePrompt() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.dsn == "" {
+		m.statusMessage = "No connection to switch database on"
+		return
+	}
+	if getDriverName(tab.dbType) == "sqlite3" {
+		m.statusMessage = "SQLite has no server databases - use Ctrl+P to open another connection"
+		if choices, err := listDatabaseChoices(tab.db, tab.dbType, ""); err == nil {
+			names := make([]string, len(choices))
+			for i, c := range choices {
+				names[i] = c.Name
+			}
+			m.statusMessage += " (attached: " + strings.Join(names, ", ") + ")"
+		}
+		return
+	}
+
+	ti := textinput.New()
+	ti.Placeholder = "filter, or type a database name"
+	ti.CharLimit = 128
+	ti.Width = 40
+	ti.Focus()
+	m.databaseInput = ti
+	m.databaseSelected = 0
+	m.statusMessage = ""
+
+	choices, err := listDatabaseChoices(tab.db, tab.dbType, dsnDatabaseName(tab.dsn, tab.dbType))
+	if err != nil {
+		m.statusMessage = "Could not list databases: " + err.Error()
+	}
+	m.databaseChoices = choices
+	for i, c := range m.filteredDatabaseChoices() {
+		if c.Current && !c.Schema {
+			m.databaseSelected = i
+		}
+	}
+	m.focus = focusDatabasePrompt
+}
+
+// filteredDatabaseChoices returns the databases and schemas matching the
+// prompt input: names equal to it first, then those starting with it, then
+// those containing it, so Enter picks the closest
+func (m Model) filteredDatabaseChoices() []DatabaseChoice {
+	filter := strings.ToLower(strings.TrimSpace(m.databaseInput.Value()))
+	rank := func(c DatabaseChoice) int {
+		name := strings.ToLower(c.Name)
+		switch {
+		case name == filter:
+			return 0
+		case strings.HasPrefix(name, filter):
+			return 1
+		case strings.Contains(name, filter):
+			return 2
+		}
+		return -1
+	}
+	var matches []DatabaseChoice
+	for _, c := range m.databaseChoices {
+		if rank(c) >= 0 {
+			matches = append(matches, c)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b DatabaseChoice) int {
+		return rank(a) - rank(b)
+	})
+	return matches
+}
+
 // openConnectionPicker opens the connection picker/manager dialog
 func (m *Model) openConnectionPicker() {
 	if m.vaultManager == nil {
@@ -652,6 +3172,9 @@ func (m *Model) switchConnection(name string) error {
 	if tab == nil {
 		return fmt.Errorf("no active tab")
 	}
+	if tab.running != "" {
+		return errors.New("wait for the running query to finish")
+	}
 
 	if m.vaultManager == nil {
 		return fmt.Errorf("no vault manager")
@@ -662,45 +3185,123 @@ func (m *Model) switchConnection(name string) error {
 		return err
 	}
 
-	// Auto-detect type if not specified
-	if dbType == "" {
-		dbType = detectDBType(dsn)
-	}
+	// Auto-detect type if not specified, falling back to default_type
+	dbType, _ = resolveDBType(connectionInfo{dsn: dsn, dbType: dbType}, false, m.vaultManager.DefaultType())
 
 	driverName := getDriverName(dbType)
 	if driverName == "" {
 		return fmt.Errorf("unknown database type for %q", name)
 	}
 
-	// Close old connection
-	if tab.db != nil {
-		_ = tab.db.Close()
-	}
+	// Close old connection; nothing's running on it to wait for
+	m.releaseDB(tab)
 
 	// Open new connection
-	db, err := sql.Open(driverName, dsn)
+	openDSN := withReadOnly(withStatementTimeout(dsn, dbType, m.vaultManager.StatementTimeout(name)), dbType, m.vaultManager.ReadOnly(name))
+	db, err := openDB(driverName, withSessionSettings(openDSN, dbType, m.vaultManager.GetSessionSettings(name)))
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-
-	if err := db.Ping(); err != nil {
-		_ = db.Close()
-		return fmt.Errorf("failed to ping: %w", err)
+		return err
 	}
 
 	tab.db = db
 	tab.dbType = dbType
+	tab.dsn = dsn
 	tab.connectionName = name
 	tab.theme = GetTheme(themeName)
-	tab.highlighter = NewSQLHighlighter(tab.theme)
+	tab.highlighter = NewSQLHighlighterForDB(tab.theme, tab.dbType)
+	tab.schema = NewSchemaCache(db, dbType)
+	loadConnectionSettings(tab, m.vaultManager)
+
+	// Clear previous results
+	tab.clearResults()
+
+	return nil
+}
+
+// switchDatabase reconnects the current tab to a different database on the same server
+func (m *Model) switchDatabase(database string) error {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return fmt.Errorf("no active tab")
+	}
+
+	dsn, err := replaceDatabaseInDSN(tab.dsn, tab.dbType, database)
+	if err != nil {
+		return err
+	}
+	return m.reconnectTab(dsn)
+}
+
+// switchSchema makes a PostgreSQL schema the first on the search path by
+// reconnecting with a search_path parameter in the DSN
+func (m *Model) switchSchema(schema string) error {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return fmt.Errorf("no active tab")
+	}
+
+	dsn, err := replaceSearchPathInDSN(tab.dsn, schema)
+	if err != nil {
+		return err
+	}
+	// The schema chosen wins over a saved search_path, for this tab
+	delete(tab.session, "search_path")
+	return m.reconnectTab(dsn)
+}
+
+// reconnectTab replaces the active tab's connection with one opened from
+// dsn, clearing its results
+func (m *Model) reconnectTab(dsn string) error {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return fmt.Errorf("no active tab")
+	}
+	if err := m.reopenTab(tab, dsn); err != nil {
+		return err
+	}
 
 	// Clear previous results
-	tab.result = nil
-	tab.queryMeta = nil
+	tab.clearResults()
+
+	return nil
+}
 
+// reopenTab replaces a tab's connection with one opened from dsn
+func (m *Model) reopenTab(tab *Tab, dsn string) error {
+	if tab.running != "" {
+		return errors.New("wait for the running query to finish")
+	}
+	openDSN := withReadOnly(withStatementTimeout(dsn, tab.dbType, tab.statementTimeout), tab.dbType, tab.readOnly)
+	db, err := openDB(getDriverName(tab.dbType), withSessionSettings(openDSN, tab.dbType, tab.session))
+	if err != nil {
+		return err
+	}
+
+	// Only close the old connection once the new one is known to work;
+	// nothing's running on it to wait for
+	m.releaseDB(tab)
+
+	tab.db = db
+	tab.dsn = dsn
+	tab.schema = NewSchemaCache(db, tab.dbType)
 	return nil
 }
 
+// openDB opens a database connection and verifies it with a ping
+func openDB(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping: %w", err)
+	}
+
+	return db, nil
+}
+
 // createNewTab creates a new tab with the given connection
 func (m *Model) createNewTab(name string) error {
 	if m.vaultManager == nil {
@@ -712,10 +3313,8 @@ func (m *Model) createNewTab(name string) error {
 		return err
 	}
 
-	// Auto-detect type if not specified
-	if dbType == "" {
-		dbType = detectDBType(dsn)
-	}
+	// Auto-detect type if not specified, falling back to default_type
+	dbType, _ = resolveDBType(connectionInfo{dsn: dsn, dbType: dbType}, false, m.vaultManager.DefaultType())
 
 	driverName := getDriverName(dbType)
 	if driverName == "" {
@@ -723,14 +3322,10 @@ func (m *Model) createNewTab(name string) error {
 	}
 
 	// Open new connection
-	db, err := sql.Open(driverName, dsn)
+	openDSN := withReadOnly(withStatementTimeout(dsn, dbType, m.vaultManager.StatementTimeout(name)), dbType, m.vaultManager.ReadOnly(name))
+	db, err := openDB(driverName, withSessionSettings(openDSN, dbType, m.vaultManager.GetSessionSettings(name)))
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-
-	if err := db.Ping(); err != nil {
-		_ = db.Close()
-		return fmt.Errorf("failed to ping: %w", err)
+		return err
 	}
 
 	// Determine SQL file path for this connection
@@ -747,7 +3342,8 @@ func (m *Model) createNewTab(name string) error {
 	}
 
 	theme := GetTheme(themeName)
-	tab := NewTab(db, dbType, m.sqlDir, sqlFile, initialSQL, name, theme)
+	tab := NewTab(db, dbType, dsn, m.sqlDir, sqlFile, initialSQL, name, theme)
+	loadConnectionSettings(tab, m.vaultManager)
 
 	// Size the textarea to match current tabs
 	if len(m.tabs) > 0 && m.tabs[0].textarea.Height() > 0 {
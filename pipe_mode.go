@@ -2,11 +2,13 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 // isPiped returns true if stdin is connected to a pipe rather than a terminal
@@ -19,8 +21,18 @@ func isPiped() bool {
 	return (stat.Mode() & os.ModeCharDevice) == 0
 }
 
+// pipeOptions controls how pipe mode executes statements and prints results
+type pipeOptions struct {
+	format   string        // table, csv or tsv
+	echo     bool          // print each statement before its result
+	dbType   string        // used for database-specific statement splitting
+	timeout  time.Duration // how long each statement may run (0 = no limit)
+	dryRun   bool          // roll back statements that write
+	readOnly bool          // refuse statements that write
+}
+
 // runPipeMode reads queries from stdin, executes them, and outputs results to stdout
-func runPipeMode(db *sql.DB, format string) {
+func runPipeMode(db *sql.DB, opts pipeOptions) {
 	// Read all of stdin
 	input, err := io.ReadAll(bufio.NewReader(os.Stdin))
 	if err != nil {
@@ -34,8 +46,17 @@ func runPipeMode(db *sql.DB, format string) {
 		os.Exit(1)
 	}
 
+	runStatements(db, inputStr, opts)
+}
+
+// runStatements splits input into statements, executes them in order, and
+// writes results to stdout. Exits non-zero if any statement fails.
+func runStatements(db *sql.DB, inputStr string, opts pipeOptions) {
+	format := opts.format
+	echo := opts.echo
+
 	// Split into individual statements
-	statements := SplitStatements(inputStr)
+	statements := SplitStatementsForDB(inputStr, opts.dbType)
 	if len(statements) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: No valid statements found")
 		os.Exit(1)
@@ -44,19 +65,75 @@ func runPipeMode(db *sql.DB, format string) {
 	// Track if we've output anything (for separating multiple results)
 	firstOutput := true
 	hasError := false
+	expanded := false // toggled by \x
 
 	for i, stmt := range statements {
-		if IsSelectStatement(stmt) {
+		// Echo the statement; it doubles as the separator between result blocks
+		if echo {
+			if !firstOutput {
+				fmt.Println()
+			}
+			firstOutput = false
+			outputEcho(stmt)
+		}
+
+		// psql-style backslash commands run as catalog queries
+		if isMetaCommand(stmt) {
+			name, arg := parseMetaCommand(stmt)
+			if name == `\x` {
+				setting, err := expandedSetting(arg, expanded)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
+					hasError = true
+					continue
+				}
+				expanded = setting
+				fmt.Fprintln(os.Stderr, expandedStatus(expanded))
+				continue
+			}
+			query, err := metaCommandQuery(name, arg, opts.dbType)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
+				hasError = true
+				continue
+			}
+			stmt = query
+		}
+
+		if opts.readOnly {
+			if err := readOnlyError(stmt); err != nil {
+				fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
+				hasError = true
+				continue
+			}
+		}
+
+		ctx, cancel := statementContext(opts.timeout)
+		start := time.Now()
+		if opts.dryRun && writesData(stmt) {
+			// Run it in a transaction that's rolled back, reporting what it did
+			result := dryRun(ctx, db, nil, opts.dbType, stmt)
+			cancel()
+			if result.Error != nil {
+				err := timeoutError(ctx, result.Error, opts.timeout)
+				fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
+				hasError = true
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Statement %d: %s in %s\n", i+1, resultOutcome(result), formatDuration(time.Since(start)))
+		} else if ReturnsRows(stmt) {
 			// Execute as query (returns rows)
-			columns, rows, err := executeSelectStatement(db, stmt)
+			columns, rows, err := executeSelectStatement(ctx, db, stmt)
+			cancel()
 			if err != nil {
+				err = timeoutError(ctx, err, opts.timeout)
 				fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
 				hasError = true
 				continue
 			}
 
 			// Add separator between multiple result sets
-			if !firstOutput {
+			if !echo && !firstOutput {
 				fmt.Println()
 				if format == "table" {
 					fmt.Println("---")
@@ -72,22 +149,30 @@ func runPipeMode(db *sql.DB, format string) {
 			case "tsv":
 				outputCSV(columns, rows, "\t")
 			default:
-				outputTable(columns, rows)
+				if expanded {
+					outputExpanded(columns, rows)
+				} else {
+					outputTable(columns, rows)
+				}
 			}
+			fmt.Fprintf(os.Stderr, "Statement %d: %d row(s) in %s\n", i+1, len(rows), formatDuration(time.Since(start)))
 		} else {
 			// Execute as statement (INSERT/UPDATE/DELETE/DDL)
-			affected, err := executeNonSelectStatement(db, stmt)
+			affected, err := executeNonSelectStatement(ctx, db, stmt)
+			cancel()
 			if err != nil {
+				err = timeoutError(ctx, err, opts.timeout)
 				fmt.Fprintf(os.Stderr, "Statement %d error: %v\n", i+1, err)
 				hasError = true
 				continue
 			}
 
 			// Report affected rows to stderr (doesn't interfere with data output)
+			elapsed := formatDuration(time.Since(start))
 			if affected >= 0 {
-				fmt.Fprintf(os.Stderr, "Statement %d: %d row(s) affected\n", i+1, affected)
+				fmt.Fprintf(os.Stderr, "Statement %d: %d row(s) affected in %s\n", i+1, affected, elapsed)
 			} else {
-				fmt.Fprintf(os.Stderr, "Statement %d: OK\n", i+1)
+				fmt.Fprintf(os.Stderr, "Statement %d: OK in %s\n", i+1, elapsed)
 			}
 		}
 	}
@@ -97,9 +182,16 @@ func runPipeMode(db *sql.DB, format string) {
 	}
 }
 
+// outputEcho prints a statement with each line prefixed by "> "
+func outputEcho(stmt string) {
+	for _, line := range strings.Split(stmt, "\n") {
+		fmt.Println("> " + line)
+	}
+}
+
 // executeSelectStatement executes a SELECT query and returns columns and rows
-func executeSelectStatement(db *sql.DB, stmt string) ([]string, [][]string, error) {
-	rows, err := db.Query(stmt)
+func executeSelectStatement(ctx context.Context, db *sql.DB, stmt string) ([]string, [][]string, error) {
+	rows, err := db.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -149,8 +241,8 @@ func executeSelectStatement(db *sql.DB, stmt string) ([]string, [][]string, erro
 
 // executeNonSelectStatement executes an INSERT/UPDATE/DELETE/DDL statement
 // Returns the number of affected rows, or -1 if not applicable
-func executeNonSelectStatement(db *sql.DB, stmt string) (int64, error) {
-	result, err := db.Exec(stmt)
+func executeNonSelectStatement(ctx context.Context, db sqlRunner, stmt string, args ...any) (int64, error) {
+	result, err := db.ExecContext(ctx, stmt, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -217,6 +309,25 @@ func outputTable(columns []string, rows [][]string) {
 	fmt.Fprintf(os.Stderr, "\n(%d rows)\n", len(rows))
 }
 
+// outputExpanded outputs each row as a block of "column | value" lines,
+// like psql's expanded display
+func outputExpanded(columns []string, rows [][]string) {
+	width := 0
+	for _, col := range columns {
+		width = max(width, len(col))
+	}
+
+	for r, row := range rows {
+		fmt.Printf("-[ RECORD %d ]-\n", r+1)
+		for i, cell := range row {
+			fmt.Println(padAndTruncate(columns[i], width) + " | " + cell)
+		}
+	}
+
+	// Print row count to stderr (so it doesn't interfere with piping)
+	fmt.Fprintf(os.Stderr, "\n(%d rows)\n", len(rows))
+}
+
 // outputCSV outputs results in CSV or TSV format
 func outputCSV(columns []string, rows [][]string, delimiter string) {
 	// Print header
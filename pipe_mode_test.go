@@ -129,6 +129,27 @@ func TestOutputTSV(t *testing.T) {
 	}
 }
 
+// TestOutputEcho tests statement echo formatting
+func TestOutputEcho(t *testing.T) {
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	outputEcho("SELECT id\nFROM users")
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	expected := "> SELECT id\n> FROM users\n"
+	if buf.String() != expected {
+		t.Errorf("outputEcho() = %q, want %q", buf.String(), expected)
+	}
+}
+
 // TestPadAndTruncate tests string padding and truncation
 func TestPadAndTruncate(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// planCostPattern matches a node's estimate: "cost=1.09..2.21 rows=4" on
+	// PostgreSQL, "cost=0.90 rows=1" on MySQL
+	planCostPattern = regexp.MustCompile(`cost=(\d+(?:\.\d+)?)(?:\.\.(\d+(?:\.\d+)?))?\s+rows=([\d.]+)`)
+
+	// planActualPattern matches what EXPLAIN ANALYZE measured for a node
+	planActualPattern = regexp.MustCompile(`actual time=(\d+(?:\.\d+)?)\.\.(\d+(?:\.\d+)?) rows=([\d.]+) loops=(\d+)`)
+)
+
+// PlanNode is one step of a query plan
+type PlanNode struct {
+	Label    string   // e.g. "Seq Scan on orders o"
+	Details  []string // lines under the node, e.g. "Filter: (total > 100)"
+	Children []*PlanNode
+
+	Cost          float64 // estimated total cost
+	EstimatedRows float64
+
+	// Measured by EXPLAIN ANALYZE; the time and rows are per loop
+	Analyzed   bool
+	ActualTime float64 // milliseconds
+	ActualRows float64
+	Loops      int
+}
+
+// TotalTime is how long the node and its children ran, over all loops, in
+// milliseconds
+func (n *PlanNode) TotalTime() float64 {
+	return n.ActualTime * float64(max(n.Loops, 1))
+}
+
+// SelfTime is how long the node ran excluding its children, in milliseconds
+func (n *PlanNode) SelfTime() float64 {
+	t := n.TotalTime()
+	for _, c := range n.Children {
+		t -= c.TotalTime()
+	}
+	return max(t, 0)
+}
+
+// QueryPlan is the output of EXPLAIN ANALYZE parsed into a tree
+type QueryPlan struct {
+	Roots   []*PlanNode
+	Summary []string // lines after the tree, e.g. "Execution Time: 0.067 ms"
+}
+
+// TotalTime is how long the whole plan ran, in milliseconds
+func (p *QueryPlan) TotalTime() float64 {
+	var t float64
+	for _, n := range p.Roots {
+		t += n.TotalTime()
+	}
+	return t
+}
+
+// parsePlan parses the text of an EXPLAIN ANALYZE in the indented "->"
+// format PostgreSQL and MySQL share. PostgreSQL's first node has no arrow,
+// and its details and summary lines have none either; MySQL puts the whole
+// tree in one cell. ok is false unless the plan has measured timings.
+func parsePlan(text string) (plan *QueryPlan, ok bool) {
+	type open struct {
+		node   *PlanNode
+		indent int
+	}
+	plan = &QueryPlan{}
+	var stack []open
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		isNode := strings.HasPrefix(trimmed, "->") || (len(stack) == 0 && len(plan.Roots) == 0)
+		if !isNode {
+			if len(stack) == 0 || indent == 0 {
+				plan.Summary = append(plan.Summary, trimmed)
+				stack = nil
+			} else {
+				last := stack[len(stack)-1].node
+				last.Details = append(last.Details, trimmed)
+			}
+			continue
+		}
+
+		node := parsePlanNode(strings.TrimSpace(strings.TrimPrefix(trimmed, "->")))
+		ok = ok || node.Analyzed
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			plan.Roots = append(plan.Roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, open{node, indent})
+	}
+	return plan, ok
+}
+
+// resultPlan parses a query result holding EXPLAIN ANALYZE output: one
+// column with a line of the plan per row on PostgreSQL, or the whole plan in
+// one cell on MySQL
+func resultPlan(result *QueryResult) (*QueryPlan, bool) {
+	if len(result.Columns) != 1 || len(result.Rows) == 0 {
+		return nil, false
+	}
+	lines := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		lines[i] = row[0].Value
+	}
+	return parsePlan(strings.Join(lines, "\n"))
+}
+
+// parsePlanNode parses a node's line, without its arrow
+func parsePlanNode(line string) *PlanNode {
+	node := &PlanNode{Label: line}
+	// The estimates follow the label after two spaces; a single space can
+	// be part of the label, as in "Index lookup on o using k (id=u.id)"
+	if i := strings.Index(line, "  ("); i >= 0 {
+		node.Label = line[:i]
+	} else if i := strings.Index(line, " (cost="); i >= 0 {
+		node.Label = line[:i]
+	}
+	if m := planCostPattern.FindStringSubmatch(line); m != nil {
+		cost := m[1]
+		if m[2] != "" {
+			cost = m[2]
+		}
+		node.Cost, _ = strconv.ParseFloat(cost, 64)
+		node.EstimatedRows, _ = strconv.ParseFloat(m[3], 64)
+	}
+	if m := planActualPattern.FindStringSubmatch(line); m != nil {
+		node.Analyzed = true
+		node.ActualTime, _ = strconv.ParseFloat(m[2], 64)
+		node.ActualRows, _ = strconv.ParseFloat(m[3], 64)
+		node.Loops, _ = strconv.Atoi(m[4])
+	} else if strings.Contains(line, "never executed") {
+		node.Analyzed = true
+	}
+	return node
+}
+
+// PlanView holds the state of the EXPLAIN ANALYZE tree viewer
+type PlanView struct {
+	plan        *QueryPlan
+	collapsed   map[*PlanNode]bool
+	selected    int        // index into rows()
+	returnFocus focusState // focus to restore when the viewer closes
+}
+
+// planRow is a node shown in the plan viewer, at its depth in the tree
+type planRow struct {
+	node  *PlanNode
+	depth int
+}
+
+// newPlanView creates a viewer over plan with every node expanded
+func newPlanView(plan *QueryPlan, returnFocus focusState) *PlanView {
+	return &PlanView{plan: plan, collapsed: make(map[*PlanNode]bool), returnFocus: returnFocus}
+}
+
+// rows returns the nodes to show, skipping the children of collapsed nodes
+func (v *PlanView) rows() []planRow {
+	var rows []planRow
+	var walk func(n *PlanNode, depth int)
+	walk = func(n *PlanNode, depth int) {
+		rows = append(rows, planRow{n, depth})
+		if v.collapsed[n] {
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	for _, n := range v.plan.Roots {
+		walk(n, 0)
+	}
+	return rows
+}
+
+// toggle collapses or expands the selected node
+func (v *PlanView) toggle() {
+	rows := v.rows()
+	if v.selected < len(rows) && len(rows[v.selected].node.Children) > 0 {
+		n := rows[v.selected].node
+		v.collapsed[n] = !v.collapsed[n]
+	}
+}
+
+// collapseOrParent collapses the selected node, or if it's already collapsed
+// (or a leaf) selects its parent
+func (v *PlanView) collapseOrParent() {
+	rows := v.rows()
+	if v.selected >= len(rows) {
+		return
+	}
+	row := rows[v.selected]
+	if len(row.node.Children) > 0 && !v.collapsed[row.node] {
+		v.collapsed[row.node] = true
+		return
+	}
+	for i := v.selected - 1; i >= 0; i-- {
+		if rows[i].depth < row.depth {
+			v.selected = i
+			return
+		}
+	}
+}
+
+// formatPlanTime formats milliseconds from a plan, keeping the precision
+// that matters for sub-millisecond nodes
+func formatPlanTime(ms float64) string {
+	switch {
+	case ms < 100:
+		return strconv.FormatFloat(ms, 'f', 2, 64) + "ms"
+	case ms < 1000:
+		return strconv.FormatFloat(ms, 'f', 0, 64) + "ms"
+	default:
+		return strconv.FormatFloat(ms/1000, 'f', 1, 64) + "s"
+	}
+}
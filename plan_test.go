@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const postgresPlan = `Hash Join  (cost=1.09..2.21 rows=4 width=72) (actual time=0.030..0.534 rows=4 loops=1)
+  Hash Cond: (o.user_id = u.id)
+  ->  Seq Scan on orders o  (cost=0.00..1.04 rows=4 width=40) (actual time=0.005..0.006 rows=4 loops=1)
+        Filter: (total > 100)
+  ->  Hash  (cost=1.04..1.04 rows=4 width=36) (actual time=0.013..0.400 rows=4 loops=1)
+        Buckets: 1024  Batches: 1  Memory Usage: 9kB
+        ->  Seq Scan on users u  (cost=0.00..1.04 rows=4 width=36) (actual time=0.004..0.100 rows=2 loops=2)
+Planning Time: 0.123 ms
+Execution Time: 0.567 ms`
+
+const mysqlPlan = `-> Nested loop inner join  (cost=0.90 rows=1) (actual time=0.040..0.046 rows=2 loops=1)
+    -> Table scan on u  (cost=0.35 rows=1) (actual time=0.020..0.024 rows=2 loops=1)
+    -> Index lookup on o using user_id (user_id=u.id)  (cost=0.55 rows=1) (actual time=0.008..0.010 rows=1 loops=2)
+`
+
+// TestParsePlanPostgres tests parsing PostgreSQL's EXPLAIN ANALYZE text
+func TestParsePlanPostgres(t *testing.T) {
+	// One row per line, as PostgreSQL returns it
+	result := &QueryResult{Columns: []string{"QUERY PLAN"}}
+	for _, line := range strings.Split(postgresPlan, "\n") {
+		result.Rows = append(result.Rows, []CellValue{{Value: line}})
+	}
+	plan, ok := resultPlan(result)
+	if !ok {
+		t.Fatal("resultPlan() didn't recognize EXPLAIN ANALYZE output")
+	}
+	if len(plan.Roots) != 1 || len(plan.Summary) != 2 {
+		t.Fatalf("got %d roots and %d summary lines, want 1 and 2", len(plan.Roots), len(plan.Summary))
+	}
+
+	join := plan.Roots[0]
+	if join.Label != "Hash Join" || join.Cost != 2.21 || join.Details[0] != "Hash Cond: (o.user_id = u.id)" {
+		t.Errorf("root = %q cost %v details %v", join.Label, join.Cost, join.Details)
+	}
+	if len(join.Children) != 2 {
+		t.Fatalf("root has %d children, want 2", len(join.Children))
+	}
+	scan, hash := join.Children[0], join.Children[1]
+	if scan.Label != "Seq Scan on orders o" || len(scan.Details) != 1 || len(scan.Children) != 0 {
+		t.Errorf("first child = %q details %v children %d", scan.Label, scan.Details, len(scan.Children))
+	}
+	if len(hash.Children) != 1 || hash.Children[0].Label != "Seq Scan on users u" {
+		t.Fatalf("Hash node children = %v", hash.Children)
+	}
+
+	// users is scanned twice at 0.1ms a loop, inside a 0.4ms Hash
+	users := hash.Children[0]
+	if users.TotalTime() != 0.2 || users.ActualRows != 2 || users.Loops != 2 {
+		t.Errorf("users scan total %v rows %v loops %d", users.TotalTime(), users.ActualRows, users.Loops)
+	}
+	if got := hash.SelfTime(); got < 0.199 || got > 0.201 {
+		t.Errorf("Hash self time = %v, want 0.2", got)
+	}
+}
+
+// TestParsePlanMySQL tests parsing MySQL's EXPLAIN ANALYZE tree, which comes
+// back in a single cell
+func TestParsePlanMySQL(t *testing.T) {
+	result := &QueryResult{Columns: []string{"EXPLAIN"}, Rows: [][]CellValue{{{Value: mysqlPlan}}}}
+	plan, ok := resultPlan(result)
+	if !ok {
+		t.Fatal("resultPlan() didn't recognize EXPLAIN ANALYZE output")
+	}
+	if len(plan.Roots) != 1 || len(plan.Roots[0].Children) != 2 {
+		t.Fatalf("got %d roots, want 1 with 2 children", len(plan.Roots))
+	}
+	lookup := plan.Roots[0].Children[1]
+	if lookup.Label != "Index lookup on o using user_id (user_id=u.id)" || lookup.Cost != 0.55 || lookup.Loops != 2 {
+		t.Errorf("lookup = %q cost %v loops %d", lookup.Label, lookup.Cost, lookup.Loops)
+	}
+}
+
+// TestParsePlanNotAnalyzed tests that plans without timings and other
+// results aren't shown as a tree
+func TestParsePlanNotAnalyzed(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *QueryResult
+	}{
+		{"plain explain", &QueryResult{Columns: []string{"QUERY PLAN"}, Rows: [][]CellValue{{{Value: "Seq Scan on users  (cost=0.00..1.04 rows=4 width=36)"}}}}},
+		{"ordinary rows", &QueryResult{Columns: []string{"name"}, Rows: [][]CellValue{{{Value: "Alice"}}, {{Value: "Bob"}}}}},
+		{"several columns", &QueryResult{Columns: []string{"id", "detail"}, Rows: [][]CellValue{{{Value: "2"}, {Value: "SCAN users"}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := resultPlan(tt.result); ok {
+				t.Errorf("resultPlan() recognized a plan in %v", tt.result.Rows)
+			}
+		})
+	}
+}
+
+// TestPlanViewCollapse tests collapsing and expanding nodes in the tree viewer
+func TestPlanViewCollapse(t *testing.T) {
+	plan, _ := parsePlan(postgresPlan)
+	v := newPlanView(plan, focusResults)
+	if n := len(v.rows()); n != 4 {
+		t.Fatalf("expanded tree shows %d rows, want 4", n)
+	}
+
+	v.selected = 2 // Hash
+	v.toggle()
+	if n := len(v.rows()); n != 3 {
+		t.Errorf("after collapsing Hash the tree shows %d rows, want 3", n)
+	}
+	v.collapseOrParent() // already collapsed, so selects the parent
+	if v.selected != 0 {
+		t.Errorf("collapseOrParent() on a collapsed node selected %d, want its parent", v.selected)
+	}
+	v.collapseOrParent()
+	if n := len(v.rows()); n != 1 {
+		t.Errorf("after collapsing the root the tree shows %d rows, want 1", n)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewRowLimit is the most rows the preview of an UPDATE or DELETE shows
+const previewRowLimit = 20
+
+// clauseWord is a top-level keyword of a statement and where it starts
+type clauseWord struct {
+	word   string // uppercased
+	offset int
+}
+
+// clauseWords returns the top-level words of stmt, outside parentheses,
+// strings and comments, with their byte offsets
+func clauseWords(stmt string) []clauseWord {
+	var words []clauseWord
+	pos, depth := 0, 0
+	for _, tok := range tokenizeForFormat(stmt) {
+		offset := pos + strings.Index(stmt[pos:], tok.text)
+		pos = offset + len(tok.text)
+		switch {
+		case tok.kind == fmtPunct && tok.text == "(":
+			depth++
+		case tok.kind == fmtPunct && tok.text == ")":
+			depth--
+		case tok.kind == fmtWord && depth == 0:
+			words = append(words, clauseWord{strings.ToUpper(tok.text), offset})
+		case tok.kind == fmtPunct && tok.text == "," && depth == 0:
+			words = append(words, clauseWord{",", offset})
+		}
+	}
+	return words
+}
+
+// writePreviewSQL rewrites an UPDATE or DELETE as the SELECT of the rows it
+// would change, keeping its tables, joins, WHERE clause and any MySQL
+// ORDER BY/LIMIT. ok is false for other statements.
+func writePreviewSQL(stmt string) (query string, ok bool) {
+	stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	words := clauseWords(stmt)
+	if len(words) == 0 || (words[0].word != "UPDATE" && words[0].word != "DELETE") {
+		return "", false
+	}
+
+	// find returns the offset of the first of the keywords from word index
+	// from on, or the end of the statement
+	find := func(from int, keywords ...string) int {
+		for _, w := range words[from:] {
+			if slices.Contains(keywords, w.word) {
+				return w.offset
+			}
+		}
+		return len(stmt)
+	}
+	index := func(keyword string) int {
+		return slices.IndexFunc(words, func(w clauseWord) bool { return w.word == keyword })
+	}
+	tail := func(from int) string {
+		// WHERE, ORDER BY and LIMIT carry over; RETURNING doesn't
+		start := find(from, "WHERE", "ORDER", "LIMIT")
+		return strings.TrimSpace(stmt[start:find(from, "RETURNING")])
+	}
+
+	// Skip MySQL's modifiers after the verb
+	first := 1
+	for first < len(words) && slices.Contains([]string{"LOW_PRIORITY", "QUICK", "IGNORE"}, words[first].word) {
+		first++
+	}
+	if first >= len(words) {
+		return "", false
+	}
+
+	var columns, tables string
+	rest := 0 // index of the word the WHERE and the rest are searched from
+	if words[0].word == "DELETE" {
+		from := index("FROM")
+		if from < 0 {
+			return "", false
+		}
+		columns = "*"
+		if from > first {
+			// MySQL's multi-table DELETE t1, t2 FROM ...
+			var targets []string
+			for _, t := range strings.Split(stmt[words[first].offset:words[from].offset], ",") {
+				targets = append(targets, strings.TrimSpace(t)+".*")
+			}
+			columns = strings.Join(targets, ", ")
+		}
+		tables = strings.TrimSpace(stmt[words[from].offset+len("FROM") : find(from+1, "USING", "WHERE", "ORDER", "LIMIT", "RETURNING")])
+		if using := index("USING"); using >= 0 {
+			// PostgreSQL's DELETE FROM t USING u: the rows of t
+			columns = tableRef(tables) + ".*"
+			tables += ", " + strings.TrimSpace(stmt[words[using].offset+len("USING"):find(using+1, "WHERE", "RETURNING")])
+		}
+		rest = from + 1
+	} else {
+		set := index("SET")
+		if set < 0 {
+			return "", false
+		}
+		tables = strings.TrimSpace(stmt[words[first].offset:words[set].offset])
+		columns = "*"
+		if from := slices.IndexFunc(words[set:], func(w clauseWord) bool { return w.word == "FROM" }); from >= 0 {
+			// PostgreSQL's UPDATE t SET ... FROM u: the rows of t
+			from += set
+			columns = tableRef(tables) + ".*"
+			tables += ", " + strings.TrimSpace(stmt[words[from].offset+len("FROM"):find(from+1, "WHERE", "RETURNING")])
+		}
+		rest = set + 1
+	}
+
+	query = "SELECT " + columns + " FROM " + tables
+	if t := tail(rest); t != "" {
+		query += " " + t
+	}
+	return query, true
+}
+
+// tableRef returns the name a table is referred to by in a FROM list entry
+// such as "orders", "orders o" or "orders AS o": its alias if it has one
+func tableRef(entry string) string {
+	fields := strings.Fields(entry)
+	switch {
+	case len(fields) >= 3 && strings.EqualFold(fields[1], "AS"):
+		return fields[2]
+	case len(fields) == 2:
+		return fields[1]
+	case len(fields) == 1:
+		return fields[0]
+	}
+	return entry
+}
+
+// previewWrite starts reading the rows the UPDATE or DELETE under the
+// cursor would change, to show them before running it if the user goes ahead
+func (m *Model) previewWrite() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	stmt := m.getQueryUnderCursor()
+	query, ok := writePreviewSQL(stmt)
+	if !ok {
+		m.statusMessage = "Preview works on an UPDATE or DELETE"
+		return nil
+	}
+	if len(findQueryVariables(stmt, tab.dbType)) > 0 {
+		m.statusMessage = "Preview can't fill in variables - run the statement to be asked for them"
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+
+	// Read the rows in the tab's transaction, if it has one, so earlier
+	// uncommitted changes show
+	return m.launchRead(queryResultMsg{tab: tab, query: query, preview: stmt}, previewRowLimit)
+}
+
+// finishPreviewWrite shows the rows previewWrite read. If the editor it was
+// started from no longer has focus, only how many there are is reported.
+func (m *Model) finishPreviewWrite(tab *Tab, msg queryResultMsg, prefix string) {
+	result := msg.result
+	more := result.HasMoreRows()
+	result.closeStream()
+	if msg.cancelled {
+		m.statusMessage = prefix + "Preview cancelled"
+		return
+	}
+	if result.Error != nil {
+		m.statusMessage = fmt.Sprintf("%sPreview failed: %v (%s)", prefix, result.Error, msg.query)
+		return
+	}
+
+	stmt := msg.preview
+	verb := strings.ToUpper(strings.Fields(skipLeadingComments(stmt, hashCommentsAllowed(tab.dbType)))[0])
+	action := "change"
+	if verb == "DELETE" {
+		action = "remove"
+	}
+	note := fmt.Sprintf("The %s would %s %d row(s)", verb, action, len(result.Rows))
+	if more {
+		note = fmt.Sprintf("The %s would %s at least %d rows; the first %d are shown", verb, action, len(result.Rows), len(result.Rows))
+	}
+	if tab != m.activeTabPtr() || m.focus != focusQuery {
+		m.statusMessage = prefix + note
+		return
+	}
+	m.preview = &TablePreview{title: "🔎 Preview: " + verb, note: note, result: result, returnFocus: m.focus, proceed: stmt}
+	m.focus = focusPreview
+	tab.textarea.Blur()
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+// TestWritePreviewSQL tests rewriting UPDATEs and DELETEs as the SELECT of the rows they change
+func TestWritePreviewSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{"delete", "DELETE FROM users WHERE id = 1;", "SELECT * FROM users WHERE id = 1"},
+		{"delete everything", "delete from users", "SELECT * FROM users"},
+		{"delete with comment", "-- tidy up\nDELETE FROM logs WHERE created < '2020-01-01';", "SELECT * FROM logs WHERE created < '2020-01-01'"},
+		{"delete returning", "DELETE FROM users WHERE active = false RETURNING id;", "SELECT * FROM users WHERE active = false"},
+		{"delete using", "DELETE FROM orders o USING users u WHERE o.user_id = u.id AND u.banned", "SELECT o.* FROM orders o, users u WHERE o.user_id = u.id AND u.banned"},
+		{"mysql multi-table delete", "DELETE o FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = 1", "SELECT o.* FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = 1"},
+		{"mysql delete limit", "DELETE LOW_PRIORITY FROM logs ORDER BY id LIMIT 100", "SELECT * FROM logs ORDER BY id LIMIT 100"},
+		{"update", "UPDATE users SET name = 'Bob', age = age + 1 WHERE id IN (1, 2);", "SELECT * FROM users WHERE id IN (1, 2)"},
+		{"update subquery where", "UPDATE users SET n = (SELECT count(*) FROM events WHERE user_id = 1)", "SELECT * FROM users"},
+		{"update from", "UPDATE orders AS o SET total = 0 FROM users u WHERE u.id = o.user_id RETURNING o.id", "SELECT o.* FROM orders AS o, users u WHERE u.id = o.user_id"},
+		{"mysql update join", "UPDATE orders o JOIN users u ON u.id = o.user_id SET o.total = 0 WHERE u.banned = 1", "SELECT * FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := writePreviewSQL(tt.stmt)
+			if !ok || got != tt.want {
+				t.Errorf("writePreviewSQL(%q) = %q, %v, want %q", tt.stmt, got, ok, tt.want)
+			}
+		})
+	}
+
+	for _, stmt := range []string{"SELECT * FROM users", "INSERT INTO users (id) VALUES (1)", "UPDATE users", ""} {
+		if got, ok := writePreviewSQL(stmt); ok {
+			t.Errorf("writePreviewSQL(%q) = %q, want not ok", stmt, got)
+		}
+	}
+}
@@ -1,27 +1,64 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
 )
 
-// executeQuery runs the SQL query and returns results with type information
-func executeQuery(db *sql.DB, query string) *QueryResult {
-	rows, err := db.Query(query)
+// sqlRunner is what statements run on: the connection pool, or a transaction
+type sqlRunner interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// executeQuery runs the SQL query and returns all of its rows, with type
+// information. Cancelling ctx stops the query.
+func executeQuery(ctx context.Context, db sqlRunner, query string, args ...any) *QueryResult {
+	return openQuery(ctx, db, query, 0, args...)
+}
+
+// openQuery runs the SQL query and fetches its first limit rows, or all of
+// them if limit is 0. If there are more, the result set is left open in the
+// result's stream, to fetch the rest as they're needed. Cancelling ctx stops
+// the query, but not a stream it leaves open. args are bound to the query's
+// placeholders.
+func openQuery(ctx context.Context, db sqlRunner, query string, limit int, args ...any) *QueryResult {
+	// An open result set holds its connection, which on a single-connection
+	// database (such as a CSV file's) would block every other query
+	if pool, ok := db.(*sql.DB); ok && pool.Stats().MaxOpenConnections == 1 {
+		limit = 0
+	}
+
+	// The rows are bound to queryCtx, which follows ctx only until the first
+	// rows are in, so an open stream outlives the query's context
+	queryCtx, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	start := time.Now()
+	rows, err := db.QueryContext(queryCtx, query, args...)
 	if err != nil {
+		cancel()
 		return &QueryResult{Error: err}
 	}
-	defer func() { _ = rows.Close() }()
+	execTime := time.Since(start)
+	stream := &rowStream{rows: rows, cancel: cancel}
 
 	columns, err := rows.Columns()
 	if err != nil {
+		stream.Close()
 		return &QueryResult{Error: err}
 	}
 
 	// Get column type information
 	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
+		stream.Close()
 		return &QueryResult{Error: err}
 	}
 
@@ -31,50 +68,118 @@ func executeQuery(db *sql.DB, query string) *QueryResult {
 		colTypes[i] = categorizeColumnType(ct.DatabaseTypeName())
 	}
 
-	var resultRows [][]CellValue
-	for rows.Next() {
-		// Create a slice of interface{} to hold each column
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return &QueryResult{Error: err}
-		}
-
-		// Convert to CellValues with NULL awareness
-		row := make([]CellValue, len(columns))
-		for i, val := range values {
-			if val == nil {
-				row[i] = CellValue{Value: "", IsNull: true}
-			} else {
-				switch v := val.(type) {
-				case []byte:
-					row[i] = CellValue{Value: string(v), IsNull: false}
-				case bool:
-					if v {
-						row[i] = CellValue{Value: "true", IsNull: false}
-					} else {
-						row[i] = CellValue{Value: "false", IsNull: false}
-					}
-				default:
-					row[i] = CellValue{Value: fmt.Sprintf("%v", v), IsNull: false}
-				}
-			}
-		}
-		resultRows = append(resultRows, row)
-	}
-
-	if err := rows.Err(); err != nil {
+	resultRows, done, err := stream.fetch(limit)
+	if err != nil {
+		stream.Close()
 		return &QueryResult{Error: err}
 	}
+	if !stop() {
+		// ctx ended before the rows could be detached from it
+		stream.Close()
+		return &QueryResult{Error: ctx.Err()}
+	}
+	if done {
+		stream.Close()
+		stream = nil
+	}
 
 	return &QueryResult{
 		Columns:     columns,
 		ColumnTypes: colTypes,
 		Rows:        resultRows,
+		ExecTime:    execTime,
+		FetchTime:   time.Since(start) - execTime,
+		stream:      stream,
+	}
+}
+
+// explainSQL prefixes a statement with EXPLAIN, or EXPLAIN QUERY PLAN on
+// SQLite, whose plain EXPLAIN lists bytecode. A statement that's already an
+// EXPLAIN is returned as it is.
+func explainSQL(stmt, dbType string) string {
+	body := skipLeadingComments(stmt, hashCommentsAllowed(dbType))
+	if word := strings.ToUpper(strings.Fields(body + " x")[0]); word == "EXPLAIN" {
+		return stmt
+	}
+	switch strings.ToLower(dbType) {
+	case "sqlite", "sqlite3":
+		return "EXPLAIN QUERY PLAN " + body
+	}
+	return "EXPLAIN " + body
+}
+
+// hasRowLimit reports whether a query limits its own rows with a top-level
+// LIMIT or FETCH FIRST, rather than in a subquery
+func hasRowLimit(query string) bool {
+	depth := 0
+	for _, tok := range tokenizeForFormat(query) {
+		switch {
+		case tok.kind == fmtPunct && tok.text == "(":
+			depth++
+		case tok.kind == fmtPunct && tok.text == ")":
+			depth--
+		case tok.kind == fmtWord && depth == 0:
+			if word := strings.ToUpper(tok.text); word == "LIMIT" || word == "FETCH" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// statementContext returns the context to run a statement in, ended by
+// calling cancel or, if timeout is set, once the timeout passes
+func statementContext(timeout time.Duration) (ctx context.Context, cancel context.CancelFunc) {
+	ctx, cancel = context.WithCancel(context.Background())
+	if timeout > 0 {
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeout(ctx, timeout)
+		cancelCtx := cancel
+		cancel = func() {
+			stop()
+			cancelCtx()
+		}
+	}
+	return ctx, cancel
+}
+
+// timeoutError replaces a statement's error with a clearer one if it failed
+// because it ran past the timeout
+func timeoutError(ctx context.Context, err error, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("statement timed out after %s", timeout)
+	}
+	return err
+}
+
+// executeStatement runs a statement that doesn't return rows and reports
+// the number of affected rows
+func executeStatement(ctx context.Context, db sqlRunner, stmt string, args ...any) *QueryResult {
+	start := time.Now()
+	affected, err := executeNonSelectStatement(ctx, db, stmt, args...)
+	if err != nil {
+		return &QueryResult{Error: err}
+	}
+	return &QueryResult{Executed: true, RowsAffected: affected, ExecTime: time.Since(start)}
+}
+
+// resultOutcome summarizes a result for the status bar and session log
+func resultOutcome(result *QueryResult) string {
+	switch {
+	case result.Error != nil:
+		return fmt.Sprintf("Error: %v", result.Error)
+	case result.DryRun && result.Executed && result.RowsAffected >= 0:
+		return fmt.Sprintf("Dry run: %d row(s) would be affected", result.RowsAffected)
+	case result.DryRun && result.Executed:
+		return "Dry run: OK, not committed"
+	case result.DryRun:
+		return fmt.Sprintf("Dry run: query returned %d rows, not committed", len(result.Rows))
+	case result.Executed && result.RowsAffected >= 0:
+		return fmt.Sprintf("%d row(s) affected", result.RowsAffected)
+	case result.Executed:
+		return "OK"
+	default:
+		return fmt.Sprintf("Query returned %d rows", len(result.Rows))
 	}
 }
 
@@ -145,13 +250,15 @@ func categorizeColumnType(dbTypeName string) ColumnType {
 	return ColTypeUnknown
 }
 
-// parseQueryMeta analyzes the query to determine if it's editable
-func parseQueryMeta(query string, result *QueryResult) *QueryMeta {
+// parseQueryMeta analyzes the query to determine if it's editable.
+// primaryKey looks up a table's primary key columns; when it is nil or the key
+// is unknown, a column named "id" is assumed to identify rows.
+func parseQueryMeta(query string, result *QueryResult, primaryKey func(table string) []string) *QueryMeta {
 	if result == nil || result.Error != nil {
 		return nil
 	}
 
-	query = strings.TrimSpace(query)
+	query = skipLeadingComments(query, false)
 	upperQuery := strings.ToUpper(query)
 
 	// Must be a SELECT query
@@ -206,44 +313,58 @@ func parseQueryMeta(query string, result *QueryResult) *QueryMeta {
 		return &QueryMeta{IsEditable: false}
 	}
 
-	// Check if result has an 'id' column
-	idIndex := -1
-	idColumn := ""
-	for i, col := range result.Columns {
-		colLower := strings.ToLower(col)
-		if colLower == "id" {
-			idIndex = i
-			idColumn = col
-			break
+	// Identify rows by the table's primary key, falling back to an 'id' column
+	keyColumns := []string{"id"}
+	if primaryKey != nil {
+		if pk := primaryKey(tableName); len(pk) > 0 {
+			keyColumns = pk
 		}
 	}
 
-	if idIndex == -1 {
-		return &QueryMeta{IsEditable: false}
+	// Check the result includes every key column
+	meta := &QueryMeta{TableName: tableName, IsEditable: true}
+	for _, key := range keyColumns {
+		idx := -1
+		for i, col := range result.Columns {
+			if strings.EqualFold(col, key) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return &QueryMeta{IsEditable: false}
+		}
+		meta.KeyColumns = append(meta.KeyColumns, result.Columns[idx])
+		meta.KeyIndexes = append(meta.KeyIndexes, idx)
 	}
 
-	return &QueryMeta{
-		TableName:  tableName,
-		IsEditable: true,
-		IDColumn:   idColumn,
-		IDIndex:    idIndex,
-	}
+	return meta
 }
 
-// extractTableName extracts the table name from a FROM clause fragment
+// extractTableName extracts the table name from a FROM clause fragment.
+// Backtick-quoted parts are unquoted and kept whole, so "`order items` o"
+// gives "order items".
 func extractTableName(tablePart string) string {
 	tablePart = strings.TrimSpace(tablePart)
 
-	// Remove backticks
-	tablePart = strings.ReplaceAll(tablePart, "`", "")
-
-	// Handle alias (e.g., "users u" or "users AS u")
-	parts := strings.Fields(tablePart)
-	if len(parts) == 0 {
-		return ""
+	// Read up to the first space outside backticks, dropping the alias
+	// (e.g., "users u" or "users AS u")
+	var name strings.Builder
+	for i := 0; i < len(tablePart); i++ {
+		ch := tablePart[i]
+		if ch == '`' {
+			end := backtickEnd(tablePart, i)
+			quoted := strings.TrimSuffix(tablePart[i+1:end], "`")
+			name.WriteString(strings.ReplaceAll(quoted, "``", "`"))
+			i = end - 1
+			continue
+		}
+		if unicode.IsSpace(rune(ch)) {
+			break
+		}
+		name.WriteByte(ch)
 	}
-
-	return parts[0]
+	return name.String()
 }
 
 // getQueryUnderCursor finds and returns the SQL query that contains the cursor position
@@ -254,12 +375,30 @@ func (m Model) getQueryUnderCursor() string {
 	}
 
 	content := tab.textarea.Value()
-	if strings.TrimSpace(content) == "" {
+	if tab.selection != nil {
+		start, end := tab.selection.Range(textareaCursorOffset(tab.textarea))
+		return selectedText(content, start, end)
+	}
+	start, end := statementRangeAtLine(content, tab.textarea.Line(), tab.dbType)
+	if start < 0 {
 		return ""
 	}
 
-	// Get cursor line (0-indexed)
-	cursorLine := tab.textarea.Line()
+	// Remove the trailing semicolon for execution
+	query := strings.TrimSuffix(content[start:end], ";")
+	return strings.TrimSpace(query)
+}
+
+// statementRangeAtLine returns the byte range [start, end) of the
+// semicolon-terminated statement containing the given line, with surrounding
+// whitespace trimmed. Returns -1, -1 if there is no complete statement there.
+// Semicolons in comments, strings, dollar-quoted bodies and quoted identifiers
+// don't end a statement, as when splitting statements. For MySQL, a DELIMITER line changes the terminator; a statement
+// ended by a custom delimiter doesn't include it.
+func statementRangeAtLine(content string, cursorLine int, dbType string) (int, int) {
+	if strings.TrimSpace(content) == "" {
+		return -1, -1
+	}
 
 	// Split content into lines and find which query block the cursor is in
 	lines := strings.Split(content, "\n")
@@ -274,54 +413,182 @@ func (m Model) getQueryUnderCursor() string {
 		cursorPos += len(lines[cursorLine]) / 2
 	}
 
-	// Find all semicolon positions
-	var semicolonPositions []int
-	for i, ch := range content {
-		if ch == ';' {
-			semicolonPositions = append(semicolonPositions, i)
+	// Find the statement terminators: delimiters, backslash meta-command
+	// lines, which need no semicolon, and DELIMITER lines
+	type terminator struct {
+		end       int  // where the statement before it ends
+		next      int  // where the next statement starts
+		lineStart int  // start of a meta-command or DELIMITER line, or -1 for a delimiter
+		runnable  bool // false for a DELIMITER line, which isn't sent to the database
+	}
+	var terminators []terminator
+	hashComments := hashCommentsAllowed(dbType)
+	dollarQuotes := dollarQuotesAllowed(dbType)
+	delimiters := delimiterCommandsAllowed(dbType)
+	delimiter := ";"
+	for i := 0; i < len(content); {
+		if i == 0 || content[i-1] == '\n' {
+			// Meta-command and DELIMITER lines, outside any string or comment
+			lineEnd := len(content)
+			if nl := strings.IndexByte(content[i:], '\n'); nl >= 0 {
+				lineEnd = i + nl
+			}
+			line := content[i:lineEnd]
+			if isMetaCommand(line) {
+				terminators = append(terminators, terminator{lineEnd, lineEnd, i, true})
+				i = lineEnd
+				continue
+			}
+			if d, ok := delimiterCommand(line); delimiters && ok {
+				terminators = append(terminators, terminator{lineEnd, lineEnd, i, false})
+				delimiter = d
+				i = lineEnd
+				continue
+			}
 		}
+		if end := skipQuoted(content, i, hashComments, dollarQuotes); end > i {
+			i = end
+			continue
+		}
+		if strings.HasPrefix(content[i:], delimiter) {
+			end := i
+			if delimiter == ";" {
+				end++ // a semicolon stays part of the statement
+			}
+			terminators = append(terminators, terminator{end, i + len(delimiter), -1, true})
+			i += len(delimiter)
+			continue
+		}
+		i++
 	}
 
-	// If no semicolons, there are no complete queries
-	if len(semicolonPositions) == 0 {
-		return ""
+	// If no terminators, there are no complete queries
+	if len(terminators) == 0 {
+		return -1, -1
 	}
 
 	// Find which query segment contains the cursor
-	// Query segments are: [0, semi1], [semi1+1, semi2], [semi2+1, semi3], ...
+	// Query segments are: [0, term1], [term1+1, term2], [term2+1, term3], ...
 	queryStart := 0
-	for _, semiPos := range semicolonPositions {
-		if cursorPos <= semiPos {
-			// Cursor is within this query (from queryStart to semiPos)
-			query := strings.TrimSpace(content[queryStart : semiPos+1])
-			// Remove the trailing semicolon for execution
-			query = strings.TrimSuffix(query, ";")
-			query = strings.TrimSpace(query)
-			return query
-		}
-		queryStart = semiPos + 1
-	}
-
-	// Cursor is after the last semicolon - check if there's an incomplete query
-	// If so, return empty (no complete query under cursor)
-	remaining := strings.TrimSpace(content[queryStart:])
-	if remaining == "" {
-		// Cursor is right after last semicolon, return the last query
-		if len(semicolonPositions) > 0 {
-			lastSemi := semicolonPositions[len(semicolonPositions)-1]
-			prevStart := 0
-			if len(semicolonPositions) > 1 {
-				prevStart = semicolonPositions[len(semicolonPositions)-2] + 1
+	for _, term := range terminators {
+		if cursorPos < term.next {
+			if term.lineStart >= 0 {
+				// A meta-command stands alone on its line; any text before it
+				// in the segment is an unterminated statement
+				if cursorPos < term.lineStart || !term.runnable {
+					return -1, -1
+				}
+				queryStart = term.lineStart
 			}
-			query := strings.TrimSpace(content[prevStart : lastSemi+1])
-			query = strings.TrimSuffix(query, ";")
-			query = strings.TrimSpace(query)
-			return query
+			// Cursor is within this query (from queryStart to the terminator)
+			return trimRange(content, queryStart, term.end)
 		}
+		queryStart = term.next
+	}
+
+	// Cursor is after the last terminator - check if there's an incomplete query
+	// If so, there is no complete query under cursor
+	if strings.TrimSpace(content[queryStart:]) == "" {
+		// Cursor is right after the last terminator, use the last query,
+		// skipping a trailing DELIMITER line
+		last := len(terminators) - 1
+		for last >= 0 && !terminators[last].runnable {
+			last--
+		}
+		if last < 0 {
+			return -1, -1
+		}
+		prevStart := 0
+		if terminators[last].lineStart >= 0 {
+			prevStart = terminators[last].lineStart
+		} else if last > 0 {
+			prevStart = terminators[last-1].next
+		}
+		return trimRange(content, prevStart, terminators[last].end)
 	}
 
 	// There's incomplete text after last semicolon - no complete query under cursor
-	return ""
+	return -1, -1
+}
+
+// nextStatementStart returns the offset where the first statement starting
+// after offset starts, or -1 if there is none
+func nextStatementStart(ranges []stmtRange, offset int) int {
+	for _, r := range ranges {
+		if r.start > offset {
+			return r.start
+		}
+	}
+	return -1
+}
+
+// prevStatementStart returns the offset where the statement before offset
+// starts: the start of the statement the offset is in, if it's past it, or
+// else the start of the one before. Returns -1 if there is none.
+func prevStatementStart(ranges []stmtRange, offset int) int {
+	prev := -1
+	for _, r := range ranges {
+		if r.start >= offset {
+			break
+		}
+		prev = r.start
+	}
+	return prev
+}
+
+// adjacentStatementStart returns the offset where the statement after (dir 1)
+// or before (dir -1) the one at offset starts. An offset between statements
+// counts as being in the one before it. Returns -1 if there is none.
+func adjacentStatementStart(ranges []stmtRange, offset int, dir int) int {
+	current := -1
+	for i, r := range ranges {
+		if r.start > offset {
+			break
+		}
+		current = i
+	}
+	i := current + dir
+	if i < 0 || i >= len(ranges) {
+		return -1
+	}
+	return ranges[i].start
+}
+
+// lineOffset returns the byte offset of the start of a line (0-indexed) of
+// content, or of the last line if there aren't that many
+func lineOffset(content string, line int) int {
+	pos := 0
+	for ; line > 0; line-- {
+		next := strings.IndexByte(content[pos:], '\n')
+		if next < 0 {
+			break
+		}
+		pos += next + 1
+	}
+	return pos
+}
+
+// trimRange narrows [start, end) so it excludes leading and trailing whitespace
+func trimRange(content string, start, end int) (int, int) {
+	segment := content[start:end]
+	start += len(segment) - len(strings.TrimLeft(segment, " \t\r\n"))
+	end -= len(segment) - len(strings.TrimRight(segment, " \t\r\n"))
+	if start >= end {
+		return -1, -1
+	}
+	return start, end
+}
+
+// statementLineRange returns the first and last line (0-indexed) of the
+// statement that getQueryUnderCursor would run. Returns -1, -1 if there is none.
+func statementLineRange(content string, cursorLine int, dbType string) (int, int) {
+	start, end := statementRangeAtLine(content, cursorLine, dbType)
+	if start < 0 {
+		return -1, -1
+	}
+	first := strings.Count(content[:start], "\n")
+	last := first + strings.Count(content[start:end], "\n")
+	return first, last
 }
 
 // formatValueForSQL formats a value for use in a SQL statement based on type and NULL state
@@ -454,16 +721,26 @@ func (m Model) generateUpdateSQL() string {
 		return ""
 	}
 
-	// Get the ID value (use original, never NULL for WHERE clause)
-	idVal := tab.detailView.originalValues[tab.queryMeta.IDIndex]
-	idColType := tab.detailView.columnTypes[tab.queryMeta.IDIndex]
-	formattedID := formatValueForSQL(idVal.Value, false, idColType, tab.dbType)
-
-	return fmt.Sprintf("UPDATE %s%s%s SET %s WHERE %s%s%s = %s",
+	return fmt.Sprintf("UPDATE %s%s%s SET %s WHERE %s",
 		q, tab.queryMeta.TableName, q,
 		strings.Join(setClauses, ", "),
-		q, tab.queryMeta.IDColumn, q,
-		formattedID)
+		m.keyWhereClause())
+}
+
+// keyWhereClause matches the detail view's row by its original key values,
+// e.g. "id" = 1, or "k1" = 'a' AND "k2" = 2 for a composite key
+func (m Model) keyWhereClause() string {
+	tab := m.tab()
+	q := quoteIdentifier(tab.dbType)
+
+	conditions := make([]string, len(tab.queryMeta.KeyIndexes))
+	for i, idx := range tab.queryMeta.KeyIndexes {
+		// Use the original value, never NULL, for the WHERE clause
+		val := tab.detailView.originalValues[idx]
+		formatted := formatValueForSQL(val.Value, false, tab.detailView.columnTypes[idx], tab.dbType)
+		conditions[i] = fmt.Sprintf("%s%s%s = %s", q, tab.queryMeta.KeyColumns[i], q, formatted)
+	}
+	return strings.Join(conditions, " AND ")
 }
 
 // generateDeleteSQL creates a DELETE statement for the current row
@@ -475,22 +752,18 @@ func (m Model) generateDeleteSQL() string {
 
 	q := quoteIdentifier(tab.dbType)
 
-	// Get the ID value
-	idVal := tab.detailView.originalValues[tab.queryMeta.IDIndex]
-	idColType := tab.detailView.columnTypes[tab.queryMeta.IDIndex]
-	formattedID := formatValueForSQL(idVal.Value, false, idColType, tab.dbType)
-
-	return fmt.Sprintf("DELETE FROM %s%s%s WHERE %s%s%s = %s",
+	return fmt.Sprintf("DELETE FROM %s%s%s WHERE %s",
 		q, tab.queryMeta.TableName, q,
-		q, tab.queryMeta.IDColumn, q,
-		formattedID)
+		m.keyWhereClause())
 }
 
-// generateInsertSQL creates an INSERT statement from the current field values
-func (m Model) generateInsertSQL() string {
+// generateInsertSQL creates an INSERT statement from the current field
+// values. It fails if the key's sequence is to be named but can't be looked
+// up.
+func (m Model) generateInsertSQL() (string, error) {
 	tab := m.tab()
 	if tab == nil || tab.detailView == nil || tab.queryMeta == nil || !tab.queryMeta.IsEditable {
-		return ""
+		return "", nil
 	}
 
 	q := quoteIdentifier(tab.dbType)
@@ -499,12 +772,24 @@ func (m Model) generateInsertSQL() string {
 	var values []string
 
 	for i, input := range tab.detailView.inputs {
-		// Skip the ID column for INSERT (let the database auto-generate it)
-		if i == tab.queryMeta.IDIndex {
+		colName := tab.result.Columns[i]
+
+		// Skip a single numeric key column for INSERT (let the database auto-generate it);
+		// natural and composite keys must be supplied
+		keys := tab.queryMeta.KeyIndexes
+		if len(keys) == 1 && i == keys[0] && tab.detailView.columnTypes[i].IsNumeric() {
+			if m.explicitInsertDefaults {
+				// The sequence is only needed for nextval(); without it DEFAULT still works
+				seq, err := tab.schema.Sequence(tab.queryMeta.TableName)
+				if err != nil {
+					return "", fmt.Errorf("looking up the key's sequence: %w", err)
+				}
+				columns = append(columns, fmt.Sprintf("%s%s%s", q, colName, q))
+				values = append(values, generatedKeyValue(seq, tab.dbType))
+			}
 			continue
 		}
 
-		colName := tab.result.Columns[i]
 		val := input.Value()
 		isNull := tab.detailView.isNull[i]
 		colType := tab.detailView.columnTypes[i]
@@ -516,5 +801,28 @@ func (m Model) generateInsertSQL() string {
 	return fmt.Sprintf("INSERT INTO %s%s%s (%s) VALUES (%s)",
 		q, tab.queryMeta.TableName, q,
 		strings.Join(columns, ", "),
+		strings.Join(values, ", ")), nil
+}
+
+// rowInsertSQL creates an INSERT of row i of the result into table, with
+// every column's value, keys included, to copy the row elsewhere as it is
+func (r *QueryResult) rowInsertSQL(i int, table, dbType string) string {
+	q := quoteIdentifier(dbType)
+
+	var parts []string
+	for _, part := range strings.Split(table, ".") {
+		parts = append(parts, q+part+q)
+	}
+	columns := make([]string, len(r.Columns))
+	values := make([]string, len(r.Columns))
+	for c, col := range r.Columns {
+		cell := r.Rows[i][c]
+		columns[c] = q + col + q
+		values[c] = formatValueForSQL(cell.Value, cell.IsNull, r.ColumnTypeAt(c), dbType)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);",
+		strings.Join(parts, "."),
+		strings.Join(columns, ", "),
 		strings.Join(values, ", "))
 }
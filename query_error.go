@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// QueryError is what a database error says beyond its one-line message:
+// its SQLSTATE code, the detail and hint PostgreSQL adds, and where in the
+// statement it went wrong
+type QueryError struct {
+	Message  string
+	Code     string      // SQLSTATE, with MySQL's error number
+	Fields   [][2]string // labelled details, e.g. {"Hint", "..."}, in display order
+	Position int         // byte offset in the statement of the error; -1 if unknown
+	Length   int         // bytes from Position to highlight
+}
+
+var (
+	// MySQL's syntax errors quote the rest of the statement from where
+	// parsing failed: "... near 'FROM x' at line 2"
+	mysqlNear = regexp.MustCompile(`(?s)near '(.*)' at line (\d+)$`)
+
+	// SQLite's quote the token it failed at: `near "FROM": syntax error`
+	sqliteNear = regexp.MustCompile(`^near "(.*)": syntax error$`)
+)
+
+// parseQueryError reads the details of err, returned by running stmt
+func parseQueryError(err error, stmt string) QueryError {
+	qe := QueryError{Message: err.Error(), Position: -1}
+
+	var pgErr *pgconn.PgError
+	var myErr *mysql.MySQLError
+	var liteErr sqlite3.Error
+	switch {
+	case errors.As(err, &pgErr):
+		qe.Message = pgErr.Message
+		qe.Code = pgErr.Code
+		for _, f := range [][2]string{
+			{"Detail", pgErr.Detail},
+			{"Hint", pgErr.Hint},
+			{"Where", pgErr.Where},
+			{"Schema", pgErr.SchemaName},
+			{"Table", pgErr.TableName},
+			{"Column", pgErr.ColumnName},
+			{"Constraint", pgErr.ConstraintName},
+		} {
+			if f[1] != "" {
+				qe.Fields = append(qe.Fields, f)
+			}
+		}
+		if pgErr.Position > 0 {
+			// A 1-based character offset
+			qe.Position = runeByteOffset(stmt, int(pgErr.Position)-1)
+		}
+
+	case errors.As(err, &myErr):
+		qe.Message = myErr.Message
+		qe.Code = strconv.Itoa(int(myErr.Number))
+		if myErr.SQLState != [5]byte{} {
+			qe.Code = fmt.Sprintf("%s (%d)", myErr.SQLState[:], myErr.Number)
+		}
+		if m := mysqlNear.FindStringSubmatch(myErr.Message); m != nil {
+			line, _ := strconv.Atoi(m[2])
+			qe.Position = mysqlNearOffset(stmt, m[1], line)
+		}
+
+	case errors.As(err, &liteErr):
+		if m := sqliteNear.FindStringSubmatch(liteErr.Error()); m != nil {
+			qe.Position = uniqueTokenOffset(stmt, m[1])
+		} else if strings.Contains(liteErr.Error(), "incomplete input") {
+			qe.Position = len(strings.TrimRight(stmt, " \t\n;"))
+		}
+	}
+
+	if qe.Position >= 0 {
+		qe.Position, qe.Length = errorSpan(stmt, qe.Position)
+	}
+	return qe
+}
+
+// runeByteOffset converts an offset in characters into stmt to bytes
+func runeByteOffset(stmt string, chars int) int {
+	offset := 0
+	for range chars {
+		if offset >= len(stmt) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(stmt[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// mysqlNearOffset finds where MySQL's near text starts on the given line
+// (from 1) of stmt; an empty near text means the statement ended too soon
+func mysqlNearOffset(stmt, near string, line int) int {
+	if near == "" {
+		return len(strings.TrimRight(stmt, " \t\n;"))
+	}
+	start := 0
+	for range line - 1 {
+		i := strings.IndexByte(stmt[start:], '\n')
+		if i < 0 {
+			return -1
+		}
+		start += i + 1
+	}
+	if i := strings.Index(stmt[start:], near); i >= 0 {
+		return start + i
+	}
+	return -1
+}
+
+// uniqueTokenOffset returns where token appears in stmt as a whole token,
+// or -1 if it's not there or there more than once, so can't be placed for
+// sure
+func uniqueTokenOffset(stmt, token string) int {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	first, _ := utf8.DecodeRuneInString(token)
+	last, _ := utf8.DecodeLastRuneInString(token)
+	found := -1
+	for from := 0; token != ""; {
+		i := strings.Index(stmt[from:], token)
+		if i < 0 {
+			break
+		}
+		i += from
+		from = i + len(token)
+		before, _ := utf8.DecodeLastRuneInString(stmt[:i])
+		after, _ := utf8.DecodeRuneInString(stmt[from:])
+		if (isWord(first) && i > 0 && isWord(before)) || (isWord(last) && from < len(stmt) && isWord(after)) {
+			continue // part of a longer word
+		}
+		if found >= 0 {
+			return -1
+		}
+		found = i
+	}
+	return found
+}
+
+// errorSpan returns the word, or single character, at offset in stmt to
+// highlight; at the end of the statement that's its last character
+func errorSpan(stmt string, offset int) (int, int) {
+	if offset >= len(stmt) {
+		if stmt == "" {
+			return 0, 0
+		}
+		_, size := utf8.DecodeLastRuneInString(stmt)
+		return len(stmt) - size, size
+	}
+	end := offset
+	for end < len(stmt) {
+		r, size := utf8.DecodeRuneInString(stmt[end:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+		end += size
+	}
+	if end == offset {
+		_, size := utf8.DecodeRuneInString(stmt[offset:])
+		end += size
+	}
+	return offset, end - offset
+}
+
+// lineAndColumn returns the line and column (both from 1, the column in
+// characters) of offset in text
+func lineAndColumn(text string, offset int) (int, int) {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	return line, utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+}
+
+// parseResultError parses the error of the tab's result, if it failed, so
+// it isn't parsed again each time the error panel is drawn
+func (t *Tab) parseResultError() {
+	if t.result != nil && t.result.Error != nil && t.result.errorDetails == nil {
+		qe := parseQueryError(t.result.Error, t.lastQuery)
+		t.result.errorDetails = &qe
+	}
+}
+
+// queryError returns the details of the error of the tab's result
+func (t *Tab) queryError() QueryError {
+	if t.result.errorDetails != nil {
+		return *t.result.errorDetails
+	}
+	return parseQueryError(t.result.Error, t.lastQuery)
+}
+
+// errorLines is the error panel shown in place of the results: the message,
+// the statement's line the error is on with the spot marked, and the
+// details
+func (m Model) errorLines(tab *Tab) []string {
+	styles := m.GetStyles()
+	qe := tab.queryError()
+
+	head := "Error"
+	if qe.Code != "" {
+		head += " " + qe.Code
+	}
+	lines := strings.Split(styles.Error.Render(head+": ")+qe.Message, "\n")
+
+	label := lipgloss.NewStyle().Bold(true).Foreground(tab.theme.TextBright)
+	if qe.Position >= 0 {
+		line, col := lineAndColumn(tab.lastQuery, qe.Position)
+		lines = append(lines, "", label.Render(fmt.Sprintf("At line %d, column %d:", line, col)))
+		text := strings.Split(tab.lastQuery, "\n")[line-1]
+		width := max(utf8.RuneCountInString(tab.lastQuery[qe.Position:qe.Position+qe.Length]), 1)
+		lines = append(lines, "  "+strings.ReplaceAll(text, "\t", " "),
+			"  "+strings.Repeat(" ", col-1)+styles.Error.Render(strings.Repeat("^", width)))
+	}
+	if len(qe.Fields) > 0 {
+		lines = append(lines, "")
+	}
+	for _, f := range qe.Fields {
+		for i, text := range strings.Split(f[1], "\n") {
+			if i == 0 {
+				lines = append(lines, label.Render(f[0]+": ")+text)
+			} else {
+				lines = append(lines, "  "+text)
+			}
+		}
+	}
+	return lines
+}
+
+// errorPanelRows returns how many of the error panel's lines fit in height
+// lines, leaving one to say there's more if they don't all fit
+func errorPanelRows(lines, height int) int {
+	if lines <= height {
+		return lines
+	}
+	return max(height-1, 1)
+}
+
+// scrollErrorPanel scrolls the active tab's error panel by dir lines,
+// keeping it in range
+func (m *Model) scrollErrorPanel(dir int) {
+	tab := m.activeTabPtr()
+	lines := len(m.errorLines(tab))
+	last := lines - errorPanelRows(lines, m.tableHeight(tab))
+	tab.errorScroll = min(max(tab.errorScroll+dir, 0), last)
+}
+
+// renderErrorPanel renders the error panel in height lines, scrolled to
+// the tab's errorScroll
+func (m Model) renderErrorPanel(tab *Tab, height int) string {
+	lines := m.errorLines(tab)
+	rows := errorPanelRows(len(lines), height)
+	if rows == len(lines) {
+		return strings.Join(lines, "\n")
+	}
+	scroll := min(tab.errorScroll, len(lines)-rows) // in case the terminal grew
+	shown := slices.Clone(lines[scroll : scroll+rows])
+	more := fmt.Sprintf("(lines %d-%d of %d, ↑↓ to scroll)", scroll+1, scroll+rows, len(lines))
+	return strings.Join(append(shown, m.GetStyles().Help.Render(more)), "\n")
+}
+
+// editorErrorSpan returns where in the editor's content the shown error
+// happened, as byte offsets, or -1, -1 if it's not known or the statement
+// isn't in the editor as it ran
+func editorErrorSpan(tab *Tab, content string) (int, int) {
+	if tab.result == nil || tab.result.Error == nil || tab.lastQuery == "" {
+		return -1, -1
+	}
+	start := strings.Index(content, tab.lastQuery)
+	if start < 0 {
+		return -1, -1
+	}
+	qe := tab.queryError()
+	if qe.Position < 0 {
+		return -1, -1
+	}
+	return start + qe.Position, start + qe.Position + qe.Length
+}
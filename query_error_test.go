@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// TestParseQueryError tests reading the code, details and position of database errors
+func TestParseQueryError(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	_, liteErr := db.Exec("SELECT * FROM users ORDER name")
+
+	tests := []struct {
+		name     string
+		err      error
+		stmt     string
+		code     string
+		fields   int
+		position int
+		length   int
+	}{
+		{
+			name:     "postgres",
+			err:      &pgconn.PgError{Code: "42P01", Message: `relation "userz" does not exist`, Position: 15},
+			stmt:     "SELECT * FROM userz",
+			code:     "42P01",
+			position: 14,
+			length:   5,
+		},
+		{
+			name:     "postgres counts characters",
+			err:      &pgconn.PgError{Code: "42703", Message: `column "nme" does not exist`, Hint: "Perhaps you meant name.", Position: 13},
+			stmt:     "SELECT 'é', nme FROM users",
+			code:     "42703",
+			fields:   1,
+			position: 13,
+			length:   3,
+		},
+		{
+			name:     "postgres, wrapped",
+			err:      fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "23505", Detail: "Key (id)=(1) already exists.", ConstraintName: "users_pkey"}),
+			stmt:     "INSERT INTO users VALUES (1)",
+			code:     "23505",
+			fields:   2,
+			position: -1,
+		},
+		{
+			name:     "mysql",
+			err:      &mysql.MySQLError{Number: 1064, SQLState: [5]byte{'4', '2', '0', '0', '0'}, Message: "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'FORM users' at line 2"},
+			stmt:     "SELECT *\nFORM users",
+			code:     "42000 (1064)",
+			position: 9,
+			length:   4,
+		},
+		{
+			name:     "mysql, at the end",
+			err:      &mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax; ... near '' at line 1"},
+			stmt:     "SELECT * FROM",
+			code:     "1064",
+			position: 12,
+			length:   1,
+		},
+		{
+			name:     "sqlite",
+			err:      liteErr,
+			stmt:     "SELECT * FROM users ORDER name",
+			position: 26,
+			length:   4,
+		},
+		{
+			name:     "other",
+			err:      errors.New("statement timed out after 5s"),
+			stmt:     "SELECT 1",
+			position: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qe := parseQueryError(tt.err, tt.stmt)
+			if qe.Code != tt.code || len(qe.Fields) != tt.fields {
+				t.Errorf("parseQueryError() code %q with %d fields, want %q with %d", qe.Code, len(qe.Fields), tt.code, tt.fields)
+			}
+			if qe.Position != tt.position || (tt.position >= 0 && qe.Length != tt.length) {
+				t.Errorf("parseQueryError() at %d (%d bytes), want %d (%d bytes)", qe.Position, qe.Length, tt.position, tt.length)
+			}
+		})
+	}
+}
+
+// TestUniqueTokenOffset tests placing the token SQLite reports an error near
+func TestUniqueTokenOffset(t *testing.T) {
+	tests := []struct {
+		stmt  string
+		token string
+		want  int
+	}{
+		{"SELECT * FROMM t", "t", 15},
+		{"SELECT * FROM t WHERE a = = 1", "=", -1},
+		{"SELECT a,, b FROM t", ",", -1},
+		{"SELECT a, FROM t", "FROM", 10},
+		{"SELECT 1", "x", -1},
+	}
+
+	for _, tt := range tests {
+		if got := uniqueTokenOffset(tt.stmt, tt.token); got != tt.want {
+			t.Errorf("uniqueTokenOffset(%q, %q) = %d, want %d", tt.stmt, tt.token, got, tt.want)
+		}
+	}
+}
+
+// TestScrollErrorPanel tests that scrolling the error panel stops at its
+// last line
+func TestScrollErrorPanel(t *testing.T) {
+	pgErr := &pgconn.PgError{Message: "failed", Code: "XX000", Detail: strings.Repeat("more\n", 30) + "last"}
+	tab := &Tab{textarea: textarea.New(), result: &QueryResult{Error: pgErr}}
+	tab.parseResultError()
+	m := Model{tabs: []*Tab{tab}, height: 30}
+
+	for range 100 {
+		m.scrollErrorPanel(1)
+	}
+	panel := m.renderErrorPanel(tab, m.tableHeight(tab))
+	if !strings.Contains(panel, "last") {
+		t.Errorf("scrolled to the end, the panel doesn't show the last line:\n%s", panel)
+	}
+	last := tab.errorScroll
+	m.scrollErrorPanel(1)
+	if tab.errorScroll != last {
+		t.Errorf("scrolling past the end moved errorScroll from %d to %d", last, tab.errorScroll)
+	}
+	for range 100 {
+		m.scrollErrorPanel(-1)
+	}
+	if tab.errorScroll != 0 {
+		t.Errorf("scrolled back up, errorScroll = %d, want 0", tab.errorScroll)
+	}
+}
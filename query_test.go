@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -51,7 +56,7 @@ func TestExecuteQuery(t *testing.T) {
 	db := setupTestDB(t)
 	defer func() { _ = db.Close() }()
 
-	result := executeQuery(db, "SELECT id, name, email FROM users ORDER BY id")
+	result := executeQuery(context.Background(), db, "SELECT id, name, email FROM users ORDER BY id")
 
 	if result.Error != nil {
 		t.Fatalf("Query failed: %v", result.Error)
@@ -92,13 +97,106 @@ func TestExecuteQueryError(t *testing.T) {
 	db := setupTestDB(t)
 	defer func() { _ = db.Close() }()
 
-	result := executeQuery(db, "SELECT * FROM nonexistent_table")
+	result := executeQuery(context.Background(), db, "SELECT * FROM nonexistent_table")
 
 	if result.Error == nil {
 		t.Error("Expected error for invalid table, got nil")
 	}
 }
 
+// TestExecuteQueryCancelled tests that a cancelled context stops a query
+func TestExecuteQueryCancelled(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := executeQuery(ctx, db, "SELECT * FROM users"); result.Error == nil {
+		t.Error("executeQuery() with a cancelled context: expected an error")
+	}
+	if result := executeStatement(ctx, db, "DELETE FROM users"); result.Error == nil {
+		t.Error("executeStatement() with a cancelled context: expected an error")
+	}
+	if result := executeQuery(context.Background(), db, "SELECT * FROM users"); len(result.Rows) == 0 {
+		t.Error("the cancelled DELETE removed rows")
+	}
+}
+
+// TestExecuteQueryTimeout tests that a statement running past its deadline
+// is stopped and reported as timed out
+func TestExecuteQueryTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := statementContext(50 * time.Millisecond)
+	defer cancel()
+	result := executeQuery(ctx, db, "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT count(*) FROM n")
+	if result.Error == nil {
+		t.Fatal("executeQuery() past its deadline: expected an error")
+	}
+	err := timeoutError(ctx, result.Error, 50*time.Millisecond)
+	if err.Error() != "statement timed out after 50ms" {
+		t.Errorf("timeoutError() = %q, want the timeout reported", err)
+	}
+}
+
+// TestStatementContextCancel tests that cancelling a statement with a
+// timeout reports it cancelled rather than timed out
+func TestStatementContextCancel(t *testing.T) {
+	ctx, cancel := statementContext(time.Hour)
+	cancel()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("after cancel(), ctx.Err() = %v, want context.Canceled", ctx.Err())
+	}
+}
+
+// TestExplainSQL tests prefixing a statement to show its plan
+func TestExplainSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		stmt     string
+		dbType   string
+		expected string
+	}{
+		{"postgres", "SELECT * FROM users;", "postgres", "EXPLAIN SELECT * FROM users;"},
+		{"mysql", "UPDATE users SET age = 1", "mysql", "EXPLAIN UPDATE users SET age = 1"},
+		{"sqlite", "SELECT * FROM users", "sqlite", "EXPLAIN QUERY PLAN SELECT * FROM users"},
+		{"after comments", "-- @name: all\nSELECT * FROM users", "postgres", "EXPLAIN SELECT * FROM users"},
+		{"already explained", "explain analyze SELECT 1", "postgres", "explain analyze SELECT 1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := explainSQL(tc.stmt, tc.dbType); result != tc.expected {
+				t.Errorf("explainSQL(%q) = %q, want %q", tc.stmt, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestHasRowLimit tests detecting a query that limits its own rows
+func TestHasRowLimit(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"SELECT * FROM users", false},
+		{"SELECT * FROM users LIMIT 10", true},
+		{"select * from users order by id limit 5 offset 10", true},
+		{"SELECT * FROM users FETCH FIRST 10 ROWS ONLY", true},
+		{"SELECT * FROM (SELECT * FROM users LIMIT 10) u", false},
+		{"SELECT 'LIMIT 10' FROM users", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			if result := hasRowLimit(tc.query); result != tc.expected {
+				t.Errorf("hasRowLimit(%q) = %v, want %v", tc.query, result, tc.expected)
+			}
+		})
+	}
+}
+
 // TestColumnTypeDetection tests column type categorization
 func TestColumnTypeDetection(t *testing.T) {
 	tests := []struct {
@@ -180,7 +278,7 @@ func TestParseQueryMeta(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result := executeQuery(db, tc.query)
+			result := executeQuery(context.Background(), db, tc.query)
 			if result.Error != nil {
 				// Skip queries that fail (like JOIN on non-existent table)
 				if tc.isEditable {
@@ -189,7 +287,7 @@ func TestParseQueryMeta(t *testing.T) {
 				return
 			}
 
-			meta := parseQueryMeta(tc.query, result)
+			meta := parseQueryMeta(tc.query, result, nil)
 
 			if meta == nil {
 				if tc.isEditable {
@@ -209,6 +307,193 @@ func TestParseQueryMeta(t *testing.T) {
 	}
 }
 
+// TestParseQueryMetaPrimaryKey tests editability using the table's primary key from the schema
+func TestParseQueryMetaPrimaryKey(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE TABLE accounts (account_uuid TEXT PRIMARY KEY, name TEXT)",
+		"CREATE TABLE memberships (user_id INTEGER, group_id INTEGER, role TEXT, PRIMARY KEY (user_id, group_id))",
+		"CREATE TABLE notes (id INTEGER, body TEXT)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	cache := NewSchemaCache(db, "sqlite")
+
+	tests := []struct {
+		name       string
+		query      string
+		isEditable bool
+		keyColumns []string
+	}{
+		{"natural key", "SELECT * FROM accounts", true, []string{"account_uuid"}},
+		{"key not selected", "SELECT name FROM accounts", false, nil},
+		{"composite key", "SELECT * FROM memberships", true, []string{"user_id", "group_id"}},
+		{"partial composite key", "SELECT user_id, role FROM memberships", false, nil},
+		{"no key falls back to id", "SELECT * FROM notes", true, []string{"id"}},
+		{"integer primary key", "SELECT * FROM users", true, []string{"id"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := executeQuery(context.Background(), db, tc.query)
+			if result.Error != nil {
+				t.Fatalf("Query failed: %v", result.Error)
+			}
+			meta := parseQueryMeta(tc.query, result, cache.PrimaryKey)
+			if meta == nil {
+				t.Fatal("Expected non-nil meta")
+			}
+			if meta.IsEditable != tc.isEditable {
+				t.Errorf("IsEditable = %v, want %v", meta.IsEditable, tc.isEditable)
+			}
+			if strings.Join(meta.KeyColumns, ",") != strings.Join(tc.keyColumns, ",") {
+				t.Errorf("KeyColumns = %q, want %q", meta.KeyColumns, tc.keyColumns)
+			}
+		})
+	}
+}
+
+// TestGenerateSQLCompositeKey tests that UPDATE and DELETE match every key column
+func TestGenerateSQLCompositeKey(t *testing.T) {
+	result := &QueryResult{
+		Columns:     []string{"user_id", "group_id", "role"},
+		ColumnTypes: []ColumnType{ColTypeNumeric, ColTypeNumeric, ColTypeText},
+	}
+	tab := &Tab{
+		dbType: "sqlite",
+		result: result,
+		queryMeta: &QueryMeta{
+			TableName:  "memberships",
+			IsEditable: true,
+			KeyColumns: []string{"user_id", "group_id"},
+			KeyIndexes: []int{0, 1},
+		},
+		detailView: &DetailView{
+			originalValues: []CellValue{{Value: "7"}, {Value: "3"}, {Value: "member"}},
+			isNull:         []bool{false, false, false},
+			columnTypes:    result.ColumnTypes,
+		},
+	}
+	for _, v := range []string{"7", "3", "admin"} {
+		ti := textinput.New()
+		ti.SetValue(v)
+		tab.detailView.inputs = append(tab.detailView.inputs, ti)
+	}
+	m := Model{tabs: []*Tab{tab}}
+
+	expectedUpdate := `UPDATE "memberships" SET "role" = 'admin' WHERE "user_id" = 7 AND "group_id" = 3`
+	if got := m.generateUpdateSQL(); got != expectedUpdate {
+		t.Errorf("generateUpdateSQL() = %q, want %q", got, expectedUpdate)
+	}
+	expectedDelete := `DELETE FROM "memberships" WHERE "user_id" = 7 AND "group_id" = 3`
+	if got := m.generateDeleteSQL(); got != expectedDelete {
+		t.Errorf("generateDeleteSQL() = %q, want %q", got, expectedDelete)
+	}
+	expectedInsert := `INSERT INTO "memberships" ("user_id", "group_id", "role") VALUES (7, 3, 'admin')`
+	if got := mustGenerateInsertSQL(t, m); got != expectedInsert {
+		t.Errorf("generateInsertSQL() = %q, want %q", got, expectedInsert)
+	}
+}
+
+// TestGenerateInsertSQLExplicitDefaults tests naming a generated key column in INSERTs
+func TestGenerateInsertSQLExplicitDefaults(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	result := &QueryResult{
+		Columns:     []string{"id", "name"},
+		ColumnTypes: []ColumnType{ColTypeNumeric, ColTypeText},
+	}
+	tab := &Tab{
+		db:     db,
+		dbType: "sqlite",
+		schema: NewSchemaCache(db, "sqlite"),
+		result: result,
+		queryMeta: &QueryMeta{
+			TableName:  "users",
+			IsEditable: true,
+			KeyColumns: []string{"id"},
+			KeyIndexes: []int{0},
+		},
+		detailView: &DetailView{
+			originalValues: []CellValue{{Value: "1"}, {Value: "Alice"}},
+			isNull:         []bool{false, false},
+			columnTypes:    result.ColumnTypes,
+		},
+	}
+	for _, v := range []string{"1", "Dora"} {
+		ti := textinput.New()
+		ti.SetValue(v)
+		tab.detailView.inputs = append(tab.detailView.inputs, ti)
+	}
+
+	m := Model{tabs: []*Tab{tab}}
+	if got, want := mustGenerateInsertSQL(t, m), `INSERT INTO "users" ("name") VALUES ('Dora')`; got != want {
+		t.Errorf("generateInsertSQL() = %q, want %q", got, want)
+	}
+
+	m.explicitInsertDefaults = true
+	want := `INSERT INTO "users" ("id", "name") VALUES (NULL, 'Dora')`
+	got := mustGenerateInsertSQL(t, m)
+	if got != want {
+		t.Errorf("generateInsertSQL() with explicit defaults = %q, want %q", got, want)
+	}
+	if _, err := db.Exec(got); err != nil {
+		t.Fatalf("generated INSERT failed: %v", err)
+	}
+	var id int
+	if err := db.QueryRow("SELECT id FROM users WHERE name = 'Dora'").Scan(&id); err != nil || id != 4 {
+		t.Errorf("inserted id = %d (err %v), want 4", id, err)
+	}
+
+	// The key isn't named when its generator can't be looked up
+	closed := setupTestDB(t)
+	_ = closed.Close()
+	tab.schema = NewSchemaCache(closed, "sqlite")
+	if got, err := m.generateInsertSQL(); err == nil {
+		t.Errorf("generateInsertSQL() with a failed sequence lookup = %q, want an error", got)
+	}
+}
+
+// mustGenerateInsertSQL returns the model's generated INSERT, failing the
+// test on an error
+func mustGenerateInsertSQL(t *testing.T, m Model) string {
+	t.Helper()
+	stmt, err := m.generateInsertSQL()
+	if err != nil {
+		t.Fatalf("generateInsertSQL(): %v", err)
+	}
+	return stmt
+}
+
+// TestRowInsertSQL tests copying a result row as an INSERT, which runs
+func TestRowInsertSQL(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	result := &QueryResult{
+		Columns:     []string{"id", "name", "email"},
+		ColumnTypes: []ColumnType{ColTypeNumeric, ColTypeText, ColTypeText},
+		Rows:        [][]CellValue{{{Value: "9"}, {Value: "O'Brien"}, {IsNull: true}}},
+	}
+	want := `INSERT INTO "users" ("id", "name", "email") VALUES (9, 'O''Brien', NULL);`
+	got := result.rowInsertSQL(0, "users", "sqlite")
+	if got != want {
+		t.Errorf("rowInsertSQL() = %q, want %q", got, want)
+	}
+	if _, err := db.Exec(got); err != nil {
+		t.Fatalf("copied INSERT failed: %v", err)
+	}
+
+	if got, want := result.rowInsertSQL(0, "app.users", "mysql"), "INSERT INTO `app`.`users` (`id`, `name`, `email`) VALUES (9, 'O''Brien', NULL);"; got != want {
+		t.Errorf("rowInsertSQL() with a schema = %q, want %q", got, want)
+	}
+}
+
 // TestFormatValueForSQL tests SQL value formatting
 func TestFormatValueForSQL(t *testing.T) {
 	tests := []struct {
@@ -286,6 +571,10 @@ func TestExtractTableName(t *testing.T) {
 		{"users u", "users"},
 		{"users AS u", "users"},
 		{"`users`", "users"},
+		{"`weird;name` w", "weird;name"},
+		{"`order items` AS oi", "order items"},
+		{"`a``b`", "a`b"},
+		{"`shop`.`orders` o", "shop.orders"},
 		{"  users  ", "users"},
 		{"", ""},
 	}
@@ -300,6 +589,197 @@ func TestExtractTableName(t *testing.T) {
 	}
 }
 
+// TestStatementLineRange tests which lines belong to the statement under the cursor
+func TestStatementLineRange(t *testing.T) {
+	content := "SELECT 1;\n\nSELECT *\nFROM users\nWHERE id = 1;\n\nSELECT 3"
+	tests := []struct {
+		name       string
+		content    string
+		cursorLine int
+		wantFirst  int
+		wantLast   int
+	}{
+		{"first statement", content, 0, 0, 0},
+		{"blank line belongs to next statement", content, 1, 2, 4},
+		{"middle of multi-line statement", content, 3, 2, 4},
+		{"last line of statement", content, 4, 2, 4},
+		{"incomplete trailing statement", content, 6, -1, -1},
+		{"after last semicolon", "SELECT 1;\nSELECT 2;\n", 2, 1, 1},
+		{"no semicolons", "SELECT 1", 0, -1, -1},
+		{"empty", "", 0, -1, -1},
+		{"meta-command without semicolon", "\\dt\nSELECT 1;", 0, 0, 0},
+		{"statement after meta-command", "\\dt\nSELECT 1;", 1, 1, 1},
+		{"unterminated text before meta-command", "SELECT 1\n\\dt", 0, -1, -1},
+		{"after trailing meta-command", "SELECT 1;\n\\x\n", 2, 1, 1},
+		{"dollar-quoted body", "SELECT 1;\nCREATE FUNCTION f() AS $$\nBEGIN\n  RETURN 1;\nEND;\n$$ LANGUAGE plpgsql;", 3, 1, 5},
+		{"after dollar-quoted body", "DO $x$ BEGIN NULL; END $x$;\nSELECT 2;", 1, 1, 1},
+		{"backtick identifier", "SELECT 1;\nSELECT *\nFROM `weird;name`;", 2, 1, 2},
+		{"semicolon in a string", "SELECT 1;\nSELECT 'a;\nb';", 2, 1, 2},
+		{"semicolon in a comment", "SELECT 1 -- one;\n+ 1;\nSELECT 2;", 0, 0, 1},
+		{"dollar sign in a string", "SELECT '$$';\nSELECT 2;", 1, 1, 1},
+		{"dollar sign in a comment", "/* $$ */ SELECT 1;\nSELECT 2;", 1, 1, 1},
+		{"meta-command in a string", "SELECT 'a\n\\dt\nb';", 1, 0, 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			first, last := statementLineRange(tc.content, tc.cursorLine, "postgres")
+			if first != tc.wantFirst || last != tc.wantLast {
+				t.Errorf("statementLineRange(line %d) = (%d, %d), want (%d, %d)", tc.cursorLine, first, last, tc.wantFirst, tc.wantLast)
+			}
+		})
+	}
+}
+
+// TestStatementLineRangeDelimiter tests the statement under the cursor in
+// MySQL scripts that change the DELIMITER
+func TestStatementLineRangeDelimiter(t *testing.T) {
+	content := "SELECT 1;\nDELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 2;\nEND$$\nDELIMITER ;\nCALL p();"
+	tests := []struct {
+		name       string
+		cursorLine int
+		wantFirst  int
+		wantLast   int
+	}{
+		{"before delimiter change", 0, 0, 0},
+		{"on DELIMITER line", 1, -1, -1},
+		{"inside procedure body", 4, 2, 5},
+		{"on the closing delimiter", 5, 2, 5},
+		{"on DELIMITER reset", 6, -1, -1},
+		{"after reset", 7, 7, 7},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			first, last := statementLineRange(content, tc.cursorLine, "mysql")
+			if first != tc.wantFirst || last != tc.wantLast {
+				t.Errorf("statementLineRange(line %d) = (%d, %d), want (%d, %d)", tc.cursorLine, first, last, tc.wantFirst, tc.wantLast)
+			}
+		})
+	}
+
+	start, end := statementRangeAtLine(content, 3, "mysql")
+	if got, want := content[start:end], "CREATE PROCEDURE p()\nBEGIN\n  SELECT 2;\nEND"; got != want {
+		t.Errorf("procedure statement = %q, want %q", got, want)
+	}
+	if start, _ := statementRangeAtLine("DELIMITER $$\nSELECT 1$$\nDELIMITER ;\n", 3, "mysql"); start != 13 {
+		t.Errorf("after trailing DELIMITER line, start = %d, want 13", start)
+	}
+}
+
+// TestStatementNavigation tests finding where the next and previous statements start
+func TestStatementNavigation(t *testing.T) {
+	content := "SELECT 1;\n\n-- users\nSELECT *\nFROM users;\n\\dt\nSELECT 'a;b'"
+	ranges := splitStatementRanges(content, "")
+	tests := []struct {
+		name     string
+		offset   int
+		wantNext int
+		wantPrev int
+	}{
+		{"start of first statement", 0, 11, -1},
+		{"inside first statement", 4, 11, 0},
+		{"inside second statement", 25, 41, 11},
+		{"start of meta-command", 41, 45, 11},
+		{"inside last statement", 47, -1, 45},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nextStatementStart(ranges, tc.offset); got != tc.wantNext {
+				t.Errorf("nextStatementStart(%d) = %d, want %d", tc.offset, got, tc.wantNext)
+			}
+			if got := prevStatementStart(ranges, tc.offset); got != tc.wantPrev {
+				t.Errorf("prevStatementStart(%d) = %d, want %d", tc.offset, got, tc.wantPrev)
+			}
+		})
+	}
+}
+
+// TestAdjacentStatementStart tests finding the statement to run after or
+// before the one at the cursor
+func TestAdjacentStatementStart(t *testing.T) {
+	content := "SELECT 1;\n\n-- users\nSELECT *\nFROM users;\n\\dt\nSELECT 'a;b'"
+	ranges := splitStatementRanges(content, "")
+	tests := []struct {
+		name     string
+		offset   int
+		wantNext int
+		wantPrev int
+	}{
+		{"inside first statement", 4, 11, -1},
+		{"after first semicolon", 9, 11, -1},
+		{"inside second statement", 25, 41, 0},
+		{"start of meta-command", 41, 45, 11},
+		{"inside last statement", 47, -1, 41},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adjacentStatementStart(ranges, tc.offset, 1); got != tc.wantNext {
+				t.Errorf("adjacentStatementStart(%d, 1) = %d, want %d", tc.offset, got, tc.wantNext)
+			}
+			if got := adjacentStatementStart(ranges, tc.offset, -1); got != tc.wantPrev {
+				t.Errorf("adjacentStatementStart(%d, -1) = %d, want %d", tc.offset, got, tc.wantPrev)
+			}
+		})
+	}
+}
+
+// TestLineOffset tests finding where a line starts
+func TestLineOffset(t *testing.T) {
+	content := "SELECT 1;\nSELECT 2;\n\nSELECT 3;"
+	tests := []struct {
+		line int
+		want int
+	}{
+		{0, 0},
+		{1, 10},
+		{2, 20},
+		{3, 21},
+		{10, 21},
+	}
+
+	for _, tc := range tests {
+		if got := lineOffset(content, tc.line); got != tc.want {
+			t.Errorf("lineOffset(%d) = %d, want %d", tc.line, got, tc.want)
+		}
+	}
+}
+
+// TestExecuteStatement tests running DML through Exec and summarizing the outcome
+func TestExecuteStatement(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	tests := []struct {
+		name    string
+		stmt    string
+		outcome string
+	}{
+		{"update", "UPDATE users SET age = age + 1 WHERE age > 26", "2 row(s) affected"},
+		{"delete none", "DELETE FROM users WHERE id = 99", "0 row(s) affected"},
+		{"error", "UPDATE missing SET x = 1", "Error: no such table: missing"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := executeStatement(context.Background(), db, tc.stmt)
+			if got := resultOutcome(result); got != tc.outcome {
+				t.Errorf("resultOutcome() = %q, want %q", got, tc.outcome)
+			}
+			if result.Error == nil && !result.Executed {
+				t.Error("Executed = false, want true")
+			}
+		})
+	}
+
+	rows := executeQuery(context.Background(), db, "SELECT * FROM users")
+	if got := resultOutcome(rows); got != "Query returned 3 rows" {
+		t.Errorf("resultOutcome(select) = %q, want %q", got, "Query returned 3 rows")
+	}
+}
+
 // TestEscapeSQLString tests SQL string escaping
 func TestEscapeSQLString(t *testing.T) {
 	tests := []struct {
@@ -328,7 +808,7 @@ func TestIntegrationQueryAndFormat(t *testing.T) {
 	defer func() { _ = db.Close() }()
 
 	// Execute query
-	result := executeQuery(db, "SELECT id, name, salary FROM users WHERE id = 2")
+	result := executeQuery(context.Background(), db, "SELECT id, name, salary FROM users WHERE id = 2")
 	if result.Error != nil {
 		t.Fatalf("Query failed: %v", result.Error)
 	}
@@ -355,7 +835,7 @@ func TestExecuteQueryWithMultilineData(t *testing.T) {
 	db := setupTestDB(t)
 	defer func() { _ = db.Close() }()
 
-	result := executeQuery(db, "SELECT notes FROM users WHERE id = 3")
+	result := executeQuery(context.Background(), db, "SELECT notes FROM users WHERE id = 3")
 	if result.Error != nil {
 		t.Fatalf("Query failed: %v", result.Error)
 	}
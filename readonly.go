@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// readOnlyError returns why a read-only connection refuses stmt, or nil if
+// it may run. Reads are allowed, and so are session statements that can't
+// write (SET, USE and transaction control) unless they'd make the session
+// writable again.
+func readOnlyError(stmt string) error {
+	words := topLevelWords(stmt)
+	if len(words) == 0 {
+		return nil
+	}
+	text := strings.Join(words, " ")
+	unlocks := strings.Contains(text, "READ WRITE") || strings.Contains(text, "READ_ONLY") || strings.Contains(text, "QUERY_ONLY")
+	switch words[0] {
+	case "SET", "USE", "BEGIN", "START", "COMMIT", "END", "ROLLBACK", "ABORT", "SAVEPOINT", "RELEASE":
+		if !unlocks {
+			return nil
+		}
+	case "PRAGMA":
+		// PRAGMA reads, unless it's assigning a setting
+		if !unlocks && !strings.Contains(text, "=") {
+			return nil
+		}
+	default:
+		if !writesData(stmt) {
+			return nil
+		}
+	}
+	return fmt.Errorf("read-only connection: %s isn't allowed", words[0])
+}
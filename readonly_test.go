@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+// TestReadOnlyError tests which statements a read-only connection refuses
+func TestReadOnlyError(t *testing.T) {
+	tests := []struct {
+		stmt    string
+		allowed bool
+	}{
+		{"SELECT * FROM users", true},
+		{"EXPLAIN SELECT * FROM users", true},
+		{"SHOW TABLES", true},
+		{"SET search_path TO reporting", true},
+		{"USE shop", true},
+		{"BEGIN", true},
+		{"PRAGMA table_info(users)", true},
+		{"INSERT INTO users (id) VALUES (1)", false},
+		{"update users set age = 1 where id = 1", false},
+		{"DROP TABLE users", false},
+		{"WITH gone AS (DELETE FROM users RETURNING *) SELECT * FROM gone", false},
+		{"SET default_transaction_read_only = off", false},
+		{"SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE", false},
+		{"START TRANSACTION READ WRITE", false},
+		{"PRAGMA query_only = 0", false},
+		{"PRAGMA journal_mode = WAL", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			if err := readOnlyError(tt.stmt); (err == nil) != tt.allowed {
+				t.Errorf("readOnlyError(%q) = %v, want allowed %v", tt.stmt, err, tt.allowed)
+			}
+		})
+	}
+}
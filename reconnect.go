@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lostConnectionMarkers are fragments of the errors drivers report when the
+// connection itself has gone, rather than the statement failing
+var lostConnectionMarkers = []string{
+	"bad connection",
+	"invalid connection",     // MySQL
+	"server has gone away",   // MySQL
+	"lost connection",        // MySQL
+	"conn closed",            // PostgreSQL (pgx)
+	"terminating connection", // PostgreSQL, e.g. after a restart
+	"connection reset",       // TCP
+	"broken pipe",            // TCP
+	"connection refused",     // TCP
+	"unexpected eof",         // the server hung up mid-reply
+	"use of closed network connection",
+}
+
+// isConnectionLost reports whether err means the database connection was
+// dropped, so the statement may succeed on a new one
+func isConnectionLost(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return slices.ContainsFunc(lostConnectionMarkers, func(marker string) bool {
+		return strings.Contains(msg, marker)
+	})
+}
+
+// lostStatement is a statement that failed because its connection dropped,
+// while asking whether to reconnect and run it again
+type lostStatement struct {
+	tab    *Tab
+	query  string
+	args   []any
+	err    error
+	txLost bool // it ran in a transaction, which went with the connection
+}
+
+// reconnectPromptText is the question shown in the status bar after a
+// statement's connection dropped
+func (m Model) reconnectPromptText() string {
+	p := m.reconnectPrompt
+	prefix := ""
+	if idx := slices.Index(m.tabs, p.tab); idx >= 0 && idx != m.activeTab {
+		prefix = m.tabDisplayName(idx) + ": "
+	}
+	if p.txLost || transactionCommand(p.query) != "" {
+		return fmt.Sprintf("%sConnection lost (%v), and the open transaction with it - r to reconnect, Esc to leave it", prefix, p.err)
+	}
+	return fmt.Sprintf("%sConnection lost (%v) - r to reconnect and run the statement again, Esc to leave it", prefix, p.err)
+}
+
+// reconnectLost opens a new connection for the tab whose statement failed
+// with a dropped connection, and runs the statement again. A statement that
+// ran in a transaction isn't, since the rest of the transaction was lost.
+// The DSN was decrypted when the tab connected, so the vault isn't needed.
+func (m *Model) reconnectLost(p *lostStatement) tea.Cmd {
+	tab := p.tab
+	if !slices.Contains(m.tabs, tab) {
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "Wait for the running query to finish"
+		return nil
+	}
+	if err := m.reopenTab(tab, tab.dsn); err != nil {
+		m.statusMessage = fmt.Sprintf("Reconnect failed: %v", err)
+		return nil
+	}
+	if p.txLost || transactionCommand(p.query) != "" {
+		m.statusMessage = "Reconnected - the transaction was rolled back by the server, so nothing was run again"
+		return nil
+	}
+	m.statusMessage = "Reconnected - running the statement again"
+	return m.startQuery(tab, p.query, p.args...)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+)
+
+// TestIsConnectionLost tests telling a dropped connection from a failed statement
+func TestIsConnectionLost(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("query: %w", io.ErrUnexpectedEOF), true},
+		{fmt.Errorf("write tcp: %w", syscall.EPIPE), true},
+		{errors.New("invalid connection"), true},
+		{errors.New("Error 2006 (HY000): MySQL server has gone away"), true},
+		{errors.New("FATAL: terminating connection due to administrator command (SQLSTATE 57P01)"), true},
+		{errors.New("conn closed"), true},
+		{errors.New("read tcp 127.0.0.1:5432: connection reset by peer"), true},
+		{errors.New(`ERROR: relation "nope" does not exist (SQLSTATE 42P01)`), false},
+		{errors.New("statement timed out after 30s"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isConnectionLost(tt.err); got != tt.want {
+			t.Errorf("isConnectionLost(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Marks for a row of a re-run's result, compared with the result before it
+const (
+	rowSame = iota
+	rowAdded
+	rowChanged
+)
+
+// ResultDiff is how a re-run query's result differs from its previous
+// result. Rows are matched by their key columns, or by their whole contents
+// when the result has no key, in which case a row that changed shows as one
+// removed and one added.
+type ResultDiff struct {
+	marks   []int    // per row of the new result: rowSame, rowAdded or rowChanged
+	cells   [][]bool // per changed row: which of its cells differ
+	removed [][]CellValue
+	added   int
+	changed int
+	partial bool // only the rows fetched so far were compared
+}
+
+// rowKey joins a row's values at indexes into a string to match rows by
+func rowKey(row []CellValue, indexes []int) string {
+	var b strings.Builder
+	for _, i := range indexes {
+		if row[i].IsNull {
+			b.WriteString("\x00N")
+		} else {
+			b.WriteString("\x00V" + row[i].Value)
+		}
+	}
+	return b.String()
+}
+
+// diffResults compares a query's new result with its previous one, matching
+// rows on the result columns at keyIndexes. ok is false if the columns
+// differ, so the rows can't be compared.
+func diffResults(old, new *QueryResult, keyIndexes []int) (diff *ResultDiff, ok bool) {
+	if !slices.Equal(old.Columns, new.Columns) {
+		return nil, false
+	}
+	all := make([]int, len(new.Columns))
+	for i := range all {
+		all[i] = i
+	}
+	if len(keyIndexes) == 0 {
+		keyIndexes = all
+	}
+
+	// Old rows by key; a list, since rows without a unique key can repeat
+	oldRows := make(map[string][]int)
+	for i, row := range old.Rows {
+		k := rowKey(row, keyIndexes)
+		oldRows[k] = append(oldRows[k], i)
+	}
+	matched := make([]bool, len(old.Rows))
+
+	diff = &ResultDiff{
+		marks:   make([]int, len(new.Rows)),
+		cells:   make([][]bool, len(new.Rows)),
+		partial: old.HasMoreRows() || new.HasMoreRows(),
+	}
+	for i, row := range new.Rows {
+		k := rowKey(row, keyIndexes)
+		candidates := oldRows[k]
+		if len(candidates) == 0 {
+			diff.marks[i] = rowAdded
+			diff.added++
+			continue
+		}
+		j := candidates[0]
+		oldRows[k] = candidates[1:]
+		matched[j] = true
+		if rowKey(row, all) == rowKey(old.Rows[j], all) {
+			continue
+		}
+		diff.marks[i] = rowChanged
+		diff.changed++
+		diff.cells[i] = make([]bool, len(row))
+		for c := range row {
+			diff.cells[i][c] = row[c] != old.Rows[j][c]
+		}
+	}
+	for j, row := range old.Rows {
+		if !matched[j] {
+			diff.removed = append(diff.removed, row)
+		}
+	}
+	return diff, true
+}
+
+// mark returns the mark of row i of the new result
+func (d *ResultDiff) mark(i int) int {
+	if d == nil || i >= len(d.marks) {
+		return rowSame // e.g. a row fetched later with Alt+L
+	}
+	return d.marks[i]
+}
+
+// summary describes the differences for the status bar
+func (d *ResultDiff) summary() string {
+	if d.added == 0 && d.changed == 0 && len(d.removed) == 0 {
+		s := "Re-run: no differences"
+		if d.partial {
+			s += " in the rows fetched"
+		}
+		return s
+	}
+	s := fmt.Sprintf("Re-run: %d added, %d changed, %d removed", d.added, d.changed, len(d.removed))
+	if d.partial {
+		s += " (comparing only the rows fetched)"
+	}
+	return s
+}
+
+// rerunDiff runs the shown result's query again and, once it finishes,
+// marks the rows that were added, changed or removed since
+func (m *Model) rerunDiff() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.lastQuery == "" {
+		m.statusMessage = "No result to compare with"
+		return nil
+	}
+	if tab.result.Error != nil || tab.result.Executed {
+		m.statusMessage = "Re-run and compare works on a query that returns rows"
+		return nil
+	}
+	if writesData(tab.lastQuery) {
+		m.statusMessage = "Only a read is re-run to compare; this statement writes"
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+	tab.diffBase = tab.result
+	m.statusMessage = "Re-running to compare..."
+	return m.startQuery(tab, tab.lastQuery, tab.lastArgs...)
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+// TestDiffResults tests marking the rows a re-run added, changed and removed
+func TestDiffResults(t *testing.T) {
+	row := func(values ...string) []CellValue {
+		var cells []CellValue
+		for _, v := range values {
+			cells = append(cells, CellValue{Value: v})
+		}
+		return cells
+	}
+	old := &QueryResult{
+		Columns: []string{"id", "name"},
+		Rows:    [][]CellValue{row("1", "Ann"), row("2", "Bob"), row("3", "Cy")},
+	}
+	updated := &QueryResult{
+		Columns: []string{"id", "name"},
+		Rows:    [][]CellValue{row("1", "Ann"), row("2", "Rob"), row("4", "Di")},
+	}
+
+	tests := []struct {
+		name        string
+		keys        []int
+		wantMarks   []int
+		wantAdded   int
+		wantChanged int
+		wantRemoved int
+	}{
+		{"by key", []int{0}, []int{rowSame, rowChanged, rowAdded}, 1, 1, 1},
+		{"by contents", nil, []int{rowSame, rowAdded, rowAdded}, 2, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, ok := diffResults(old, updated, tt.keys)
+			if !ok {
+				t.Fatal("diffResults() ok = false, want true")
+			}
+			for i, want := range tt.wantMarks {
+				if got := diff.mark(i); got != want {
+					t.Errorf("mark(%d) = %d, want %d", i, got, want)
+				}
+			}
+			if diff.added != tt.wantAdded || diff.changed != tt.wantChanged || len(diff.removed) != tt.wantRemoved {
+				t.Errorf("diffResults() = %d added, %d changed, %d removed, want %d, %d, %d",
+					diff.added, diff.changed, len(diff.removed), tt.wantAdded, tt.wantChanged, tt.wantRemoved)
+			}
+		})
+	}
+
+	// Only the changed value is marked
+	diff, _ := diffResults(old, updated, []int{0})
+	if cells := diff.cells[1]; cells[0] || !cells[1] {
+		t.Errorf("changed cells = %v, want [false true]", cells)
+	}
+
+	// A NULL differs from an empty string
+	withNull := &QueryResult{Columns: []string{"id", "name"}, Rows: [][]CellValue{{{Value: "1"}, {IsNull: true}}}}
+	withEmpty := &QueryResult{Columns: []string{"id", "name"}, Rows: [][]CellValue{{{Value: "1"}, {Value: ""}}}}
+	if diff, _ := diffResults(withNull, withEmpty, []int{0}); diff.changed != 1 {
+		t.Errorf("NULL to empty string: %d changed, want 1", diff.changed)
+	}
+
+	if _, ok := diffResults(old, &QueryResult{Columns: []string{"id"}}, nil); ok {
+		t.Error("diffResults() with different columns should not be ok")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// resultFilter narrows the rows shown to those containing some text, or
+// with a column equal to a value when it's typed as column=value
+type resultFilter struct {
+	text   string // as typed
+	column int    // the column compared, or -1 to look in every column
+	value  string // lowercased
+}
+
+// parseResultFilter reads the text typed into the filter prompt, or
+// returns nil for no filter. Text before an = is only a column if the
+// result has one by that name.
+func parseResultFilter(text string, columns []string) *resultFilter {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	f := &resultFilter{text: text, column: -1, value: strings.ToLower(text)}
+	if name, value, ok := strings.Cut(text, "="); ok {
+		for i, col := range columns {
+			if strings.EqualFold(strings.TrimSpace(name), col) {
+				f.column, f.value = i, strings.ToLower(strings.TrimSpace(value))
+				break
+			}
+		}
+	}
+	return f
+}
+
+// matches reports whether row passes the filter, ignoring case. A column
+// compared with NULL matches NULLs; text never does.
+func (f *resultFilter) matches(row []CellValue) bool {
+	if f.column >= 0 {
+		cell := row[f.column]
+		if f.value == "null" {
+			return cell.IsNull
+		}
+		return !cell.IsNull && strings.ToLower(cell.Value) == f.value
+	}
+	for _, cell := range row {
+		if !cell.IsNull && strings.Contains(strings.ToLower(cell.Value), f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// openFilter asks what to narrow the shown result's rows to
+func (m *Model) openFilter() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.result.Error != nil || len(tab.result.Columns) == 0 {
+		return
+	}
+	ti := textinput.New()
+	ti.Prompt = "Filter: "
+	ti.Placeholder = "text, or column=value"
+	ti.Width = 40
+	if tab.result.filter != nil {
+		ti.SetValue(tab.result.filter.text)
+	}
+	ti.Focus()
+	m.filterPrompt = &ti
+	m.focus = focusFilter
+	m.statusMessage = ""
+}
+
+// setFilter filters the shown result's rows by text, keeping the selection
+// on the same row if it still matches
+func (t *Tab) setFilter(text string) {
+	if t.result == nil {
+		return
+	}
+	var selected []CellValue
+	if t.selectedRow < len(t.result.Rows) {
+		selected = t.result.Rows[t.selectedRow]
+	}
+	t.result.filter = parseResultFilter(text, t.result.Columns)
+	t.result.applyView()
+	t.keepSelectedRow(selected)
+}
+
+// filterStatus is the status bar's note of how many rows the filter shows
+func (r *QueryResult) filterStatus() string {
+	total := fmt.Sprintf("%d", len(r.fetched))
+	if r.HasMoreRows() {
+		total += " fetched"
+	}
+	return fmt.Sprintf("Filter %q: %d of %s rows", r.filter.text, len(r.Rows), total)
+}
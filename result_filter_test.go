@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestResultFilter tests matching rows against text typed into the filter
+// prompt
+func TestResultFilter(t *testing.T) {
+	columns := []string{"id", "name", "email"}
+	alice := []CellValue{{Value: "1"}, {Value: "Alice"}, {Value: "alice@example.com"}}
+	bob := []CellValue{{Value: "2"}, {Value: "Bob"}, {IsNull: true}}
+	tests := []struct {
+		name string
+		text string
+		want []bool // matches alice, bob
+	}{
+		{"text in any column", "ali", []bool{true, false}},
+		{"ignores case", "BOB", []bool{false, true}},
+		{"column equals value", "name=alice", []bool{true, false}},
+		{"column name ignores case and spaces", " ID = 2", []bool{false, true}},
+		{"column is null", "email=null", []bool{false, true}},
+		{"not a column", "foo=bar", []bool{false, false}},
+		{"equals is text when not a column", "@example", []bool{true, false}},
+		{"null text never matches", "null", []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := parseResultFilter(tt.text, columns)
+			got := []bool{f.matches(alice), f.matches(bob)}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseResultFilter(%q).matches() = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+	if f := parseResultFilter("  ", columns); f != nil {
+		t.Errorf("parseResultFilter(blank) = %+v, want nil", f)
+	}
+}
+
+// TestApplyView tests sorting and filtering a result's rows together, and
+// rows fetched later joining them
+func TestApplyView(t *testing.T) {
+	row := func(id, name string) []CellValue { return []CellValue{{Value: id}, {Value: name}} }
+	ids := func(rows [][]CellValue) []string {
+		var out []string
+		for _, r := range rows {
+			out = append(out, r[0].Value)
+		}
+		return out
+	}
+	r := &QueryResult{Columns: []string{"id", "name"}, Rows: [][]CellValue{row("3", "ann"), row("1", "bob"), row("2", "anna")}}
+
+	r.filter = parseResultFilter("ann", r.Columns)
+	r.applyView()
+	if got := ids(r.Rows); !slices.Equal(got, []string{"3", "2"}) {
+		t.Errorf("filtered rows = %v", got)
+	}
+	r.sort = &resultSort{column: 0}
+	r.applyView()
+	if got := ids(r.Rows); !slices.Equal(got, []string{"2", "3"}) {
+		t.Errorf("filtered and sorted rows = %v", got)
+	}
+	r.addFetchedRows([][]CellValue{row("0", "joanne"), row("4", "carl")})
+	if got := ids(r.Rows); !slices.Equal(got, []string{"0", "2", "3"}) {
+		t.Errorf("rows after fetching more = %v", got)
+	}
+	r.sort, r.filter = nil, nil
+	r.applyView()
+	if got := ids(r.Rows); !slices.Equal(got, []string{"3", "1", "2", "0", "4"}) || r.fetched != nil {
+		t.Errorf("unfiltered rows = %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resultHistorySize is how many results each tab keeps to flip back to
+const resultHistorySize = 10
+
+// ResultSnapshot is a statement's result, kept so it can be shown again
+// without running the statement again
+type ResultSnapshot struct {
+	Query  string
+	Args   []any
+	Result *QueryResult
+	Meta   *QueryMeta
+	Diff   *ResultDiff // set for a re-run to compare
+}
+
+// pushResult records the tab's current result as the newest in its result
+// history, forgetting the oldest beyond resultHistorySize. A script's
+// results are all kept, however many statements it has.
+func (t *Tab) pushResult() {
+	if t.script != nil {
+		t.scriptResults++
+	} else {
+		t.scriptResults = 0
+	}
+	t.results = append(t.results, ResultSnapshot{t.lastQuery, t.lastArgs, t.result, t.queryMeta, t.diff})
+	if keep := max(resultHistorySize, t.scriptResults); len(t.results) > keep {
+		t.results = t.results[len(t.results)-keep:]
+	}
+	t.resultIndex = len(t.results) - 1
+}
+
+// refreshResult replaces the shown result's snapshot after the result has
+// been fetched again, e.g. once a row edit is applied
+func (t *Tab) refreshResult() {
+	if t.resultIndex < len(t.results) {
+		t.results[t.resultIndex] = ResultSnapshot{t.lastQuery, t.lastArgs, t.result, t.queryMeta, t.diff}
+	}
+}
+
+// replaceLatestResult replaces the newest result in the result history with
+// the tab's current result, and shows it. A watched query's runs after the
+// first replace its result this way.
+func (t *Tab) replaceLatestResult() {
+	if len(t.results) == 0 {
+		t.pushResult()
+		return
+	}
+	t.resultIndex = len(t.results) - 1
+	t.refreshResult()
+}
+
+// clearResults forgets the tab's result and result history, which belong to
+// the connection they were run on
+func (t *Tab) clearResults() {
+	t.result = nil
+	t.queryMeta = nil
+	t.diff = nil
+	t.results = nil
+	t.resultIndex = 0
+	t.scriptResults = 0
+}
+
+// showResult shows the result at index i of the result history
+func (t *Tab) showResult(i int) {
+	snap := t.results[i]
+	t.resultIndex = i
+	t.lastQuery = snap.Query
+	t.lastArgs = snap.Args
+	t.result = snap.Result
+	t.queryMeta = snap.Meta
+	t.diff = snap.Diff
+	t.resultSearch = nil // its matches were for the other result
+	t.errorScroll = 0
+	t.firstColumn = 0
+	t.selectedColumn = 0
+	t.showSelectedColumn()
+	t.selectedRow = 0
+	t.currentPage = 0
+	t.totalPages = 1
+	if !snap.Result.Executed && snap.Result.Error == nil {
+		t.totalPages = t.pageCount(len(snap.Result.Rows))
+	}
+	t.detailView = nil
+}
+
+// flipResult shows the previous (dir < 0) or next (dir > 0) result in the
+// active tab's result history
+func (m *Model) flipResult(dir int) {
+	tab := m.activeTabPtr()
+	if tab == nil || len(tab.results) == 0 {
+		m.statusMessage = "No results yet"
+		return
+	}
+	i := tab.resultIndex + dir
+	if i < 0 || i >= len(tab.results) {
+		if dir < 0 {
+			m.statusMessage = "This is the oldest result kept"
+		} else {
+			m.statusMessage = "This is the latest result"
+		}
+		return
+	}
+	tab.showResult(i)
+	m.statusMessage = fmt.Sprintf("Result %d of %d: %s", i+1, len(tab.results), truncateString(strings.Join(strings.Fields(tab.lastQuery), " "), 60))
+}
+
+// scriptResultsStart returns the index in the result history of the last
+// script run's first result, or -1 unless that run left more than one
+// result and one of them is shown
+func (t *Tab) scriptResultsStart() int {
+	start := len(t.results) - t.scriptResults
+	if t.scriptResults < 2 || t.resultIndex < start {
+		return -1
+	}
+	return start
+}
+
+// showScriptResult shows the nth (from 1) result of the last script run,
+// picked with the number keys in the results view
+func (m *Model) showScriptResult(n int) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	start := tab.scriptResultsStart()
+	if start < 0 {
+		return
+	}
+	if n > tab.scriptResults {
+		m.statusMessage = fmt.Sprintf("The script left %d results", tab.scriptResults)
+		return
+	}
+	tab.showResult(start + n - 1)
+	m.statusMessage = fmt.Sprintf("Result %d of %d: %s", n, tab.scriptResults, truncateString(strings.Join(strings.Fields(tab.lastQuery), " "), 60))
+}
+
+// scriptResultLabel names a script's result in the strip above the
+// results: the statement's verb, and its row count or a mark if it failed
+func scriptResultLabel(snap ResultSnapshot) string {
+	verb := "?"
+	if fields := strings.Fields(skipLeadingComments(snap.Query, false)); len(fields) > 0 {
+		verb = strings.ToUpper(fields[0])
+	}
+	switch {
+	case snap.Result.Error != nil:
+		return verb + " ✗"
+	case snap.Result.Executed:
+		return verb
+	}
+	return fmt.Sprintf("%s (%d)", verb, len(snap.Result.Rows))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestResultHistory tests keeping recent results and flipping between them
+func TestResultHistory(t *testing.T) {
+	tab := &Tab{}
+	for i := range resultHistorySize + 2 {
+		tab.lastQuery = fmt.Sprintf("SELECT %d", i)
+		tab.result = &QueryResult{Rows: make([][]CellValue, i*defaultPageSize+1)}
+		tab.pushResult()
+	}
+	if len(tab.results) != resultHistorySize {
+		t.Fatalf("kept %d results, want %d", len(tab.results), resultHistorySize)
+	}
+	if got := tab.results[0].Query; got != "SELECT 2" {
+		t.Errorf("oldest kept result = %q, want SELECT 2", got)
+	}
+	if tab.resultIndex != resultHistorySize-1 {
+		t.Errorf("resultIndex = %d, want the newest", tab.resultIndex)
+	}
+
+	tab.selectedRow = 5
+	tab.resultSearch = newResultSearch("x", 0, 0)
+	tab.showResult(1)
+	if tab.lastQuery != "SELECT 3" || tab.selectedRow != 0 || tab.totalPages != 4 {
+		t.Errorf("showResult(1): query %q, row %d, pages %d; want SELECT 3, 0, 4", tab.lastQuery, tab.selectedRow, tab.totalPages)
+	}
+	if tab.resultSearch != nil {
+		t.Error("showResult(1) kept the search of the result shown before")
+	}
+
+	// Refreshing replaces the shown snapshot rather than adding one
+	tab.result = &QueryResult{}
+	tab.refreshResult()
+	if len(tab.results) != resultHistorySize || tab.results[1].Result != tab.result {
+		t.Errorf("refreshResult() didn't replace the shown result")
+	}
+
+	tab.clearResults()
+	if tab.result != nil || len(tab.results) != 0 {
+		t.Errorf("clearResults() left results behind")
+	}
+}
+
+// TestScriptResults tests keeping every result of a script run
+func TestScriptResults(t *testing.T) {
+	tab := &Tab{}
+	tab.lastQuery, tab.result = "SELECT 0", &QueryResult{}
+	tab.pushResult()
+
+	tab.script = &scriptRun{}
+	for i := range resultHistorySize + 2 {
+		tab.lastQuery = fmt.Sprintf("SELECT %d", i+1)
+		tab.result = &QueryResult{}
+		tab.pushResult()
+	}
+	tab.script = nil
+	if tab.scriptResults != resultHistorySize+2 || len(tab.results) != resultHistorySize+2 {
+		t.Fatalf("kept %d results, %d from the script; want all %d", len(tab.results), tab.scriptResults, resultHistorySize+2)
+	}
+	if start := tab.scriptResultsStart(); start != 0 {
+		t.Errorf("scriptResultsStart() = %d, want 0", start)
+	}
+
+	// A statement run on its own ends the strip
+	tab.lastQuery, tab.result = "SELECT 99", &QueryResult{}
+	tab.pushResult()
+	if tab.scriptResultsStart() != -1 || len(tab.results) != resultHistorySize {
+		t.Errorf("after a single statement: start %d with %d results; want -1 with %d", tab.scriptResultsStart(), len(tab.results), resultHistorySize)
+	}
+}
+
+// TestScriptResultLabel tests naming a script's results in the strip
+func TestScriptResultLabel(t *testing.T) {
+	tests := []struct {
+		snap ResultSnapshot
+		want string
+	}{
+		{ResultSnapshot{Query: "select * from users", Result: &QueryResult{Rows: make([][]CellValue, 3)}}, "SELECT (3)"},
+		{ResultSnapshot{Query: "-- fix\nUPDATE users SET age = 1", Result: &QueryResult{Executed: true, RowsAffected: 1}}, "UPDATE"},
+		{ResultSnapshot{Query: "DELETE FROM nope", Result: &QueryResult{Error: fmt.Errorf("no such table")}}, "DELETE ✗"},
+	}
+
+	for _, tt := range tests {
+		if got := scriptResultLabel(tt.snap); got != tt.want {
+			t.Errorf("scriptResultLabel(%q) = %q, want %q", tt.snap.Query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// ResultSearch is a regular expression searched for across the cells of a
+// tab's result. The matching cells are highlighted until the search is
+// cleared, and n/N jump between them.
+type ResultSearch struct {
+	input   textinput.Model
+	re      *regexp.Regexp // nil while the input is empty or invalid
+	err     error          // why the input doesn't compile
+	row     int            // the current match, when there is one
+	col     int
+	current int // index of the current match, or -1
+	total   int
+
+	// The selected cell when the prompt opened, where typing searches from
+	originRow, originCol int
+}
+
+// newResultSearch starts a search of the results from the cell at row,
+// col, editing pattern
+func newResultSearch(pattern string, row, col int) *ResultSearch {
+	ti := textinput.New()
+	ti.Placeholder = "regular expression, (?i) to ignore case"
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Prompt = "Search results: "
+	ti.SetValue(pattern)
+	ti.Focus()
+	return &ResultSearch{input: ti, current: -1, originRow: row, originCol: col}
+}
+
+// matches reports whether a cell matches the search. NULLs never do.
+func (s *ResultSearch) matches(cell CellValue) bool {
+	return s != nil && s.re != nil && !cell.IsNull && s.re.MatchString(cell.Value)
+}
+
+// compile reads the pattern typed so far
+func (s *ResultSearch) compile() {
+	s.re, s.err = nil, nil
+	if s.input.Value() == "" {
+		return
+	}
+	s.re, s.err = regexp.Compile(s.input.Value())
+}
+
+// find moves to the next (dir 1) or previous (dir -1) matching cell of
+// rows from the one at row, col, going across each row and then down, and
+// wrapping around. With from true, the cell at row, col counts too. Cells
+// in hidden columns are skipped.
+func (s *ResultSearch) find(rows [][]CellValue, hidden []bool, row, col, dir int, from bool) {
+	s.current, s.total = -1, 0
+	if s.re == nil || len(rows) == 0 {
+		return
+	}
+	cols := len(rows[0])
+	cells := len(rows) * cols
+	start := min(max(row, 0), len(rows)-1)*cols + min(max(col, 0), cols-1)
+	if !from {
+		start += dir
+	}
+	matches := func(at int) bool {
+		c := at % cols
+		return !(c < len(hidden) && hidden[c]) && s.matches(rows[at/cols][c])
+	}
+	found := -1
+	for i := range cells {
+		at := ((start+i*dir)%cells + cells) % cells
+		if matches(at) {
+			found = at
+			break
+		}
+	}
+	if found < 0 {
+		return
+	}
+	s.row, s.col = found/cols, found%cols
+	for i := range cells {
+		if matches(i) {
+			s.total++
+			if i <= found {
+				s.current++
+			}
+		}
+	}
+}
+
+// status describes the search for the status bar
+func (s *ResultSearch) status() string {
+	switch {
+	case s.err != nil:
+		return "Invalid regular expression: " + s.err.Error()
+	case s.re == nil:
+		return ""
+	case s.current < 0:
+		return "No matching cells"
+	}
+	return fmt.Sprintf("Match %d of %d", s.current+1, s.total)
+}
+
+// openResultSearch asks for a regular expression to search the shown
+// result's cells for
+func (m *Model) openResultSearch() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.result.Error != nil || len(tab.result.Columns) == 0 {
+		return
+	}
+	pattern := ""
+	if tab.resultSearch != nil {
+		pattern = tab.resultSearch.input.Value()
+	}
+	tab.resultSearch = newResultSearch(pattern, tab.selectedRow, tab.selectedColumn)
+	tab.resultSearch.compile()
+	m.focus = focusResultSearch
+	m.statusMessage = ""
+}
+
+// showSearchMatch selects the current match's row and column, scrolling
+// the table to it
+func (m *Model) showSearchMatch(tab *Tab) {
+	s := tab.resultSearch
+	if s == nil || s.current < 0 {
+		return
+	}
+	tab.selectedRow = s.row
+	tab.currentPage = s.row / tab.rowsPerPage()
+	tab.selectedColumn = s.col
+	tab.scrollToSelectedColumn(m.mainWidth())
+}
+
+// stepResultSearch jumps to the next (dir 1) or previous (dir -1) match
+func (m *Model) stepResultSearch(dir int) {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil {
+		return
+	}
+	s := tab.resultSearch
+	s.find(tab.result.Rows, tab.result.hidden, tab.selectedRow, tab.selectedColumn, dir, false)
+	m.showSearchMatch(tab)
+	m.statusMessage = s.status()
+}
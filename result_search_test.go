@@ -0,0 +1,50 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestResultSearchFind tests stepping between the cells matching a results
+// search, across each row and then down, wrapping around and skipping
+// hidden columns
+func TestResultSearchFind(t *testing.T) {
+	rows := [][]CellValue{
+		{{Value: "1"}, {Value: "apple"}, {Value: "banana"}},
+		{{Value: "2"}, {IsNull: true}, {Value: "cherry"}},
+		{{Value: "3"}, {Value: "grape"}, {Value: "pineapple"}},
+	}
+	tests := []struct {
+		name     string
+		pattern  string
+		hidden   []bool
+		row, col int
+		dir      int
+		from     bool
+		wantRow  int
+		wantCol  int
+		wantCur  int
+		wantTot  int
+	}{
+		{"from the start", "apple", nil, 0, 0, 1, true, 0, 1, 0, 2},
+		{"counts the cell it starts at", "apple", nil, 0, 1, 1, true, 0, 1, 0, 2},
+		{"next skips the current cell", "apple", nil, 0, 1, 1, false, 2, 2, 1, 2},
+		{"next wraps around", "apple", nil, 2, 2, 1, false, 0, 1, 0, 2},
+		{"previous wraps around", "apple", nil, 0, 1, -1, false, 2, 2, 1, 2},
+		{"regular expression", "^[bc]", nil, 0, 0, 1, true, 0, 2, 0, 2},
+		{"hidden columns skipped", "apple", []bool{false, true}, 0, 0, 1, true, 2, 2, 0, 1},
+		{"no match", "kiwi", nil, 0, 0, 1, true, 0, 0, -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &ResultSearch{re: regexp.MustCompile(tt.pattern)}
+			s.find(rows, tt.hidden, tt.row, tt.col, tt.dir, tt.from)
+			if s.current != tt.wantCur || s.total != tt.wantTot {
+				t.Fatalf("find() = match %d of %d, want %d of %d", s.current, s.total, tt.wantCur, tt.wantTot)
+			}
+			if s.current >= 0 && (s.row != tt.wantRow || s.col != tt.wantCol) {
+				t.Errorf("find() = cell %d,%d, want %d,%d", s.row, s.col, tt.wantRow, tt.wantCol)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultSort is how a result's fetched rows are sorted in memory
+type resultSort struct {
+	column int
+	desc   bool
+}
+
+// compareCells orders two values of a column: numerically when both are
+// numbers, otherwise as text. NULLs sort after every value.
+func compareCells(a, b CellValue) int {
+	switch {
+	case a.IsNull || b.IsNull:
+		if a.IsNull == b.IsNull {
+			return 0
+		}
+		if a.IsNull {
+			return 1
+		}
+		return -1
+	}
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a.Value), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(b.Value), 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(fa, fb)
+	}
+	return strings.Compare(a.Value, b.Value)
+}
+
+// sortRows returns the order of rows sorted by column, stable so rows with
+// equal values keep their order. NULLs stay last either way.
+func sortRows(rows [][]CellValue, column int, desc bool) []int {
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		a, b := rows[i][column], rows[j][column]
+		c := compareCells(a, b)
+		if desc && !a.IsNull && !b.IsNull {
+			c = -c
+		}
+		return c
+	})
+	return order
+}
+
+// applyView sets the rows shown from those fetched: sorted by the result's
+// sort and narrowed to those matching its filter, or all of them in the
+// order the database sent them when there's neither
+func (r *QueryResult) applyView() {
+	if r.sort == nil && r.filter == nil {
+		if r.fetched != nil {
+			r.Rows, r.fetched = r.fetched, nil
+		}
+		return
+	}
+	if r.fetched == nil {
+		r.fetched = r.Rows
+	}
+	rows := r.fetched
+	if r.sort != nil {
+		order := sortRows(rows, r.sort.column, r.sort.desc)
+		rows = make([][]CellValue, len(order))
+		for to, from := range order {
+			rows[to] = r.fetched[from]
+		}
+	}
+	if r.filter != nil {
+		rows = slices.DeleteFunc(slices.Clone(rows), func(row []CellValue) bool {
+			return !r.filter.matches(row)
+		})
+	}
+	r.Rows = rows
+}
+
+// addFetchedRows adds rows fetched later to the result, sorted and
+// filtered in with the others
+func (r *QueryResult) addFetchedRows(rows [][]CellValue) {
+	if r.fetched == nil {
+		r.Rows = append(r.Rows, rows...)
+		return
+	}
+	r.fetched = append(r.fetched, rows...)
+	r.applyView()
+}
+
+// keepSelectedRow moves the tab's selection to where row is after the rows
+// shown changed, or to the first row if it's gone. The diff's marks are by
+// row position, so they're dropped.
+func (t *Tab) keepSelectedRow(row []CellValue) {
+	t.selectedRow = max(rowPosition(t.result.Rows, row), 0)
+	t.currentPage = t.selectedRow / t.rowsPerPage()
+	t.totalPages = t.pageCount(len(t.result.Rows))
+	t.diff = nil
+	if t.resultIndex < len(t.results) && t.results[t.resultIndex].Result == t.result {
+		t.results[t.resultIndex].Diff = nil
+	}
+}
+
+// rowPosition returns where row is in rows, matching the row itself rather
+// than its values, or -1
+func rowPosition(rows [][]CellValue, row []CellValue) int {
+	return slices.IndexFunc(rows, func(r []CellValue) bool {
+		return len(r) > 0 && len(row) > 0 && &r[0] == &row[0]
+	})
+}
+
+// sortArrow returns the arrow shown after the name of the column the
+// tab's result is sorted by, in memory or in the database, or "" for the
+// other columns
+func (t *Tab) sortArrow(column int) string {
+	if s := t.result.sort; s != nil {
+		switch {
+		case s.column != column:
+			return ""
+		case s.desc:
+			return "▼"
+		}
+		return "▲"
+	}
+	if _, order, ok := unwrapSortedQuery(t.lastQuery); ok {
+		switch order {
+		case orderBy(t.result.Columns[column], t.dbType, false):
+			return "▲"
+		case orderBy(t.result.Columns[column], t.dbType, true):
+			return "▼"
+		}
+	}
+	return ""
+}
+
+// sortByColumn sorts the active tab's fetched rows by the selected column,
+// each press going from ascending to descending and back to the order the
+// database sent them in. The selected row stays on the same row.
+func (m *Model) sortByColumn() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.result.Error != nil || len(tab.result.Columns) == 0 {
+		return
+	}
+	result := tab.result
+	column := min(tab.selectedColumn, len(result.Columns)-1)
+	name := result.Columns[column]
+
+	var selected []CellValue
+	if tab.selectedRow < len(result.Rows) {
+		selected = result.Rows[tab.selectedRow]
+	}
+
+	switch s := result.sort; {
+	case s == nil || s.column != column:
+		result.sort = &resultSort{column: column}
+		m.statusMessage = fmt.Sprintf("Sorted by %s, ascending", name)
+	case !s.desc:
+		s.desc = true
+		m.statusMessage = fmt.Sprintf("Sorted by %s, descending", name)
+	default:
+		result.sort = nil
+		m.statusMessage = "Back in the order the database sent"
+	}
+	result.applyView()
+	if result.HasMoreRows() {
+		m.statusMessage += " (only the rows fetched; S sorts in the database)"
+	}
+	tab.keepSelectedRow(selected)
+}
+
+// orderBy returns what to ORDER BY to sort by column
+func orderBy(column, dbType string, desc bool) string {
+	q := quoteIdentifier(dbType)
+	order := q + strings.ReplaceAll(column, q, q+q) + q
+	if desc {
+		order += " DESC"
+	}
+	return order
+}
+
+// sortedQuery wraps query so the database returns its rows ordered by
+// column
+func sortedQuery(query, column, dbType string, desc bool) string {
+	return sortedQueryPrefix + strings.TrimRight(strings.TrimSpace(query), ";") + sortedQueryInfix + orderBy(column, dbType, desc)
+}
+
+// The parts of a query sortedQuery wrapped, so sorting it again sorts the
+// original query rather than wrapping it twice
+const (
+	sortedQueryPrefix = "SELECT * FROM (\n"
+	sortedQueryInfix  = "\n) AS sorted ORDER BY "
+)
+
+// unwrapSortedQuery returns the query sortedQuery wrapped and the ORDER BY
+// it added, or ok false if query isn't one sortedQuery made
+func unwrapSortedQuery(query string) (inner, order string, ok bool) {
+	rest, ok := strings.CutPrefix(query, sortedQueryPrefix)
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, sortedQueryInfix)
+	if i < 0 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+len(sortedQueryInfix):], true
+}
+
+// sortInDatabase runs the shown result's query again ordered by the
+// selected column, so every row is sorted and not just those fetched. A
+// second press on the same column sorts it descending.
+func (m *Model) sortInDatabase() tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.lastQuery == "" || len(tab.result.Columns) == 0 {
+		m.statusMessage = "No result to sort"
+		return nil
+	}
+	if tab.result.Error != nil || tab.result.Executed {
+		m.statusMessage = "Sorting works on a query that returns rows"
+		return nil
+	}
+	if writesData(tab.lastQuery) {
+		m.statusMessage = "Only a read is re-run to sort; this statement writes"
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+	name := tab.result.Columns[min(tab.selectedColumn, len(tab.result.Columns)-1)]
+	query, desc := tab.lastQuery, false
+	if inner, order, ok := unwrapSortedQuery(query); ok {
+		query = inner
+		desc = order == orderBy(name, tab.dbType, false)
+	}
+	direction := "ascending"
+	if desc {
+		direction = "descending"
+	}
+	m.statusMessage = fmt.Sprintf("Sorting by %s, %s, in the database...", name, direction)
+	return m.startQuery(tab, sortedQuery(query, name, tab.dbType, desc), tab.lastArgs...)
+}
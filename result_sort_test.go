@@ -0,0 +1,68 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestSortRows tests sorting rows by a column, numbers as numbers and NULLs
+// last in either direction
+func TestSortRows(t *testing.T) {
+	v := func(s string) []CellValue { return []CellValue{{Value: s}} }
+	null := []CellValue{{IsNull: true}}
+	tests := []struct {
+		name string
+		rows [][]CellValue
+		desc bool
+		want []int
+	}{
+		{"numbers", [][]CellValue{v("10"), v("9"), v("-1.5")}, false, []int{2, 1, 0}},
+		{"numbers descending", [][]CellValue{v("10"), v("9"), v("100")}, true, []int{2, 0, 1}},
+		{"text", [][]CellValue{v("b"), v("a"), v("B")}, false, []int{2, 1, 0}},
+		{"nulls last", [][]CellValue{null, v("2"), v("1")}, false, []int{2, 1, 0}},
+		{"nulls last descending", [][]CellValue{null, v("1"), v("2")}, true, []int{2, 1, 0}},
+		{"stable", [][]CellValue{v("x"), v("a"), v("x")}, false, []int{1, 0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortRows(tt.rows, 0, tt.desc); !slices.Equal(got, tt.want) {
+				t.Errorf("sortRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSortedQuery tests wrapping a query to sort it in the database, and
+// unwrapping it to sort it again
+func TestSortedQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		column string
+		dbType string
+		desc   bool
+		want   string
+	}{
+		{"postgres", "SELECT * FROM users;", "name", "postgres", false,
+			"SELECT * FROM (\nSELECT * FROM users\n) AS sorted ORDER BY \"name\""},
+		{"mysql descending", "SELECT * FROM users", "name", "mysql", true,
+			"SELECT * FROM (\nSELECT * FROM users\n) AS sorted ORDER BY `name` DESC"},
+		{"quote in name", "SELECT 1 AS \"a\"\"b\"", "a\"b", "sqlite", false,
+			"SELECT * FROM (\nSELECT 1 AS \"a\"\"b\"\n) AS sorted ORDER BY \"a\"\"b\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sortedQuery(tt.query, tt.column, tt.dbType, tt.desc)
+			if got != tt.want {
+				t.Fatalf("sortedQuery() = %q, want %q", got, tt.want)
+			}
+			inner, order, ok := unwrapSortedQuery(got)
+			if !ok || sortedQuery(inner, tt.column, tt.dbType, tt.desc) != got || order != orderBy(tt.column, tt.dbType, tt.desc) {
+				t.Errorf("unwrapSortedQuery() = %q, %q, %v", inner, order, ok)
+			}
+		})
+	}
+	if _, _, ok := unwrapSortedQuery("SELECT * FROM users"); ok {
+		t.Error("unwrapSortedQuery() unwrapped a query it didn't wrap")
+	}
+}
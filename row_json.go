@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rowJSON returns row i of the result as an indented JSON object, keyed by
+// column name in the columns' order. Columns hidden from the table are left
+// out. A name that's already a key gets a _2, _3... suffix, so a join's
+// columns of the same name are all kept.
+func (r *QueryResult) rowJSON(i int) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	seen := make(map[string]int)
+	first := true
+	for c, col := range r.Columns {
+		if r.isHidden(c) {
+			continue
+		}
+		seen[col]++
+		key := col
+		if n := seen[col]; n > 1 {
+			key = fmt.Sprintf("%s_%d", col, n)
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.WriteString(jsonValue(r.Rows[i][c], r.ColumnTypeAt(c)))
+	}
+	b.WriteByte('}')
+
+	var out bytes.Buffer
+	_ = json.Indent(&out, b.Bytes(), "", "  ") // valid, as it's built from marshalled parts
+	return out.String()
+}
+
+// jsonValue returns a cell as JSON: null for NULL, a number or boolean when
+// the column holds them and the value reads as one, and otherwise a string
+func jsonValue(cell CellValue, ct ColumnType) string {
+	switch {
+	case cell.IsNull:
+		return "null"
+	case ct.IsNumeric():
+		v := strings.TrimSpace(cell.Value)
+		if _, err := strconv.ParseFloat(v, 64); err == nil && json.Valid([]byte(v)) {
+			return v // as sent, so no precision is lost
+		}
+	case ct.IsBoolean():
+		if v, err := strconv.ParseBool(cell.Value); err == nil {
+			return strconv.FormatBool(v)
+		}
+	}
+	s, _ := json.Marshal(cell.Value)
+	return string(s)
+}
+
+// copyRowJSON copies the selected row of the results to the clipboard as a
+// JSON object
+func (m *Model) copyRowJSON() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.selectedRow >= len(tab.result.Rows) {
+		return
+	}
+	text := tab.result.rowJSON(tab.selectedRow)
+	m.clipboard = text
+	if !copyToClipboard(text) {
+		m.statusMessage = fmt.Sprintf("Copied row %d as JSON (system clipboard unavailable)", tab.selectedRow+1)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Copied row %d as JSON", tab.selectedRow+1)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// TestRowJSON tests copying a row as a JSON object
+func TestRowJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *QueryResult
+		want   string
+	}{
+		{
+			name: "types and NULL",
+			result: &QueryResult{
+				Columns:     []string{"id", "price", "active", "name", "note"},
+				ColumnTypes: []ColumnType{ColTypeNumeric, ColTypeNumeric, ColTypeBoolean, ColTypeText, ColTypeText},
+				Rows:        [][]CellValue{{{Value: "7"}, {Value: "12.50"}, {Value: "t"}, {Value: `say "hi"`}, {IsNull: true}}},
+			},
+			want: "{\n  \"id\": 7,\n  \"price\": 12.50,\n  \"active\": true,\n  \"name\": \"say \\\"hi\\\"\",\n  \"note\": null\n}",
+		},
+		{
+			name: "not a number in a numeric column",
+			result: &QueryResult{
+				Columns:     []string{"n"},
+				ColumnTypes: []ColumnType{ColTypeNumeric},
+				Rows:        [][]CellValue{{{Value: "NaN"}}},
+			},
+			want: "{\n  \"n\": \"NaN\"\n}",
+		},
+		{
+			name: "repeated names and hidden columns",
+			result: &QueryResult{
+				Columns: []string{"id", "secret", "id"},
+				Rows:    [][]CellValue{{{Value: "1"}, {Value: "x"}, {Value: "2"}}},
+				hidden:  []bool{false, true},
+			},
+			want: "{\n  \"id\": \"1\",\n  \"id_2\": \"2\"\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.rowJSON(0); got != tt.want {
+				t.Errorf("rowJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultRowLimit is how many rows a SELECT without a LIMIT fetches at a
+// time, unless row_limit is set
+const defaultRowLimit = 500
+
+// rowStream is a query's result set, left open after its first rows were
+// fetched so the rest can be fetched on demand
+type rowStream struct {
+	rows     *sql.Rows
+	cancel   context.CancelFunc // releases the rows' context
+	fetching bool               // a fetch is in flight; only touched by Update
+}
+
+// fetch scans up to n more rows, or all of them if n is 0. done reports
+// whether the result set is exhausted.
+func (s *rowStream) fetch(n int) (rows [][]CellValue, done bool, err error) {
+	columns, err := s.rows.Columns()
+	if err != nil {
+		return nil, false, err
+	}
+	for n == 0 || len(rows) < n {
+		if !s.rows.Next() {
+			if err := s.rows.Err(); err != nil {
+				return nil, false, err
+			}
+			return rows, true, nil
+		}
+
+		// Create a slice of interface{} to hold each column
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := s.rows.Scan(valuePtrs...); err != nil {
+			return nil, false, err
+		}
+
+		// Convert to CellValues with NULL awareness
+		row := make([]CellValue, len(columns))
+		for i, val := range values {
+			if val == nil {
+				row[i] = CellValue{Value: "", IsNull: true}
+			} else {
+				switch v := val.(type) {
+				case []byte:
+					row[i] = CellValue{Value: string(v), IsNull: false}
+				case bool:
+					if v {
+						row[i] = CellValue{Value: "true", IsNull: false}
+					} else {
+						row[i] = CellValue{Value: "false", IsNull: false}
+					}
+				default:
+					row[i] = CellValue{Value: fmt.Sprintf("%v", v), IsNull: false}
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, false, nil
+}
+
+// Close releases the result set and its connection
+func (s *rowStream) Close() {
+	_ = s.rows.Close()
+	s.cancel()
+}
+
+// HasMoreRows reports whether the result has rows not fetched yet
+func (r *QueryResult) HasMoreRows() bool {
+	return r.stream != nil
+}
+
+// RowCountText is the number of rows fetched, with a "+" while there are more
+func (r *QueryResult) RowCountText() string {
+	if r.HasMoreRows() {
+		return fmt.Sprintf("%d+", len(r.Rows))
+	}
+	return fmt.Sprintf("%d", len(r.Rows))
+}
+
+// closeStream stops fetching rows for a result, keeping the rows it has
+func (r *QueryResult) closeStream() {
+	if r != nil && r.stream != nil {
+		r.stream.Close()
+		r.stream = nil
+	}
+}
+
+// closeStreams closes the open result sets of the tab's results. Each holds
+// a connection, and on SQLite a read lock that would block writes.
+func (t *Tab) closeStreams() {
+	t.result.closeStream()
+	for _, snap := range t.results {
+		snap.Result.closeStream()
+	}
+}
+
+// rowsFetchedMsg carries rows fetched in the background for a result
+type rowsFetchedMsg struct {
+	tab    *Tab
+	result *QueryResult
+	rows   [][]CellValue
+	done   bool
+	err    error
+	// pageDown moves to the next page once the rows are in
+	pageDown bool
+}
+
+// fetchMoreRows starts fetching the next batch of the active tab's result
+func (m *Model) fetchMoreRows() tea.Cmd {
+	return m.fetchRows(false)
+}
+
+// fetchRows fetches the next batch of the active tab's result, moving to
+// the next page when it arrives if pageDown is set
+func (m *Model) fetchRows(pageDown bool) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.result == nil || tab.result.stream == nil {
+		m.statusMessage = "All rows are fetched"
+		return nil
+	}
+	if tab.result.stream.fetching {
+		return nil
+	}
+	result := tab.result
+	stream := result.stream
+	stream.fetching = true
+	m.statusMessage = "Fetching more rows..."
+	limit := m.rowLimit
+	return func() tea.Msg {
+		rows, done, err := stream.fetch(limit)
+		return rowsFetchedMsg{tab: tab, result: result, rows: rows, done: done, err: err, pageDown: pageDown}
+	}
+}
+
+// fetchedRowsStatus describes how much of a result is fetched
+func fetchedRowsStatus(result *QueryResult) string {
+	if result.HasMoreRows() {
+		return fmt.Sprintf("Showing first %d rows, Alt+L for more", len(result.Rows))
+	}
+	return fmt.Sprintf("All %d rows fetched", len(result.Rows))
+}
+
+// queryRowLimit returns how many rows to fetch first for query, or 0 for
+// all of them when the query limits its rows itself
+func (m *Model) queryRowLimit(query string) int {
+	if m.rowLimit <= 0 || hasRowLimit(query) {
+		return 0
+	}
+	return m.rowLimit
+}
+
+// finishFetch adds fetched rows to the result they were fetched for
+func (m *Model) finishFetch(msg rowsFetchedMsg) {
+	result := msg.result
+	if result.stream == nil {
+		return // closed while the fetch ran
+	}
+	result.stream.fetching = false
+	result.addFetchedRows(msg.rows)
+	if msg.err != nil || msg.done {
+		result.closeStream()
+	}
+	tab := msg.tab
+	if !slices.Contains(m.tabs, tab) || tab.result != result {
+		return
+	}
+	tab.totalPages = tab.pageCount(len(result.Rows))
+	if msg.pageDown && tab.currentPage < tab.totalPages-1 {
+		tab.currentPage++
+		tab.selectedRow = tab.currentPage * tab.rowsPerPage()
+	}
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Fetching more rows failed: %v", msg.err)
+	} else if tab == m.activeTabPtr() {
+		m.statusMessage = fetchedRowsStatus(result)
+	}
+}
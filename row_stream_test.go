@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestOpenQueryStreamsRows tests fetching a query's first rows and leaving
+// the rest to fetch on demand
+func TestOpenQueryStreamsRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "stream.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec("CREATE TABLE n (i INTEGER); INSERT INTO n VALUES (1), (2), (3), (4), (5)"); err != nil {
+		t.Fatal(err)
+	}
+
+	result := openQuery(context.Background(), db, "SELECT i FROM n ORDER BY i", 2)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(result.Rows) != 2 || !result.HasMoreRows() || result.RowCountText() != "2+" {
+		t.Fatalf("openQuery() fetched %s rows, want the first 2 and more to come", result.RowCountText())
+	}
+
+	// Other queries still run while the rows are open
+	if other := executeQuery(context.Background(), db, "SELECT count(*) FROM n"); other.Error != nil {
+		t.Errorf("query alongside an open stream failed: %v", other.Error)
+	}
+
+	rows, done, err := result.stream.fetch(2)
+	if err != nil || done || len(rows) != 2 || rows[0][0].Value != "3" {
+		t.Errorf("fetch(2) = %v, %v, %v; want rows 3 and 4", rows, done, err)
+	}
+	rows, done, err = result.stream.fetch(2)
+	if err != nil || !done || len(rows) != 1 || rows[0][0].Value != "5" {
+		t.Errorf("fetch(2) = %v, %v, %v; want row 5 and done", rows, done, err)
+	}
+	result.closeStream()
+	if result.HasMoreRows() || result.RowCountText() != "2" {
+		t.Errorf("after closeStream() the result still has more rows")
+	}
+
+	// Everything is fetched when the limit covers it
+	if all := openQuery(context.Background(), db, "SELECT i FROM n", 10); len(all.Rows) != 5 || all.HasMoreRows() {
+		t.Errorf("openQuery() with a limit covering every row fetched %s rows", all.RowCountText())
+	}
+
+	// A single-connection database can't keep rows open
+	db.SetMaxOpenConns(1)
+	if all := openQuery(context.Background(), db, "SELECT i FROM n", 2); len(all.Rows) != 5 || all.HasMoreRows() {
+		t.Errorf("openQuery() on a single-connection database fetched %s rows, want all 5", all.RowCountText())
+	}
+}
+
+// TestPageDownFetchesMoreRows tests that paging down past the rows fetched
+// so far fetches the next batch and moves on to it
+func TestPageDownFetchesMoreRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "stream.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec("CREATE TABLE n (i INTEGER); INSERT INTO n VALUES (1), (2), (3), (4), (5)"); err != nil {
+		t.Fatal(err)
+	}
+
+	result := openQuery(context.Background(), db, "SELECT i FROM n ORDER BY i", 2)
+	defer result.closeStream()
+	tab := &Tab{result: result, pageSize: 2}
+	tab.totalPages = tab.pageCount(len(result.Rows))
+	m := Model{tabs: []*Tab{tab}, keys: DefaultKeymap(), rowLimit: 2}
+
+	next, cmd := m.handleResultsNavigation(tea.KeyMsg{Type: tea.KeyPgDown})
+	if cmd == nil {
+		t.Fatal("PgDn on the last page fetched should fetch more rows")
+	}
+	m = next.(Model)
+	m.finishFetch(cmd().(rowsFetchedMsg))
+	if len(result.Rows) != 4 || tab.currentPage != 1 || tab.selectedRow != 2 {
+		t.Errorf("after PgDn: %d rows, page %d, row %d; want 4 rows, page 1, row 2",
+			len(result.Rows), tab.currentPage, tab.selectedRow)
+	}
+}
@@ -0,0 +1,861 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaColumn describes a column of a table or view
+type SchemaColumn struct {
+	Name       string
+	Type       string
+	Nullable   bool
+	Default    sql.NullString
+	PrimaryKey bool
+	Comment    string
+}
+
+// SchemaTable describes a table or view in the connected database
+type SchemaTable struct {
+	Name    string
+	IsView  bool
+	Columns []SchemaColumn
+}
+
+// SchemaIndex describes an index on a table
+type SchemaIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+	Type    string // access method, e.g. BTREE, hash, gin
+}
+
+// SchemaForeignKey describes a foreign key from columns of one table to
+// columns of another; Columns and RefColumns are matched by position
+type SchemaForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// SchemaSequence describes the auto-increment column or owning sequence
+// that generates a table's key values
+type SchemaSequence struct {
+	Column   string
+	Sequence string        // owning sequence (PostgreSQL only)
+	Next     sql.NullInt64 // next value to be generated, if known
+}
+
+// SchemaObject describes a trigger, stored procedure or function
+type SchemaObject struct {
+	Name  string
+	Kind  string // "trigger", "procedure" or "function"
+	Table string // the table a trigger fires on
+}
+
+// SchemaCache holds table and column metadata for one connection.
+// It is loaded lazily on first use and kept until invalidated, so features
+// that need metadata don't query the catalog on every keystroke.
+type SchemaCache struct {
+	db            *sql.DB
+	dbType        string
+	tables        []SchemaTable
+	loaded        bool
+	loadedAt      time.Time                // when tables and columns were last read
+	indexes       map[string][]SchemaIndex // per table (lowercased), loaded on demand
+	fks           map[string][]SchemaForeignKey
+	counts        map[string]RowCount        // per table (lowercased), loaded on demand
+	objects       []SchemaObject             // triggers and routines, loaded on demand
+	sequences     map[string]*SchemaSequence // per table (lowercased), nil if none; loaded on demand
+	objectsLoaded bool
+}
+
+// RowCount is the number of rows in a table; estimates come from catalog
+// statistics and may be stale
+type RowCount struct {
+	Rows     int64
+	Estimate bool
+}
+
+// NewSchemaCache creates an empty cache for the given connection
+func NewSchemaCache(db *sql.DB, dbType string) *SchemaCache {
+	return &SchemaCache{db: db, dbType: dbType}
+}
+
+// Tables returns the cached tables, loading them from the database if needed
+func (c *SchemaCache) Tables() ([]SchemaTable, error) {
+	if !c.loaded {
+		if err := c.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return c.tables, nil
+}
+
+// CachedTables returns the tables if they have already been loaded, without
+// querying the database
+func (c *SchemaCache) CachedTables() ([]SchemaTable, bool) {
+	return c.tables, c.loaded
+}
+
+// Table returns the cached metadata for a table (case-insensitive), or nil if unknown
+func (c *SchemaCache) Table(name string) *SchemaTable {
+	tables, err := c.Tables()
+	if err != nil {
+		return nil
+	}
+	for i := range tables {
+		if strings.EqualFold(tables[i].Name, name) {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+// Invalidate discards the cached metadata; it is reloaded on next use
+func (c *SchemaCache) Invalidate() {
+	c.tables = nil
+	c.loaded = false
+	c.indexes = nil
+	c.fks = nil
+	c.counts = nil
+	c.objects = nil
+	c.objectsLoaded = false
+	c.sequences = nil
+}
+
+// Indexes returns the indexes of a table, loading them from the database if needed
+func (c *SchemaCache) Indexes(table string) ([]SchemaIndex, error) {
+	key := strings.ToLower(table)
+	if indexes, ok := c.indexes[key]; ok {
+		return indexes, nil
+	}
+
+	query, arg := indexQuery(c.dbType, table)
+	rows, err := c.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var indexes []SchemaIndex
+	for rows.Next() {
+		var name, column, unique, primary string
+		var indexType sql.NullString
+		if err := rows.Scan(&name, &column, &unique, &primary, &indexType); err != nil {
+			return nil, err
+		}
+		// Rows are ordered by index, one per indexed column
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		indexes = append(indexes, SchemaIndex{
+			Name:    name,
+			Columns: []string{column},
+			Unique:  unique == "YES",
+			Primary: primary == "YES",
+			Type:    indexType.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.indexes == nil {
+		c.indexes = make(map[string][]SchemaIndex)
+	}
+	c.indexes[key] = indexes
+	return indexes, nil
+}
+
+// CachedIndexes returns the indexes of a table if they have already been loaded
+func (c *SchemaCache) CachedIndexes(table string) ([]SchemaIndex, bool) {
+	indexes, ok := c.indexes[strings.ToLower(table)]
+	return indexes, ok
+}
+
+// Reload reads table and column metadata from the database
+func (c *SchemaCache) Reload() error {
+	tablesQuery, columnsQuery := schemaQueries(c.dbType)
+
+	var tables []SchemaTable
+	index := make(map[string]int)
+
+	rows, err := c.db.Query(tablesQuery)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, tableType string
+		if err := rows.Scan(&name, &tableType); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		index[name] = len(tables)
+		tables = append(tables, SchemaTable{
+			Name:   name,
+			IsView: strings.Contains(strings.ToUpper(tableType), "VIEW"),
+		})
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = c.db.Query(columnsQuery)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var table string
+		var col SchemaColumn
+		var nullable, primaryKey sql.NullString
+		var colType, comment sql.NullString
+		if err := rows.Scan(&table, &col.Name, &colType, &nullable, &col.Default, &primaryKey, &comment); err != nil {
+			return err
+		}
+		col.Type = colType.String
+		col.Comment = comment.String
+		col.Nullable = strings.EqualFold(nullable.String, "YES")
+		col.PrimaryKey = strings.EqualFold(primaryKey.String, "YES")
+		if i, ok := index[table]; ok {
+			tables[i].Columns = append(tables[i].Columns, col)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	c.tables = tables
+	c.loaded = true
+	c.loadedAt = time.Now()
+	c.indexes = nil
+	c.fks = nil
+	c.counts = nil
+	c.objects = nil
+	c.objectsLoaded = false
+	c.sequences = nil
+	return nil
+}
+
+// Objects returns the triggers, procedures and functions, loading them from
+// the database if needed
+func (c *SchemaCache) Objects() ([]SchemaObject, error) {
+	if c.objectsLoaded {
+		return c.objects, nil
+	}
+
+	rows, err := c.db.Query(objectsQuery(c.dbType))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var objects []SchemaObject
+	for rows.Next() {
+		var obj SchemaObject
+		if err := rows.Scan(&obj.Name, &obj.Kind, &obj.Table); err != nil {
+			return nil, err
+		}
+		// Overloaded functions share a name; list them once
+		if n := len(objects); n > 0 && objects[n-1] == obj {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	c.objects = objects
+	c.objectsLoaded = true
+	return objects, nil
+}
+
+// Sequence returns the generator of a table's key column, or nil if its
+// keys aren't generated, loading it from the database if needed
+func (c *SchemaCache) Sequence(table string) (*SchemaSequence, error) {
+	key := strings.ToLower(table)
+	if seq, ok := c.sequences[key]; ok {
+		return seq, nil
+	}
+
+	query, args := sequenceQuery(c.dbType, table)
+	seq := &SchemaSequence{}
+	var sequence sql.NullString
+	err := c.db.QueryRow(query, args...).Scan(&seq.Column, &sequence, &seq.Next)
+	if err == sql.ErrNoRows {
+		seq = nil
+	} else if err != nil {
+		return nil, err
+	} else {
+		seq.Sequence = sequence.String
+	}
+
+	switch strings.ToLower(c.dbType) {
+	case "mysql", "postgres", "postgresql", "pg":
+	default:
+		// An AUTOINCREMENT table never reuses the keys of deleted rows, so the
+		// next value may be past MAX(rowid). sqlite_sequence only exists once
+		// an AUTOINCREMENT table has been created, so errors are ignored.
+		var used sql.NullInt64
+		err := c.db.QueryRow("SELECT seq FROM sqlite_sequence WHERE name = ?", table).Scan(&used)
+		if seq != nil && err == nil && used.Valid && used.Int64+1 > seq.Next.Int64 {
+			seq.Next = sql.NullInt64{Int64: used.Int64 + 1, Valid: true}
+		}
+	}
+
+	if c.sequences == nil {
+		c.sequences = make(map[string]*SchemaSequence)
+	}
+	c.sequences[key] = seq
+	return seq, nil
+}
+
+// CachedSequence returns the generator of a table's key column if it has
+// already been loaded; the sequence is nil if the keys aren't generated
+func (c *SchemaCache) CachedSequence(table string) (*SchemaSequence, bool) {
+	seq, ok := c.sequences[strings.ToLower(table)]
+	return seq, ok
+}
+
+// ForeignKeys returns the foreign keys of a table, loading them from the database if needed
+func (c *SchemaCache) ForeignKeys(table string) ([]SchemaForeignKey, error) {
+	key := strings.ToLower(table)
+	if fks, ok := c.fks[key]; ok {
+		return fks, nil
+	}
+
+	query, arg := foreignKeyQuery(c.dbType, table)
+	rows, err := c.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var fks []SchemaForeignKey
+	for rows.Next() {
+		var name, column, refTable string
+		var refColumn sql.NullString
+		if err := rows.Scan(&name, &column, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		// Rows are ordered by constraint, one per column
+		if n := len(fks); n > 0 && fks[n-1].Name == name {
+			fks[n-1].Columns = append(fks[n-1].Columns, column)
+			fks[n-1].RefColumns = append(fks[n-1].RefColumns, refColumn.String)
+			continue
+		}
+		fks = append(fks, SchemaForeignKey{
+			Name:       name,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn.String},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.fks == nil {
+		c.fks = make(map[string][]SchemaForeignKey)
+	}
+	c.fks[key] = fks
+	return fks, nil
+}
+
+// PrimaryKey returns the primary key columns of a table, or nil if the table
+// or its key is unknown. A schema-qualified name is looked up by its table part.
+func (c *SchemaCache) PrimaryKey(table string) []string {
+	if dot := strings.LastIndex(table, "."); dot >= 0 {
+		table = table[dot+1:]
+	}
+	info := c.Table(strings.Trim(table, `"`))
+	if info == nil {
+		return nil
+	}
+	var pk []string
+	for _, col := range info.Columns {
+		if col.PrimaryKey {
+			pk = append(pk, col.Name)
+		}
+	}
+	return pk
+}
+
+// foreignKeyForColumn returns the foreign key that includes the column
+// (case-insensitive) and the column's position in it, or nil
+func foreignKeyForColumn(fks []SchemaForeignKey, column string) (*SchemaForeignKey, int) {
+	for i := range fks {
+		for pos, col := range fks[i].Columns {
+			if strings.EqualFold(col, column) {
+				return &fks[i], pos
+			}
+		}
+	}
+	return nil, -1
+}
+
+// followForeignKeySQL returns a SELECT of the row referenced by a foreign key
+// value, where ref is the referenced "table.column"
+func followForeignKeySQL(ref string, value string, colType ColumnType, dbType string) string {
+	dot := strings.LastIndex(ref, ".")
+	table, column := ref[:dot], ref[dot+1:]
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s = %s LIMIT 100",
+		quoteNameIfNeeded(table, dbType), quoteNameIfNeeded(column, dbType),
+		formatValueForSQL(value, false, colType, dbType))
+}
+
+// RowCounts returns the row counts known for the tables, loading them if
+// needed. MySQL and PostgreSQL estimate every table's from the catalog in one
+// query. SQLite has no estimates, and counting every table could take a
+// while on a big database, so its tables are counted one at a time by
+// TableRowCount.
+func (c *SchemaCache) RowCounts() (map[string]RowCount, error) {
+	if c.counts != nil {
+		return c.counts, nil
+	}
+
+	counts := make(map[string]RowCount)
+	switch strings.ToLower(c.dbType) {
+	case "mysql", "postgres", "postgresql", "pg":
+		query := `SELECT table_name, table_rows FROM information_schema.tables
+			WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'`
+		if strings.ToLower(c.dbType) != "mysql" {
+			// reltuples is -1 for tables that have never been analyzed
+			query = `SELECT c.relname, c.reltuples::bigint FROM pg_class c
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND c.reltuples >= 0`
+		}
+		rows, err := c.db.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var name string
+			var n sql.NullInt64
+			if err := rows.Scan(&name, &n); err != nil {
+				return nil, err
+			}
+			if n.Valid {
+				counts[strings.ToLower(name)] = RowCount{Rows: n.Int64, Estimate: true}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.counts = counts
+	return counts, nil
+}
+
+// TableRowCount returns a table's row count, counting its rows on SQLite if
+// they haven't been counted yet. It reports false for views, and for tables
+// the catalog has no estimate for.
+func (c *SchemaCache) TableRowCount(table string) (RowCount, bool, error) {
+	tables, err := c.Tables() // first, as loading them clears the counts
+	if err != nil {
+		return RowCount{}, false, err
+	}
+	counts, err := c.RowCounts()
+	if err != nil {
+		return RowCount{}, false, err
+	}
+	key := strings.ToLower(table)
+	if count, ok := counts[key]; ok {
+		return count, true, nil
+	}
+	switch strings.ToLower(c.dbType) {
+	case "mysql", "postgres", "postgresql", "pg":
+		return RowCount{}, false, nil
+	}
+	i := slices.IndexFunc(tables, func(t SchemaTable) bool { return strings.EqualFold(t.Name, table) })
+	if i < 0 || tables[i].IsView {
+		return RowCount{}, false, nil
+	}
+	var n int64
+	if err := c.db.QueryRow("SELECT COUNT(*) FROM " + quoteNameIfNeeded(tables[i].Name, c.dbType)).Scan(&n); err != nil {
+		return RowCount{}, false, err
+	}
+	counts[key] = RowCount{Rows: n}
+	return counts[key], true, nil
+}
+
+// CachedRowCount returns a table's row count if counts have already been loaded
+func (c *SchemaCache) CachedRowCount(table string) (RowCount, bool) {
+	count, ok := c.counts[strings.ToLower(table)]
+	return count, ok
+}
+
+// formatRowCount abbreviates a row count for the schema browser, e.g. 950,
+// 12k, 3.4M, with a ~ prefix for estimates
+func formatRowCount(count RowCount) string {
+	n := float64(count.Rows)
+	var s string
+	switch {
+	case count.Rows < 1000:
+		s = fmt.Sprintf("%d", count.Rows)
+	case count.Rows < 10_000:
+		s = fmt.Sprintf("%.1fk", n/1e3)
+	case count.Rows < 1_000_000:
+		s = fmt.Sprintf("%.0fk", n/1e3)
+	case count.Rows < 10_000_000:
+		s = fmt.Sprintf("%.1fM", n/1e6)
+	case count.Rows < 1_000_000_000:
+		s = fmt.Sprintf("%.0fM", n/1e6)
+	default:
+		s = fmt.Sprintf("%.1fB", n/1e9)
+	}
+	if count.Estimate {
+		s = "~" + s
+	}
+	return s
+}
+
+// indexSummary describes an index on one line: name, columns, and kind
+func indexSummary(idx SchemaIndex) string {
+	s := idx.Name + " (" + strings.Join(idx.Columns, ", ") + ")"
+	switch {
+	case idx.Primary:
+		s += " PRIMARY"
+	case idx.Unique:
+		s += " UNIQUE"
+	}
+	if idx.Type != "" {
+		s += " " + strings.ToLower(idx.Type)
+	}
+	return s
+}
+
+// columnSummary describes a column on one line: name, type, key and
+// nullability, and default value
+func columnSummary(col SchemaColumn) string {
+	parts := []string{col.Name}
+	if col.Type != "" {
+		parts = append(parts, col.Type)
+	}
+	if col.PrimaryKey {
+		parts = append(parts, "PK")
+	}
+	if !col.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if col.Default.Valid {
+		parts = append(parts, "= "+col.Default.String)
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteNameIfNeeded quotes a table or column name only if it isn't a plain
+// identifier, or in PostgreSQL has capitals it would otherwise fold to
+// lowercase. Each part of a schema-qualified name is quoted separately.
+func quoteNameIfNeeded(name string, dbType string) string {
+	parts := strings.Split(name, ".")
+	q := quoteIdentifier(dbType)
+	folds := false
+	switch strings.ToLower(dbType) {
+	case "postgres", "postgresql", "pg":
+		folds = true
+	}
+	for i, part := range parts {
+		if part == "" || sanitizeIdentifier(part) != part || (folds && strings.ToLower(part) != part) {
+			parts[i] = q + strings.ReplaceAll(part, q, q+q) + q
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// selectTableSQL returns a SELECT of the first rows of a table
+func selectTableSQL(table string, dbType string, limit int) string {
+	return fmt.Sprintf("SELECT * FROM %s LIMIT %d", quoteNameIfNeeded(table, dbType), limit)
+}
+
+// schemaQueries returns the catalog queries for tables and columns.
+// Tables: (name, type). Columns: (table, name, type, nullable, default, primary key, comment).
+func schemaQueries(dbType string) (string, string) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return `SELECT table_name, table_type FROM information_schema.tables
+				WHERE table_schema = DATABASE() ORDER BY table_name`,
+			`SELECT table_name, column_name, column_type, is_nullable, column_default,
+					CASE WHEN column_key = 'PRI' THEN 'YES' ELSE 'NO' END,
+					NULLIF(column_comment, '')
+				FROM information_schema.columns
+				WHERE table_schema = DATABASE() ORDER BY table_name, ordinal_position`
+	case "postgres", "postgresql", "pg":
+		return `SELECT table_name, table_type FROM information_schema.tables
+				WHERE table_schema = current_schema() ORDER BY table_name`,
+			`SELECT c.table_name, c.column_name, c.data_type, c.is_nullable, c.column_default,
+					CASE WHEN EXISTS (
+						SELECT 1 FROM information_schema.table_constraints tc
+						JOIN information_schema.key_column_usage k
+							ON k.constraint_name = tc.constraint_name AND k.table_schema = tc.table_schema
+						WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+							AND tc.table_name = c.table_name AND k.column_name = c.column_name
+					) THEN 'YES' ELSE 'NO' END,
+					(SELECT d.description FROM pg_description d
+						JOIN pg_class cl ON cl.oid = d.objoid
+						JOIN pg_namespace n ON n.oid = cl.relnamespace
+						JOIN pg_attribute a ON a.attrelid = cl.oid AND a.attnum = d.objsubid
+						WHERE n.nspname = c.table_schema AND cl.relname = c.table_name
+							AND a.attname = c.column_name)
+				FROM information_schema.columns c
+				WHERE c.table_schema = current_schema() ORDER BY c.table_name, c.ordinal_position`
+	default:
+		return `SELECT name, type FROM sqlite_master
+				WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+			`SELECT m.name, p.name, p.type, CASE WHEN p."notnull" = 0 THEN 'YES' ELSE 'NO' END, p.dflt_value,
+					CASE WHEN p.pk > 0 THEN 'YES' ELSE 'NO' END, NULL
+				FROM sqlite_master m JOIN pragma_table_info(m.name) p
+				WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'
+				ORDER BY m.name, p.cid`
+	}
+}
+
+// objectsQuery returns the catalog query listing triggers and routines.
+// Rows: (name, kind, table), ordered by kind and name.
+func objectsQuery(dbType string) string {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return `SELECT routine_name, LOWER(routine_type), '' FROM information_schema.routines
+				WHERE routine_schema = DATABASE()
+				UNION ALL
+				SELECT trigger_name, 'trigger', event_object_table FROM information_schema.triggers
+				WHERE trigger_schema = DATABASE()
+				ORDER BY 2, 1`
+	case "postgres", "postgresql", "pg":
+		return `SELECT p.proname, CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END, ''
+				FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+				WHERE n.nspname = current_schema() AND p.prokind IN ('f', 'p')
+				UNION ALL
+				SELECT t.tgname, 'trigger', c.relname
+				FROM pg_trigger t
+				JOIN pg_class c ON c.oid = t.tgrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE n.nspname = current_schema() AND NOT t.tgisinternal
+				ORDER BY 2, 1`
+	default:
+		// SQLite has no stored routines
+		return `SELECT name, 'trigger', tbl_name FROM sqlite_master
+				WHERE type = 'trigger' ORDER BY 2, 1`
+	}
+}
+
+// objectSummary returns a one-line description of a trigger or routine,
+// e.g. "touch_updated (on users)" or "refresh_totals() procedure"
+func objectSummary(obj SchemaObject) string {
+	switch obj.Kind {
+	case "trigger":
+		return obj.Name + " (on " + obj.Table + ")"
+	case "procedure":
+		return obj.Name + "() procedure"
+	default:
+		return obj.Name + "()"
+	}
+}
+
+// indexQuery returns the catalog query listing a table's indexes and its argument.
+// Rows: (index, column, unique, primary, type), ordered by index and column position.
+func indexQuery(dbType string, table string) (string, any) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return `SELECT index_name, column_name,
+				CASE WHEN non_unique = 0 THEN 'YES' ELSE 'NO' END,
+				CASE WHEN index_name = 'PRIMARY' THEN 'YES' ELSE 'NO' END,
+				index_type
+				FROM information_schema.statistics
+				WHERE table_schema = DATABASE() AND table_name = ?
+				ORDER BY index_name, seq_in_index`, table
+	case "postgres", "postgresql", "pg":
+		return `SELECT i.relname, COALESCE(a.attname, '(expression)'),
+				CASE WHEN ix.indisunique THEN 'YES' ELSE 'NO' END,
+				CASE WHEN ix.indisprimary THEN 'YES' ELSE 'NO' END,
+				am.amname
+				FROM pg_index ix
+				JOIN pg_class i ON i.oid = ix.indexrelid
+				JOIN pg_am am ON am.oid = i.relam
+				CROSS JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+				LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+				WHERE ix.indrelid = $1::regclass
+				ORDER BY i.relname, k.ord`, quoteNameIfNeeded(table, "postgres")
+	default:
+		return `SELECT il.name, COALESCE(ii.name, '(expression)'),
+				CASE WHEN il."unique" = 1 THEN 'YES' ELSE 'NO' END,
+				CASE WHEN il.origin = 'pk' THEN 'YES' ELSE 'NO' END,
+				'btree'
+				FROM pragma_index_list(?) il JOIN pragma_index_info(il.name) ii
+				ORDER BY il.name, ii.seqno`, table
+	}
+}
+
+// sequenceQuery returns the catalog query finding a table's generated key
+// column and its arguments. Row: (column, sequence, next value).
+func sequenceQuery(dbType string, table string) (string, []any) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return `SELECT c.column_name, NULL, t.auto_increment
+				FROM information_schema.columns c
+				JOIN information_schema.tables t
+					ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+				WHERE c.table_schema = DATABASE() AND c.table_name = ?
+					AND c.extra LIKE '%auto_increment%'`, []any{table}
+	case "postgres", "postgresql", "pg":
+		// Serial and identity columns both own a sequence; last_value is
+		// NULL until the sequence is first used. The names are quoted by
+		// format() so mixed-case and schema-qualified tables are found.
+		schema, name := "", table
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			schema, name = table[:i], table[i+1:]
+		}
+		return `SELECT a.attname, seq.name,
+					(SELECT COALESCE(s.last_value + s.increment_by, s.start_value) FROM pg_sequences s
+						WHERE format('%I.%I', s.schemaname, s.sequencename)::regclass = seq.name::regclass)
+				FROM (SELECT to_regclass(CASE WHEN $1::text = '' THEN format('%I', $2::text)
+						ELSE format('%I.%I', $1::text, $2::text) END) AS oid) rel
+				JOIN pg_attribute a ON a.attrelid = rel.oid
+				CROSS JOIN LATERAL (SELECT pg_get_serial_sequence(rel.oid::regclass::text, a.attname) AS name) seq
+				WHERE a.attnum > 0 AND NOT a.attisdropped
+					AND seq.name IS NOT NULL
+				ORDER BY a.attnum LIMIT 1`, []any{schema, name}
+	default:
+		// A lone INTEGER PRIMARY KEY is an alias for the rowid, which SQLite generates
+		return `SELECT p.name, NULL, (SELECT COALESCE(MAX(rowid), 0) + 1 FROM ` + quoteNameIfNeeded(table, "sqlite") + `)
+				FROM pragma_table_info(?) p
+				WHERE p.pk = 1 AND upper(p.type) = 'INTEGER'
+					AND (SELECT COUNT(*) FROM pragma_table_info(?) WHERE pk > 0) = 1`, []any{table, table}
+	}
+}
+
+// sequenceSummary returns a one-line description of a key generator,
+// e.g. "id: next 43 (users_id_seq)"
+func sequenceSummary(seq SchemaSequence) string {
+	summary := seq.Column + ": next "
+	if seq.Next.Valid {
+		summary += fmt.Sprint(seq.Next.Int64)
+	} else {
+		summary += "unknown"
+	}
+	if seq.Sequence != "" {
+		summary += " (" + seq.Sequence + ")"
+	}
+	return summary
+}
+
+// generatedKeyValue returns the expression that asks the database to
+// generate a key explicitly in an INSERT: nextval() of the owning sequence on
+// PostgreSQL, DEFAULT on MySQL, and NULL on SQLite (which has no DEFAULT
+// keyword in VALUES, but assigns a rowid for NULL)
+func generatedKeyValue(seq *SchemaSequence, dbType string) string {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return "DEFAULT"
+	case "postgres", "postgresql", "pg":
+		if seq != nil && seq.Sequence != "" {
+			return "nextval('" + escapeSQLString(seq.Sequence) + "')"
+		}
+		return "DEFAULT"
+	default:
+		return "NULL"
+	}
+}
+
+// foreignKeyQuery returns the catalog query listing a table's foreign keys and its argument.
+// Rows: (constraint, column, referenced table, referenced column), ordered by constraint and position.
+func foreignKeyQuery(dbType string, table string) (string, any) {
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		return `SELECT constraint_name, column_name, referenced_table_name, referenced_column_name
+				FROM information_schema.key_column_usage
+				WHERE table_schema = DATABASE() AND table_name = ? AND referenced_table_name IS NOT NULL
+				ORDER BY constraint_name, ordinal_position`, table
+	case "postgres", "postgresql", "pg":
+		return `SELECT c.conname, a.attname, c.confrelid::regclass::text, ra.attname
+				FROM pg_constraint c
+				CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum
+				WHERE c.contype = 'f' AND c.conrelid = $1::regclass
+				ORDER BY c.conname, k.ord`, quoteNameIfNeeded(table, "postgres")
+	default:
+		// SQLite foreign keys are unnamed; the id groups the columns of each one.
+		// A missing "to" column means the referenced table's primary key.
+		return `SELECT 'fk_' || fk.id, fk."from", fk."table",
+				COALESCE(fk."to", (SELECT p.name FROM pragma_table_info(fk."table") p WHERE p.pk = fk.seq + 1))
+				FROM pragma_foreign_key_list(?) fk
+				ORDER BY fk.id, fk.seq`, table
+	}
+}
+
+// DatabaseChoice is a database (or PostgreSQL schema) offered by the database switcher
+type DatabaseChoice struct {
+	Name    string
+	Schema  bool // a schema in the current database rather than another database
+	Current bool
+}
+
+// listDatabaseChoices lists the databases on the server, and for PostgreSQL
+// the schemas of the current database. For SQLite it lists attached databases.
+func listDatabaseChoices(db *sql.DB, dbType string, currentDB string) ([]DatabaseChoice, error) {
+	var dbQuery, schemaQuery string
+	switch strings.ToLower(dbType) {
+	case "mysql":
+		dbQuery = "SHOW DATABASES"
+	case "postgres", "postgresql", "pg":
+		dbQuery = "SELECT datname FROM pg_database WHERE datallowconn AND NOT datistemplate ORDER BY datname"
+		schemaQuery = `SELECT schema_name FROM information_schema.schemata
+			WHERE schema_name NOT LIKE 'pg\_%' AND schema_name <> 'information_schema'
+			ORDER BY schema_name`
+	default:
+		dbQuery = "SELECT name FROM pragma_database_list ORDER BY seq"
+	}
+
+	names, err := queryStrings(db, dbQuery)
+	if err != nil {
+		return nil, err
+	}
+	var choices []DatabaseChoice
+	for _, name := range names {
+		choices = append(choices, DatabaseChoice{Name: name, Current: name == currentDB})
+	}
+
+	if schemaQuery != "" {
+		schemas, err := queryStrings(db, schemaQuery)
+		if err != nil {
+			return choices, err
+		}
+		var current string
+		_ = db.QueryRow("SELECT current_schema()").Scan(&current)
+		for _, name := range schemas {
+			choices = append(choices, DatabaseChoice{Name: name, Schema: true, Current: name == current})
+		}
+	}
+	return choices, nil
+}
+
+// queryStrings runs a query returning a single text column and collects the values
+func queryStrings(db *sql.DB, query string) ([]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// TestSchemaCacheSQLite tests loading tables and columns from SQLite
+func TestSchemaCacheSQLite(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("CREATE VIEW active_users AS SELECT id, name FROM users WHERE is_active = 1"); err != nil {
+		t.Fatalf("Failed to create view: %v", err)
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	tables, err := cache.Tables()
+	if err != nil {
+		t.Fatalf("Tables() error: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("Tables() returned %d tables, want 2", len(tables))
+	}
+
+	users := cache.Table("USERS")
+	if users == nil {
+		t.Fatal("Table(\"USERS\") = nil, want users table")
+	}
+	if users.IsView {
+		t.Error("users.IsView = true, want false")
+	}
+	if len(users.Columns) != 7 {
+		t.Fatalf("users has %d columns, want 7", len(users.Columns))
+	}
+
+	if !users.Columns[0].PrimaryKey {
+		t.Error("id column should be the primary key")
+	}
+	name := users.Columns[1]
+	if name.Name != "name" || name.Type != "TEXT" || name.Nullable || name.PrimaryKey {
+		t.Errorf("name column = %+v, want non-nullable TEXT", name)
+	}
+	isActive := users.Columns[5]
+	if !isActive.Nullable || !isActive.Default.Valid || isActive.Default.String != "1" {
+		t.Errorf("is_active column = %+v, want nullable with default 1", isActive)
+	}
+
+	view := cache.Table("active_users")
+	if view == nil || !view.IsView || len(view.Columns) != 2 {
+		t.Errorf("active_users = %+v, want a view with 2 columns", view)
+	}
+}
+
+// TestSchemaCacheInvalidate tests that invalidation picks up schema changes
+func TestSchemaCacheInvalidate(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	cache := NewSchemaCache(db, "sqlite")
+	if cache.Table("orders") != nil {
+		t.Fatal("orders table should not exist yet")
+	}
+
+	if _, err := db.Exec("CREATE TABLE orders (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	// Still cached
+	if cache.Table("orders") != nil {
+		t.Error("cache should not see new table before invalidation")
+	}
+
+	cache.Invalidate()
+	if cache.Table("orders") == nil {
+		t.Error("cache should see new table after invalidation")
+	}
+}
+
+// TestSchemaCacheReload tests that a manual reload records the load time and
+// drops metadata loaded on demand
+func TestSchemaCacheReload(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	cache := NewSchemaCache(db, "sqlite")
+	if _, err := cache.Tables(); err != nil {
+		t.Fatalf("Tables() error: %v", err)
+	}
+	firstLoad := cache.loadedAt
+	if firstLoad.IsZero() {
+		t.Fatal("loadedAt should be set after loading")
+	}
+	if _, err := cache.Indexes("users"); err != nil {
+		t.Fatalf("Indexes() error: %v", err)
+	}
+
+	if err := cache.Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if cache.loadedAt.Before(firstLoad) {
+		t.Error("loadedAt should advance on reload")
+	}
+	if _, ok := cache.CachedIndexes("users"); ok {
+		t.Error("indexes should be discarded on reload")
+	}
+}
+
+// TestSelectTableSQL tests the query generated when picking a table in the sidebar
+func TestSelectTableSQL(t *testing.T) {
+	tests := []struct {
+		table    string
+		dbType   string
+		expected string
+	}{
+		{"users", "sqlite", "SELECT * FROM users LIMIT 100"},
+		{"order items", "postgres", `SELECT * FROM "order items" LIMIT 100`},
+		{"order-items", "mysql", "SELECT * FROM `order-items` LIMIT 100"},
+		{`odd"name`, "sqlite", `SELECT * FROM "odd""name" LIMIT 100`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.table, func(t *testing.T) {
+			result := selectTableSQL(tc.table, tc.dbType, 100)
+			if result != tc.expected {
+				t.Errorf("selectTableSQL(%q, %q) = %q, want %q", tc.table, tc.dbType, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestColumnSummary tests the one-line column description shown in the schema browser
+func TestColumnSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		col      SchemaColumn
+		expected string
+	}{
+		{"primary key", SchemaColumn{Name: "id", Type: "INTEGER", PrimaryKey: true}, "id INTEGER PK NOT NULL"},
+		{"nullable", SchemaColumn{Name: "bio", Type: "TEXT", Nullable: true}, "bio TEXT"},
+		{"default", SchemaColumn{Name: "n", Type: "int", Nullable: true, Default: sql.NullString{String: "0", Valid: true}}, "n int = 0"},
+		{"no type", SchemaColumn{Name: "x", Nullable: true}, "x"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := columnSummary(tc.col)
+			if result != tc.expected {
+				t.Errorf("columnSummary() = %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestSchemaCacheIndexes tests loading a table's indexes from SQLite
+func TestSchemaCacheIndexes(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE UNIQUE INDEX idx_users_email ON users (email)",
+		"CREATE INDEX idx_users_name_age ON users (name, age)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create index: %v", err)
+		}
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	if _, ok := cache.CachedIndexes("users"); ok {
+		t.Fatal("indexes should not be cached before first use")
+	}
+	indexes, err := cache.Indexes("users")
+	if err != nil {
+		t.Fatalf("Indexes() error: %v", err)
+	}
+	if len(indexes) != 2 {
+		t.Fatalf("Indexes() returned %d indexes, want 2", len(indexes))
+	}
+
+	email, nameAge := indexes[0], indexes[1]
+	if email.Name != "idx_users_email" || !email.Unique || len(email.Columns) != 1 {
+		t.Errorf("email index = %+v, want unique on one column", email)
+	}
+	if nameAge.Unique || len(nameAge.Columns) != 2 || nameAge.Columns[0] != "name" || nameAge.Columns[1] != "age" {
+		t.Errorf("name/age index = %+v, want non-unique on (name, age)", nameAge)
+	}
+
+	if _, ok := cache.CachedIndexes("USERS"); !ok {
+		t.Error("indexes should be cached after loading")
+	}
+	cache.Invalidate()
+	if _, ok := cache.CachedIndexes("users"); ok {
+		t.Error("indexes should be discarded on invalidation")
+	}
+}
+
+// TestIndexSummary tests the one-line index description shown in the schema browser
+func TestIndexSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		idx      SchemaIndex
+		expected string
+	}{
+		{"primary", SchemaIndex{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true, Type: "BTREE"}, "PRIMARY (id) PRIMARY btree"},
+		{"unique composite", SchemaIndex{Name: "uq", Columns: []string{"a", "b"}, Unique: true, Type: "btree"}, "uq (a, b) UNIQUE btree"},
+		{"plain", SchemaIndex{Name: "ix", Columns: []string{"tags"}, Type: "gin"}, "ix (tags) gin"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := indexSummary(tc.idx)
+			if result != tc.expected {
+				t.Errorf("indexSummary() = %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestSchemaCacheForeignKeys tests loading foreign keys from SQLite
+func TestSchemaCacheForeignKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users, note TEXT)",
+		"CREATE TABLE regions (code TEXT, country TEXT, PRIMARY KEY (code, country))",
+		"CREATE TABLE shops (id INTEGER, region TEXT, country TEXT, FOREIGN KEY (region, country) REFERENCES regions (code, country))",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	fks, err := cache.ForeignKeys("orders")
+	if err != nil {
+		t.Fatalf("ForeignKeys() error: %v", err)
+	}
+	if len(fks) != 1 {
+		t.Fatalf("orders has %d foreign keys, want 1", len(fks))
+	}
+	fk, pos := foreignKeyForColumn(fks, "USER_ID")
+	if fk == nil || fk.RefTable != "users" || fk.RefColumns[pos] != "id" {
+		t.Errorf("user_id foreign key = %+v, want reference to users.id", fk)
+	}
+	if fk, _ := foreignKeyForColumn(fks, "note"); fk != nil {
+		t.Errorf("note should not be a foreign key, got %+v", fk)
+	}
+
+	fks, err = cache.ForeignKeys("shops")
+	if err != nil {
+		t.Fatalf("ForeignKeys() error: %v", err)
+	}
+	if len(fks) != 1 || len(fks[0].Columns) != 2 {
+		t.Fatalf("shops foreign keys = %+v, want one composite key", fks)
+	}
+	fk, pos = foreignKeyForColumn(fks, "country")
+	if fk == nil || fk.RefColumns[pos] != "country" {
+		t.Errorf("country foreign key = %+v, want reference to regions.country", fk)
+	}
+}
+
+// TestFollowForeignKeySQL tests the query generated to follow a foreign key
+func TestFollowForeignKeySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		value    string
+		colType  ColumnType
+		dbType   string
+		expected string
+	}{
+		{"numeric", "users.id", "42", ColTypeNumeric, "sqlite", "SELECT * FROM users WHERE id = 42 LIMIT 100"},
+		{"text", "regions.code", "O'Brien", ColTypeText, "mysql", "SELECT * FROM regions WHERE code = 'O''Brien' LIMIT 100"},
+		{"schema qualified", "sales.Order Items.id", "7", ColTypeNumeric, "postgres", `SELECT * FROM sales."Order Items" WHERE id = 7 LIMIT 100`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := followForeignKeySQL(tc.ref, tc.value, tc.colType, tc.dbType)
+			if result != tc.expected {
+				t.Errorf("followForeignKeySQL() = %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestListDatabaseChoicesSQLite tests that SQLite lists its attached databases
+func TestListDatabaseChoicesSQLite(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("ATTACH DATABASE ':memory:' AS aux"); err != nil {
+		t.Fatalf("Failed to attach database: %v", err)
+	}
+
+	choices, err := listDatabaseChoices(db, "sqlite", "main")
+	if err != nil {
+		t.Fatalf("listDatabaseChoices() error: %v", err)
+	}
+	if len(choices) != 2 || choices[0].Name != "main" || choices[1].Name != "aux" {
+		t.Fatalf("choices = %+v, want main and aux", choices)
+	}
+	if !choices[0].Current || choices[1].Current || choices[1].Schema {
+		t.Errorf("choices = %+v, want only main marked current", choices)
+	}
+}
+
+// TestFilteredDatabaseChoices tests that the switcher ranks exact names,
+// then prefixes, then other matches
+func TestFilteredDatabaseChoices(t *testing.T) {
+	m := Model{databaseChoices: []DatabaseChoice{
+		{Name: "test_app"}, {Name: "app_test"}, {Name: "App"}, {Name: "postgres"},
+	}}
+	m.databaseInput = textinput.New()
+	m.databaseInput.SetValue("app")
+
+	var names []string
+	for _, c := range m.filteredDatabaseChoices() {
+		names = append(names, c.Name)
+	}
+	if want := []string{"App", "app_test", "test_app"}; !slices.Equal(names, want) {
+		t.Errorf("filteredDatabaseChoices() = %v, want %v", names, want)
+	}
+}
+
+// TestColumnComments tests matching catalog comments to result columns
+func TestColumnComments(t *testing.T) {
+	cache := &SchemaCache{loaded: true, tables: []SchemaTable{{
+		Name: "users",
+		Columns: []SchemaColumn{
+			{Name: "id"},
+			{Name: "st", Comment: "Account status: a=active, s=suspended"},
+		},
+	}}}
+	tab := &Tab{schema: cache, queryMeta: &QueryMeta{TableName: "public.users"}}
+	m := Model{tabs: []*Tab{tab}}
+
+	comments := m.columnComments([]string{"ST", "id", "computed"})
+	expected := []string{"Account status: a=active, s=suspended", "", ""}
+	for i := range expected {
+		if comments[i] != expected[i] {
+			t.Errorf("comment %d = %q, want %q", i, comments[i], expected[i])
+		}
+	}
+}
+
+// TestSchemaCacheRowCounts tests counting rows of SQLite tables
+func TestSchemaCacheRowCounts(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("CREATE VIEW active_users AS SELECT * FROM users WHERE is_active = 1"); err != nil {
+		t.Fatalf("Failed to create view: %v", err)
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	if _, err := cache.RowCounts(); err != nil {
+		t.Fatalf("RowCounts() error: %v", err)
+	}
+	if _, ok := cache.CachedRowCount("users"); ok {
+		t.Fatal("SQLite tables should not be counted until they're asked for")
+	}
+	if _, _, err := cache.TableRowCount("Users"); err != nil {
+		t.Fatalf("TableRowCount() error: %v", err)
+	}
+	count, ok := cache.CachedRowCount("users")
+	if !ok || count.Rows != 3 || count.Estimate {
+		t.Errorf("users count = %+v (cached %v), want exact 3", count, ok)
+	}
+	if _, ok, _ := cache.TableRowCount("active_users"); ok {
+		t.Error("views should not be counted")
+	}
+}
+
+// TestFormatRowCount tests abbreviated row counts
+func TestFormatRowCount(t *testing.T) {
+	tests := []struct {
+		count    RowCount
+		expected string
+	}{
+		{RowCount{Rows: 0}, "0"},
+		{RowCount{Rows: 950}, "950"},
+		{RowCount{Rows: 1234}, "1.2k"},
+		{RowCount{Rows: 56789, Estimate: true}, "~57k"},
+		{RowCount{Rows: 3_400_000}, "3.4M"},
+		{RowCount{Rows: 250_000_000, Estimate: true}, "~250M"},
+		{RowCount{Rows: 7_100_000_000}, "7.1B"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expected, func(t *testing.T) {
+			if result := formatRowCount(tc.count); result != tc.expected {
+				t.Errorf("formatRowCount(%+v) = %q, want %q", tc.count, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestSchemaCacheObjects tests loading triggers (SQLite's only stored objects)
+func TestSchemaCacheObjects(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("CREATE TRIGGER touch AFTER UPDATE ON users BEGIN UPDATE users SET notes = 'touched' WHERE id = NEW.id; END"); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	objects, err := cache.Objects()
+	if err != nil {
+		t.Fatalf("Objects() error: %v", err)
+	}
+	want := SchemaObject{Name: "touch", Kind: "trigger", Table: "users"}
+	if len(objects) != 1 || objects[0] != want {
+		t.Fatalf("Objects() = %+v, want [%+v]", objects, want)
+	}
+
+	ddl, err := objectDDL(db, "sqlite", objects[0])
+	if err != nil {
+		t.Fatalf("objectDDL() error: %v", err)
+	}
+	if !strings.HasPrefix(ddl, "CREATE TRIGGER touch AFTER UPDATE ON users") || !strings.HasSuffix(ddl, "END;") {
+		t.Errorf("objectDDL() = %q, want the CREATE TRIGGER statement", ddl)
+	}
+	if _, err := objectDDL(db, "sqlite", SchemaObject{Name: "missing", Kind: "trigger"}); err == nil {
+		t.Error("objectDDL() should fail for a missing trigger")
+	}
+
+	cache.Invalidate()
+	if cache.objectsLoaded {
+		t.Error("objects should be discarded on invalidation")
+	}
+}
+
+// TestObjectSummary tests the one-line trigger and routine description shown in the schema browser
+func TestObjectSummary(t *testing.T) {
+	tests := []struct {
+		obj  SchemaObject
+		want string
+	}{
+		{SchemaObject{Name: "touch", Kind: "trigger", Table: "users"}, "touch (on users)"},
+		{SchemaObject{Name: "refresh_totals", Kind: "procedure"}, "refresh_totals() procedure"},
+		{SchemaObject{Name: "full_name", Kind: "function"}, "full_name()"},
+	}
+
+	for _, tc := range tests {
+		if got := objectSummary(tc.obj); got != tc.want {
+			t.Errorf("objectSummary(%+v) = %q, want %q", tc.obj, got, tc.want)
+		}
+	}
+}
+
+// TestSchemaCacheSequence tests finding generated key columns in SQLite
+func TestSchemaCacheSequence(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"INSERT INTO events (name) VALUES ('a'), ('b'), ('c')",
+		"DELETE FROM events WHERE id = 3",
+		"CREATE TABLE tags (name TEXT PRIMARY KEY)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up tables: %v", err)
+		}
+	}
+
+	cache := NewSchemaCache(db, "sqlite")
+	tests := []struct {
+		table    string
+		wantNil  bool
+		wantNext int64
+	}{
+		{"users", false, 4},  // rowid alias: MAX(rowid) + 1
+		{"events", false, 4}, // AUTOINCREMENT doesn't reuse the deleted id 3
+		{"tags", true, 0},    // text key, nothing generated
+	}
+
+	for _, tc := range tests {
+		seq, err := cache.Sequence(tc.table)
+		if err != nil {
+			t.Fatalf("Sequence(%s) error: %v", tc.table, err)
+		}
+		if tc.wantNil {
+			if seq != nil {
+				t.Errorf("Sequence(%s) = %+v, want nil", tc.table, seq)
+			}
+			continue
+		}
+		if seq == nil || seq.Column != "id" || !seq.Next.Valid || seq.Next.Int64 != tc.wantNext {
+			t.Errorf("Sequence(%s) = %+v, want id with next %d", tc.table, seq, tc.wantNext)
+		}
+	}
+
+	if _, ok := cache.CachedSequence("TAGS"); !ok {
+		t.Error("a table without a sequence should still be cached")
+	}
+	cache.Invalidate()
+	if _, ok := cache.CachedSequence("users"); ok {
+		t.Error("sequences should be discarded on invalidation")
+	}
+}
+
+// TestSequenceSummary tests the key generator description and INSERT expressions
+func TestSequenceSummary(t *testing.T) {
+	seq := SchemaSequence{Column: "id", Sequence: "public.users_id_seq", Next: sql.NullInt64{Int64: 43, Valid: true}}
+	if got, want := sequenceSummary(seq), "id: next 43 (public.users_id_seq)"; got != want {
+		t.Errorf("sequenceSummary() = %q, want %q", got, want)
+	}
+	if got, want := sequenceSummary(SchemaSequence{Column: "id"}), "id: next unknown"; got != want {
+		t.Errorf("sequenceSummary() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		seq    *SchemaSequence
+		dbType string
+		want   string
+	}{
+		{&seq, "postgres", "nextval('public.users_id_seq')"},
+		{nil, "postgres", "DEFAULT"},
+		{&SchemaSequence{Column: "id"}, "mysql", "DEFAULT"},
+		{&SchemaSequence{Column: "id"}, "sqlite", "NULL"},
+	}
+	for _, tc := range tests {
+		if got := generatedKeyValue(tc.seq, tc.dbType); got != tc.want {
+			t.Errorf("generatedKeyValue(%+v, %s) = %q, want %q", tc.seq, tc.dbType, got, tc.want)
+		}
+	}
+}
+
+// TestSequenceQueryPostgresArgs tests that PostgreSQL's sequence lookup is
+// given the table's schema and name as they are, for format() to quote
+func TestSequenceQueryPostgresArgs(t *testing.T) {
+	tests := []struct {
+		table string
+		want  []any
+	}{
+		{"users", []any{"", "users"}},
+		{"Users", []any{"", "Users"}},
+		{"app.Order Items", []any{"app", "Order Items"}},
+	}
+	for _, tc := range tests {
+		query, args := sequenceQuery("postgres", tc.table)
+		if !slices.Equal(args, tc.want) {
+			t.Errorf("sequenceQuery(%q) args = %v, want %v", tc.table, args, tc.want)
+		}
+		if !strings.Contains(query, "to_regclass") {
+			t.Errorf("sequenceQuery(%q) should resolve the table with to_regclass: %s", tc.table, query)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// searchMatch is the byte range of a search match in the editor content
+type searchMatch struct {
+	start, end int
+}
+
+// EditorSearch is the incremental search over the query editor's text
+type EditorSearch struct {
+	input   textinput.Model
+	origin  int // cursor offset when the search started
+	matches []searchMatch
+	current int // index of the match at the cursor, or -1
+}
+
+// newEditorSearch starts a search from the cursor offset origin
+func newEditorSearch(origin int) *EditorSearch {
+	ti := textinput.New()
+	ti.Placeholder = "search the editor"
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Prompt = "Find: "
+	ti.Focus()
+	return &EditorSearch{input: ti, origin: origin, current: -1}
+}
+
+// update recomputes the matches for the current input in content and picks
+// the first one at or after the search's origin
+func (s *EditorSearch) update(content string) {
+	s.matches = findMatches(content, s.input.Value())
+	s.current = nextMatchIndex(s.matches, s.origin)
+}
+
+// step moves to the next (dir 1) or previous (dir -1) match, wrapping around
+func (s *EditorSearch) step(dir int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.current = (s.current + dir + len(s.matches)) % len(s.matches)
+}
+
+// findMatches returns every non-overlapping case-insensitive occurrence of
+// query in content, in order
+func findMatches(content, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	n := utf8.RuneCountInString(query)
+	var matches []searchMatch
+	for i := 0; i < len(content); {
+		// Compare the same number of runes, since folding case can change
+		// how many bytes a rune takes
+		end := i
+		for r := 0; r < n && end < len(content); r++ {
+			_, size := utf8.DecodeRuneInString(content[end:])
+			end += size
+		}
+		if strings.EqualFold(content[i:end], query) {
+			matches = append(matches, searchMatch{i, end})
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(content[i:])
+		i += size
+	}
+	return matches
+}
+
+// nextMatchIndex returns the index of the first match starting at or after
+// offset, wrapping around to the first match, or -1 if there are none
+func nextMatchIndex(matches []searchMatch, offset int) int {
+	if len(matches) == 0 {
+		return -1
+	}
+	for i, match := range matches {
+		if match.start >= offset {
+			return i
+		}
+	}
+	return 0
+}
+
+// searchSpan is a match's rune columns [from, to) on one line
+type searchSpan struct {
+	from, to int
+	current  bool
+}
+
+// lineSearchSpans returns the parts of the matches on a line that starts at
+// byte offset lineStart of the content. current is the index of the match
+// at the cursor.
+func lineSearchSpans(line string, lineStart int, matches []searchMatch, current int) []searchSpan {
+	lineEnd := lineStart + len(line)
+	var spans []searchSpan
+	for i, match := range matches {
+		if match.start >= lineEnd {
+			break
+		}
+		if match.end <= lineStart {
+			continue
+		}
+		from := utf8.RuneCountInString(line[:max(match.start-lineStart, 0)])
+		to := utf8.RuneCountInString(line[:min(match.end-lineStart, len(line))])
+		if from < to {
+			spans = append(spans, searchSpan{from, to, i == current})
+		}
+	}
+	return spans
+}
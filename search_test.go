@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFindMatches tests finding search matches in the editor content
+func TestFindMatches(t *testing.T) {
+	tests := []struct {
+		content string
+		query   string
+		want    []searchMatch
+	}{
+		{"SELECT * FROM users;\nselect 1;", "select", []searchMatch{{0, 6}, {21, 27}}},
+		{"aaaa", "aa", []searchMatch{{0, 2}, {2, 4}}},
+		{"SELECT 'Größe';", "GRÖSSE", nil},
+		{"SELECT 'größe' FROM t", "GRÖ", []searchMatch{{8, 12}}},
+		{"SELECT 1;", "", nil},
+		{"SELECT 1;", "from", nil},
+	}
+
+	for _, tc := range tests {
+		if got := findMatches(tc.content, tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("findMatches(%q, %q) = %v, want %v", tc.content, tc.query, got, tc.want)
+		}
+	}
+}
+
+// TestNextMatchIndex tests picking the first match after the cursor
+func TestNextMatchIndex(t *testing.T) {
+	matches := []searchMatch{{5, 8}, {20, 23}}
+	tests := []struct {
+		offset int
+		want   int
+	}{
+		{0, 0},
+		{5, 0},
+		{6, 1},
+		{21, 0}, // wraps around
+	}
+
+	for _, tc := range tests {
+		if got := nextMatchIndex(matches, tc.offset); got != tc.want {
+			t.Errorf("nextMatchIndex(%d) = %d, want %d", tc.offset, got, tc.want)
+		}
+	}
+	if got := nextMatchIndex(nil, 0); got != -1 {
+		t.Errorf("nextMatchIndex() with no matches = %d, want -1", got)
+	}
+}
+
+// TestLineSearchSpans tests placing search matches on a line
+func TestLineSearchSpans(t *testing.T) {
+	content := "SELECT id FROM users;\nSELECT 'é', id FROM t;"
+	matches := findMatches(content, "id")
+	tests := []struct {
+		line      string
+		lineStart int
+		want      []searchSpan
+	}{
+		{"SELECT id FROM users;", 0, []searchSpan{{7, 9, false}}},
+		{"SELECT 'é', id FROM t;", 22, []searchSpan{{12, 14, true}}},
+	}
+
+	for _, tc := range tests {
+		if got := lineSearchSpans(tc.line, tc.lineStart, matches, 1); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("lineSearchSpans(%q) = %v, want %v", tc.line, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Selection is a range of the query text selected with Shift+arrows, or
+// with Alt+V (vim's visual mode) followed by the plain movement keys
+type Selection struct {
+	anchor int  // byte offset where the selection started
+	visual bool // started with Alt+V: plain movement keys extend it
+}
+
+// selectionMoves maps the Shift+movement keys to the movement that extends
+// the selection
+var selectionMoves = map[string]tea.KeyType{
+	"shift+left":  tea.KeyLeft,
+	"shift+right": tea.KeyRight,
+	"shift+up":    tea.KeyUp,
+	"shift+down":  tea.KeyDown,
+	"shift+home":  tea.KeyHome,
+	"shift+end":   tea.KeyEnd,
+}
+
+// visualMoves are the movement keys that extend a visual (Alt+V) selection
+var visualMoves = map[string]bool{
+	"left": true, "right": true, "up": true, "down": true, "home": true, "end": true,
+	"pgup": true, "pgdown": true, "ctrl+home": true, "ctrl+end": true,
+	"alt+left": true, "alt+right": true, "alt+b": true, "alt+f": true,
+}
+
+// Range returns the selected byte range between the anchor and the cursor
+func (s *Selection) Range(cursor int) (int, int) {
+	return min(s.anchor, cursor), max(s.anchor, cursor)
+}
+
+// selectedText returns the selected statement to run: the selection trimmed
+// of surrounding whitespace and a trailing semicolon
+func selectedText(content string, start, end int) string {
+	text := strings.TrimSpace(content[start:end])
+	return strings.TrimSpace(strings.TrimSuffix(text, ";"))
+}
+
+// lineSelectionColumns returns the rune columns [from, to) of lines[line]
+// covered by the byte range [start, end) of the text the lines were split
+// from, or false if the selection doesn't touch that line
+func lineSelectionColumns(lines []string, line, start, end int) (int, int, bool) {
+	lineStart := 0
+	for _, l := range lines[:line] {
+		lineStart += len(l) + 1
+	}
+	text := lines[line]
+	lineEnd := lineStart + len(text)
+	if start >= end || end <= lineStart || start > lineEnd {
+		return 0, 0, false
+	}
+	from := utf8.RuneCountInString(text[:max(start-lineStart, 0)])
+	to := utf8.RuneCountInString(text[:min(end-lineStart, len(text))])
+	if from == to {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// pastedText prepares pasted text for the editor, which would turn each \r
+// of a Windows line ending into a line break of its own
+func pastedText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
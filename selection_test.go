@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLineSelectionColumns tests which part of each line a selection covers
+func TestLineSelectionColumns(t *testing.T) {
+	content := "SELECT id,\n  naïve\nFROM t;"
+	lines := strings.Split(content, "\n")
+
+	tests := []struct {
+		name       string
+		line       int
+		start, end int
+		ok         bool
+		from, to   int
+	}{
+		{"within a line", 0, 7, 9, true, 7, 9},
+		{"to the end of the first line", 0, 7, 14, true, 7, 10},
+		{"middle line fully covered", 1, 7, 25, true, 0, 7},
+		{"from the start of the last line", 2, 20, 24, true, 0, 4},
+		{"multi-byte characters", 1, 13, 19, true, 2, 7},
+		{"before the line", 2, 0, 9, false, 0, 0},
+		{"after the line", 0, 11, 20, false, 0, 0},
+		{"starting at the line break", 0, 10, 20, false, 0, 0},
+		{"empty selection", 0, 3, 3, false, 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to, ok := lineSelectionColumns(lines, tc.line, tc.start, tc.end)
+			if ok != tc.ok || from != tc.from || to != tc.to {
+				t.Errorf("lineSelectionColumns(line %d, %d, %d) = %d, %d, %v, want %d, %d, %v",
+					tc.line, tc.start, tc.end, from, to, ok, tc.from, tc.to, tc.ok)
+			}
+		})
+	}
+}
+
+// TestSelectedText tests trimming a selection into a runnable statement
+func TestSelectedText(t *testing.T) {
+	tests := []struct {
+		content    string
+		start, end int
+		want       string
+	}{
+		{"SELECT 1 + 2 FROM t;", 7, 12, "1 + 2"},
+		{"SELECT 1;\n\nSELECT 2;", 0, 11, "SELECT 1"},
+		{"  SELECT 2;  ", 0, 13, "SELECT 2"},
+		{"SELECT 1;", 2, 2, ""},
+	}
+
+	for _, tc := range tests {
+		if got := selectedText(tc.content, tc.start, tc.end); got != tc.want {
+			t.Errorf("selectedText(%q, %d, %d) = %q, want %q", tc.content, tc.start, tc.end, got, tc.want)
+		}
+	}
+}
+
+// TestPastedText tests normalizing line endings of pasted text
+func TestPastedText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"SELECT 1;", "SELECT 1;"},
+		{"SELECT 1\r\nFROM t;\r\n", "SELECT 1\nFROM t;\n"},
+		{"SELECT 1\rFROM t;", "SELECT 1\nFROM t;"},
+		{"SELECT '\t';\n", "SELECT '\t';\n"},
+	}
+
+	for _, tc := range tests {
+		if got := pastedText(tc.text); got != tc.want {
+			t.Errorf("pastedText(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// sessionSetting is a session-level setting the session settings panel can
+// change, applied through the DSN so every pooled connection gets it
+type sessionSetting struct {
+	name  string // as saved in the config
+	param string // the DSN parameter that sets it
+	query string // reads the session's current value
+	hint  string
+}
+
+// sessionSettings returns the settings the panel offers for a database type
+func sessionSettings(dbType string) []sessionSetting {
+	switch getDriverName(dbType) {
+	case "pgx":
+		return []sessionSetting{
+			{"search_path", "search_path", "SHOW search_path", "schemas in search order, e.g. app, public"},
+			{"timezone", "timezone", "SHOW TimeZone", "e.g. UTC or Europe/Paris"},
+		}
+	case "mysql":
+		return []sessionSetting{
+			{"time_zone", "time_zone", "SELECT @@SESSION.time_zone", "e.g. +00:00 or Europe/Paris (needs the time zone tables)"},
+			{"sql_mode", "sql_mode", "SELECT @@SESSION.sql_mode", "comma-separated, e.g. ANSI_QUOTES,STRICT_TRANS_TABLES"},
+		}
+	case "sqlite3":
+		return []sessionSetting{
+			{"foreign_keys", "_foreign_keys", "PRAGMA foreign_keys", "on or off"},
+		}
+	}
+	return nil
+}
+
+// SessionPanel shows the active tab's session settings and changes them
+type SessionPanel struct {
+	tab      *Tab
+	settings []sessionSetting
+	values   []string // each setting's current value, as the server reports it
+	selected int
+	editing  bool
+	input    textinput.Model
+	err      string
+}
+
+// openSessionPanel opens the session settings of the active tab's connection
+func (m *Model) openSessionPanel() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.db == nil {
+		return
+	}
+	settings := sessionSettings(tab.dbType)
+	if len(settings) == 0 {
+		m.statusMessage = "No session settings for this database"
+		return
+	}
+	ti := textinput.New()
+	ti.CharLimit = 256
+	ti.Width = 50
+	m.sessionPanel = &SessionPanel{tab: tab, settings: settings, input: ti}
+	m.sessionPanel.refresh()
+	m.focus = focusSession
+	tab.textarea.Blur()
+}
+
+// refresh reads each setting's current value from the database
+func (p *SessionPanel) refresh() {
+	p.values = make([]string, len(p.settings))
+	for i, s := range p.settings {
+		var value string
+		if err := p.tab.db.QueryRow(s.query).Scan(&value); err != nil {
+			value = "?"
+		}
+		p.values[i] = value
+	}
+}
+
+// edit starts editing the selected setting, starting from its saved value
+// or else its current one
+func (p *SessionPanel) edit() {
+	s := p.settings[p.selected]
+	value, ok := p.tab.session[s.name]
+	if !ok {
+		value = p.values[p.selected]
+	}
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	p.input.Placeholder = s.hint
+	p.input.Focus()
+	p.editing = true
+	p.err = ""
+}
+
+// applySessionSetting sets the selected setting to value, or back to the
+// server's default if value is empty, by reconnecting the tab. The setting
+// is saved with the connection, so it's applied again on every reconnect.
+func (m *Model) applySessionSetting(value string) {
+	p := m.sessionPanel
+	tab := p.tab
+	s := p.settings[p.selected]
+	if tab.tx != nil {
+		p.err = "Commit or roll back the open transaction first"
+		return
+	}
+	if tab.running != "" {
+		p.err = "Wait for the running query to finish"
+		return
+	}
+
+	previous, had := tab.session[s.name]
+	setSession := func(value string) {
+		if value == "" {
+			delete(tab.session, s.name)
+			return
+		}
+		if tab.session == nil {
+			tab.session = make(map[string]string)
+		}
+		tab.session[s.name] = value
+	}
+	setSession(value)
+	if err := m.reconnectTab(tab.dsn); err != nil {
+		if had {
+			setSession(previous)
+		} else {
+			setSession("")
+		}
+		p.err = err.Error()
+		return
+	}
+
+	saved := ""
+	if m.vaultManager != nil && tab.connectionName != "" {
+		if err := m.vaultManager.SetSessionSetting(tab.connectionName, s.name, value); err != nil {
+			p.err = fmt.Sprintf("Applied, but not saved: %v", err)
+		} else {
+			saved = ", saved for " + tab.connectionName
+		}
+	}
+	p.editing = false
+	p.input.Blur()
+	p.refresh()
+	if value == "" {
+		m.statusMessage = fmt.Sprintf("%s reset to the server default%s", s.name, saved)
+	} else {
+		m.statusMessage = fmt.Sprintf("%s set to %s%s", s.name, value, saved)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// snippetsDirName is the directory under the SQL directory holding snippet
+// files, one named SQL fragment per .sql file
+const snippetsDirName = "snippets"
+
+// placeholderPattern matches a ${name} placeholder in an inserted snippet
+var placeholderPattern = regexp.MustCompile(`\$\{[^{}\n]*\}`)
+
+// Snippet is a named SQL fragment that can be inserted at the cursor
+type Snippet struct {
+	Name string
+	Body string
+}
+
+// snippetsDir returns the snippets directory in the SQL directory
+func snippetsDir(sqlDir string) string {
+	return filepath.Join(sqlDir, snippetsDirName)
+}
+
+// loadSnippets reads every .sql file in dir as a snippet named after the
+// file, sorted by name. A missing directory has no snippets.
+func loadSnippets(dir string) ([]Snippet, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snippets directory: %w", err)
+	}
+
+	var snippets []Snippet
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(strings.ToLower(file.Name()), ".sql") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snippet %s: %w", file.Name(), err)
+		}
+		snippets = append(snippets, Snippet{
+			Name: strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())),
+			Body: strings.TrimRight(string(data), "\r\n"),
+		})
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return strings.ToLower(snippets[i].Name) < strings.ToLower(snippets[j].Name)
+	})
+	return snippets, nil
+}
+
+// nextPlaceholder returns the byte range of the first ${name} placeholder at
+// or after byte offset from in content, or -1, -1 if there is none
+func nextPlaceholder(content string, from int) (int, int) {
+	loc := placeholderPattern.FindStringIndex(content[from:])
+	if loc == nil {
+		return -1, -1
+	}
+	return from + loc[0], from + loc[1]
+}
+
+// SnippetPicker is the fuzzy-search popup over the snippet library
+type SnippetPicker struct {
+	dir      string
+	input    textinput.Model
+	snippets []Snippet
+	matches  []Snippet
+	selected int
+}
+
+// newSnippetPicker creates a picker over snippets loaded from dir
+func newSnippetPicker(dir string, snippets []Snippet) *SnippetPicker {
+	ti := textinput.New()
+	ti.Placeholder = "snippet name"
+	ti.CharLimit = 128
+	ti.Width = 40
+	ti.Focus()
+
+	p := &SnippetPicker{dir: dir, input: ti, snippets: snippets}
+	p.filter()
+	return p
+}
+
+// filter recomputes the matches for the current input, best first
+func (p *SnippetPicker) filter() {
+	pattern := strings.TrimSpace(p.input.Value())
+	type scored struct {
+		snippet Snippet
+		score   int
+	}
+	var matches []scored
+	for _, s := range p.snippets {
+		if score, ok := fuzzyScore(pattern, s.Name); ok {
+			matches = append(matches, scored{s, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	p.matches = p.matches[:0]
+	for _, m := range matches {
+		p.matches = append(p.matches, m.snippet)
+	}
+	p.selected = 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadSnippets tests reading .sql files from the snippets directory
+func TestLoadSnippets(t *testing.T) {
+	dir := t.TempDir()
+
+	snippets, err := loadSnippets(filepath.Join(dir, "missing"))
+	if err != nil || snippets != nil {
+		t.Fatalf("loadSnippets() of a missing directory = %v, %v; want no snippets and no error", snippets, err)
+	}
+
+	files := map[string]string{
+		"top_n.sql":     "SELECT * FROM ${table}\nORDER BY ${column} DESC\nLIMIT 10;\n",
+		"Count.SQL":     "SELECT COUNT(*) FROM ${table};\r\n",
+		"notes.txt":     "not a snippet",
+		"sub/inner.sql": "SELECT 1;",
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snippets, err = loadSnippets(dir)
+	if err != nil {
+		t.Fatalf("loadSnippets() error: %v", err)
+	}
+	want := []Snippet{
+		{Name: "Count", Body: "SELECT COUNT(*) FROM ${table};"},
+		{Name: "top_n", Body: "SELECT * FROM ${table}\nORDER BY ${column} DESC\nLIMIT 10;"},
+	}
+	if len(snippets) != len(want) {
+		t.Fatalf("loadSnippets() = %+v, want %+v", snippets, want)
+	}
+	for i := range want {
+		if snippets[i] != want[i] {
+			t.Errorf("snippet %d = %+v, want %+v", i, snippets[i], want[i])
+		}
+	}
+}
+
+// TestNextPlaceholder tests finding the next ${name} placeholder from an offset
+func TestNextPlaceholder(t *testing.T) {
+	content := "SELECT ${cols} FROM t\nWHERE ${} = 1 AND x = '${not\nclosed'"
+
+	tests := []struct {
+		name  string
+		from  int
+		start int
+		end   int
+	}{
+		{"first", 0, 7, 14},
+		{"at placeholder", 7, 7, 14},
+		{"empty name", 8, 28, 31},
+		{"none left", 31, -1, -1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := nextPlaceholder(content, tc.from)
+			if start != tc.start || end != tc.end {
+				t.Errorf("nextPlaceholder(%d) = %d, %d; want %d, %d", tc.from, start, end, tc.start, tc.end)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -9,16 +10,50 @@ import (
 // It respects:
 // - Single-quoted strings ('...')
 // - Double-quoted strings ("...")
+// - Backtick-quoted identifiers (`...`)
 // - Line comments (--)
 // - Block comments (/* ... */)
+// - PostgreSQL dollar-quoted strings ($$...$$ and $tag$...$tag$)
 //
-// It does NOT handle:
-// - PostgreSQL dollar-quoted strings ($$...$$)
-// - MySQL DELIMITER command
-// - Backtick-quoted identifiers containing semicolons
+// SplitStatementsForDB also handles MySQL's DELIMITER command.
 func SplitStatements(sql string) []string {
+	return SplitStatementsForDB(sql, "")
+}
+
+// SplitStatementsForDB splits a SQL string like SplitStatements, and also
+// respects database-specific syntax such as MySQL's # line comments and
+// DELIMITER command (which also turns off dollar quoting, since MySQL scripts
+// use $$ as a DELIMITER). DELIMITER lines aren't returned as statements.
+func SplitStatementsForDB(sql string, dbType string) []string {
 	var statements []string
-	var current strings.Builder
+	for _, r := range splitStatementRanges(sql, dbType) {
+		statements = append(statements, sql[r.start:r.end])
+	}
+	return statements
+}
+
+// stmtRange is the byte range [start, end) of a statement in a SQL string,
+// without surrounding whitespace or its terminating semicolon
+type stmtRange struct {
+	start, end int
+}
+
+// splitStatementRanges finds where each statement SplitStatementsForDB
+// returns is in sql
+func splitStatementRanges(sql string, dbType string) []stmtRange {
+	hashComments := hashCommentsAllowed(dbType)
+	dollarQuotes := dollarQuotesAllowed(dbType)
+	delimiters := delimiterCommandsAllowed(dbType)
+	delimiter := ";"
+	var ranges []stmtRange
+	start := 0 // start of the current statement
+
+	// add records sql[from:to] as a statement, unless it's blank
+	add := func(from, to int) {
+		if r, ok := trimStatementRange(sql, from, to); ok {
+			ranges = append(ranges, r)
+		}
+	}
 
 	i := 0
 	n := len(sql)
@@ -26,125 +61,283 @@ func SplitStatements(sql string) []string {
 	for i < n {
 		ch := sql[i]
 
-		// Check for line comment (--)
-		if ch == '-' && i+1 < n && sql[i+1] == '-' {
-			// Consume until end of line
-			current.WriteByte(ch)
-			i++
-			for i < n && sql[i] != '\n' {
-				current.WriteByte(sql[i])
-				i++
+		// A backslash meta-command (\dt, \d users) at the start of a
+		// statement runs to the end of its line; no semicolon is needed
+		if ch == '\\' && strings.TrimSpace(sql[start:i]) == "" {
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = n - i
 			}
-			if i < n {
-				current.WriteByte(sql[i]) // include the newline
-				i++
+			line := strings.TrimRight(sql[i:i+end], " \t\r")
+			ranges = append(ranges, stmtRange{i, i + len(strings.TrimSuffix(line, ";"))})
+			i += end
+			start = i
+			continue
+		}
+
+		// A DELIMITER line at the start of a statement changes the
+		// terminator, e.g. to $$ so a procedure body's semicolons don't end it
+		if delimiters && (ch == 'D' || ch == 'd') && skipLeadingComments(sql[start:i], hashComments) == "" {
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = n - i
 			}
+			if d, ok := delimiterCommand(sql[i : i+end]); ok {
+				delimiter = d
+				i += end
+				start = i
+				continue
+			}
+		}
+
+		// Skip comments, strings and quoted identifiers
+		if end := skipQuoted(sql, i, hashComments, dollarQuotes); end > i {
+			i = end
 			continue
 		}
 
-		// Check for block comment (/* ... */)
-		if ch == '/' && i+1 < n && sql[i+1] == '*' {
-			current.WriteByte(ch)
-			i++
-			current.WriteByte(sql[i]) // *
-			i++
-			// Consume until */
-			for i < n {
-				if sql[i] == '*' && i+1 < n && sql[i+1] == '/' {
-					current.WriteByte(sql[i])
-					i++
-					current.WriteByte(sql[i])
-					i++
-					break
-				}
-				current.WriteByte(sql[i])
+		// Check for statement terminator
+		if strings.HasPrefix(sql[i:], delimiter) {
+			add(start, i)
+			i += len(delimiter)
+			start = i
+			continue
+		}
+
+		// Regular character
+		i++
+	}
+
+	// Don't forget the last statement (may not end with semicolon)
+	add(start, n)
+
+	return ranges
+}
+
+// skipQuoted returns the offset just past the comment, string or quoted
+// identifier starting at sql[i], or i if there isn't one there
+func skipQuoted(sql string, i int, hashComments, dollarQuotes bool) int {
+	n := len(sql)
+	switch ch := sql[i]; {
+	case (ch == '-' && i+1 < n && sql[i+1] == '-') || (ch == '#' && hashComments):
+		// Line comment (-- or MySQL #), up to and including the newline
+		end := strings.IndexByte(sql[i:], '\n')
+		if end < 0 {
+			return n
+		}
+		return i + end + 1
+
+	case ch == '/' && i+1 < n && sql[i+1] == '*':
+		// Block comment (/* ... */)
+		end := strings.Index(sql[i+2:], "*/")
+		if end < 0 {
+			return n
+		}
+		return i + 2 + end + 2
+
+	case ch == '$' && dollarQuotes:
+		// Dollar-quoted string ($$...$$ or $tag$...$tag$)
+		if tag := dollarQuoteTag(sql, i); tag != "" {
+			return dollarQuoteEnd(sql, i, tag)
+		}
+
+	case ch == '\'':
+		// Single-quoted string, with '' and MySQL's backslash escapes
+		for i++; i < n; i++ {
+			switch {
+			case sql[i] == '\\' && i+1 < n:
+				i++
+			case sql[i] == '\'' && i+1 < n && sql[i+1] == '\'':
 				i++
+			case sql[i] == '\'':
+				return i + 1
 			}
-			continue
 		}
+		return n
 
-		// Check for single-quoted string
-		if ch == '\'' {
-			current.WriteByte(ch)
-			i++
-			for i < n {
-				if sql[i] == '\'' {
-					current.WriteByte(sql[i])
-					i++
-					// Check for escaped quote ('')
-					if i < n && sql[i] == '\'' {
-						current.WriteByte(sql[i])
-						i++
-						continue
-					}
-					break
-				}
-				// Handle backslash escape (MySQL style)
-				if sql[i] == '\\' && i+1 < n {
-					current.WriteByte(sql[i])
-					i++
-					current.WriteByte(sql[i])
+	case ch == '"':
+		// Double-quoted string or identifier, with "" escapes
+		for i++; i < n; i++ {
+			if sql[i] == '"' {
+				if i+1 < n && sql[i+1] == '"' {
 					i++
 					continue
 				}
-				current.WriteByte(sql[i])
-				i++
+				return i + 1
 			}
-			continue
 		}
+		return n
 
-		// Check for double-quoted string/identifier
-		if ch == '"' {
-			current.WriteByte(ch)
-			i++
-			for i < n {
-				if sql[i] == '"' {
-					current.WriteByte(sql[i])
-					i++
-					// Check for escaped quote ("")
-					if i < n && sql[i] == '"' {
-						current.WriteByte(sql[i])
-						i++
-						continue
-					}
-					break
-				}
-				current.WriteByte(sql[i])
+	case ch == '`':
+		// Backtick-quoted identifier (MySQL, SQLite)
+		return backtickEnd(sql, i)
+	}
+	return i
+}
+
+// backtickEnd returns the offset just past the closing backtick of the
+// identifier quoted at sql[i], or len(sql) if it isn't closed. Two backticks in
+// a row inside the identifier are an escaped backtick.
+func backtickEnd(sql string, i int) int {
+	for i++; i < len(sql); i++ {
+		if sql[i] == '`' {
+			if i+1 < len(sql) && sql[i+1] == '`' {
 				i++
+				continue
 			}
-			continue
+			return i + 1
 		}
+	}
+	return len(sql)
+}
 
-		// Check for statement terminator
-		if ch == ';' {
-			stmt := strings.TrimSpace(current.String())
-			if stmt != "" {
-				statements = append(statements, stmt)
-			}
-			current.Reset()
-			i++
-			continue
+// trimStatementRange narrows sql[from:to] to exclude surrounding whitespace,
+// reporting false if nothing is left
+func trimStatementRange(sql string, from, to int) (stmtRange, bool) {
+	segment := sql[from:to]
+	trimmed := strings.TrimSpace(segment)
+	if trimmed == "" {
+		return stmtRange{}, false
+	}
+	from += strings.Index(segment, trimmed)
+	return stmtRange{from, from + len(trimmed)}, true
+}
+
+// hashCommentsAllowed reports whether # starts a line comment for the database type.
+// In PostgreSQL # is an operator, so it is only treated as a comment for MySQL.
+func hashCommentsAllowed(dbType string) bool {
+	return strings.ToLower(dbType) == "mysql"
+}
+
+// dollarQuotesAllowed reports whether $$ and $tag$ start dollar-quoted
+// strings for the database type. MySQL scripts use $$ as a DELIMITER instead.
+func dollarQuotesAllowed(dbType string) bool {
+	return strings.ToLower(dbType) != "mysql"
+}
+
+// delimiterCommandsAllowed reports whether DELIMITER lines change the
+// statement terminator for the database type. DELIMITER is a mysql client
+// command, so it's only recognized for MySQL.
+func delimiterCommandsAllowed(dbType string) bool {
+	return strings.ToLower(dbType) == "mysql"
+}
+
+// delimiterCommand returns the new terminator if line is a DELIMITER
+// command, e.g. "DELIMITER $$" or "delimiter ;"
+func delimiterCommand(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "DELIMITER") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// dollarQuoteTag returns the opening tag ($$ or $tag$) of a dollar-quoted
+// string starting at sql[i], or "" if there isn't one there. A tag is an
+// identifier that doesn't start with a digit, so $1 parameters don't count.
+func dollarQuoteTag(sql string, i int) string {
+	if sql[i] != '$' || (i > 0 && isIdentifierByte(sql[i-1])) {
+		return ""
+	}
+	j := i + 1
+	for ; j < len(sql) && sql[j] != '$'; j++ {
+		ch := sql[j]
+		if !(ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (j > i+1 && ch >= '0' && ch <= '9')) {
+			return ""
 		}
+	}
+	if j >= len(sql) {
+		return ""
+	}
+	return sql[i : j+1]
+}
 
-		// Regular character
-		current.WriteByte(ch)
-		i++
+// dollarQuoteEnd returns the offset just past the closing tag of the
+// dollar-quoted string opened by tag at sql[i], or len(sql) if it isn't closed
+func dollarQuoteEnd(sql string, i int, tag string) int {
+	end := strings.Index(sql[i+len(tag):], tag)
+	if end < 0 {
+		return len(sql)
 	}
+	return i + len(tag) + end + len(tag)
+}
 
-	// Don't forget the last statement (may not end with semicolon)
-	stmt := strings.TrimSpace(current.String())
-	if stmt != "" {
-		statements = append(statements, stmt)
+// dollarQuoteRanges returns the byte ranges [start, end) of the dollar-quoted
+// strings in sql, skipping dollar signs in comments and other strings
+func dollarQuoteRanges(sql string, dbType string) [][2]int {
+	if !dollarQuotesAllowed(dbType) || !strings.Contains(sql, "$") {
+		return nil
 	}
+	var ranges [][2]int
+	n := len(sql)
+	for i := 0; i < n; i++ {
+		switch ch := sql[i]; {
+		case ch == '-' && i+1 < n && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return ranges
+			}
+			i += end
+		case ch == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return ranges
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(sql[i+1:], ch)
+			if end < 0 {
+				return ranges
+			}
+			i += end + 1
+		case ch == '$':
+			if tag := dollarQuoteTag(sql, i); tag != "" {
+				end := dollarQuoteEnd(sql, i, tag)
+				ranges = append(ranges, [2]int{i, end})
+				i = end - 1
+			}
+		}
+	}
+	return ranges
+}
 
-	return statements
+// isCommentOnly reports whether a statement is nothing but comments (such as
+// a trailing comment after the last semicolon), so there is nothing to run
+func isCommentOnly(stmt string, dbType string) bool {
+	return skipLeadingComments(stmt, hashCommentsAllowed(dbType)) == ""
+}
+
+// skipLeadingComments returns a statement without the whitespace and
+// comments before its first keyword, e.g. a "-- @name:" bookmark
+func skipLeadingComments(stmt string, hashComments bool) string {
+	rest := strings.TrimSpace(stmt)
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "--") || (hashComments && rest[0] == '#'):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return ""
+			}
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return ""
+			}
+			rest = rest[end+2:]
+		default:
+			return rest
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return ""
 }
 
 // IsSelectStatement returns true if the statement appears to be a SELECT query
 // (or other query that returns rows like SHOW, DESCRIBE, EXPLAIN, etc.)
 func IsSelectStatement(stmt string) bool {
-	// Trim leading whitespace and get the first word
-	trimmed := strings.TrimLeftFunc(stmt, unicode.IsSpace)
+	// Skip leading whitespace and comments to get to the first word
+	trimmed := skipLeadingComments(stmt, false)
 
 	// Handle common prefixes like WITH (CTE)
 	keywords := []string{
@@ -170,3 +363,35 @@ func IsSelectStatement(stmt string) bool {
 
 	return false
 }
+
+// ReturnsRows returns true if the statement produces a result set: a read,
+// or an INSERT, UPDATE, DELETE or MERGE with a RETURNING clause
+// (PostgreSQL, SQLite, MariaDB)
+func ReturnsRows(stmt string) bool {
+	if IsSelectStatement(stmt) {
+		return true
+	}
+	words := topLevelWords(stmt)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE":
+		return slices.Contains(words, "RETURNING")
+	}
+	return false
+}
+
+// IsDDLStatement returns true if the statement changes the schema
+// (CREATE, ALTER, DROP, RENAME)
+func IsDDLStatement(stmt string) bool {
+	fields := strings.Fields(skipLeadingComments(stmt, false))
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CREATE", "ALTER", "DROP", "RENAME":
+		return true
+	}
+	return false
+}
@@ -41,6 +41,16 @@ func TestSplitStatements(t *testing.T) {
 			input:    `SELECT "col;name" FROM t; SELECT 2`,
 			expected: []string{`SELECT "col;name" FROM t`, "SELECT 2"},
 		},
+		{
+			name:     "semicolon in backtick identifier",
+			input:    "SELECT `weird;name` FROM t; SELECT 2",
+			expected: []string{"SELECT `weird;name` FROM t", "SELECT 2"},
+		},
+		{
+			name:     "escaped backtick",
+			input:    "SELECT 1 FROM `a``b;c`; SELECT 2",
+			expected: []string{"SELECT 1 FROM `a``b;c`", "SELECT 2"},
+		},
 		{
 			name:     "escaped single quote",
 			input:    "SELECT 'it''s a test; really'; SELECT 2",
@@ -89,6 +99,36 @@ func TestSplitStatements(t *testing.T) {
 				"SELECT\n  *\nFROM\n  orders",
 			},
 		},
+		{
+			name:     "meta-commands end at the line",
+			input:    "\\dt\n\\d users;\nSELECT 1;",
+			expected: []string{"\\dt", "\\d users", "SELECT 1"},
+		},
+		{
+			name:     "dollar-quoted function body",
+			input:    "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT 2",
+			expected: []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", "SELECT 2"},
+		},
+		{
+			name:     "tagged dollar quote containing $$",
+			input:    "DO $body$ BEGIN PERFORM $$a;b$$; END; $body$; SELECT 2",
+			expected: []string{"DO $body$ BEGIN PERFORM $$a;b$$; END; $body$", "SELECT 2"},
+		},
+		{
+			name:     "positional parameters are not dollar quotes",
+			input:    "SELECT $1; SELECT $2",
+			expected: []string{"SELECT $1", "SELECT $2"},
+		},
+		{
+			name:     "dollar in identifier is not a dollar quote",
+			input:    "SELECT a$b$ FROM t; SELECT 2",
+			expected: []string{"SELECT a$b$ FROM t", "SELECT 2"},
+		},
+		{
+			name:     "backslash inside a statement is not a meta-command",
+			input:    "SELECT 1\n\\dt;",
+			expected: []string{"SELECT 1\n\\dt"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,6 +141,85 @@ func TestSplitStatements(t *testing.T) {
 	}
 }
 
+func TestSplitStatementsForDB(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "mysql hash comment with semicolon",
+			dbType:   "mysql",
+			input:    "SELECT 1 # first; not a split\nFROM dual; SELECT 2",
+			expected: []string{"SELECT 1 # first; not a split\nFROM dual", "SELECT 2"},
+		},
+		{
+			name:     "mysql hash comment line",
+			dbType:   "mysql",
+			input:    "# setup; step one\nDELETE FROM t;",
+			expected: []string{"# setup; step one\nDELETE FROM t"},
+		},
+		{
+			name:     "mysql hash inside string",
+			dbType:   "mysql",
+			input:    "SELECT '#a;b'; SELECT 2",
+			expected: []string{"SELECT '#a;b'", "SELECT 2"},
+		},
+		{
+			name:     "postgres hash is not a comment",
+			dbType:   "postgres",
+			input:    "SELECT 5 # 3; SELECT 2",
+			expected: []string{"SELECT 5 # 3", "SELECT 2"},
+		},
+		{
+			name:     "mysql $$ is not a dollar quote",
+			dbType:   "mysql",
+			input:    "SELECT '$$'; SELECT $$; SELECT 3",
+			expected: []string{"SELECT '$$'", "SELECT $$", "SELECT 3"},
+		},
+		{
+			name:     "unknown type hash is not a comment",
+			dbType:   "",
+			input:    "SELECT 1 # a; SELECT 2",
+			expected: []string{"SELECT 1 # a", "SELECT 2"},
+		},
+		{
+			name:     "mysql delimiter for procedure body",
+			dbType:   "mysql",
+			input:    "DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND$$\nDELIMITER ;\nCALL p();",
+			expected: []string{"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND", "CALL p()"},
+		},
+		{
+			name:     "mysql delimiter after statement",
+			dbType:   "mysql",
+			input:    "SELECT 1;\n-- triggers\ndelimiter //\nCREATE TRIGGER t BEFORE INSERT ON x FOR EACH ROW BEGIN SET NEW.a = 1; END //",
+			expected: []string{"SELECT 1", "CREATE TRIGGER t BEFORE INSERT ON x FOR EACH ROW BEGIN SET NEW.a = 1; END"},
+		},
+		{
+			name:     "delimiter inside a statement is not a command",
+			dbType:   "mysql",
+			input:    "SELECT 1\nDELIMITER $$; SELECT 2",
+			expected: []string{"SELECT 1\nDELIMITER $$", "SELECT 2"},
+		},
+		{
+			name:     "postgres has no delimiter command",
+			dbType:   "postgres",
+			input:    "DELIMITER //\nSELECT 1;",
+			expected: []string{"DELIMITER //\nSELECT 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SplitStatementsForDB(tt.input, tt.dbType)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("SplitStatementsForDB(%q, %q)\n  got:  %#v\n  want: %#v", tt.input, tt.dbType, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsSelectStatement(t *testing.T) {
 	tests := []struct {
 		stmt     string
@@ -110,6 +229,9 @@ func TestIsSelectStatement(t *testing.T) {
 		{"select * from users", true},
 		{"  SELECT * FROM users", true},
 		{"\n\tSELECT 1", true},
+		{"-- @name: report\nSELECT 1", true},
+		{"/* count */ SELECT 1", true},
+		{"-- remove them\nDELETE FROM users", false},
 		{"WITH cte AS (SELECT 1) SELECT * FROM cte", true},
 		{"SHOW TABLES", true},
 		{"DESCRIBE users", true},
@@ -137,3 +259,76 @@ func TestIsSelectStatement(t *testing.T) {
 		})
 	}
 }
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		stmt     string
+		expected bool
+	}{
+		{"SELECT * FROM users", true},
+		{"INSERT INTO users (name) VALUES ('x') RETURNING id", true},
+		{"update users set age = 31 where id = 1 returning *", true},
+		{"-- gone\nDELETE FROM users WHERE id = 1 RETURNING id, name;", true},
+		{"INSERT INTO users VALUES (1)", false},
+		{"DELETE FROM users WHERE note = 'RETURNING'", false},
+		{"UPDATE users SET name = (SELECT name FROM x RETURNING y)", false},
+		{"CREATE TABLE returning_rows (id INT)", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			result := ReturnsRows(tt.stmt)
+			if result != tt.expected {
+				t.Errorf("ReturnsRows(%q) = %v, want %v", tt.stmt, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsDDLStatement(t *testing.T) {
+	tests := []struct {
+		stmt     string
+		expected bool
+	}{
+		{"CREATE TABLE t (id INT)", true},
+		{"  create index idx ON t (id)", true},
+		{"ALTER TABLE t ADD col INT", true},
+		{"DROP TABLE t", true},
+		{"RENAME TABLE a TO b", true},
+		{"SELECT * FROM created", false},
+		{"INSERT INTO t VALUES (1)", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			result := IsDDLStatement(tt.stmt)
+			if result != tt.expected {
+				t.Errorf("IsDDLStatement(%q) = %v, want %v", tt.stmt, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsCommentOnly(t *testing.T) {
+	tests := []struct {
+		stmt     string
+		dbType   string
+		expected bool
+	}{
+		{"-- trailing comment", "", true},
+		{"/* block */\n-- and line", "postgres", true},
+		{"", "", true},
+		{"-- setup\nDELETE FROM t", "", false},
+		{"/* hint */ SELECT 1", "", false},
+		{"# mysql comment", "mysql", true},
+		{"# not a comment", "postgres", false},
+	}
+
+	for _, tt := range tests {
+		if got := isCommentOnly(tt.stmt, tt.dbType); got != tt.expected {
+			t.Errorf("isCommentOnly(%q, %q) = %v, want %v", tt.stmt, tt.dbType, got, tt.expected)
+		}
+	}
+}
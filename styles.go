@@ -25,6 +25,13 @@ type ThemedStyles struct {
 	NumericValue    lipgloss.Style
 	BooleanValue    lipgloss.Style
 	NullCell        lipgloss.Style
+	NumericCell     lipgloss.Style
+	BooleanCell     lipgloss.Style
+	AddedCell       lipgloss.Style // a row a re-run added
+	ChangedCell     lipgloss.Style // a value a re-run changed
+	RemovedCell     lipgloss.Style // a row a re-run no longer returned
+	MatchCell       lipgloss.Style // a value the results search matches
+	CurrentMatch    lipgloss.Style // a match in the selected cell
 }
 
 // NewThemedStyles creates a new ThemedStyles from a Theme
@@ -117,6 +124,51 @@ func NewThemedStyles(t Theme) ThemedStyles {
 			Foreground(t.TextDim).
 			Italic(true).
 			Padding(0, 1),
+
+		NumericCell: lipgloss.NewStyle().
+			Foreground(t.SyntaxNumber).
+			Padding(0, 1),
+
+		BooleanCell: lipgloss.NewStyle().
+			Foreground(t.SyntaxBoolean).
+			Padding(0, 1),
+
+		AddedCell: lipgloss.NewStyle().
+			Foreground(t.Success).
+			Padding(0, 1),
+
+		ChangedCell: lipgloss.NewStyle().
+			Foreground(t.Warning).
+			Bold(true).
+			Padding(0, 1),
+
+		RemovedCell: lipgloss.NewStyle().
+			Foreground(t.Danger).
+			Strikethrough(true).
+			Padding(0, 1),
+
+		MatchCell: lipgloss.NewStyle().
+			Background(t.Warning).
+			Foreground(t.Secondary).
+			Padding(0, 1),
+
+		CurrentMatch: lipgloss.NewStyle().
+			Background(t.Danger).
+			Foreground(t.TextBright).
+			Bold(true).
+			Padding(0, 1),
+	}
+}
+
+// cellStyle returns the results grid style for a non-NULL value of the given column type
+func (s ThemedStyles) cellStyle(colType ColumnType) lipgloss.Style {
+	switch colType {
+	case ColTypeNumeric:
+		return s.NumericCell
+	case ColTypeBoolean:
+		return s.BooleanCell
+	default:
+		return s.TableCell
 	}
 }
 
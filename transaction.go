@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Statements that start or end a transaction, as classified by
+// transactionCommand
+const (
+	txBegin    = "begin"
+	txCommit   = "commit"
+	txRollback = "rollback"
+)
+
+// transactionCommand reports whether stmt starts or ends a transaction:
+// txBegin, txCommit or txRollback, or "" for any other statement. Only the
+// plain forms count; BEGIN with options (e.g. SQLite's BEGIN IMMEDIATE) and
+// ROLLBACK TO SAVEPOINT run as typed.
+func transactionCommand(stmt string) string {
+	text := strings.TrimSpace(skipLeadingComments(stmt, false))
+	text = strings.TrimSpace(strings.TrimSuffix(text, ";"))
+	switch strings.Join(strings.Fields(strings.ToUpper(text)), " ") {
+	case "BEGIN", "BEGIN TRANSACTION", "BEGIN WORK", "START TRANSACTION":
+		return txBegin
+	case "COMMIT", "COMMIT TRANSACTION", "COMMIT WORK", "END", "END TRANSACTION", "END WORK":
+		return txCommit
+	case "ROLLBACK", "ROLLBACK TRANSACTION", "ROLLBACK WORK", "ABORT":
+		return txRollback
+	}
+	return ""
+}
+
+// runner returns what the tab's statements run on: its open transaction,
+// or the connection pool
+func (t *Tab) runner() sqlRunner {
+	if t.tx != nil {
+		return t.tx
+	}
+	return t.db
+}
+
+// beginTx starts a transaction on db. The transaction holds one of the
+// pool's connections until it ends, so a single-connection pool (a CSV
+// file) can't have one.
+func beginTx(db *sql.DB) (*sql.Tx, error) {
+	if db.Stats().MaxOpenConnections == 1 {
+		return nil, errors.New("transactions aren't supported on this connection")
+	}
+	return db.BeginTx(context.Background(), nil)
+}
+
+// toggleTransaction starts a transaction in the active tab, or asks whether
+// to commit or roll back the one that's open
+func (m *Model) toggleTransaction() {
+	tab := m.activeTabPtr()
+	if tab == nil || tab.db == nil {
+		return
+	}
+	if tab.running != "" {
+		m.statusMessage = "Wait for the running query to finish"
+		return
+	}
+	if tab.tx != nil {
+		m.txPrompt = tab
+		return
+	}
+	if tab.dryRun {
+		m.statusMessage = "Dry-run mode rolls back every statement; there's nothing to commit"
+		return
+	}
+
+	start := time.Now()
+	tx, err := beginTx(tab.db)
+	m.logMessage("BEGIN", err, -1, time.Since(start))
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	tab.tx = tx
+	tab.txSince = time.Now()
+	m.statusMessage = "Transaction started - statements run in it until you commit or roll back (Alt+Shift+T)"
+}
+
+// endTransactionFirst asks whether to commit or roll back the tab's open
+// transaction before doing something that would end it, such as closing
+// the tab, and then does it, unless the prompt is cancelled or the commit
+// fails. It returns false, doing nothing, if there's no transaction open.
+func (m *Model) endTransactionFirst(tab *Tab, doing string, then func(*Model) tea.Cmd) bool {
+	if tab == nil || tab.tx == nil {
+		return false
+	}
+	m.txPrompt = tab
+	m.txPromptNote = ""
+	m.txThen, m.txThenDoing = then, doing
+	return true
+}
+
+// endTransaction commits or rolls back the tab's open transaction,
+// returning the command that refreshes the results if they showed a change
+// that was rolled back
+func (m *Model) endTransaction(tab *Tab, commit bool) (tea.Cmd, error) {
+	tab.closeStreams() // a result still fetching holds the transaction's connection
+	start := time.Now()
+	stmt, err := "ROLLBACK", error(nil)
+	if commit {
+		stmt, err = "COMMIT", tab.tx.Commit()
+	} else {
+		err = tab.tx.Rollback()
+	}
+	m.logMessage(stmt, err, -1, time.Since(start))
+	// The transaction is over even if COMMIT failed; the database rolls it back
+	tab.tx = nil
+	switch {
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+	case commit:
+		m.statusMessage = "Committed"
+	default:
+		m.statusMessage = "Rolled back"
+	}
+	var reload tea.Cmd
+	if m.txPromptNote != "" && (err != nil || !commit) {
+		reload = m.reloadResult(tab) // the results showed the change
+	}
+	return reload, err
+}
+
+// txPromptText is the question shown in the status bar while asking how to
+// end a transaction
+func (m Model) txPromptText() string {
+	if m.txPromptNote != "" {
+		return m.txPromptNote + " - commit? (y/n, Esc to keep the transaction open)"
+	}
+	if m.txThen != nil {
+		return fmt.Sprintf("Transaction open for %s - before %s: c to commit, r to roll back, Esc to cancel",
+			time.Since(m.txPrompt.txSince).Round(time.Second), m.txThenDoing)
+	}
+	return fmt.Sprintf("Transaction open for %s: c to commit, r to roll back, Esc to keep it open",
+		time.Since(m.txPrompt.txSince).Round(time.Second))
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestTransactionCommand tests recognising the statements that start and end a transaction
+func TestTransactionCommand(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{"BEGIN", txBegin},
+		{"begin;", txBegin},
+		{"BEGIN TRANSACTION", txBegin},
+		{"start  transaction;", txBegin},
+		{"-- open one\nBEGIN WORK", txBegin},
+		{"COMMIT", txCommit},
+		{"commit work;", txCommit},
+		{"END", txCommit},
+		{"ROLLBACK", txRollback},
+		{"abort;", txRollback},
+		{"ROLLBACK TO SAVEPOINT sp1", ""},
+		{"BEGIN IMMEDIATE", ""},
+		{"SAVEPOINT sp1", ""},
+		{"SELECT 1", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			if got := transactionCommand(tt.stmt); got != tt.want {
+				t.Errorf("transactionCommand(%q) = %q, want %q", tt.stmt, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTabRunner tests that statements run in the tab's transaction until it ends
+func TestTabRunner(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	tab := &Tab{db: db}
+	if tab.runner() != db {
+		t.Fatal("runner() should be the pool with no transaction open")
+	}
+	tab.tx, err = beginTx(db)
+	if err != nil {
+		t.Fatalf("beginTx() error = %v", err)
+	}
+	ctx := context.Background()
+	if result := executeStatement(ctx, tab.runner(), "INSERT INTO t VALUES (1)"); result.Error != nil {
+		t.Fatalf("INSERT error = %v", result.Error)
+	}
+	if result := executeQuery(ctx, tab.runner(), "SELECT * FROM t"); len(result.Rows) != 1 {
+		t.Errorf("the transaction sees %d rows, want 1", len(result.Rows))
+	}
+
+	if err := tab.tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	tab.tx = nil
+	if result := executeQuery(ctx, tab.runner(), "SELECT * FROM t"); len(result.Rows) != 0 {
+		t.Errorf("%d rows after rolling back, want 0", len(result.Rows))
+	}
+}
+
+// TestBeginTxSingleConnection tests that a single-connection pool refuses a transaction
+func TestBeginTxSingleConnection(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := beginTx(db); err == nil {
+		t.Error("beginTx() should fail on a single-connection pool")
+	}
+}
+
+// TestConfirmsWrite tests which detail view statements wait for confirmation with confirm_writes
+func TestConfirmsWrite(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	tab := &Tab{db: db}
+
+	tests := []struct {
+		stmt    string
+		enabled bool
+		want    bool
+	}{
+		{"UPDATE users SET age = 31 WHERE id = 1;", true, true},
+		{"DELETE FROM users WHERE id = 1;", true, true},
+		{"INSERT INTO users (id, name) VALUES (4, 'Dan');", true, false},
+		{"UPDATE users SET age = 31 WHERE id = 1;", false, false},
+	}
+
+	for _, tt := range tests {
+		m := Model{confirmWrites: tt.enabled}
+		if got := m.confirmsWrite(tab, tt.stmt); got != tt.want {
+			t.Errorf("confirmsWrite(%q) with confirm_writes: %v = %v, want %v", tt.stmt, tt.enabled, got, tt.want)
+		}
+	}
+
+	// A single-connection database can't hold a transaction open, so the
+	// dialog confirms the statement before it runs instead
+	single := &Tab{db: setupTestDB(t)}
+	defer single.db.Close()
+	single.db.SetMaxOpenConns(1)
+	if (Model{confirmWrites: true}).confirmsWrite(single, "DELETE FROM users WHERE id = 1;") {
+		t.Error("confirmsWrite() should be false on a single-connection database")
+	}
+
+	// A transaction that's already open is left for the user to commit
+	tx, err := beginTx(db)
+	if err != nil {
+		t.Fatalf("beginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+	tab.tx = tx
+	if (Model{confirmWrites: true}).confirmsWrite(tab, "DELETE FROM users WHERE id = 1;") {
+		t.Error("confirmsWrite() should be false with a transaction open")
+	}
+}
+
+// TestQuitAsksAboutTransaction tests that quitting with a transaction open
+// asks to end it first, and quits once it's rolled back
+func TestQuitAsksAboutTransaction(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	tab := &Tab{db: db}
+	if tab.tx, err = beginTx(db); err != nil {
+		t.Fatalf("beginTx() error = %v", err)
+	}
+
+	m := Model{tabs: []*Tab{tab}}
+	if cmd := m.quit(); cmd != nil || m.txPrompt != tab {
+		t.Fatal("quit() with a transaction open should ask about it first")
+	}
+	if _, err := m.endTransaction(tab, false); err != nil {
+		t.Fatalf("endTransaction() error = %v", err)
+	}
+	if cmd := m.txThen(&m); cmd == nil {
+		t.Error("quit() should quit once the transaction's rolled back")
+	}
+}
+
+// TestDetailWriteWaitsForConfirmation tests that, with confirm_writes, a
+// change from the detail view runs in the background in a transaction that
+// the refreshed results see, and is left open to be confirmed
+func TestDetailWriteWaitsForConfirmation(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO t VALUES (1, 'a')"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	tab := &Tab{db: db, dbType: "sqlite", schema: NewSchemaCache(db, "sqlite"), lastQuery: "SELECT name FROM t", detailView: &DetailView{}}
+	m := Model{tabs: []*Tab{tab}, confirmWrites: true}
+	stmt := "UPDATE t SET name = 'b' WHERE id = 1"
+	cmd := m.executeDetailSQL(stmt)
+	if cmd == nil || tab.running != stmt {
+		t.Fatal("executeDetailSQL() should run the statement in the background")
+	}
+	if m.executeDetailSQL(stmt) != nil {
+		t.Error("executeDetailSQL() should refuse while a query is running")
+	}
+
+	msg := cmd().(tea.BatchMsg)[0]().(queryResultMsg)
+	m.finishQuery(msg)
+	if tab.running != "" || tab.tx == nil || m.txPrompt != tab {
+		t.Fatal("the change should wait in an open transaction for confirmation")
+	}
+	if tab.detailView != nil {
+		t.Error("the detail view should close once the change is made")
+	}
+	if got := tab.result.Rows[0][0].Value; got != "b" {
+		t.Errorf("refreshed result = %q, want the change it's waiting on", got)
+	}
+
+	var name string
+	cmd, err = m.endTransaction(tab, false)
+	if err != nil || cmd == nil {
+		t.Fatalf("endTransaction() = %v, want the results refreshed", err)
+	}
+	m.finishQuery(cmd().(tea.BatchMsg)[0]().(queryResultMsg))
+	if got := tab.result.Rows[0][0].Value; got != "a" {
+		t.Errorf("result refreshed after rolling back = %q, want %q", got, "a")
+	}
+	if err := db.QueryRow("SELECT name FROM t").Scan(&name); err != nil || name != "a" {
+		t.Errorf("after rolling back, name = %q (%v), want %q", name, err, "a")
+	}
+}
+
+// TestCloseTabCancelsQuery tests that closing a tab with a query running
+// cancels it before its transaction is rolled back
+func TestCloseTabCancelsQuery(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	tab := &Tab{db: db, running: "SELECT 1"}
+	if tab.tx, err = beginTx(db); err != nil {
+		t.Fatalf("beginTx() error = %v", err)
+	}
+	tx := tab.tx
+	ctx, cancel := statementContext(0)
+	tab.cancelQuery = cancel
+
+	m := Model{tabs: []*Tab{tab, {db: db}}}
+	cmd := m.closeCurrentTab()
+	if ctx.Err() == nil {
+		t.Error("closeCurrentTab() should cancel the running query")
+	}
+	if cmd == nil || len(m.tabs) != 1 {
+		t.Fatal("closeCurrentTab() should close the tab, releasing the transaction once the query's stopped")
+	}
+	cmd()
+	if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("Commit() after closing the tab = %v, want the transaction rolled back", err)
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -18,6 +20,25 @@ const (
 	focusConnectionPicker
 	focusPasswordPrompt
 	focusNewTabPicker // when selecting a connection for a new tab
+	focusDatabasePrompt
+	focusSidebar
+	focusFinder
+	focusDDL
+	focusER
+	focusPreview
+	focusHistory
+	focusSnippets
+	focusVariables
+	focusSearch
+	focusGotoLine
+	focusBookmarks
+	focusPlan
+	focusConfirmWrite
+	focusSession
+	focusWatch
+	focusFilter
+	focusResultSearch
+	focusColumnPicker
 )
 
 // Tab represents a single database connection tab with its own query and results
@@ -25,23 +46,100 @@ type Tab struct {
 	// Connection state
 	db             *sql.DB
 	dbType         string
+	dsn            string
 	connectionName string
+	session        map[string]string // session settings applied through the DSN
+	health         connHealth        // from the last ping, shown in the title bar
+	note           string            // free-form note from the saved connection
+
+	// How long a statement may run before it's stopped (0 = no limit)
+	statementTimeout time.Duration
+
+	// Roll back everything that writes, to see what it would do
+	dryRun bool
+
+	// Refuse statements that write
+	readOnly bool
+
+	// The transaction statements run in while transaction mode is on, and
+	// when it started
+	tx      *sql.Tx
+	txSince time.Time
 
 	// SQL file state
 	sqlDir           string
 	sqlFile          string
 	lastSavedContent string
+	diskStat         fileStat // the file as last loaded or saved, to notice outside changes
+	saveErr          error    // the last save's failure, cleared by a successful save
+	backupErr        error    // the last backup's failure, cleared by a successful backup
+	lastBackup       time.Time
 
 	// Query UI state
 	textarea  textarea.Model
 	result    *QueryResult
 	queryMeta *QueryMeta
 	lastQuery string
+	lastArgs  []any // bound to lastQuery's placeholders
+
+	// How the result differs from the one before it, after re-running its
+	// query to compare; diffBase is the result compared with while the
+	// re-run runs
+	diff     *ResultDiff
+	diffBase *QueryResult
+
+	// Recent results, oldest first, to flip between with Alt+←/Alt+→;
+	// resultIndex is the one shown. The newest scriptResults of them came
+	// from the last Alt+Shift+R run, and are numbered in a strip above the
+	// results.
+	results       []ResultSnapshot
+	resultIndex   int
+	scriptResults int
+
+	// The query running in the background, and when it started; script is
+	// set while Alt+Shift+R works through the editor a statement at a time
+	running      string
+	runningSince time.Time
+	cancelQuery  context.CancelFunc
+	serverQuery  *serverQuery // where it runs on the server, to kill it there
+	script       *scriptRun
+
+	// The query run again every few seconds, while Alt+W watches one
+	watch *watchState
+
+	// Keyword and name suggestions shown under the cursor while typing
+	completion *CompletionPopup
+
+	// Set after inserting a snippet with ${name} placeholders; Tab jumps
+	// between them until none are left
+	placeholders bool
+
+	// Text selected with Shift+arrows or Alt+V; Ctrl+R runs it
+	selection *Selection
+
+	// Undo/redo of changes to the editor
+	edits EditHistory
 
 	// Results navigation
-	selectedRow int
-	currentPage int
-	totalPages  int
+	errorScroll    int // first line of the error panel shown
+	firstColumn    int // leftmost result column shown
+	selectedColumn int // result column moved to with ←/→, which s sorts by
+	resultSearch   *ResultSearch
+	selectedRow    int
+	currentPage    int
+	totalPages     int
+	pageSize       int  // rows per page, fitted to the table's height; see fitPages
+	expanded       bool // \x: open each result in the record view
+
+	// Table/column metadata for this connection
+	schema *SchemaCache
+
+	// Schema browser sidebar selection and expanded tables (keyed by name)
+	sidebarSelected int
+	sidebarExpanded map[string]bool
+
+	// Per-column width overrides for the results grid (keyed by column name)
+	columnWidths map[string]int
 
 	// Theming (per-tab based on connection)
 	theme       Theme
@@ -83,14 +181,51 @@ type QueryResult struct {
 	ColumnTypes []ColumnType
 	Rows        [][]CellValue
 	Error       error
+
+	// Set for statements run via Exec (INSERT/UPDATE/DELETE/DDL)
+	Executed     bool
+	RowsAffected int64 // -1 if the driver doesn't report it
+
+	// Set when the statement ran in dry-run mode and was rolled back
+	DryRun bool
+
+	// How long the database took to run the statement, and then to send
+	// the rows back
+	ExecTime  time.Duration
+	FetchTime time.Duration
+
+	// The open result set while there are rows still to fetch
+	stream *rowStream
+
+	// Error's details, parsed once when the result arrives
+	errorDetails *QueryError
+
+	// Set while the fetched rows are sorted or filtered in memory, when
+	// Rows are those shown and fetched all of them in the database's order
+	sort    *resultSort
+	filter  *resultFilter
+	fetched [][]CellValue
+
+	// Per column, whether it's hidden from the table, and whether it's
+	// pinned to its left
+	hidden []bool
+	pinned []bool
+}
+
+// ColumnTypeAt returns the type category of column i, or ColTypeUnknown if not known
+func (r *QueryResult) ColumnTypeAt(i int) ColumnType {
+	if i < 0 || i >= len(r.ColumnTypes) {
+		return ColTypeUnknown
+	}
+	return r.ColumnTypes[i]
 }
 
 // QueryMeta holds parsed metadata about the query
 type QueryMeta struct {
 	TableName  string
 	IsEditable bool
-	IDColumn   string
-	IDIndex    int
+	KeyColumns []string // columns identifying a row: the primary key, or "id"
+	KeyIndexes []int    // result column index of each key column
 }
 
 // DetailView holds the state for the detail/edit view
@@ -103,7 +238,45 @@ type DetailView struct {
 	focusedField        int
 	scrollOffset        int
 	visibleFields       int
-	contentScrollOffset int // scroll offset within a multi-line field
+	contentScrollOffset int      // scroll offset within a multi-line field
+	pendingSQL          string   // generated statement awaiting confirmation before it runs
+	references          []string // per column: "table.column" it references via a foreign key
+	comments            []string // per column: comment from the database catalog
+}
+
+// fieldValue returns field i's value as edited, or the whole of the value
+// the row had if it hasn't been edited: its input only holds so many
+// characters of it, on one line
+func (dv *DetailView) fieldValue(i int) CellValue {
+	original := dv.originalValues[i]
+	unedited := textinput.New()
+	unedited.CharLimit = dv.inputs[i].CharLimit
+	unedited.SetValue(original.Value)
+	if dv.isNull[i] == original.IsNull && dv.inputs[i].Value() == unedited.Value() {
+		return original
+	}
+	return CellValue{Value: dv.inputs[i].Value(), IsNull: dv.isNull[i]}
+}
+
+// TablePreview holds a small result about a table (its first rows, or the
+// current user's privileges), shown in a popup without touching the editor
+// or the current result
+type TablePreview struct {
+	title       string
+	note        string // what the rows are, shown in the footer
+	result      *QueryResult
+	returnFocus focusState // focus to restore when the popup closes
+	proceed     string     // a previewed UPDATE/DELETE that Enter runs
+}
+
+// MessageEntry is one line of the session's execution log
+type MessageEntry struct {
+	time      time.Time
+	tabName   string
+	statement string
+	outcome   string
+	duration  time.Duration
+	isError   bool
 }
 
 // FileDialogEntry represents a file or directory in the file dialog
@@ -145,8 +318,10 @@ const (
 	PickerModeAddDSN
 	PickerModeAddType
 	PickerModeAddTheme
+	PickerModeAddNote
 	PickerModeAddEncrypt // choose whether to encrypt the DSN
 	PickerModeConfirmDelete
+	PickerModeEditNote // edit the selected connection's note
 	PickerModeCreateVault
 	PickerModeConfirmVaultPassword
 )
@@ -168,6 +343,7 @@ type ConnectionPicker struct {
 	newConnDSN    string
 	newConnType   string
 	newConnTheme  string
+	newConnNote   string
 	themeIdx      int  // for theme selection
 	noEncrypt     bool // store DSN in plaintext (for local databases)
 	encryptOptIdx int  // 0 = encrypted, 1 = plaintext
@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
 
 // TestCellValueString tests CellValue string representation
 func TestCellValueString(t *testing.T) {
@@ -41,3 +46,57 @@ func TestColumnTypeHelpers(t *testing.T) {
 		t.Error("ColTypeDatetime.IsText() should be true")
 	}
 }
+
+// TestQueryResultColumnTypeAt tests column type lookup with out-of-range indexes
+func TestQueryResultColumnTypeAt(t *testing.T) {
+	r := &QueryResult{ColumnTypes: []ColumnType{ColTypeNumeric, ColTypeBoolean}}
+
+	tests := []struct {
+		index    int
+		expected ColumnType
+	}{
+		{0, ColTypeNumeric},
+		{1, ColTypeBoolean},
+		{2, ColTypeUnknown},
+		{-1, ColTypeUnknown},
+	}
+
+	for _, tc := range tests {
+		if got := r.ColumnTypeAt(tc.index); got != tc.expected {
+			t.Errorf("ColumnTypeAt(%d) = %q, want %q", tc.index, got, tc.expected)
+		}
+	}
+}
+
+// TestDetailViewFieldValue tests that a field's whole original value is
+// copied while it's unedited, even when its input holds only part of it
+func TestDetailViewFieldValue(t *testing.T) {
+	long := strings.Repeat("x", 600)
+	tests := []struct {
+		name     string
+		original CellValue
+		edit     func(ti *textinput.Model) bool // returns the field's NULL state
+		want     CellValue
+	}{
+		{"unedited long value", CellValue{Value: long}, nil, CellValue{Value: long}},
+		{"unedited multi-line value", CellValue{Value: "a\nb"}, nil, CellValue{Value: "a\nb"}},
+		{"unedited NULL", CellValue{IsNull: true}, nil, CellValue{IsNull: true}},
+		{"edited", CellValue{Value: long}, func(ti *textinput.Model) bool { ti.SetValue("short"); return false }, CellValue{Value: "short"}},
+		{"set to NULL", CellValue{Value: "a"}, func(ti *textinput.Model) bool { return true }, CellValue{Value: "a", IsNull: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti := textinput.New()
+			ti.CharLimit = 500
+			ti.SetValue(tt.original.Value)
+			isNull := tt.original.IsNull
+			if tt.edit != nil {
+				isNull = tt.edit(&ti)
+			}
+			dv := &DetailView{originalValues: []CellValue{tt.original}, inputs: []textinput.Model{ti}, isNull: []bool{isNull}}
+			if got := dv.fieldValue(0); got != tt.want {
+				t.Errorf("fieldValue() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
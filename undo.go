@@ -0,0 +1,159 @@
+package main
+
+import (
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUndoSteps caps how many edits a tab can undo
+const maxUndoSteps = 200
+
+// editSnapshot is the query editor's content and cursor at one point
+type editSnapshot struct {
+	content string
+	cursor  int // byte offset
+}
+
+// EditHistory is a tab's undo and redo stacks of editor snapshots. Every
+// change to the editor is recorded, whether typed, pasted or generated.
+type EditHistory struct {
+	undo   []editSnapshot
+	redo   []editSnapshot
+	typing bool // the last edit was typing a word, which further typing extends
+}
+
+// Record saves the state before an edit. Consecutive typed characters of a
+// word are one step, so undo takes back a word at a time.
+func (h *EditHistory) Record(before editSnapshot, typing bool) {
+	if !typing || !h.typing {
+		h.undo = append(h.undo, before)
+		if len(h.undo) > maxUndoSteps {
+			h.undo = h.undo[1:]
+		}
+	}
+	h.typing = typing
+	h.redo = nil
+}
+
+// Break ends the current typing step, e.g. when the cursor moves
+func (h *EditHistory) Break() {
+	h.typing = false
+}
+
+// Undo returns the state before the last edit, keeping current for Redo
+func (h *EditHistory) Undo(current editSnapshot) (editSnapshot, bool) {
+	if len(h.undo) == 0 {
+		return editSnapshot{}, false
+	}
+	prev := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, current)
+	h.typing = false
+	return prev, true
+}
+
+// Redo returns the state the last Undo went back from
+func (h *EditHistory) Redo(current editSnapshot) (editSnapshot, bool) {
+	if len(h.redo) == 0 {
+		return editSnapshot{}, false
+	}
+	next := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, current)
+	h.typing = false
+	return next, true
+}
+
+// Reset forgets all edits, e.g. when another file is opened in the tab
+func (h *EditHistory) Reset() {
+	*h = EditHistory{}
+}
+
+// isTypingKey reports whether a key types a single word character, which
+// groups with the characters typed before it into one undo step
+func isTypingKey(msg tea.Msg) bool {
+	key, ok := msg.(tea.KeyMsg)
+	return ok && key.Type == tea.KeyRunes && !key.Alt && !key.Paste &&
+		len(key.Runes) == 1 && !unicode.IsSpace(key.Runes[0])
+}
+
+// tabSnapshot captures a tab's editor content and cursor
+func tabSnapshot(tab *Tab) editSnapshot {
+	return editSnapshot{content: tab.textarea.Value(), cursor: textareaCursorOffset(tab.textarea)}
+}
+
+// restoreSnapshot puts a tab's editor back to a snapshot
+func restoreSnapshot(tab *Tab, s editSnapshot) {
+	tab.textarea.SetValue(s.content)
+	moveCursorTo(&tab.textarea, s.cursor)
+	tab.selection = nil
+	tab.completion = nil
+}
+
+// undoEdit takes back the last change to the active tab's editor
+func (m *Model) undoEdit() {
+	tab := m.activeTabPtr()
+	prev, ok := tab.edits.Undo(tabSnapshot(tab))
+	if !ok {
+		m.statusMessage = "Nothing to undo"
+		return
+	}
+	restoreSnapshot(tab, prev)
+	m.statusMessage = "Undo"
+}
+
+// redoEdit reapplies the last change undone in the active tab's editor
+func (m *Model) redoEdit() {
+	tab := m.activeTabPtr()
+	next, ok := tab.edits.Redo(tabSnapshot(tab))
+	if !ok {
+		m.statusMessage = "Nothing to redo"
+		return
+	}
+	restoreSnapshot(tab, next)
+	m.statusMessage = "Redo"
+}
+
+// recordEdit wraps update to record changes to the active tab's editor for
+// undo, and handles the undo and redo keys itself so restoring isn't recorded
+func (m Model) recordEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return m.update(msg)
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && !m.confirmingQuit && m.reloadPrompt == nil && (m.focus == focusQuery || m.focus == focusResults) {
+		if key.String() == "u" && m.vimNormal() && m.focus == focusQuery && tab.selection == nil && m.vim.pending == "" {
+			m.undoEdit()
+			return m, nil
+		}
+		if m.keys.Undo.Matches(key.String()) {
+			m.undoEdit()
+			return m, nil
+		}
+		if m.keys.Redo.Matches(key.String()) {
+			m.redoEdit()
+			return m, nil
+		}
+	}
+
+	before := tabSnapshot(tab)
+	file := tab.sqlFile
+	typing := isTypingKey(msg) && !m.vimNormal() // vim normal-mode keys are commands
+	updated, cmd := m.update(msg)
+
+	next, ok := updated.(Model)
+	switch {
+	case !ok || next.activeTabPtr() != tab:
+		// Switched or closed tabs
+	case tab.sqlFile != file:
+		tab.edits.Reset() // another file was opened in the tab
+	case tab.textarea.Value() != before.content:
+		tab.edits.Record(before, typing)
+	default:
+		if _, ok := msg.(tea.KeyMsg); ok {
+			tab.edits.Break()
+		}
+	}
+	return updated, cmd
+}
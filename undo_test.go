@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestEditHistory tests undoing and redoing recorded edits
+func TestEditHistory(t *testing.T) {
+	var h EditHistory
+	snap := func(s string) editSnapshot { return editSnapshot{content: s, cursor: len(s)} }
+
+	// Typing "ab", a space, then "c" is three steps: "ab", " " and "c"
+	h.Record(snap(""), true)
+	h.Record(snap("a"), true)
+	h.Record(snap("ab"), false)
+	h.Record(snap("ab "), true)
+
+	steps := []string{"ab ", "ab", ""}
+	current := snap("ab c")
+	for _, want := range steps {
+		prev, ok := h.Undo(current)
+		if !ok || prev.content != want {
+			t.Fatalf("Undo() = %q, %v, want %q", prev.content, ok, want)
+		}
+		current = prev
+	}
+	if _, ok := h.Undo(current); ok {
+		t.Errorf("Undo() with nothing left succeeded")
+	}
+
+	next, ok := h.Redo(current)
+	if !ok || next.content != "ab" {
+		t.Fatalf("Redo() = %q, %v, want %q", next.content, ok, "ab")
+	}
+
+	// A new edit drops what could have been redone
+	h.Record(next, false)
+	if _, ok := h.Redo(snap("abX")); ok {
+		t.Errorf("Redo() after a new edit succeeded")
+	}
+}
+
+// TestEditHistoryBreak tests that moving the cursor starts a new typing step
+func TestEditHistoryBreak(t *testing.T) {
+	var h EditHistory
+	h.Record(editSnapshot{content: ""}, true)
+	h.Break()
+	h.Record(editSnapshot{content: "a"}, true)
+	if len(h.undo) != 2 {
+		t.Errorf("got %d undo steps, want 2", len(h.undo))
+	}
+}
+
+// TestEditHistoryLimit tests that the oldest steps are dropped past the limit
+func TestEditHistoryLimit(t *testing.T) {
+	var h EditHistory
+	for i := 0; i < maxUndoSteps+10; i++ {
+		h.Record(editSnapshot{cursor: i}, false)
+	}
+	if len(h.undo) != maxUndoSteps || h.undo[0].cursor != 10 {
+		t.Errorf("got %d steps starting at %d, want %d starting at 10", len(h.undo), h.undo[0].cursor, maxUndoSteps)
+	}
+}
+
+// TestIsTypingKey tests which keys group into a single undo step
+func TestIsTypingKey(t *testing.T) {
+	tests := []struct {
+		msg  tea.Msg
+		want bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, true},
+		{tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("abc"), Paste: true}, false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true}, false},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, false},
+		{tea.WindowSizeMsg{}, false},
+	}
+
+	for _, tc := range tests {
+		if got := isTypingKey(tc.msg); got != tc.want {
+			t.Errorf("isTypingKey(%v) = %v, want %v", tc.msg, got, tc.want)
+		}
+	}
+}
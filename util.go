@@ -1,6 +1,9 @@
 package main
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // truncateString truncates a string to maxLen, adding ellipsis if needed
 func truncateString(s string, maxLen int) string {
@@ -13,6 +16,18 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// formatDuration rounds a query's duration for display, e.g. "84ms" or "1.2s"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
+
 // padRight pads a string with spaces to reach the specified length
 func padRight(s string, length int) string {
 	if len(s) >= length {
@@ -21,6 +36,20 @@ func padRight(s string, length int) string {
 	return s + strings.Repeat(" ", length-len(s))
 }
 
+// columnWidthLimit returns the width cap for a column, honoring any override by name
+func columnWidthLimit(overrides map[string]int, column string, defaultWidth int) int {
+	if w, ok := overrides[column]; ok && w > 0 {
+		return w
+	}
+	// Fall back to a case-insensitive match (databases differ in identifier casing)
+	for name, w := range overrides {
+		if w > 0 && strings.EqualFold(name, column) {
+			return w
+		}
+	}
+	return defaultWidth
+}
+
 // quoteIdentifier returns the identifier quote character for the database type
 func quoteIdentifier(dbType string) string {
 	switch dbType {
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 // TestQuoteIdentifier tests identifier quoting
 func TestQuoteIdentifier(t *testing.T) {
@@ -49,6 +52,27 @@ func TestTruncateString(t *testing.T) {
 	}
 }
 
+// TestFormatDuration tests rounding query durations for display
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{300 * time.Microsecond, "<1ms"},
+		{84*time.Millisecond + 400*time.Microsecond, "84ms"},
+		{1234 * time.Millisecond, "1.2s"},
+		{90 * time.Second, "1m30s"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expected, func(t *testing.T) {
+			if result := formatDuration(tc.input); result != tc.expected {
+				t.Errorf("formatDuration(%v) = %q, want %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
 // TestPadRight tests string padding
 func TestPadRight(t *testing.T) {
 	tests := []struct {
@@ -72,3 +96,36 @@ func TestPadRight(t *testing.T) {
 		})
 	}
 }
+
+// TestColumnWidthLimit tests per-column width overrides
+func TestColumnWidthLimit(t *testing.T) {
+	overrides := map[string]int{
+		"payload": 120,
+		"Notes":   10,
+		"broken":  0,
+	}
+
+	tests := []struct {
+		column   string
+		expected int
+	}{
+		{"payload", 120},
+		{"notes", 10},
+		{"NOTES", 10},
+		{"broken", 40},
+		{"id", 40},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.column, func(t *testing.T) {
+			result := columnWidthLimit(overrides, tc.column, 40)
+			if result != tc.expected {
+				t.Errorf("columnWidthLimit(%q) = %d, want %d", tc.column, result, tc.expected)
+			}
+		})
+	}
+
+	if got := columnWidthLimit(nil, "id", 40); got != 40 {
+		t.Errorf("columnWidthLimit with nil overrides = %d, want 40", got)
+	}
+}
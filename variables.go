@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// QueryVariable is one placeholder found in a query: a {{name}} template
+// variable, or a :name, ? or $1 bind parameter. Positional parameters are
+// named $1, $2 and so on.
+type QueryVariable struct {
+	Name  string
+	bind  bool // sent as a bind parameter rather than substituted
+	start int  // byte range of the placeholder in the query
+	end   int
+}
+
+// findQueryVariables returns the placeholders in a query, in order:
+// {{name}} and :name everywhere, ? outside PostgreSQL (where it's a JSON
+// operator) and $1 outside MySQL. Strings, quoted identifiers, comments and
+// PostgreSQL's dollar-quoted bodies are skipped the way the statement
+// splitter skips them (MySQL's backslash escapes and # comments included),
+// as are :: casts and colons directly after a name or number (a:b, [1:n]).
+func findQueryVariables(query, dbType string) []QueryVariable {
+	var vars []QueryVariable
+	driver := getDriverName(dbType)
+	hashComments := hashCommentsAllowed(dbType)
+	positional := 0 // ? placeholders so far
+	n := len(query)
+	for i := 0; i < n; i++ {
+		// A $$...$$ or $tag$...$tag$ body's $1s are its own
+		if end := skipQuoted(query, i, hashComments, driver == "pgx"); end > i {
+			i = end - 1
+			continue
+		}
+		ch := query[i]
+		switch {
+		case ch == '$' && driver != "mysql" && (i == 0 || !isIdentifierByte(query[i-1])):
+			j := i + 1
+			for j < n && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 && (j == n || !isIdentifierByte(query[j])) {
+				vars = append(vars, QueryVariable{Name: query[i:j], bind: true, start: i, end: j})
+				i = j - 1
+			}
+		case ch == '?' && driver != "pgx":
+			// ? takes the next number, and SQLite's ?2 the one given
+			j := i + 1
+			for j < n && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			name := "$" + query[i+1:j]
+			if j == i+1 {
+				positional++
+				name = "$" + strconv.Itoa(positional)
+			}
+			vars = append(vars, QueryVariable{Name: name, bind: true, start: i, end: j})
+			i = j - 1
+		case ch == '{' && i+1 < n && query[i+1] == '{':
+			end := strings.Index(query[i+2:], "}}")
+			if end < 0 {
+				continue
+			}
+			name := strings.TrimSpace(query[i+2 : i+2+end])
+			if isVariableName(name) {
+				vars = append(vars, QueryVariable{Name: name, start: i, end: i + 2 + end + 2})
+				i += end + 3
+			}
+		case ch == ':':
+			if i+1 < n && query[i+1] == ':' {
+				i++ // :: cast
+				continue
+			}
+			if i > 0 && (isIdentifierByte(query[i-1]) || query[i-1] == ':') {
+				continue
+			}
+			j := i + 1
+			for j < n && isIdentifierByte(query[j]) && query[j] != '$' {
+				j++
+			}
+			if name := query[i+1 : j]; isVariableName(name) {
+				vars = append(vars, QueryVariable{Name: name, bind: true, start: i, end: j})
+				i = j - 1
+			}
+		}
+	}
+	return vars
+}
+
+// isVariableName reports whether name is an identifier that doesn't start with a digit
+func isVariableName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentifierByte(name[i]) || name[i] == '$' {
+			return false
+		}
+	}
+	return true
+}
+
+// variableNames returns the distinct variable names in order of first use
+func variableNames(vars []QueryVariable) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, v := range vars {
+		if !seen[v.Name] {
+			seen[v.Name] = true
+			names = append(names, v.Name)
+		}
+	}
+	return names
+}
+
+// bindVariables fills in a query's placeholders: {{name}} is replaced with
+// its value as typed, and bind parameters with the database's own
+// placeholders ($1 on PostgreSQL, ? elsewhere) whose values are returned as
+// args, so they need no quoting. A value of NULL binds as null.
+func bindVariables(query string, vars []QueryVariable, values map[string]string, dbType string) (string, []any) {
+	var b strings.Builder
+	var args []any
+	numbers := make(map[string]int) // PostgreSQL's $n for each parameter
+	last := 0
+	for _, v := range vars {
+		b.WriteString(query[last:v.start])
+		last = v.end
+		if !v.bind {
+			b.WriteString(values[v.Name])
+			continue
+		}
+
+		var arg any = values[v.Name]
+		if strings.EqualFold(values[v.Name], "NULL") {
+			arg = nil
+		}
+		if getDriverName(dbType) != "pgx" {
+			b.WriteString("?")
+			args = append(args, arg)
+			continue
+		}
+		if numbers[v.Name] == 0 {
+			args = append(args, arg)
+			numbers[v.Name] = len(args)
+		}
+		fmt.Fprintf(&b, "$%d", numbers[v.Name])
+	}
+	b.WriteString(query[last:])
+	return b.String(), args
+}
+
+// scriptVariables returns the placeholders of each statement of a script in
+// turn, for a prompt that fills them in for the whole script. Positional
+// parameters are numbered per statement, so $1 takes the same value in each.
+func scriptVariables(script, dbType string) []QueryVariable {
+	var vars []QueryVariable
+	for _, stmt := range SplitStatementsForDB(script, dbType) {
+		if !isCommentOnly(stmt, dbType) && !isMetaCommand(stmt) {
+			vars = append(vars, findQueryVariables(stmt, dbType)...)
+		}
+	}
+	return vars
+}
+
+// VariablePrompt asks for the values of a query's variables before it runs
+type VariablePrompt struct {
+	query   string
+	all     bool // query is the whole editor, run statement by statement
+	vars    []QueryVariable
+	names   []string
+	inputs  []textinput.Model
+	focused int
+}
+
+// newVariablePrompt creates a prompt for the variables in query, prefilled
+// with the values used last time
+func newVariablePrompt(query string, vars []QueryVariable, previous map[string]string) *VariablePrompt {
+	p := &VariablePrompt{query: query, vars: vars, names: variableNames(vars)}
+	bound := make(map[string]bool)
+	for _, v := range vars {
+		bound[v.Name] = v.bind
+	}
+	for _, name := range p.names {
+		ti := textinput.New()
+		ti.Placeholder = "SQL text, e.g. users or 'text'"
+		if bound[name] {
+			ti.Placeholder = "value, e.g. 42 or O'Brien"
+		}
+		ti.CharLimit = 1024
+		ti.Width = 40
+		ti.SetValue(previous[name])
+		p.inputs = append(p.inputs, ti)
+	}
+	p.inputs[0].Focus()
+	return p
+}
+
+// focus moves input focus to field i
+func (p *VariablePrompt) focus(i int) {
+	p.inputs[p.focused].Blur()
+	p.focused = i
+	p.inputs[p.focused].Focus()
+}
+
+// values returns the entered value of each variable
+func (p *VariablePrompt) values() map[string]string {
+	values := make(map[string]string, len(p.names))
+	for i, name := range p.names {
+		values[name] = p.inputs[i].Value()
+	}
+	return values
+}
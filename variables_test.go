@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFindQueryVariables tests detecting placeholders outside literals
+func TestFindQueryVariables(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbType string
+		query  string
+		want   []string
+	}{
+		{"colon", "postgres", "SELECT * FROM users WHERE id = :id", []string{"id"}},
+		{"braces", "postgres", "SELECT * FROM {{table}} LIMIT {{ n }}", []string{"table", "n"}},
+		{"repeated", "postgres", "SELECT :a, :b, :a", []string{"a", "b", "a"}},
+		{"postgres cast", "postgres", "SELECT created_at::date, :day::date FROM t", []string{"day"}},
+		{"in string", "postgres", "SELECT ':nope', '{{nope}}' FROM t WHERE x = :yes", []string{"yes"}},
+		{"in comments", "postgres", "SELECT 1 -- :nope\n/* {{nope}} */ + :yes", []string{"yes"}},
+		{"in quoted identifier", "postgres", `SELECT "a:b" FROM t`, nil},
+		{"after name or number", "postgres", "SELECT arr[1:n], ts FROM t WHERE t.a:b", nil},
+		{"not a name", "postgres", "SELECT :1, {{}}, {{a b}}", nil},
+		{"none", "postgres", "SELECT * FROM users", nil},
+		{"postgres numbered", "postgres", "SELECT * FROM t WHERE a = $2 OR b = $1", []string{"$2", "$1"}},
+		{"postgres json operator", "postgres", "SELECT * FROM t WHERE doc ? 'key'", nil},
+		{"postgres dollar quoted", "postgres", "CREATE FUNCTION f(int) RETURNS int AS $$ SELECT $1 $$ LANGUAGE sql", nil},
+		{"question marks", "mysql", "SELECT * FROM t WHERE a = ? AND b = ?", []string{"$1", "$2"}},
+		{"mysql dollar", "mysql", "SELECT $1 FROM t", nil},
+		{"question mark in string", "mysql", "SELECT '?' FROM t WHERE a = ?", []string{"$1"}},
+		{"mysql backslash escape", "mysql", `SELECT 'it\'s ?' FROM t WHERE a = ?`, []string{"$1"}},
+		{"mysql hash comment", "mysql", "SELECT 1 # :nope ?\nFROM t WHERE a = ?", []string{"$1"}},
+		{"hash outside mysql", "postgres", "SELECT a # :b FROM t", []string{"b"}},
+		{"sqlite numbered", "sqlite", "SELECT ?2, ?1, $1", []string{"$2", "$1", "$1"}},
+		{"name containing dollar", "sqlite", "SELECT a$1 FROM t", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, v := range findQueryVariables(tc.query, tc.dbType) {
+				got = append(got, v.Name)
+				text := tc.query[v.start:v.end]
+				if v.bind != !strings.HasPrefix(text, "{{") {
+					t.Errorf("variable %s covering %q has bind %v", v.Name, text, v.bind)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("findQueryVariables(%q) = %v, want %v", tc.query, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestBindVariables tests substituting template variables and binding the rest
+func TestBindVariables(t *testing.T) {
+	values := map[string]string{"table": "users", "name": "O'Brien", "age": "NULL", "$1": "7"}
+	tests := []struct {
+		dbType   string
+		query    string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			"postgres",
+			"SELECT * FROM {{ table }} WHERE name = :name OR nick = :name OR age = :age",
+			"SELECT * FROM users WHERE name = $1 OR nick = $1 OR age = $2",
+			[]any{"O'Brien", nil},
+		},
+		{
+			"postgres",
+			"SELECT * FROM users WHERE id = $1 AND name = :name",
+			"SELECT * FROM users WHERE id = $1 AND name = $2",
+			[]any{"7", "O'Brien"},
+		},
+		{
+			"mysql",
+			"SELECT * FROM {{table}} WHERE name = :name OR nick = :name AND id = ?",
+			"SELECT * FROM users WHERE name = ? OR nick = ? AND id = ?",
+			[]any{"O'Brien", "O'Brien", "7"},
+		},
+	}
+
+	for _, tt := range tests {
+		vars := findQueryVariables(tt.query, tt.dbType)
+		gotSQL, gotArgs := bindVariables(tt.query, vars, values, tt.dbType)
+		if gotSQL != tt.wantSQL || !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+			t.Errorf("bindVariables(%q) = %q, %v; want %q, %v", tt.query, gotSQL, gotArgs, tt.wantSQL, tt.wantArgs)
+		}
+	}
+
+	vars := findQueryVariables("SELECT * FROM {{ table }} WHERE name = :name OR nick = :name", "postgres")
+	if names := variableNames(vars); !reflect.DeepEqual(names, []string{"table", "name"}) {
+		t.Errorf("variableNames() = %v, want [table name]", names)
+	}
+}
+
+// TestScriptVariables tests numbering positional parameters per statement
+func TestScriptVariables(t *testing.T) {
+	script := "SELECT * FROM a WHERE id = ?;\nUPDATE b SET x = ? WHERE y = :y;\n\\dt"
+	var got []string
+	for _, v := range scriptVariables(script, "sqlite") {
+		got = append(got, v.Name)
+	}
+	if want := []string{"$1", "$1", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scriptVariables() = %v, want %v", got, want)
+	}
+}
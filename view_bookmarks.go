@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderBookmarks renders the bookmark picker with a preview of the selected statement
+func (m Model) renderBookmarks() string {
+	styles := m.GetStyles()
+	p := m.bookmarks
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("🔖 Bookmarks"))
+	b.WriteString("\n\n")
+	b.WriteString("  " + p.input.View() + "\n\n")
+
+	// Half the space lists bookmarks, the rest previews the selected one
+	listHeight := max((m.height-7)/2, 1)
+	start := 0
+	if p.selected >= listHeight {
+		start = p.selected - listHeight + 1
+	}
+	end := min(start+listHeight, len(p.matches))
+
+	dimStyle := lipgloss.NewStyle().Foreground(m.tab().theme.TextDim)
+	for i := start; i < end; i++ {
+		line := p.matches[i].Name + dimStyle.Render(fmt.Sprintf("  line %d", p.matches[i].Line+1))
+		if i == p.selected {
+			b.WriteString(styles.SelectedRow.Render("▶ ") + line)
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	if len(p.matches) == 0 {
+		b.WriteString(dimStyle.Render("  No matches") + "\n")
+	}
+	for i := max(end-start, 1); i < listHeight; i++ {
+		b.WriteString("\n")
+	}
+
+	previewHeight := max(m.height-7-listHeight, 1)
+	b.WriteString("\n")
+	var preview []string
+	if p.selected < len(p.matches) {
+		preview = strings.Split(p.matches[p.selected].SQL, "\n")
+	}
+	for i := 0; i < previewHeight; i++ {
+		if i < len(preview) {
+			b.WriteString("  " + m.tab().highlighter.HighlightLine(preview[i]))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(styles.Help.Render(fmt.Sprintf("%d bookmarks | ↑↓: Select | Enter: Jump | Ctrl+R: Run | Esc: Cancel", len(p.matches))))
+
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderColumnPicker renders the column picker popup centered on screen
+func (m Model) renderColumnPicker() string {
+	styles := m.GetStyles()
+	p := m.columnPicker
+	result := p.tab.result
+
+	// Scroll the list to keep the selected column in view
+	rows := max(m.height-10, 3)
+	start := max(min(p.selected-rows/2, len(result.Columns)-rows), 0)
+	end := min(start+rows, len(result.Columns))
+
+	var lines []string
+	lines = append(lines, styles.Title.Render("Columns"), "")
+	for i := start; i < end; i++ {
+		check := "[x] "
+		if result.isHidden(i) {
+			check = "[ ] "
+		}
+		line := check + result.Columns[i]
+		if result.isPinned(i) {
+			line += " (pinned)"
+		}
+		if i == p.selected {
+			line = styles.SelectedRow.Render(line)
+		} else if result.isHidden(i) {
+			line = styles.Help.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if start > 0 || end < len(result.Columns) {
+		lines = append(lines, styles.Help.Render(strings.Repeat(" ", 4)+"..."))
+	}
+	lines = append(lines, "", styles.Help.Render("Space: Hide/show | p: Pin | a: Show all | Esc: Close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(p.tab.theme.Primary).
+		Padding(0, 1).
+		Render(lipgloss.NewStyle().MaxWidth(max(m.width-8, 20)).Render(strings.Join(lines, "\n")))
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renderDDLView renders the read-only CREATE statement viewer
+func (m Model) renderDDLView() string {
+	styles := m.GetStyles()
+	tab := m.tab()
+	v := m.ddlView
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("📜 DDL: " + v.table))
+	b.WriteString("\n\n")
+
+	lines := strings.Split(v.ddl, "\n")
+	page := max(m.height-6, 1)
+	end := min(v.scroll+page, len(lines))
+	for _, line := range lines[v.scroll:end] {
+		b.WriteString("  " + tab.highlighter.HighlightLine(line) + "\n")
+	}
+	for i := end - v.scroll; i < page; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	position := ""
+	if len(lines) > page {
+		position = fmt.Sprintf("Lines %d-%d of %d | ", v.scroll+1, end, len(lines))
+	}
+	b.WriteString(styles.Help.Render(position + "↑↓/PgUp/PgDn: Scroll | c/Enter: Copy to editor | Esc: Close"))
+
+	return b.String()
+}
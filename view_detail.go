@@ -14,6 +14,9 @@ func (m Model) renderDetailView() string {
 	if tab == nil || tab.detailView == nil {
 		return ""
 	}
+	if tab.detailView.pendingSQL != "" {
+		return m.renderDetailConfirm()
+	}
 
 	// Calculate heights
 	// Title: 1 line + 1 blank = 2
@@ -43,11 +46,8 @@ func (m Model) renderDetailView() string {
 	var b strings.Builder
 
 	// Title
-	titleText := "🌱  Dibber - Database Client"
-	if tab.connectionName != "" {
-		titleText = fmt.Sprintf("🌱  Dibber - %s (%s)", tab.connectionName, tab.dbType)
-	}
-	b.WriteString(styles.Title.Render(titleText))
+	b.WriteString(styles.Title.Render(m.titleText()))
+	b.WriteString(m.renderConnectionNote())
 	b.WriteString("\n\n")
 
 	// Detail view header
@@ -261,6 +261,25 @@ func (m Model) renderDetailView() string {
 				linesWritten++
 			}
 		}
+
+		// Column comment from the database catalog
+		if i < len(tab.detailView.comments) && tab.detailView.comments[i] != "" {
+			comment := []rune(strings.ReplaceAll(tab.detailView.comments[i], "\n", " "))
+			if maxLen := max(m.width-6, 10); len(comment) > maxLen {
+				comment = append(comment[:maxLen-3], []rune("...")...)
+			}
+			b.WriteString(styles.Help.Render("  ⓘ " + string(comment)))
+			b.WriteString("\n")
+			linesWritten++
+		}
+
+		// Foreign key target, if the column references another table
+		if i < len(tab.detailView.references) && tab.detailView.references[i] != "" {
+			ref := tab.detailView.references[i]
+			b.WriteString(styles.Help.Render("  ↳ references " + ref))
+			b.WriteString("\n")
+			linesWritten++
+		}
 	}
 
 	// Scroll indicator
@@ -284,11 +303,51 @@ func (m Model) renderDetailView() string {
 	// Help
 	var helpText string
 	if tab.queryMeta != nil && tab.queryMeta.IsEditable {
-		helpText = "↑↓: Navigate | Ctrl+N: Toggle NULL | Ctrl+U/D/I: UPDATE/DELETE/INSERT | Esc: Back"
+		helpText = "↑↓: Navigate | Ctrl+N: Toggle NULL | Alt+Y: Copy | Ctrl+U/D/I: UPDATE/DELETE/INSERT | Alt+U/D/I: Run now | Esc: Back"
 	} else {
 		helpText = "↑↓/Tab: Navigate fields | PgUp/PgDn: Scroll content | Esc: Back | Ctrl+Q: Quit"
 	}
+	if f := tab.detailView.focusedField; f < len(tab.detailView.references) && tab.detailView.references[f] != "" {
+		helpText = "Ctrl+G: Follow FK | " + helpText
+	}
 	b.WriteString(styles.Help.Render(helpText))
 
 	return b.String()
 }
+
+// renderDetailConfirm renders the dialog asking whether to run the statement
+// generated from the detail view, showing it in full
+func (m Model) renderDetailConfirm() string {
+	styles := m.GetStyles()
+	tab := m.tab()
+	stmt := tab.detailView.pendingSQL
+	verb := strings.ToUpper(strings.Fields(stmt)[0])
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render(m.titleText()))
+	b.WriteString(m.renderConnectionNote())
+	b.WriteString("\n\n")
+
+	titleStyle := lipgloss.NewStyle().Foreground(tab.theme.TextBright).Background(tab.theme.Warning).Bold(true).Padding(0, 1)
+	if verb == "DELETE" {
+		titleStyle = titleStyle.Background(tab.theme.Danger)
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Run this %s now?", verb)))
+	b.WriteString("\n\n")
+
+	for _, line := range strings.Split(stmt, "\n") {
+		if tab.highlighter != nil {
+			line = tab.highlighter.HighlightLine(line)
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("\n")
+	if tab.isProduction() {
+		b.WriteString(styles.Error.Render("This is a production connection."))
+		b.WriteString("\n\n")
+	}
+	b.WriteString("The results are refreshed after it runs.\n\n")
+	b.WriteString(styles.Help.Render("y/Enter: Run | n/Esc: Cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderERView renders the entity-relationship overview
+func (m Model) renderERView() string {
+	styles := m.GetStyles()
+	tab := m.tab()
+	v := m.erView
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("🔗 Relationships: " + m.tabDisplayName(m.activeTab)))
+	b.WriteString("\n\n")
+
+	tableStyle := lipgloss.NewStyle().Bold(true).Foreground(tab.theme.Primary)
+	dimStyle := lipgloss.NewStyle().Foreground(tab.theme.TextDim)
+
+	page := max(m.height-5, 1)
+	end := min(v.scroll+page, len(v.lines))
+	for _, line := range v.lines[v.scroll:end] {
+		if runes := []rune(line); len(runes) > max(m.width-2, 10) {
+			line = string(runes[:max(m.width-5, 7)]) + "..."
+		}
+		switch {
+		case strings.HasPrefix(line, "▪ "):
+			b.WriteString(tableStyle.Render(line))
+		case strings.HasPrefix(line, "    ◀── "):
+			b.WriteString(dimStyle.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	for i := end - v.scroll; i < page; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	position := ""
+	if len(v.lines) > page {
+		position = fmt.Sprintf("Lines %d-%d of %d | ", v.scroll+1, end, len(v.lines))
+	}
+	b.WriteString(styles.Help.Render(position + "──▶ references | ◀── referenced by | ↑↓/PgUp/PgDn: Scroll | Esc: Close"))
+
+	return b.String()
+}
@@ -96,7 +96,7 @@ func (m Model) renderFileDialog() string {
 	b.WriteString("\n")
 
 	// Help
-	b.WriteString(helpStyle.Render("↑↓: Select | Enter: Open/Navigate | Esc: Cancel"))
+	b.WriteString(helpStyle.Render("↑↓: Select | Enter: Open/Navigate | t: Open in new tab | Esc: Cancel"))
 
 	return b.String()
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderFinder renders the fuzzy finder over table and column names
+func (m Model) renderFinder() string {
+	styles := m.GetStyles()
+	f := m.finder
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("🔍 Find Table or Column"))
+	b.WriteString("\n\n")
+	b.WriteString("  " + f.input.View() + "\n\n")
+
+	// Keep the selection visible, leaving room for the title, input and help lines
+	visible := max(m.height-6, 1)
+	start := 0
+	if f.selected >= visible {
+		start = f.selected - visible + 1
+	}
+	end := min(start+visible, len(f.matches))
+
+	dimStyle := lipgloss.NewStyle().Foreground(m.tab().theme.TextDim)
+	for i := start; i < end; i++ {
+		item := f.matches[i]
+		label := item.Label()
+		kind := "table"
+		switch {
+		case item.Column != "":
+			kind = "column"
+		case item.IsView:
+			kind = "view"
+		}
+		if i == f.selected {
+			b.WriteString(styles.SelectedRow.Render("▶ " + label))
+		} else {
+			b.WriteString("  " + label)
+		}
+		b.WriteString(" " + dimStyle.Render(kind) + "\n")
+	}
+	if len(f.matches) == 0 {
+		b.WriteString(dimStyle.Render("  No matches") + "\n")
+	}
+
+	for i := end - start; i < visible; i++ {
+		b.WriteString("\n")
+	}
+	b.WriteString(styles.Help.Render(fmt.Sprintf("%d matches | ↑↓: Select | Enter: Insert | Space: Preview | Tab: Show in sidebar | Esc: Cancel", len(f.matches))))
+
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderHistory renders the query history browser
+func (m Model) renderHistory() string {
+	styles := m.GetStyles()
+	h := m.history
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("🕘 History: " + h.connection))
+	b.WriteString("\n\n")
+	b.WriteString("  " + h.input.View() + "\n\n")
+
+	// Keep the selection visible, leaving room for the title, input and help lines
+	visible := max(m.height-6, 1)
+	start := 0
+	if h.selected >= visible {
+		start = h.selected - visible + 1
+	}
+	end := min(start+visible, len(h.matches))
+
+	theme := m.tab().theme
+	dimStyle := lipgloss.NewStyle().Foreground(theme.TextDim)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Danger)
+	for i := start; i < end; i++ {
+		entry := h.matches[i]
+		outcome := fmt.Sprintf("%d rows, %dms", entry.Rows, entry.DurationMs)
+		if entry.Error != "" {
+			outcome = errStyle.Render(fmt.Sprintf("%-16s", "error"))
+		} else {
+			outcome = dimStyle.Render(fmt.Sprintf("%-16s", outcome))
+		}
+		meta := dimStyle.Render(entry.Time.Format("2006-01-02 15:04")) + "  " + outcome
+
+		// Fit the statement, on one line, into what's left after the metadata
+		stmt := strings.Join(strings.Fields(entry.Statement), " ")
+		room := max(m.width-lipgloss.Width(meta)-4, 10)
+		if runes := []rune(stmt); len(runes) > room {
+			stmt = string(runes[:room-1]) + "…"
+		}
+		if i == h.selected {
+			b.WriteString(styles.SelectedRow.Render("▶ "+meta) + " " + styles.SelectedRow.Render(stmt))
+		} else {
+			b.WriteString("  " + meta + " " + stmt)
+		}
+		b.WriteString("\n")
+	}
+	if len(h.matches) == 0 {
+		b.WriteString(dimStyle.Render("  No statements") + "\n")
+	}
+
+	for i := end - start; i < visible; i++ {
+		b.WriteString("\n")
+	}
+	b.WriteString(styles.Help.Render(fmt.Sprintf("%d of %d statements | ↑↓: Select | Enter: Insert into editor | Esc: Close", len(h.matches), len(h.entries))))
+
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// planDetailLines is how many of the selected node's detail lines are shown
+// under the tree
+const planDetailLines = 4
+
+// renderPlanView renders the EXPLAIN ANALYZE tree: each node's own time,
+// its share of the total, and actual against estimated rows. Nodes taking
+// a large share of the time are highlighted.
+func (m Model) renderPlanView() string {
+	styles := m.GetStyles()
+	theme := m.tab().theme
+	v := m.planView
+	var b strings.Builder
+
+	total := v.plan.TotalTime()
+	b.WriteString(styles.Title.Render("🌳 Query plan: " + formatPlanTime(total)))
+	b.WriteString("\n\n")
+
+	dimStyle := lipgloss.NewStyle().Foreground(theme.TextDim)
+	hotStyle := lipgloss.NewStyle().Foreground(theme.Danger).Bold(true)
+	warmStyle := lipgloss.NewStyle().Foreground(theme.Warning)
+
+	statsWidth := 42
+	labelWidth := max(m.width-statsWidth-4, 20)
+	b.WriteString(styles.TableHeader.Render(padRight("  Node", labelWidth+2) + fmt.Sprintf("%10s %5s %12s %12s", "Self", "%", "Rows", "Est. rows")))
+	b.WriteString("\n")
+
+	rows := v.rows()
+	// Keep the selection visible
+	visible := max(m.height-10-planDetailLines-len(v.plan.Summary), 3)
+	start := 0
+	if v.selected >= visible {
+		start = v.selected - visible + 1
+	}
+	end := min(start+visible, len(rows))
+	for i := start; i < end; i++ {
+		row := rows[i]
+		n := row.node
+		marker := "· "
+		if len(n.Children) > 0 {
+			marker = "▾ "
+			if v.collapsed[n] {
+				marker = "▸ "
+			}
+		}
+		label := truncateString(strings.Repeat("  ", row.depth)+marker+n.Label, labelWidth)
+
+		share := 0.0
+		if total > 0 {
+			share = n.SelfTime() / total
+		}
+		stats := fmt.Sprintf("%10s %4.0f%% %12.0f %12.0f", formatPlanTime(n.SelfTime()), share*100, n.ActualRows*float64(max(n.Loops, 1)), n.EstimatedRows)
+		if !n.Analyzed {
+			stats = fmt.Sprintf("%10s %5s %12s %12.0f", "-", "-", "-", n.EstimatedRows)
+		}
+		line := padRight(label, labelWidth) + stats
+
+		switch {
+		case i == v.selected:
+			b.WriteString(styles.SelectedRow.Render("▶ " + line))
+		case share >= 0.5:
+			b.WriteString("  " + hotStyle.Render(line))
+		case share >= 0.2:
+			b.WriteString("  " + warmStyle.Render(line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	for i := end - start; i < visible; i++ {
+		b.WriteString("\n")
+	}
+
+	// The selected node's details (filters, join conditions, buffers)
+	b.WriteString("\n")
+	var details []string
+	if v.selected < len(rows) {
+		details = rows[v.selected].node.Details
+	}
+	for i := 0; i < planDetailLines; i++ {
+		switch {
+		case i < len(details) && i == planDetailLines-1 && len(details) > planDetailLines:
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  … %d more", len(details)-i)))
+		case i < len(details):
+			b.WriteString(dimStyle.Render("  " + truncateString(details[i], max(m.width-4, 10))))
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range v.plan.Summary {
+		b.WriteString("  " + line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.Help.Render("↑↓: Select | Enter/Space: Collapse/expand | ←/→: Collapse/expand | Esc: Close"))
+
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderPreview renders the table preview popup centered on screen
+func (m Model) renderPreview() string {
+	styles := m.GetStyles()
+	tab := m.tab()
+	p := m.preview
+	result := p.result
+
+	// Narrow columns so several fit in the popup
+	const maxColWidth = 20
+	innerWidth := max(m.width-8, 20)
+	colWidths := make([]int, len(result.Columns))
+	for i, col := range result.Columns {
+		colWidths[i] = min(len(col), maxColWidth)
+	}
+	for _, row := range result.Rows {
+		for i, cell := range row {
+			colWidths[i] = min(max(colWidths[i], len(cell.String())), maxColWidth)
+		}
+	}
+
+	var lines []string
+	lines = append(lines, styles.Title.Render(p.title), "")
+
+	var header []string
+	var sep []string
+	for i, col := range result.Columns {
+		header = append(header, styles.TableHeader.Render(padRight(truncateString(col, colWidths[i]), colWidths[i])))
+		sep = append(sep, strings.Repeat("─", colWidths[i]+2))
+	}
+	lines = append(lines, strings.Join(header, ""), strings.Join(sep, ""))
+
+	for _, row := range result.Rows {
+		var cells []string
+		for i, cell := range row {
+			// Keep each row on one line
+			value := strings.ReplaceAll(cell.String(), "\n", " ")
+			text := padRight(truncateString(value, colWidths[i]), colWidths[i])
+			if cell.IsNull {
+				cells = append(cells, styles.NullCell.Render(text))
+			} else {
+				cells = append(cells, styles.cellStyle(result.ColumnTypeAt(i)).Render(text))
+			}
+		}
+		lines = append(lines, strings.Join(cells, ""))
+	}
+	if len(result.Rows) == 0 {
+		lines = append(lines, styles.Help.Render("(no rows)"))
+	}
+
+	help := " | Space/Esc: Close"
+	if p.proceed != "" {
+		help = " | Enter: Run it | Esc: Cancel"
+	}
+	lines = append(lines, "", styles.Help.Render(p.note+help))
+
+	// Clip wide tables rather than wrapping them inside the border
+	body := lipgloss.NewStyle().MaxWidth(innerWidth).Render(strings.Join(lines, "\n"))
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(tab.theme.Primary).
+		Padding(0, 1).
+		Render(body)
+	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, box)
+}
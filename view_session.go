@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderSessionPanel renders the session settings panel
+func (m Model) renderSessionPanel() string {
+	styles := m.GetStyles()
+	p := m.sessionPanel
+	dimStyle := lipgloss.NewStyle().Foreground(p.tab.theme.TextDim)
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("⚙ Session Settings"))
+	b.WriteString("\n\n")
+
+	labelWidth := 0
+	for _, s := range p.settings {
+		labelWidth = max(labelWidth, len(s.name))
+	}
+	for i, s := range p.settings {
+		label := lipgloss.NewStyle().Width(labelWidth + 2).Render(s.name)
+		if i == p.selected {
+			label = styles.SelectedRow.Render(label)
+		}
+		line := "  " + label + " " + p.values[i]
+		if _, set := p.tab.session[s.name]; set && p.tab.connectionName != "" {
+			line += dimStyle.Render("  (saved)")
+		} else if set {
+			line += dimStyle.Render("  (set)")
+		}
+		b.WriteString(line + "\n")
+		if p.editing && i == p.selected {
+			b.WriteString("  " + strings.Repeat(" ", labelWidth+2) + " " + p.input.View() + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if p.err != "" {
+		b.WriteString(styles.Error.Render(p.err))
+		b.WriteString("\n\n")
+	}
+	if p.tab.connectionName == "" {
+		b.WriteString(dimStyle.Render("Changes apply to this tab only; save the connection to keep them."))
+		b.WriteString("\n\n")
+	}
+
+	if p.editing {
+		b.WriteString(styles.Help.Render("Enter: Apply (empty for the server default) | Esc: Cancel"))
+	} else {
+		b.WriteString(styles.Help.Render("↑↓: Select | Enter: Change | d: Reset to default | Esc: Close"))
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderSnippets renders the snippet picker with a preview of the selected snippet
+func (m Model) renderSnippets() string {
+	styles := m.GetStyles()
+	p := m.snippets
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("✂ Insert Snippet"))
+	b.WriteString("\n\n")
+	b.WriteString("  " + p.input.View() + "\n\n")
+
+	// Half the space lists snippets, the rest previews the selected one
+	listHeight := max((m.height-7)/2, 1)
+	start := 0
+	if p.selected >= listHeight {
+		start = p.selected - listHeight + 1
+	}
+	end := min(start+listHeight, len(p.matches))
+
+	dimStyle := lipgloss.NewStyle().Foreground(m.tab().theme.TextDim)
+	for i := start; i < end; i++ {
+		name := p.matches[i].Name
+		if i == p.selected {
+			b.WriteString(styles.SelectedRow.Render("▶ " + name))
+		} else {
+			b.WriteString("  " + name)
+		}
+		b.WriteString("\n")
+	}
+	if len(p.matches) == 0 {
+		b.WriteString(dimStyle.Render("  No matches") + "\n")
+	}
+	for i := max(end-start, 1); i < listHeight; i++ {
+		b.WriteString("\n")
+	}
+
+	previewHeight := max(m.height-7-listHeight, 1)
+	b.WriteString("\n")
+	var preview []string
+	if p.selected < len(p.matches) {
+		preview = strings.Split(p.matches[p.selected].Body, "\n")
+	}
+	for i := 0; i < previewHeight; i++ {
+		if i < len(preview) {
+			b.WriteString("  " + m.tab().highlighter.HighlightLine(preview[i]))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(styles.Help.Render(fmt.Sprintf("%d snippets in %s | ↑↓: Select | Enter: Insert | Esc: Cancel", len(p.matches), p.dir)))
+
+	return b.String()
+}
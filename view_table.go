@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -62,31 +64,93 @@ func (m Model) renderBanner() string {
 	return b.String()
 }
 
-// renderTable renders the results as a table
-func (m Model) renderTable() string {
-	tab := m.tab()
-	if tab == nil || tab.result == nil || len(tab.result.Columns) == 0 {
-		return ""
+// pageRows returns the rows of the results page shown, and the index of
+// the first
+func (t *Tab) pageRows() ([][]CellValue, int) {
+	startIdx := t.currentPage * t.rowsPerPage()
+	endIdx := startIdx + t.rowsPerPage()
+	if endIdx > len(t.result.Rows) {
+		endIdx = len(t.result.Rows)
 	}
+	return t.result.Rows[startIdx:endIdx], startIdx
+}
 
-	styles := m.GetStyles()
+// Bounds of the width of a results column
+const (
+	maxColWidth = 40 // unless it's overridden
+	minColWidth = 3
+)
 
-	// Calculate column widths
-	colWidths := make([]int, len(tab.result.Columns))
-	for i, col := range tab.result.Columns {
-		colWidths[i] = len(col)
+// columnWidthStep is how much narrowing or widening a column changes its
+// width by
+const columnWidthStep = 5
+
+// rowsPerPage returns how many rows of results a page shows: as many as fit
+// in the table's height, once the window's size is known
+func (t *Tab) rowsPerPage() int {
+	if t.pageSize > 0 {
+		return t.pageSize
 	}
+	return defaultPageSize
+}
 
-	// Get page slice
-	startIdx := tab.currentPage * pageSize
-	endIdx := startIdx + pageSize
-	if endIdx > len(tab.result.Rows) {
-		endIdx = len(tab.result.Rows)
+// fitPages sizes each tab's pages of results to the rows its table has room
+// for, keeping the selected row on the page shown
+func (m *Model) fitPages() {
+	if !m.ready {
+		return
 	}
-	pageRows := tab.result.Rows[startIdx:endIdx]
+	for _, t := range m.tabs {
+		// The table's header and separator, and the line after its last row
+		rows := m.tableHeight(t) - 3
+		if t.scriptResultsStart() >= 0 {
+			rows-- // the strip of the script's results
+		}
+		rows = max(rows, 1)
+		if rows == t.pageSize {
+			continue
+		}
+		t.pageSize = rows
+		if t.result != nil {
+			t.totalPages = t.pageCount(len(t.result.Rows))
+			t.currentPage = t.selectedRow / rows
+		}
+	}
+}
 
-	// Update widths based on data (limit to reasonable max)
-	maxColWidth := 40
+// pageCount returns how many pages it takes to show rows rows, at least one
+func (t *Tab) pageCount(rows int) int {
+	return max((rows+t.rowsPerPage()-1)/t.rowsPerPage(), 1)
+}
+
+// tableColumnWidths returns the width of each result column: the widest of
+// its name and its values on the page shown, capped
+func (t *Tab) tableColumnWidths() []int {
+	colWidths := t.contentColumnWidths()
+
+	// Cap widths (per-column overrides take precedence over the global cap)
+	for i, col := range t.result.Columns {
+		limit := columnWidthLimit(t.columnWidths, col, maxColWidth)
+		if colWidths[i] > limit {
+			colWidths[i] = limit
+		}
+	}
+	return colWidths
+}
+
+// contentColumnWidths returns the width each result column needs to show
+// its name and its values on the page shown in full
+func (t *Tab) contentColumnWidths() []int {
+	colWidths := make([]int, len(t.result.Columns))
+	for i, col := range t.result.Columns {
+		colWidths[i] = len(col)
+		if t.sortArrow(i) != "" {
+			colWidths[i] += 2 // room for the arrow
+		}
+	}
+
+	// Update widths based on data
+	pageRows, _ := t.pageRows()
 	for _, row := range pageRows {
 		for i, cell := range row {
 			displayLen := len(cell.String())
@@ -95,30 +159,174 @@ func (m Model) renderTable() string {
 			}
 		}
 	}
+	return colWidths
+}
 
-	// Cap widths
-	for i := range colWidths {
-		if colWidths[i] > maxColWidth {
-			colWidths[i] = maxColWidth
+// resizeSelectedColumn narrows (dir -1) or widens (dir 1) the selected
+// column by columnWidthStep, overriding its width cap in this tab for the
+// rest of the session. It returns what happened, for the status bar.
+func (t *Tab) resizeSelectedColumn(dir int) string {
+	if t.result == nil || t.selectedColumn >= len(t.result.Columns) {
+		return ""
+	}
+	col := t.result.Columns[t.selectedColumn]
+	width := t.tableColumnWidths()[t.selectedColumn]
+	need := t.contentColumnWidths()[t.selectedColumn]
+	if dir > 0 && width >= need {
+		return fmt.Sprintf("%s already shows its values on this page in full", col)
+	}
+	width = max(width+dir*columnWidthStep, minColWidth)
+	if dir > 0 {
+		width = min(width, need)
+	}
+
+	// The overrides may be the connection's from the config, so they're
+	// copied rather than changed there
+	widths := make(map[string]int, len(t.columnWidths)+1)
+	for name, w := range t.columnWidths {
+		if !strings.EqualFold(name, col) {
+			widths[name] = w
 		}
 	}
+	widths[col] = width
+	t.columnWidths = widths
+	return fmt.Sprintf("%s is %d characters wide", col, width)
+}
+
+// columnWindow returns the end (exclusive) of the columns from first on
+// that fit side by side in width, each padded by a space either side,
+// skipping those marked in skip. There's always at least one.
+func columnWindow(colWidths []int, skip []bool, first, width int) int {
+	used, shown := 0, 0
+	for i := first; i < len(colWidths); i++ {
+		if i < len(skip) && skip[i] {
+			continue
+		}
+		used += colWidths[i] + 2
+		if used > width && shown > 0 {
+			return i
+		}
+		shown++
+	}
+	return len(colWidths)
+}
+
+// visibleColumns returns the range of result columns a table width wide
+// shows after its pinned columns, scrolled to the tab's firstColumn
+func (t *Tab) visibleColumns(width int) (first, last int) {
+	first = min(t.firstColumn, max(len(t.result.Columns)-1, 0))
+	colWidths := t.tableColumnWidths()
+	skip := make([]bool, len(colWidths))
+	// Leave room for the focus marker drawn before the table
+	width -= 2
+	for i := range skip {
+		skip[i] = t.result.isHidden(i) || t.result.isPinned(i)
+		if t.result.isPinned(i) && !t.result.isHidden(i) {
+			width -= colWidths[i] + 2
+		}
+	}
+	return first, columnWindow(colWidths, skip, first, width)
+}
+
+// tableColumns returns the result columns a table width wide shows, in
+// order: the pinned ones, then those scrolled to
+func (t *Tab) tableColumns(width int) []int {
+	var cols []int
+	for i := range t.result.Columns {
+		if t.result.isPinned(i) && !t.result.isHidden(i) {
+			cols = append(cols, i)
+		}
+	}
+	first, last := t.visibleColumns(width)
+	for i := first; i < last; i++ {
+		if !t.result.isPinned(i) && !t.result.isHidden(i) {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// columnOrder returns the columns shown, in the order the table shows
+// them, which ←/→ move through
+func (t *Tab) columnOrder() []int {
+	var pinned, rest []int
+	for i := range t.result.Columns {
+		switch {
+		case t.result.isHidden(i):
+		case t.result.isPinned(i):
+			pinned = append(pinned, i)
+		default:
+			rest = append(rest, i)
+		}
+	}
+	return append(pinned, rest...)
+}
+
+// stepColumn moves the selected column to the next (dir 1) or previous
+// (dir -1) one shown, in the order the table shows them
+func (t *Tab) stepColumn(dir int) {
+	order := t.columnOrder()
+	if i := slices.Index(order, t.selectedColumn) + dir; i >= 0 && i < len(order) {
+		t.selectedColumn = order[i]
+	} else if len(order) > 0 && !slices.Contains(order, t.selectedColumn) {
+		t.selectedColumn = order[0]
+	}
+}
+
+// scrollToSelectedColumn scrolls a table width wide so the selected column
+// is in view; pinned columns always are
+func (t *Tab) scrollToSelectedColumn(width int) {
+	if t.result.isPinned(t.selectedColumn) {
+		return
+	}
+	t.firstColumn = min(t.firstColumn, t.selectedColumn)
+	for t.firstColumn < t.selectedColumn {
+		if _, last := t.visibleColumns(width); t.selectedColumn < last {
+			break
+		}
+		t.firstColumn++
+	}
+}
+
+// renderTable renders the results as a table, the columns scrolled to the
+// tab's firstColumn
+func (m Model) renderTable() string {
+	tab := m.tab()
+	if tab == nil || tab.result == nil || len(tab.result.Columns) == 0 {
+		return ""
+	}
+
+	styles := m.GetStyles()
+	colWidths := tab.tableColumnWidths()
+	cols := tab.tableColumns(m.width)
+	pageRows, startIdx := tab.pageRows()
+	endIdx := startIdx + len(pageRows)
 
 	var b strings.Builder
 
 	// Header
 	var headerCells []string
-	for i, col := range tab.result.Columns {
-		cell := truncateString(col, colWidths[i])
-		cell = padRight(cell, colWidths[i])
-		headerCells = append(headerCells, styles.TableHeader.Render(cell))
+	for _, i := range cols {
+		col := tab.result.Columns[i]
+		var cell string
+		if arrow := tab.sortArrow(i); arrow != "" && colWidths[i] > 2 {
+			cell = padRight(truncateString(col, colWidths[i]-2), colWidths[i]-2) + " " + arrow
+		} else {
+			cell = padRight(truncateString(col, colWidths[i]), colWidths[i])
+		}
+		style := styles.TableHeader
+		if i == tab.selectedColumn && m.focus == focusResults {
+			style = style.Underline(true)
+		}
+		headerCells = append(headerCells, style.Render(cell))
 	}
 	b.WriteString(strings.Join(headerCells, ""))
 	b.WriteString("\n")
 
 	// Separator
 	var sepParts []string
-	for _, w := range colWidths {
-		sepParts = append(sepParts, strings.Repeat("─", w+2))
+	for _, i := range cols {
+		sepParts = append(sepParts, strings.Repeat("─", colWidths[i]+2))
 	}
 	b.WriteString(strings.Join(sepParts, ""))
 	b.WriteString("\n")
@@ -127,14 +335,19 @@ func (m Model) renderTable() string {
 	for rowIdx, row := range pageRows {
 		actualRowIdx := startIdx + rowIdx
 		var cells []string
-		for i, cell := range row {
+		for _, i := range cols {
+			cell := row[i]
 			displayVal := cell.String()
 			cellStr := truncateString(displayVal, colWidths[i])
 			cellStr = padRight(cellStr, colWidths[i])
 
 			isSelected := actualRowIdx == tab.selectedRow && m.focus == focusResults
 
-			if cell.IsNull {
+			if match := tab.resultSearch.matches(cell); match && actualRowIdx == tab.selectedRow && i == tab.selectedColumn {
+				cells = append(cells, styles.CurrentMatch.Render(cellStr))
+			} else if match {
+				cells = append(cells, styles.MatchCell.Render(cellStr))
+			} else if cell.IsNull {
 				// NULL values get special styling
 				if isSelected {
 					cells = append(cells, styles.SelectedRow.Render(styles.NullCell.Render(cellStr)))
@@ -142,14 +355,39 @@ func (m Model) renderTable() string {
 					cells = append(cells, styles.NullCell.Render(cellStr))
 				}
 			} else if isSelected {
+				// Selected row keeps a uniform color so it stays readable
 				cells = append(cells, styles.SelectedRow.Render(styles.TableCell.Render(cellStr)))
+			} else if mark := tab.diff.mark(actualRowIdx); mark == rowAdded {
+				cells = append(cells, styles.AddedCell.Render(cellStr))
+			} else if mark == rowChanged && tab.diff.cells[actualRowIdx][i] {
+				cells = append(cells, styles.ChangedCell.Render(cellStr))
 			} else {
-				cells = append(cells, styles.TableCell.Render(cellStr))
+				cells = append(cells, styles.cellStyle(tab.result.ColumnTypeAt(i)).Render(cellStr))
 			}
 		}
 		b.WriteString(strings.Join(cells, ""))
 		b.WriteString("\n")
 	}
 
+	// Rows a re-run no longer returned follow the last page, struck through
+	if tab.diff != nil && endIdx == len(tab.result.Rows) {
+		removed := tab.diff.removed
+		if len(removed) > tab.rowsPerPage() {
+			removed = removed[:tab.rowsPerPage()]
+		}
+		for _, row := range removed {
+			var cells []string
+			for _, i := range cols {
+				cells = append(cells, styles.RemovedCell.Render(padRight(truncateString(row[i].String(), colWidths[i]), colWidths[i])))
+			}
+			b.WriteString(strings.Join(cells, ""))
+			b.WriteString("\n")
+		}
+		if more := len(tab.diff.removed) - len(removed); more > 0 {
+			b.WriteString(styles.Help.Render(fmt.Sprintf("... and %d more removed rows", more)))
+			b.WriteString("\n")
+		}
+	}
+
 	return b.String()
 }
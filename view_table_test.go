@@ -0,0 +1,102 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestColumnWindow tests which columns fit in the table's width when it's
+// scrolled to a given first column, with some columns hidden
+func TestColumnWindow(t *testing.T) {
+	widths := []int{10, 20, 5, 30}
+	tests := []struct {
+		name   string
+		hidden []bool
+		first  int
+		width  int
+		want   int
+	}{
+		{"all fit", nil, 0, 100, 4},
+		{"first two fit", nil, 0, 40, 2},
+		{"exact fit", nil, 0, 34, 2},
+		{"scrolled", nil, 1, 30, 3},
+		{"scrolled to last", nil, 3, 100, 4},
+		{"one wider than the width still shown", nil, 3, 10, 4},
+		{"first column wider than the width", nil, 1, 5, 2},
+		{"hidden column takes no room", []bool{false, true}, 0, 20, 3},
+		{"hidden first column", []bool{true}, 0, 25, 2},
+		{"all hidden from first", []bool{false, true, true, true}, 1, 5, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnWindow(widths, tt.hidden, tt.first, tt.width); got != tt.want {
+				t.Errorf("columnWindow(%v, %v, %d, %d) = %d, want %d", widths, tt.hidden, tt.first, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTableColumns tests that pinned columns are drawn first and stay in
+// view while the others scroll, and that ←/→ move in the order drawn
+func TestTableColumns(t *testing.T) {
+	row := []CellValue{{Value: "1234567890"}, {Value: "1234567890"}, {Value: "1234567890"}, {Value: "1234567890"}}
+	tab := &Tab{result: &QueryResult{
+		Columns: []string{"a", "b", "c", "d"},
+		Rows:    [][]CellValue{row},
+		pinned:  []bool{false, false, true},
+	}}
+
+	// Each column takes 12, and the focus marker 2
+	if got, want := tab.tableColumns(40), []int{2, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("tableColumns() = %v, want %v", got, want)
+	}
+	tab.firstColumn = 1
+	if got, want := tab.tableColumns(40), []int{2, 1, 3}; !slices.Equal(got, want) {
+		t.Errorf("scrolled tableColumns() = %v, want %v", got, want)
+	}
+
+	var order []int
+	for tab.selectedColumn = 2; len(order) < 4; tab.stepColumn(1) {
+		order = append(order, tab.selectedColumn)
+	}
+	if want := []int{2, 0, 1, 3}; !slices.Equal(order, want) {
+		t.Errorf("stepColumn() went through %v, want %v", order, want)
+	}
+}
+
+// TestResizeSelectedColumn tests narrowing and widening a column past the
+// cap, without changing the connection's configured widths
+func TestResizeSelectedColumn(t *testing.T) {
+	configured := map[string]int{"Payload": 20, "id": 4}
+	tab := &Tab{
+		columnWidths: configured,
+		result: &QueryResult{
+			Columns: []string{"id", "payload"},
+			Rows:    [][]CellValue{{{Value: "1"}, {Value: strings.Repeat("x", 28)}}},
+		},
+		selectedColumn: 1,
+	}
+	width := func() int { return tab.tableColumnWidths()[1] }
+
+	tab.resizeSelectedColumn(1)
+	if got := width(); got != 25 {
+		t.Errorf("widened width = %d, want 25", got)
+	}
+	tab.resizeSelectedColumn(1)
+	if got := width(); got != 28 {
+		t.Errorf("widened to the longest value = %d, want 28", got)
+	}
+	if msg := tab.resizeSelectedColumn(1); !strings.Contains(msg, "in full") || width() != 28 {
+		t.Errorf("widening a column shown in full = %q, width %d", msg, width())
+	}
+	for range 10 {
+		tab.resizeSelectedColumn(-1)
+	}
+	if got := width(); got != minColWidth {
+		t.Errorf("narrowed width = %d, want %d", got, minColWidth)
+	}
+	if len(tab.columnWidths) != 2 || configured["Payload"] != 20 {
+		t.Errorf("columnWidths = %v, configured = %v", tab.columnWidths, configured)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderVariablePrompt renders the form asking for a query's variable values
+func (m Model) renderVariablePrompt() string {
+	styles := m.GetStyles()
+	p := m.variablePrompt
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("🧩 Query Variables"))
+	b.WriteString("\n\n")
+
+	// Show the start of the query so it's clear what is about to run
+	dimStyle := lipgloss.NewStyle().Foreground(m.tab().theme.TextDim)
+	lines := strings.Split(p.query, "\n")
+	for i, line := range lines {
+		if i == 5 {
+			b.WriteString(dimStyle.Render("  …") + "\n")
+			break
+		}
+		b.WriteString("  " + m.tab().highlighter.HighlightLine(line) + "\n")
+	}
+	b.WriteString("\n")
+
+	labelWidth := 0
+	for _, name := range p.names {
+		labelWidth = max(labelWidth, len(name))
+	}
+	for i, name := range p.names {
+		label := lipgloss.NewStyle().Width(labelWidth + 2).Render(name + ":")
+		if i == p.focused {
+			label = styles.SelectedRow.Render(label)
+		}
+		b.WriteString("  " + label + " " + p.inputs[i].View() + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.Help.Render("Parameters are bound - no quotes needed, NULL for null; {{name}} is substituted as typed | ↑↓/Tab: Field | Enter: Next/Run | Esc: Cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderWriteConfirm renders the warning before an UPDATE or DELETE without
+// WHERE runs, with the statement and the YES prompt
+func (m Model) renderWriteConfirm() string {
+	styles := m.GetStyles()
+	c := m.writeConfirm
+	theme := c.tab.theme
+	var b strings.Builder
+
+	dangerStyle := lipgloss.NewStyle().Foreground(theme.TextBright).Background(theme.Danger).Bold(true).Padding(0, 1)
+	b.WriteString(dangerStyle.Render(fmt.Sprintf("⚠ %s without WHERE", c.verb)))
+	b.WriteString("\n\n")
+
+	what := "every row of the table"
+	if table := c.table(); table != "" {
+		what = "every row of " + table
+	}
+	action := "changes"
+	if c.verb == "DELETE" {
+		action = "deletes"
+	}
+	b.WriteString(styles.Error.Render(fmt.Sprintf("This %s %s.", action, what)))
+	b.WriteString("\n")
+	if c.tab.isProduction() {
+		b.WriteString(styles.Error.Render("This is a production connection."))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	dimStyle := lipgloss.NewStyle().Foreground(theme.TextDim)
+	lines := strings.Split(c.query, "\n")
+	for i, line := range lines {
+		if i == 8 {
+			b.WriteString(dimStyle.Render("  …") + "\n")
+			break
+		}
+		b.WriteString("  " + c.tab.highlighter.HighlightLine(line) + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Type YES to run it: " + c.input.View())
+	b.WriteString("\n")
+	if c.mismatch {
+		b.WriteString(styles.Error.Render("Type YES in capitals, or press Esc to cancel"))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(styles.Help.Render("Enter: Run | Esc: Cancel"))
+
+	return b.String()
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rivo/uniseg"
 )
 
+// messagesPanelHeight is the number of lines used by the messages panel, including its header and spacing
+const messagesPanelHeight = 8
+
 // renderHighlightedQuery renders the query textarea content with SQL syntax highlighting
 func (m Model) renderHighlightedQuery() string {
 	tab := m.tab()
@@ -57,26 +63,90 @@ func (m Model) renderHighlightedQuery() string {
 		Width(lineNumWidth).
 		Align(lipgloss.Right)
 
+	// The first line of a statement with lint warnings is numbered in the
+	// warning colour; the warnings themselves are in the status bar
+	lintLineNumStyle := lineNumStyle.
+		Foreground(tab.theme.Warning).
+		Bold(true)
+
 	// Cursor style
 	cursorStyle := lipgloss.NewStyle().
 		Background(tab.theme.TextBright).
 		Foreground(tab.theme.Secondary)
 
-	var b strings.Builder
-	isFocused := m.focus == focusQuery
+	isFocused := m.focus == focusQuery || m.focus == focusSearch || m.focus == focusGotoLine || m.focus == focusWatch
+
+	// Lines of the statement Ctrl+R would run, shaded so it's clear what will
+	// execute - unless text is selected, in which case that's what runs
+	stmtFirst, stmtLast := statementLineRange(content, cursorLine, tab.dbType)
+	selStart, selEnd := -1, -1
+	if tab.selection != nil {
+		selStart, selEnd = tab.selection.Range(textareaCursorOffset(tab.textarea))
+		stmtFirst, stmtLast = -1, -1
+	}
+	selectionStyle := lipgloss.NewStyle().
+		Background(tab.theme.Primary).
+		Foreground(tab.theme.TextBright)
+	lintLine := -1
+	if len(m.cursorLintWarnings()) > 0 {
+		lintLine = stmtFirst
+	}
+
+	// Ctrl+F search matches, the one at the cursor picked out
+	var search *EditorSearch
+	if m.focus == focusSearch && m.editorSearch != nil {
+		search = m.editorSearch
+		stmtFirst, stmtLast = -1, -1
+	}
+	matchStyle := lipgloss.NewStyle().
+		Background(tab.theme.Warning).
+		Foreground(tab.theme.Secondary)
+
+	// Where the shown error happened, and the line it's on
+	errFrom, errTo := editorErrorSpan(tab, content)
+	errorLine := -1
+	if errFrom >= 0 {
+		errorLine = strings.Count(content[:errFrom], "\n")
+	}
+	errorStyle := lipgloss.NewStyle().
+		Background(tab.theme.Danger).
+		Foreground(tab.theme.TextBright)
+	errorLineNumStyle := lineNumStyle.
+		Foreground(tab.theme.Danger).
+		Bold(true)
+
+	// Dollar-quoted bodies ($$ ... $$) span lines, which the line-at-a-time
+	// highlighter can't see, so lines inside one are marked as strings
+	dollarBodies := dollarQuoteRanges(content, tab.dbType)
+	stringStyle := lipgloss.NewStyle().Foreground(tab.theme.SyntaxString)
+	lineStart := 0
+	for _, l := range lines[:scrollOffset] {
+		lineStart += len(l) + 1
+	}
+
+	// Each visible row is its line number gutter and its rendered content;
+	// plain keeps the unstyled text so the completion popup can be laid over it
+	gutters := make([]string, 0, height)
+	rows := make([]string, 0, height)
+	plain := make([]string, 0, height)
 
 	// Render visible lines
 	for i := scrollOffset; i < len(lines) && i < scrollOffset+height; i++ {
 		line := lines[i]
 
 		// Line number
+		gutter := ""
 		if tab.textarea.ShowLineNumbers {
-			if i == cursorLine {
-				b.WriteString(cursorLineNumStyle.Render(fmt.Sprintf("%d", i+1)))
+			if i == errorLine {
+				gutter = errorLineNumStyle.Render(fmt.Sprintf("%d", i+1))
+			} else if i == lintLine {
+				gutter = lintLineNumStyle.Render(fmt.Sprintf("%d", i+1))
+			} else if i == cursorLine {
+				gutter = cursorLineNumStyle.Render(fmt.Sprintf("%d", i+1))
 			} else {
-				b.WriteString(lineNumStyle.Render(fmt.Sprintf("%d", i+1)))
+				gutter = lineNumStyle.Render(fmt.Sprintf("%d", i+1))
 			}
-			b.WriteString(" ")
+			gutter += " "
 		}
 
 		// Apply syntax highlighting to the line and pad to full width
@@ -84,7 +154,45 @@ func (m Model) renderHighlightedQuery() string {
 		isCursorLine := isFocused && i == cursorLine
 		cursorAtEnd := isCursorLine && cursorCol >= len([]rune(line))
 
-		if tab.highlighter != nil {
+		var marks []lineMark
+		if from, to, ok := lineSelectionColumns(lines, i, selStart, selEnd); ok {
+			marks = append(marks, lineMark{from, to, selectionStyle})
+		}
+		if search != nil {
+			for _, span := range lineSearchSpans(line, lineStart, search.matches, search.current) {
+				style := matchStyle
+				if span.current {
+					style = selectionStyle
+				}
+				marks = append(marks, lineMark{span.from, span.to, style})
+			}
+		}
+		if errFrom < lineStart+len(line) && errTo > lineStart {
+			from := utf8.RuneCountInString(line[:max(errFrom-lineStart, 0)])
+			to := utf8.RuneCountInString(line[:min(errTo-lineStart, len(line))])
+			if from < utf8.RuneCountInString(line) {
+				marks = underMarks(marks, lineMark{from, max(to, from+1), errorStyle})
+			}
+		}
+		for _, body := range dollarBodies {
+			// Only bodies opened on an earlier line; the highlighter handles
+			// the line a body opens on
+			if body[0] < lineStart && body[1] > lineStart {
+				to := utf8.RuneCountInString(line[:min(body[1]-lineStart, len(line))])
+				if to > 0 {
+					marks = underMarks(marks, lineMark{0, to, stringStyle})
+				}
+			}
+		}
+		lineStart += len(line) + 1
+
+		if len(marks) > 0 {
+			col := -1
+			if isCursorLine {
+				col = cursorCol
+			}
+			renderedLine = m.renderMarkedLine(tab, line, marks, col, cursorStyle, contentWidth)
+		} else if tab.highlighter != nil {
 			highlightedLine := tab.highlighter.HighlightLine(line)
 
 			// If this is the cursor line and we're focused, insert cursor
@@ -108,35 +216,90 @@ func (m Model) renderHighlightedQuery() string {
 		if cursorAtEnd {
 			effectivePlainWidth++ // Account for cursor block
 		}
-		b.WriteString(m.padToWidthWithVisibleWidth(renderedLine, effectivePlainWidth, contentWidth))
-
-		if i < scrollOffset+height-1 {
-			b.WriteString("\n")
+		renderedLine = m.padToWidthWithVisibleWidth(renderedLine, effectivePlainWidth, contentWidth)
+		if i >= stmtFirst && i <= stmtLast {
+			renderedLine = withBackground(renderedLine, tab.theme.Secondary)
 		}
+		gutters = append(gutters, gutter)
+		rows = append(rows, renderedLine)
+		plain = append(plain, line)
 	}
 
 	// Pad with empty lines if content is shorter than height
-	linesRendered := len(lines) - scrollOffset
-	if linesRendered < 0 {
-		linesRendered = 0
-	}
-	if linesRendered > height {
-		linesRendered = height
-	}
-	for i := linesRendered; i < height; i++ {
+	for len(rows) < height {
+		gutter := ""
 		if tab.textarea.ShowLineNumbers {
-			b.WriteString(strings.Repeat(" ", lineNumWidth+1))
+			gutter = strings.Repeat(" ", lineNumWidth+1)
 		}
-		// Pad empty lines to full width
-		b.WriteString(strings.Repeat(" ", contentWidth))
-		if i < height-1 {
+		gutters = append(gutters, gutter)
+		rows = append(rows, strings.Repeat(" ", contentWidth))
+		plain = append(plain, "")
+	}
+
+	if isFocused && tab.completion != nil {
+		m.overlayCompletionPopup(tab, rows, plain, cursorLine-scrollOffset, cursorCol, contentWidth)
+	}
+
+	var b strings.Builder
+	for i := range rows {
+		b.WriteString(gutters[i] + rows[i])
+		if i < len(rows)-1 {
 			b.WriteString("\n")
 		}
 	}
-
 	return b.String()
 }
 
+// overlayCompletionPopup draws the completion suggestions over the rows below
+// the cursor row (or above it, if there isn't room below), aligned with the
+// start of the word being completed. Covered rows are redrawn unhighlighted.
+func (m Model) overlayCompletionPopup(tab *Tab, rows, plain []string, cursorRow, cursorCol, width int) {
+	p := tab.completion
+	count := min(len(p.Candidates), popupMaxItems)
+	start := 0
+	if p.selected >= count {
+		start = p.selected - count + 1
+	}
+
+	itemWidth := 0
+	for _, c := range p.Candidates {
+		itemWidth = max(itemWidth, uniseg.StringWidth(c))
+	}
+	itemWidth = min(itemWidth+2, width)
+
+	top := cursorRow + 1
+	if top+count > len(rows) {
+		top = cursorRow - count
+	}
+	if top < 0 {
+		return // editor too short to show it
+	}
+	col := max(cursorCol-len([]rune(p.Prefix)), 0)
+	col = max(min(col, width-itemWidth), 0)
+
+	itemStyle := lipgloss.NewStyle().Width(itemWidth).Background(tab.theme.Secondary).Foreground(tab.theme.TextNormal)
+	selectedStyle := itemStyle.Background(tab.theme.Primary).Foreground(tab.theme.TextBright).Bold(true)
+	for i := 0; i < count; i++ {
+		idx := start + i
+		label := []rune(" " + p.Candidates[idx])
+		label = label[:min(len(label), itemWidth)]
+		style := itemStyle
+		if idx == p.selected {
+			style = selectedStyle
+		}
+
+		runes := []rune(plain[top+i])
+		left := string(runes[:min(col, len(runes))])
+		left += strings.Repeat(" ", col-len([]rune(left)))
+		right := ""
+		if col+itemWidth < len(runes) {
+			right = string(runes[col+itemWidth:])
+		}
+		line := left + style.Render(string(label)) + right
+		rows[top+i] = m.padToWidthWithVisibleWidth(line, col+itemWidth+len([]rune(right)), width)
+	}
+}
+
 // insertCursor inserts a cursor character into a highlighted line at the correct position
 func (m Model) insertCursor(tab *Tab, plainLine, highlightedLine string, cursorCol int, cursorStyle lipgloss.Style, maxWidth int) string {
 	// If cursor is at or past end of line, append cursor block
@@ -170,6 +333,89 @@ func (m Model) insertCursor(tab *Tab, plainLine, highlightedLine string, cursorC
 	return m.truncateLine(result.String(), maxWidth)
 }
 
+// lineMark is a run of rune columns [from, to) of a line drawn in a style,
+// such as the selection or a search match
+type lineMark struct {
+	from, to int
+	style    lipgloss.Style
+}
+
+// underMarks adds mark to marks, minus any columns already marked, keeping
+// marks in order
+func underMarks(marks []lineMark, mark lineMark) []lineMark {
+	pieces := []lineMark{mark}
+	for _, m := range marks {
+		var cut []lineMark
+		for _, p := range pieces {
+			if m.to <= p.from || m.from >= p.to {
+				cut = append(cut, p)
+				continue
+			}
+			if p.from < m.from {
+				cut = append(cut, lineMark{p.from, m.from, p.style})
+			}
+			if m.to < p.to {
+				cut = append(cut, lineMark{m.to, p.to, p.style})
+			}
+		}
+		pieces = cut
+	}
+	marks = append(marks, pieces...)
+	sort.Slice(marks, func(i, j int) bool { return marks[i].from < marks[j].from })
+	return marks
+}
+
+// renderMarkedLine renders a line with the marked runs of columns in their
+// styles and, if cursorCol isn't -1, the cursor. Unmarked text keeps its
+// syntax highlighting. Marks must be in order and not overlap.
+func (m Model) renderMarkedLine(tab *Tab, line string, marks []lineMark, cursorCol int, cursorStyle lipgloss.Style, maxWidth int) string {
+	runes := []rune(line)
+	highlight := func(s string) string {
+		if s == "" || tab.highlighter == nil {
+			return s
+		}
+		return tab.highlighter.HighlightLine(s)
+	}
+
+	// Split the line at the mark edges and the cursor
+	cuts := []int{0, len(runes)}
+	for _, mark := range marks {
+		cuts = append(cuts, mark.from, mark.to)
+	}
+	if cursorCol >= 0 && cursorCol < len(runes) {
+		cuts = append(cuts, cursorCol, cursorCol+1)
+	}
+	sort.Ints(cuts)
+
+	var b strings.Builder
+	for i := 0; i+1 < len(cuts); i++ {
+		a, z := cuts[i], cuts[i+1]
+		if a == z {
+			continue
+		}
+		part := string(runes[a:z])
+		if a == cursorCol {
+			b.WriteString(cursorStyle.Render(part))
+			continue
+		}
+		marked := false
+		for _, mark := range marks {
+			if a >= mark.from && z <= mark.to {
+				b.WriteString(mark.style.Render(part))
+				marked = true
+				break
+			}
+		}
+		if !marked {
+			b.WriteString(highlight(part))
+		}
+	}
+	if cursorCol >= len(runes) {
+		return m.truncateLine(b.String(), maxWidth-1) + cursorStyle.Render(" ")
+	}
+	return m.truncateLine(b.String(), maxWidth)
+}
+
 // insertCursorPlain inserts a cursor into a plain (non-highlighted) line
 func (m Model) insertCursorPlain(line string, cursorCol int, cursorStyle lipgloss.Style, maxWidth int) string {
 	runes := []rune(line)
@@ -197,6 +443,18 @@ func (m Model) truncateLine(line string, maxWidth int) string {
 	return line
 }
 
+// withBackground applies a background color to an already-styled line.
+// The background is re-applied after every reset emitted by the inner styles.
+func withBackground(line string, bg lipgloss.Color) string {
+	sample := lipgloss.NewStyle().Background(bg).Render(" ")
+	prefix := sample[:strings.Index(sample, " ")]
+	if prefix == "" {
+		return line // no color support
+	}
+	const reset = "\x1b[0m"
+	return prefix + strings.ReplaceAll(line, reset, reset+prefix) + reset
+}
+
 // padToWidthWithVisibleWidth pads a rendered line to the specified width
 // using a pre-calculated visible width
 func (m Model) padToWidthWithVisibleWidth(renderedLine string, visibleWidth, targetWidth int) string {
@@ -232,10 +490,84 @@ func (m Model) View() string {
 		return m.renderConnectionPicker()
 	}
 
-	// Get themed styles
-	styles := m.GetStyles()
+	// Show database switch prompt if active
+	if m.focus == focusDatabasePrompt {
+		return m.renderDatabasePrompt()
+	}
+
+	// Show ER overview if active
+	if m.focus == focusER && m.erView != nil {
+		return m.renderERView()
+	}
+
+	// Show DDL viewer if active
+	if m.focus == focusDDL && m.ddlView != nil {
+		return m.renderDDLView()
+	}
+
+	// Show table preview popup if active
+	if m.focus == focusPreview && m.preview != nil {
+		return m.renderPreview()
+	}
+
+	// Show query plan viewer if active
+	if m.focus == focusPlan && m.planView != nil {
+		return m.renderPlanView()
+	}
 
-	// Calculate heights
+	// Show query variable prompt if active
+	if m.focus == focusVariables && m.variablePrompt != nil {
+		return m.renderVariablePrompt()
+	}
+
+	// Show the confirmation of an UPDATE or DELETE without WHERE if active
+	if m.focus == focusConfirmWrite && m.writeConfirm != nil {
+		return m.renderWriteConfirm()
+	}
+
+	// Show column picker if active
+	if m.focus == focusColumnPicker && m.columnPicker != nil {
+		return m.renderColumnPicker()
+	}
+
+	// Show session settings panel if active
+	if m.focus == focusSession && m.sessionPanel != nil {
+		return m.renderSessionPanel()
+	}
+
+	// Show snippet picker if active
+	if m.focus == focusSnippets && m.snippets != nil {
+		return m.renderSnippets()
+	}
+
+	// Show bookmark picker if active
+	if m.focus == focusBookmarks && m.bookmarks != nil {
+		return m.renderBookmarks()
+	}
+
+	// Show query history browser if active
+	if m.focus == focusHistory && m.history != nil {
+		return m.renderHistory()
+	}
+
+	// Show table/column finder if active
+	if m.focus == focusFinder && m.finder != nil {
+		return m.renderFinder()
+	}
+
+	if m.showSidebar {
+		main := m
+		main.width = m.mainWidth()
+		// Clip the main view so wide results don't push the sidebar off screen
+		mainView := lipgloss.NewStyle().MaxWidth(main.width).Render(main.renderMainView())
+		return lipgloss.JoinHorizontal(lipgloss.Top, mainView, m.renderSidebar())
+	}
+	return m.renderMainView()
+}
+
+// tableHeight returns the lines left for the results area of tab, below
+// its query editor
+func (m Model) tableHeight(tab *Tab) int {
 	// Title: 1 line + 1 blank = 2
 	// Tab bar: 1 line + 1 blank = 2
 	// Query box: textarea height + 2 (border) + 1 blank = textarea.Height() + 3
@@ -250,17 +582,32 @@ func (m Model) View() string {
 	queryBoxHeight := textareaHeight + 4 // includes border padding and blank line
 	statusHeight := 1
 	helpHeight := 1
-	tableHeight := m.height - titleHeight - tabBarHeight - queryBoxHeight - statusHeight - helpHeight
+	messagesHeight := 0
+	if m.showMessages {
+		messagesHeight = messagesPanelHeight
+	}
+	return max(m.height-titleHeight-tabBarHeight-queryBoxHeight-statusHeight-helpHeight-messagesHeight, 3)
+}
+
+// renderMainView renders the title, tab bar, query editor, results and status bar
+func (m Model) renderMainView() string {
+	tab := m.tab()
 
-	if tableHeight < 3 {
-		tableHeight = 3
+	// Get themed styles
+	styles := m.GetStyles()
+
+	tableHeight := m.tableHeight(tab)
+	messagesHeight := 0
+	if m.showMessages {
+		messagesHeight = messagesPanelHeight
 	}
 
 	var b strings.Builder
 
 	// Title
-	titleText := "🌱  Dibber - Database Client"
-	b.WriteString(styles.Title.Render(titleText))
+	b.WriteString(styles.Title.Render(m.titleText()))
+	b.WriteString(m.renderHealth())
+	b.WriteString(m.renderConnectionNote())
 	b.WriteString("\n\n")
 
 	// Tab bar
@@ -281,9 +628,11 @@ func (m Model) View() string {
 
 	if tab != nil && tab.result != nil {
 		if tab.result.Error != nil {
-			tableContent = styles.Error.Render(fmt.Sprintf("Error: %v", tab.result.Error))
-		} else if len(tab.result.Rows) > 0 {
+			tableContent = m.renderErrorPanel(tab, tableHeight)
+		} else if len(tab.result.Rows) > 0 || tab.result.filter != nil || (tab.diff != nil && len(tab.diff.removed) > 0) {
 			tableContent = m.renderTable()
+		} else if tab.result.Executed {
+			tableContent = fmt.Sprintf("Statement executed successfully. %s.", resultOutcome(tab.result))
 		} else {
 			tableContent = "Query executed successfully. No rows returned."
 		}
@@ -296,6 +645,9 @@ func (m Model) View() string {
 		focusIndicator := styles.EditableBadge.Render("▶ ")
 		tableContent = focusIndicator + tableContent
 	}
+	if strip := m.renderScriptResults(); strip != "" {
+		tableContent = strip + "\n" + tableContent
+	}
 
 	// Count lines in table content and pad to fill available space
 	tableLines := strings.Count(tableContent, "\n") + 1
@@ -306,6 +658,13 @@ func (m Model) View() string {
 		b.WriteString("\n")
 	}
 
+	// Messages panel (session log)
+	if m.showMessages {
+		b.WriteString("\n")
+		b.WriteString(m.renderMessagesPanel(messagesHeight - 1))
+		b.WriteString("\n")
+	}
+
 	// Status bar
 	statusText := m.statusMessage
 	if tab != nil && tab.result != nil && len(tab.result.Rows) > 0 {
@@ -317,10 +676,92 @@ func (m Model) View() string {
 				editableText = " [Read-only]"
 			}
 		}
-		statusText = fmt.Sprintf("%s%s | Page %d/%d | Row %d/%d",
-			m.statusMessage, editableText, tab.currentPage+1, tab.totalPages, tab.selectedRow+1, len(tab.result.Rows))
+		pages := fmt.Sprintf("%d", tab.totalPages)
+		if tab.result.HasMoreRows() {
+			pages += "+"
+		}
+		statusText = fmt.Sprintf("%s%s | Page %d/%s | Row %d/%s",
+			m.statusMessage, editableText, tab.currentPage+1, pages, tab.selectedRow+1, tab.result.RowCountText())
+		if first, last := tab.visibleColumns(m.width); first > 0 || last < len(tab.result.Columns) {
+			statusText += fmt.Sprintf(" | Cols %d-%d/%d", first+1, last, len(tab.result.Columns))
+		}
+		if hidden := tab.result.hiddenCount(); hidden > 0 {
+			statusText += fmt.Sprintf(" | %d hidden", hidden)
+		}
+	}
+	if tab != nil && tab.result != nil && tab.result.filter != nil {
+		statusText += " | " + tab.result.filterStatus()
+	}
+	if tab != nil && tab.result != nil && len(tab.results) > 1 {
+		statusText += fmt.Sprintf(" | Result %d/%d", tab.resultIndex+1, len(tab.results))
+	}
+	if tab != nil && tab.running != "" {
+		elapsed := time.Since(tab.runningSince).Round(100 * time.Millisecond)
+		statusText = fmt.Sprintf("%s Running... %s (Esc to cancel)", m.spinner.View(), elapsed)
+		if tab.script != nil {
+			statusText = fmt.Sprintf("%s Running statement %d of %d... %s (Esc to cancel)", m.spinner.View(), tab.script.next, len(tab.script.statements), elapsed)
+		}
+	}
+	if warnings := m.cursorLintWarnings(); m.focus == focusQuery && len(warnings) > 0 {
+		lint := "⚠ " + strings.Join(warnings, "; ")
+		if statusText != "" {
+			lint = statusText + " | " + lint
+		}
+		statusText = lint
+	}
+	if tab != nil && tab.saveErr != nil {
+		statusText = fmt.Sprintf("Error saving %s: %v", tab.sqlFile, tab.saveErr)
+	} else if tab != nil && tab.backupErr != nil {
+		statusText = fmt.Sprintf("Saved %s, but backing it up failed: %v", tab.sqlFile, tab.backupErr)
+	}
+	if m.focus == focusSearch && m.editorSearch != nil {
+		statusText = m.editorSearchText()
+	}
+	if m.focus == focusGotoLine && m.gotoLine != nil {
+		statusText = m.gotoLine.View()
+	}
+	if m.focus == focusWatch && m.watchPrompt != nil {
+		statusText = m.watchPrompt.View()
+	}
+	if m.focus == focusResultSearch && tab != nil && tab.resultSearch != nil {
+		statusText = tab.resultSearch.input.View()
+		if status := tab.resultSearch.status(); status != "" {
+			statusText += "  " + status
+		}
+	}
+	if m.focus == focusFilter && m.filterPrompt != nil {
+		statusText = m.filterPrompt.View()
+		if tab != nil && tab.result != nil && tab.result.filter != nil {
+			statusText += fmt.Sprintf("  (%d of %d rows)", len(tab.result.Rows), len(tab.result.fetched))
+		}
+	}
+	if m.reloadPrompt != nil {
+		statusText = m.reloadPromptText()
+	}
+	if m.txPrompt != nil {
+		statusText = m.txPromptText()
+	}
+	if m.reconnectPrompt != nil {
+		statusText = m.reconnectPromptText()
+	}
+	badge := ""
+	if tab != nil && tab.readOnly {
+		badge = lipgloss.NewStyle().Bold(true).Foreground(tab.theme.TextBright).Background(tab.theme.Primary).
+			Padding(0, 1).Render("READ ONLY")
+	}
+	if tab != nil && tab.dryRun {
+		badge += lipgloss.NewStyle().Bold(true).Foreground(tab.theme.TextBright).Background(tab.theme.Success).
+			Padding(0, 1).Render("DRY RUN")
 	}
-	b.WriteString(styles.StatusBar.Width(m.width).Render(statusText))
+	if tab != nil && tab.tx != nil {
+		badge += lipgloss.NewStyle().Bold(true).Foreground(tab.theme.TextBright).Background(tab.theme.Warning).
+			Padding(0, 1).Render("TX OPEN")
+	}
+	if tab != nil && tab.watch != nil {
+		badge += lipgloss.NewStyle().Bold(true).Foreground(tab.theme.TextBright).Background(tab.theme.Primary).
+			Padding(0, 1).Render("WATCH " + tab.watch.interval.String())
+	}
+	b.WriteString(badge + styles.StatusBar.Width(m.width-lipgloss.Width(badge)).Render(statusText))
 	b.WriteString("\n")
 
 	// Help - context-sensitive
@@ -328,43 +769,350 @@ func (m Model) View() string {
 	switch m.focus {
 	case focusQuery:
 		helpText = "Ctrl+R: Run | Ctrl+T: New Tab | Ctrl+Tab: Switch Tab | Ctrl+W: Close Tab | Ctrl+Q: Quit"
+		if m.vimMode {
+			helpText = m.vimModeText() + " | " + helpText
+		}
+	case focusSearch:
+		helpText = "Enter/↓: Next match | ↑: Previous match | Esc: Close"
+	case focusGotoLine:
+		helpText = "Enter: Go to line | Esc: Cancel"
+	case focusWatch:
+		helpText = "Enter: Start watching | Esc: Cancel"
+	case focusFilter:
+		helpText = "Enter: Keep filter | Esc: Clear filter"
+	case focusResultSearch:
+		helpText = "Enter: Keep search, n/N to step through matches | Esc: Clear search"
 	case focusResults:
 		if tab != nil && tab.result != nil && len(tab.result.Rows) > 0 {
-			helpText = "↑↓: Navigate | Enter: Detail | -/+: Resize | Tab: Switch | Ctrl+Q: Quit"
+			helpText = "↑↓←→: Navigate | Enter: Detail | /: Filter | Ctrl+F: Search | s: Sort | r: Re-run & diff | Alt+←→: Earlier results | -/+: Resize | Tab: Switch | Ctrl+Q: Quit"
+			if tab.scriptResultsStart() >= 0 {
+				helpText = "↑↓←→: Navigate | Enter: Detail | 1-9: Script results | Alt+←→: Earlier results | Tab: Switch | Ctrl+Q: Quit"
+			}
+			if tab.result.HasMoreRows() {
+				helpText = "↑↓←→: Navigate | Enter: Detail | Alt+L: Load more | Alt+←→: Earlier results | Tab: Switch | Ctrl+Q: Quit"
+			}
+		} else if tab != nil && tab.result != nil && tab.result.Error != nil {
+			helpText = "↑↓: Scroll error | Alt+←→: Earlier results | -/+: Resize | Tab: Switch | Ctrl+Q: Quit"
 		} else {
 			helpText = "-/+: Resize | Tab: Switch | Ctrl+R: Run | Ctrl+Q: Quit"
 		}
 	default:
-		helpText = "Ctrl+R: Run | Ctrl+T: New Tab | Ctrl+Tab: Switch Tab | Ctrl+P: Connections | Ctrl+Q: Quit"
+		helpText = "Ctrl+R: Run | Ctrl+T: New Tab | Ctrl+Tab: Switch Tab | Ctrl+P: Connections | Ctrl+B: Database | Ctrl+Q: Quit"
 	}
-	b.WriteString(styles.Help.Render(helpText))
+	b.WriteString(styles.Help.MaxWidth(m.width).Render(helpText))
 
 	return b.String()
 }
 
-// renderTabBar renders the tab bar showing all open tabs
-func (m Model) renderTabBar() string {
-	if len(m.tabs) == 0 {
+// renderMessagesPanel renders the most recent session log entries in the given number of lines
+func (m Model) renderMessagesPanel(height int) string {
+	styles := m.GetStyles()
+	tab := m.tab()
+	theme := DefaultTheme
+	if tab != nil {
+		theme = tab.theme
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(theme.TextDim)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Danger)
+
+	header := fmt.Sprintf("── Messages (%d) ── Alt+M: Hide | Alt+↑↓: Scroll ", len(m.messages))
+	lines := []string{styles.Help.Render(header)}
+
+	// Show the newest entries that fit, offset by the scroll position
+	end := len(m.messages) - m.messagesScroll
+	start := max(end-(height-1), 0)
+	for _, entry := range m.messages[start:end] {
+		stmt := strings.Join(strings.Fields(entry.statement), " ")
+		line := fmt.Sprintf("%s [%s] %s → %s (%s)",
+			entry.time.Format("15:04:05"), entry.tabName, stmt, entry.outcome, entry.duration.Round(time.Millisecond))
+		if runes := []rune(line); m.width > 4 && len(runes) > m.width-1 {
+			line = string(runes[:m.width-4]) + "..."
+		}
+		if entry.isError {
+			lines = append(lines, errorStyle.Render(line))
+		} else {
+			lines = append(lines, dimStyle.Render(line))
+		}
+	}
+	if len(m.messages) == 0 {
+		lines = append(lines, dimStyle.Render("No statements executed yet"))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSidebar renders the schema browser listing the tables and views of the current connection
+func (m Model) renderSidebar() string {
+	tab := m.tab()
+	if tab == nil {
 		return ""
 	}
+	styles := m.GetStyles()
+	focused := m.focus == focusSidebar
+
+	borderColor := tab.theme.Secondary
+	if focused {
+		borderColor = tab.theme.Success
+	}
+	contentWidth := sidebarWidth - 3 // border + padding
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(borderColor).
+		PaddingLeft(1).
+		Width(sidebarWidth - 1).
+		Height(m.height - 1)
+	dimStyle := lipgloss.NewStyle().Foreground(tab.theme.TextDim)
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(tab.theme.Primary).Render("Schema"))
+
+	switch {
+	case !tab.schema.loaded:
+		lines = append(lines, dimStyle.Render("Not loaded (Alt+R)"))
+	case len(tab.schema.tables) == 0:
+		lines = append(lines, dimStyle.Render("No tables"))
+	default:
+		// The load time hints at whether Alt+R is needed after outside changes
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("%d tables/views · %s", len(tab.schema.tables), tab.schema.loadedAt.Format("15:04"))))
+	}
+	lines = append(lines, "")
 
+	// Flatten tables and the columns of expanded tables into rows, remembering
+	// which row holds the selected table
+	type sidebarRow struct {
+		text  string
+		style lipgloss.Style
+	}
+	var rows []sidebarRow
+	selectedRow := 0
+	for i, table := range tab.schema.tables {
+		marker := "▸ "
+		if tab.sidebarExpanded[table.Name] {
+			marker = "▾ "
+		}
+		label := marker + table.Name
+		if table.IsView {
+			label += " (view)"
+		}
+		if count, ok := tab.schema.CachedRowCount(table.Name); ok {
+			// Right-align the count, truncating the name to make room
+			countText := " " + formatRowCount(count)
+			nameWidth := contentWidth - len(countText)
+			if runes := []rune(label); len(runes) > nameWidth {
+				label = string(runes[:nameWidth-3]) + "..."
+			}
+			label += strings.Repeat(" ", max(nameWidth-lipgloss.Width(label), 0)) + countText
+		}
+		style := lipgloss.NewStyle()
+		if table.IsView {
+			style = dimStyle
+		}
+		if i == tab.sidebarSelected {
+			selectedRow = len(rows)
+			if focused {
+				style = styles.SelectedRow
+			}
+		}
+		rows = append(rows, sidebarRow{label, style})
+		if !tab.sidebarExpanded[table.Name] {
+			continue
+		}
+		for _, col := range table.Columns {
+			colStyle := dimStyle
+			if col.PrimaryKey {
+				colStyle = lipgloss.NewStyle().Foreground(tab.theme.Warning)
+			}
+			rows = append(rows, sidebarRow{"    " + columnSummary(col), colStyle})
+		}
+		if len(table.Columns) == 0 {
+			rows = append(rows, sidebarRow{"    (no columns)", dimStyle})
+		}
+		if seq, ok := tab.schema.CachedSequence(table.Name); ok && seq != nil {
+			rows = append(rows, sidebarRow{"    ⟳ " + sequenceSummary(*seq), dimStyle})
+		}
+		if indexes, ok := tab.schema.CachedIndexes(table.Name); ok && len(indexes) > 0 {
+			rows = append(rows, sidebarRow{"  Indexes", dimStyle.Bold(true)})
+			for _, idx := range indexes {
+				rows = append(rows, sidebarRow{"    " + indexSummary(idx), dimStyle})
+			}
+		}
+	}
+
+	// Routines and triggers follow the tables (ordered by kind, so triggers come last)
+	for i, obj := range tab.schema.objects {
+		if i == 0 || (obj.Kind == "trigger") != (tab.schema.objects[i-1].Kind == "trigger") {
+			header := "Routines"
+			if obj.Kind == "trigger" {
+				header = "Triggers"
+			}
+			rows = append(rows, sidebarRow{"", lipgloss.NewStyle()}, sidebarRow{header, dimStyle.Bold(true)})
+		}
+		style := lipgloss.NewStyle()
+		if len(tab.schema.tables)+i == tab.sidebarSelected {
+			selectedRow = len(rows)
+			if focused {
+				style = styles.SelectedRow
+			}
+		}
+		rows = append(rows, sidebarRow{"  " + objectSummary(obj), style})
+	}
+
+	// Keep the selection visible, leaving room for the header and help lines
+	// (the main view leaves the terminal's last line empty, so match it)
+	visible := max(m.height-6, 1)
+	start := 0
+	if selectedRow >= visible {
+		start = selectedRow - visible + 1
+	}
+	end := min(start+visible, len(rows))
+
+	for _, row := range rows[start:end] {
+		label := row.text
+		if runes := []rune(label); len(runes) > contentWidth {
+			label = string(runes[:contentWidth-3]) + "..."
+		}
+		label += strings.Repeat(" ", max(contentWidth-lipgloss.Width(label), 0))
+		lines = append(lines, row.style.Render(label))
+	}
+
+	for len(lines) < m.height-2 {
+		lines = append(lines, "")
+	}
+	if focused {
+		lines = append(lines, dimStyle.Render("→/←: Columns | Enter: Select"))
+	} else {
+		lines = append(lines, dimStyle.Render("Alt+S: Focus"))
+	}
+
+	return boxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// titleText returns the title bar text for the active tab's connection and database
+func (m Model) titleText() string {
+	tab := m.tab()
+	if tab == nil {
+		return "🌱  Dibber - Database Client"
+	}
+
+	title := "🌱  Dibber - Database Client"
+	if tab.connectionName != "" {
+		title = fmt.Sprintf("🌱  Dibber - %s (%s)", tab.connectionName, tab.dbType)
+	}
+	if dbName := dsnDatabaseName(tab.dsn, tab.dbType); dbName != "" {
+		title += " › " + dbName
+	}
+	return title
+}
+
+// renderDatabasePrompt renders the prompt for switching database on the same server
+func (m Model) renderDatabasePrompt() string {
+	styles := m.GetStyles()
 	var b strings.Builder
 
-	for i, tab := range m.tabs {
-		// Determine tab label
-		label := tab.connectionName
-		if label == "" {
-			label = tab.dbType
+	b.WriteString(styles.Title.Render("🗄️  Switch Database"))
+	b.WriteString("\n\n")
+	if tab := m.tab(); tab != nil {
+		b.WriteString(fmt.Sprintf("  Connection: %s (%s)\n\n", m.tabDisplayName(m.activeTab), tab.dbType))
+	}
+	b.WriteString("  Database name (same server and credentials):\n")
+	b.WriteString("  " + m.databaseInput.View() + "\n\n")
+
+	// Keep the selection visible, leaving room for the header, input and help lines
+	matches := m.filteredDatabaseChoices()
+	visible := max(m.height-13, 3)
+	start := 0
+	if m.databaseSelected >= visible {
+		start = m.databaseSelected - visible + 1
+	}
+	end := min(start+visible, len(matches))
+	for i := start; i < end; i++ {
+		c := matches[i]
+		label := c.Name
+		if c.Schema {
+			label = "schema " + c.Name
 		}
-		if label == "" {
-			label = "untitled"
+		if c.Current {
+			label += " (current)"
 		}
+		if i == m.databaseSelected {
+			b.WriteString(styles.SelectedRow.Render("▶ " + label))
+		} else {
+			b.WriteString("  " + label)
+		}
+		b.WriteString("\n")
+	}
+	if len(matches) == 0 && len(m.databaseChoices) > 0 {
+		b.WriteString(styles.Help.Render("  No matches - Enter connects to the typed name"))
+		b.WriteString("\n")
+	}
+
+	if m.statusMessage != "" {
+		b.WriteString("\n")
+		b.WriteString(styles.Help.Render("  " + m.statusMessage))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(styles.Help.Render("↑↓: Select | Enter: Connect | Esc: Cancel"))
+
+	return b.String()
+}
 
-		// Truncate long labels
-		if len(label) > 15 {
-			label = label[:12] + "..."
+// renderConnectionNote renders the active connection's note for the title line
+func (m Model) renderConnectionNote() string {
+	tab := m.tab()
+	if tab == nil || tab.note == "" {
+		return ""
+	}
+	noteStyle := lipgloss.NewStyle().
+		Foreground(tab.theme.Warning).
+		Bold(true).
+		Padding(0, 1)
+	return noteStyle.Render("📝 " + tab.note)
+}
+
+// renderScriptResults renders the strip of numbered results left by the
+// last script run, or "" if there aren't several to pick from
+func (m Model) renderScriptResults() string {
+	tab := m.tab()
+	if tab == nil {
+		return ""
+	}
+	start := tab.scriptResultsStart()
+	if start < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, snap := range tab.results[start:] {
+		style := lipgloss.NewStyle().Foreground(tab.theme.TextDim).Padding(0, 1)
+		if start+i == tab.resultIndex {
+			style = lipgloss.NewStyle().
+				Background(tab.theme.Secondary).
+				Foreground(tab.theme.TextBright).
+				Bold(true).
+				Padding(0, 1)
 		}
+		label := fmt.Sprintf("%d: %s", i+1, scriptResultLabel(snap))
+		if i >= 9 {
+			label = scriptResultLabel(snap) // past the number keys; Alt+←/Alt+→ reach it
+		}
+		b.WriteString(style.Render(label))
+	}
+	return lipgloss.NewStyle().MaxWidth(m.mainWidth()).Render(b.String())
+}
+
+// renderTabBar renders the tab bar showing all open tabs
+func (m Model) renderTabBar() string {
+	if len(m.tabs) == 0 {
+		return ""
+	}
 
+	var b strings.Builder
+
+	for i, tab := range m.tabs {
 		// Style based on whether this is the active tab
 		var tabStyle lipgloss.Style
 		if i == m.activeTab {
@@ -381,7 +1129,7 @@ func (m Model) renderTabBar() string {
 		}
 
 		// Add tab number for quick reference
-		tabLabel := fmt.Sprintf("%d: %s", i+1, label)
+		tabLabel := fmt.Sprintf("%d: %s", i+1, m.tabLabel(i))
 		b.WriteString(tabStyle.Render(tabLabel))
 
 		// Add separator between tabs
@@ -475,6 +1223,15 @@ func (m Model) renderConnectionPicker() string {
 			}
 		}
 
+		if len(m.connectionPicker.connections) > 0 && m.vaultManager != nil {
+			selected := m.connectionPicker.connections[m.connectionPicker.selectedIdx]
+			if note := m.vaultManager.GetConnectionNote(selected); note != "" {
+				b.WriteString("\n")
+				b.WriteString(styles.Help.Render("  📝 " + note))
+				b.WriteString("\n")
+			}
+		}
+
 		m.renderPickerError(&b, styles)
 
 		tab := m.tab()
@@ -487,7 +1244,7 @@ func (m Model) renderConnectionPicker() string {
 			if m.creatingNewTab {
 				b.WriteString(styles.Help.Render("↑↓: Navigate | Enter: Open in new tab | Esc: Cancel"))
 			} else {
-				b.WriteString(styles.Help.Render("↑↓: Navigate | Enter: Connect | a: Add | d: Delete | Esc: Close"))
+				b.WriteString(styles.Help.Render("↑↓: Navigate | Enter: Connect | a: Add | e: Edit Note | d: Delete | Esc: Close"))
 			}
 		} else {
 			b.WriteString(styles.Help.Render("a: Add Connection | Esc: Close"))
@@ -578,6 +1335,19 @@ func (m Model) renderConnectionPicker() string {
 		b.WriteString("\n")
 		b.WriteString(styles.Help.Render("↑↓: Select | Enter: Continue | Esc: Back"))
 
+	case PickerModeAddNote:
+		b.WriteString(styles.Title.Render("➕  Add Connection - Note"))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("  Connection: %s (%s)\n\n", m.connectionPicker.newConnName, m.connectionPicker.newConnType))
+		b.WriteString("  Add a note for this connection (optional):\n")
+		b.WriteString(fmt.Sprintf("  %s█\n", m.connectionPicker.newConnNote))
+		b.WriteString("\n")
+		b.WriteString(styles.Help.Render("  e.g. PROD - do not run migrations here"))
+		b.WriteString("\n")
+		m.renderPickerError(&b, styles)
+		b.WriteString("\n")
+		b.WriteString(styles.Help.Render("Enter: Continue | Esc: Back"))
+
 	case PickerModeAddEncrypt:
 		b.WriteString(styles.Title.Render("➕  Add Connection - Storage"))
 		b.WriteString("\n\n")
@@ -605,6 +1375,18 @@ func (m Model) renderConnectionPicker() string {
 		b.WriteString("\n")
 		b.WriteString(styles.Help.Render("↑↓/Tab: Select | Enter: Save Connection | Esc: Back"))
 
+	case PickerModeEditNote:
+		b.WriteString(styles.Title.Render("📝  Edit Note"))
+		b.WriteString("\n\n")
+		if len(m.connectionPicker.connections) > 0 {
+			b.WriteString(fmt.Sprintf("  Connection: %s\n\n", m.connectionPicker.connections[m.connectionPicker.selectedIdx]))
+		}
+		b.WriteString("  Note (leave empty to remove it):\n")
+		b.WriteString(fmt.Sprintf("  %s█\n", m.connectionPicker.newConnNote))
+		m.renderPickerError(&b, styles)
+		b.WriteString("\n")
+		b.WriteString(styles.Help.Render("Enter: Save | Esc: Cancel"))
+
 	case PickerModeConfirmDelete:
 		b.WriteString(styles.Title.Render("🗑️  Delete Connection"))
 		b.WriteString("\n\n")
@@ -629,3 +1411,17 @@ func (m Model) renderPickerError(b *strings.Builder, styles ThemedStyles) {
 		b.WriteString("\n")
 	}
 }
+
+// editorSearchText renders the editor search bar shown in the status bar
+func (m Model) editorSearchText() string {
+	s := m.editorSearch
+	text := s.input.View()
+	switch {
+	case s.input.Value() == "":
+	case len(s.matches) == 0:
+		text += "  No matches"
+	default:
+		text += fmt.Sprintf("  %d of %d", s.current+1, len(s.matches))
+	}
+	return text
+}
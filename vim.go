@@ -0,0 +1,649 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// vimMode is the mode of the query editor's vim emulation. Visual mode is
+// normal mode with text selected.
+type vimMode int
+
+const (
+	vimNormal vimMode = iota
+	vimInsert
+)
+
+// VimState is the vim emulation's mode, its register, and the part of a
+// normal-mode command typed so far
+type VimState struct {
+	mode     vimMode
+	count    int    // count typed before a command or motion, 0 for none
+	pending  string // operator (d, c, y) waiting for a motion, or g/r waiting for a second key
+	register string // text yanked or deleted, which p puts back
+	linewise bool   // the register holds whole lines, which p puts below the cursor line
+}
+
+// vimMotions are the normal-mode keys that move the cursor, and that follow
+// an operator to say what it acts on
+var vimMotions = map[string]bool{
+	"h": true, "j": true, "k": true, "l": true, "w": true, "b": true, "e": true,
+	"0": true, "^": true, "$": true, "G": true, "gg": true,
+}
+
+// vimCharClass puts a rune in one of the classes that words are made of:
+// 0 for whitespace, 1 for letters, digits and _, 2 for other symbols
+func vimCharClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// vimLineStart returns the offset of the start of the line containing pos
+func vimLineStart(content string, pos int) int {
+	return strings.LastIndexByte(content[:pos], '\n') + 1
+}
+
+// vimLineEnd returns the offset of the end of the line containing pos,
+// before its newline
+func vimLineEnd(content string, pos int) int {
+	if i := strings.IndexByte(content[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(content)
+}
+
+// vimFirstNonBlank returns the offset of the first non-blank character of
+// the line containing pos, or its end if it's blank
+func vimFirstNonBlank(content string, pos int) int {
+	start, end := vimLineStart(content, pos), vimLineEnd(content, pos)
+	line := content[start:end]
+	return start + len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// vimClamp keeps pos off the end of its line, where the normal-mode cursor
+// can't be, unless the line is empty
+func vimClamp(content string, pos int) int {
+	pos = max(min(pos, len(content)), 0)
+	if start := vimLineStart(content, pos); pos == vimLineEnd(content, pos) && pos > start {
+		_, size := utf8.DecodeLastRuneInString(content[:pos])
+		return pos - size
+	}
+	return pos
+}
+
+// vimLineCol moves to the line n lines below (or above, if negative) the
+// line containing pos, keeping the column where that line is long enough
+func vimLineCol(content string, pos, n int) int {
+	start := vimLineStart(content, pos)
+	col := utf8.RuneCountInString(content[start:pos])
+	line := max(strings.Count(content[:pos], "\n")+n, 0)
+	target := lineOffset(content, line)
+	end := vimLineEnd(content, target)
+	for i := 0; i < col && target < end; i++ {
+		_, size := utf8.DecodeRuneInString(content[target:])
+		target += size
+	}
+	return vimClamp(content, target)
+}
+
+// vimWordForward returns the offset of the start of the word after pos
+func vimWordForward(content string, pos int) int {
+	n := len(content)
+	if pos >= n {
+		return n
+	}
+	r, _ := utf8.DecodeRuneInString(content[pos:])
+	i := pos
+	if cls := vimCharClass(r); cls != 0 {
+		for i < n {
+			r, size := utf8.DecodeRuneInString(content[i:])
+			if vimCharClass(r) != cls {
+				break
+			}
+			i += size
+		}
+	}
+	for i < n {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if vimCharClass(r) != 0 {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// vimWordBackward returns the offset of the start of the word before pos,
+// or of the word pos is in if it's past the word's start
+func vimWordBackward(content string, pos int) int {
+	i := pos
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(content[:i])
+		if vimCharClass(r) != 0 {
+			break
+		}
+		i -= size
+	}
+	if i == 0 {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRuneInString(content[:i])
+	cls := vimCharClass(r)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(content[:i])
+		if vimCharClass(r) != cls {
+			break
+		}
+		i -= size
+	}
+	return i
+}
+
+// vimWordEnd returns the offset of the last character of the word after
+// pos, or of the word pos is in if it's before the word's end
+func vimWordEnd(content string, pos int) int {
+	n := len(content)
+	if pos >= n {
+		return n
+	}
+	_, size := utf8.DecodeRuneInString(content[pos:])
+	i := pos + size
+	for i < n {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if vimCharClass(r) != 0 {
+			break
+		}
+		i += size
+	}
+	if i >= n {
+		return vimClamp(content, n)
+	}
+	r, _ := utf8.DecodeRuneInString(content[i:])
+	cls := vimCharClass(r)
+	for {
+		_, size := utf8.DecodeRuneInString(content[i:])
+		if i+size >= n {
+			return i
+		}
+		next, _ := utf8.DecodeRuneInString(content[i+size:])
+		if vimCharClass(next) != cls {
+			return i
+		}
+		i += size
+	}
+}
+
+// vimMotion returns where a motion repeated count times moves the cursor
+// from pos
+func vimMotion(content string, pos int, motion string, count int) int {
+	repeat := max(count, 1)
+	switch motion {
+	case "h":
+		start := vimLineStart(content, pos)
+		for i := 0; i < repeat && pos > start; i++ {
+			_, size := utf8.DecodeLastRuneInString(content[:pos])
+			pos -= size
+		}
+		return pos
+	case "l":
+		end := vimLineEnd(content, pos)
+		for i := 0; i < repeat && pos < end; i++ {
+			_, size := utf8.DecodeRuneInString(content[pos:])
+			pos += size
+		}
+		return vimClamp(content, pos)
+	case "j":
+		return vimLineCol(content, pos, repeat)
+	case "k":
+		return vimLineCol(content, pos, -repeat)
+	case "w":
+		for i := 0; i < repeat; i++ {
+			pos = vimWordForward(content, pos)
+		}
+		return vimClamp(content, pos)
+	case "b":
+		for i := 0; i < repeat; i++ {
+			pos = vimWordBackward(content, pos)
+		}
+		return pos
+	case "e":
+		for i := 0; i < repeat; i++ {
+			pos = vimWordEnd(content, pos)
+		}
+		return pos
+	case "0":
+		return vimLineStart(content, pos)
+	case "^":
+		return vimFirstNonBlank(content, pos)
+	case "$":
+		if repeat > 1 {
+			pos = vimLineCol(content, pos, repeat-1)
+		}
+		return vimClamp(content, vimLineEnd(content, pos))
+	case "G":
+		line := strings.Count(content, "\n")
+		if count > 0 {
+			line = count - 1
+		}
+		return vimFirstNonBlank(content, lineOffset(content, line))
+	case "gg":
+		return vimFirstNonBlank(content, lineOffset(content, max(count-1, 0)))
+	}
+	return pos
+}
+
+// vimLinesRange returns the byte range of whole lines first to last
+// (0-indexed), including the newline after the last line if it has one
+func vimLinesRange(content string, first, last int) (int, int) {
+	start := lineOffset(content, first)
+	end := vimLineEnd(content, lineOffset(content, last))
+	if end < len(content) {
+		end++
+	}
+	return start, end
+}
+
+// vimOperatorRange returns the range an operator acts on when followed by
+// a motion repeated count times, and whether it's whole lines
+func vimOperatorRange(content string, pos int, motion string, count int) (start, end int, linewise bool) {
+	line := strings.Count(content[:pos], "\n")
+	repeat := max(count, 1)
+	switch motion {
+	case "j", "k", "G", "gg":
+		other := line + repeat
+		switch motion {
+		case "k":
+			other = line - repeat
+		case "G", "gg":
+			other = strings.Count(content[:vimMotion(content, pos, motion, count)], "\n")
+		}
+		start, end = vimLinesRange(content, max(min(line, other), 0), max(line, other))
+		return start, end, true
+	case "w":
+		target := pos
+		for i := 0; i < repeat; i++ {
+			target = vimWordForward(content, target)
+		}
+		// A word motion doesn't take the line break with it
+		if lineEnd := vimLineEnd(content, pos); target > lineEnd && pos < lineEnd {
+			target = lineEnd
+		}
+		return pos, target, false
+	case "e":
+		target := vimMotion(content, pos, "e", count)
+		if target >= len(content) {
+			return pos, len(content), false
+		}
+		_, size := utf8.DecodeRuneInString(content[target:])
+		return pos, target + size, false
+	case "l":
+		end := vimLineEnd(content, pos)
+		target := pos
+		for i := 0; i < repeat && target < end; i++ {
+			_, size := utf8.DecodeRuneInString(content[target:])
+			target += size
+		}
+		return pos, target, false
+	case "$":
+		target := pos
+		if repeat > 1 {
+			target = vimLineCol(content, pos, repeat-1)
+		}
+		return pos, vimLineEnd(content, target), false
+	default: // h, b, 0, ^ move backwards
+		target := vimMotion(content, pos, motion, count)
+		return min(pos, target), max(pos, target), false
+	}
+}
+
+// vimPutPosition returns where p (after) or P (before) puts the register
+// at pos, and the text to insert there
+func vimPutPosition(content string, pos int, text string, linewise, after bool) (int, string) {
+	if linewise {
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if !after {
+			return vimLineStart(content, pos), text
+		}
+		end := vimLineEnd(content, pos)
+		if end == len(content) {
+			// The last line has no newline to put the lines after
+			return end, "\n" + strings.TrimSuffix(text, "\n")
+		}
+		return end + 1, text
+	}
+	if after && pos < vimLineEnd(content, pos) {
+		_, size := utf8.DecodeRuneInString(content[pos:])
+		pos += size
+	}
+	return pos, text
+}
+
+// vimNormal reports whether the vim emulation is on and in normal mode
+func (m *Model) vimNormal() bool {
+	return m.vimMode && m.vim.mode == vimNormal
+}
+
+// vimModeText names the vim emulation's mode for the help line, with any
+// half-typed command
+func (m Model) vimModeText() string {
+	mode := "-- NORMAL --"
+	switch {
+	case m.vim.mode == vimInsert:
+		mode = "-- INSERT --"
+	case m.tab() != nil && m.tab().selection != nil:
+		mode = "-- VISUAL --"
+	}
+	if m.vim.count > 0 {
+		mode += " " + strconv.Itoa(m.vim.count)
+	}
+	if m.vim.pending != "" {
+		mode += " " + m.vim.pending
+	}
+	return mode
+}
+
+// setVimMode switches the vim emulation's mode, dropping any half-typed command
+func (m *Model) setVimMode(mode vimMode) {
+	m.vim.mode = mode
+	m.vim.count = 0
+	m.vim.pending = ""
+}
+
+// vimReplace replaces content[start:end] of the active tab's editor with
+// text and moves the cursor to offset cursor of the result
+func (m *Model) vimReplace(start, end int, text string, cursor int) {
+	tab := m.activeTabPtr()
+	content := tab.textarea.Value()
+	tab.textarea.SetValue(content[:start] + text + content[end:])
+	moveCursorTo(&tab.textarea, cursor)
+}
+
+// vimYank puts content[start:end] of the editor in the register
+func (m *Model) vimYank(start, end int, linewise bool) {
+	text := m.activeTabPtr().textarea.Value()[start:end]
+	if linewise && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	m.vim.register = text
+	m.vim.linewise = linewise
+}
+
+// vimDelete yanks and deletes content[start:end] of the editor. Deleting
+// whole lines takes the line break before them if they're the last lines.
+func (m *Model) vimDelete(start, end int, linewise bool) {
+	m.vimYank(start, end, linewise)
+	content := m.activeTabPtr().textarea.Value()
+	if linewise && end == len(content) && !strings.HasSuffix(content, "\n") && start > 0 {
+		start--
+	}
+	rest := content[:start] + content[end:]
+	cursor := vimClamp(rest, start)
+	if linewise {
+		cursor = vimFirstNonBlank(rest, min(start, len(rest)))
+	}
+	m.vimReplace(start, end, "", cursor)
+}
+
+// vimChange deletes content[start:end] of the editor into the register and
+// starts inserting in its place. Changing whole lines leaves an empty line.
+func (m *Model) vimChange(start, end int, linewise bool) {
+	m.vimYank(start, end, linewise)
+	content := m.activeTabPtr().textarea.Value()
+	if linewise && end > start && content[end-1] == '\n' {
+		end--
+	}
+	m.vimReplace(start, end, "", start)
+	m.setVimMode(vimInsert)
+}
+
+// handleVimKey runs a key through the vim emulation of the query editor,
+// and reports whether it was used up. Keys it doesn't use go on to the
+// usual editor bindings.
+func (m *Model) handleVimKey(msg tea.KeyMsg) bool {
+	tab := m.activeTabPtr()
+	key := msg.String()
+
+	if m.vim.mode == vimInsert {
+		if key != "esc" {
+			return false
+		}
+		// Leaving insert mode steps back onto the last character typed
+		m.setVimMode(vimNormal)
+		content := tab.textarea.Value()
+		moveCursorTo(&tab.textarea, vimMotion(content, textareaCursorOffset(tab.textarea), "h", 1))
+		return true
+	}
+
+	if tab.selection != nil {
+		return m.handleVimVisualKey(key)
+	}
+
+	switch key {
+	case "enter":
+		key = "+"
+	case "backspace":
+		key = "h"
+	case " ":
+		key = "l"
+	case "delete":
+		key = "x"
+	case "esc":
+		m.vim.count, m.vim.pending = 0, ""
+		return false // still stops jumping between snippet placeholders
+	default:
+		if msg.Type != tea.KeyRunes || msg.Alt {
+			m.vim.count, m.vim.pending = 0, ""
+			return false
+		}
+	}
+
+	content := tab.textarea.Value()
+	pos := textareaCursorOffset(tab.textarea)
+
+	// r replaces characters under the cursor with the next key
+	if m.vim.pending == "r" {
+		count := max(m.vim.count, 1)
+		m.vim.count, m.vim.pending = 0, ""
+		start, end, _ := vimOperatorRange(content, pos, "l", count)
+		if msg.Type == tea.KeyRunes && utf8.RuneCountInString(content[start:end]) == count {
+			replacement := strings.Repeat(string(msg.Runes), count)
+			m.vimReplace(start, end, replacement, start+len(replacement)-len(string(msg.Runes)))
+		}
+		return true
+	}
+
+	// A count before the command, such as 3 in 3j or 2dd
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.vim.count > 0) {
+		m.vim.count = m.vim.count*10 + int(key[0]-'0')
+		return true
+	}
+
+	// g waits for a second g, as does an operator followed by g
+	if strings.HasSuffix(m.vim.pending, "g") {
+		m.vim.pending = strings.TrimSuffix(m.vim.pending, "g")
+		if key != "g" {
+			m.vim.count, m.vim.pending = 0, ""
+			return true
+		}
+		key = "gg"
+	} else if key == "g" {
+		m.vim.pending += "g"
+		return true
+	}
+	if key == "+" {
+		if m.vim.pending == "" {
+			moveCursorTo(&tab.textarea, vimFirstNonBlank(content, vimMotion(content, pos, "j", m.vim.count)))
+			m.vim.count = 0
+			return true
+		}
+		key = "j"
+	}
+
+	count := m.vim.count
+
+	// An operator waiting for its motion
+	if op := m.vim.pending; op != "" {
+		m.vim.count, m.vim.pending = 0, ""
+		var start, end int
+		var linewise bool
+		switch {
+		case key == op:
+			// dd, cc, yy act on count lines
+			line := strings.Count(content[:pos], "\n")
+			start, end = vimLinesRange(content, line, line+max(count, 1)-1)
+			linewise = true
+		case vimMotions[key]:
+			if r, _ := utf8.DecodeRuneInString(content[pos:]); op == "c" && key == "w" && pos < len(content) && vimCharClass(r) != 0 {
+				key = "e" // cw changes to the end of the word, like ce
+			}
+			start, end, linewise = vimOperatorRange(content, pos, key, count)
+		default:
+			return true
+		}
+		switch op {
+		case "d":
+			m.vimDelete(start, end, linewise)
+		case "c":
+			m.vimChange(start, end, linewise)
+		case "y":
+			m.vimYank(start, end, linewise)
+			if !linewise {
+				moveCursorTo(&tab.textarea, start)
+			}
+		}
+		return true
+	}
+
+	m.vim.count = 0
+	if vimMotions[key] {
+		moveCursorTo(&tab.textarea, vimMotion(content, pos, key, count))
+		return true
+	}
+
+	lineStart, lineEnd := vimLineStart(content, pos), vimLineEnd(content, pos)
+	switch key {
+	case "d", "c", "y", "r":
+		m.vim.pending = key
+		m.vim.count = count
+	case "i":
+		m.setVimMode(vimInsert)
+	case "a":
+		if pos < lineEnd {
+			_, size := utf8.DecodeRuneInString(content[pos:])
+			moveCursorTo(&tab.textarea, pos+size)
+		}
+		m.setVimMode(vimInsert)
+	case "I":
+		moveCursorTo(&tab.textarea, vimFirstNonBlank(content, pos))
+		m.setVimMode(vimInsert)
+	case "A":
+		moveCursorTo(&tab.textarea, lineEnd)
+		m.setVimMode(vimInsert)
+	case "o":
+		m.vimReplace(lineEnd, lineEnd, "\n", lineEnd+1)
+		m.setVimMode(vimInsert)
+	case "O":
+		m.vimReplace(lineStart, lineStart, "\n", lineStart)
+		m.setVimMode(vimInsert)
+	case "x":
+		if start, end, _ := vimOperatorRange(content, pos, "l", count); end > start {
+			m.vimDelete(start, end, false)
+		}
+	case "X":
+		if start, end, _ := vimOperatorRange(content, pos, "h", count); end > start {
+			m.vimDelete(start, end, false)
+		}
+	case "D":
+		start, end, _ := vimOperatorRange(content, pos, "$", count)
+		m.vimDelete(start, end, false)
+	case "C":
+		start, end, _ := vimOperatorRange(content, pos, "$", count)
+		m.vimChange(start, end, false)
+	case "s":
+		start, end, _ := vimOperatorRange(content, pos, "l", count)
+		m.vimChange(start, end, false)
+	case "S":
+		line := strings.Count(content[:pos], "\n")
+		start, end := vimLinesRange(content, line, line+max(count, 1)-1)
+		m.vimChange(start, end, true)
+	case "Y":
+		line := strings.Count(content[:pos], "\n")
+		start, end := vimLinesRange(content, line, line+max(count, 1)-1)
+		m.vimYank(start, end, true)
+	case "p", "P":
+		if m.vim.register == "" {
+			return true
+		}
+		at, text := vimPutPosition(content, pos, strings.Repeat(m.vim.register, max(count, 1)), m.vim.linewise, key == "p")
+		cursor := at // lines: the start of the first one put
+		switch {
+		case m.vim.linewise && at == len(content):
+			cursor++ // after the line break added at the end
+		case !m.vim.linewise:
+			_, size := utf8.DecodeLastRuneInString(text)
+			cursor = at + len(text) - size // on the last character put
+		}
+		m.vimReplace(at, at, text, cursor)
+	case "J":
+		// Join the next line onto this one, separated by a space
+		if lineEnd < len(content) {
+			next := content[lineEnd+1:]
+			trimmed := strings.TrimLeft(next, " \t")
+			m.vimReplace(lineEnd, lineEnd+1+len(next)-len(trimmed), " ", lineEnd)
+		}
+	case "v":
+		tab.selection = &Selection{anchor: pos, visual: true}
+	case "/":
+		m.openEditorSearch()
+	}
+	return true
+}
+
+// handleVimVisualKey handles a key in visual mode: motions extend the
+// selection, and y, d/x and c act on it
+func (m *Model) handleVimVisualKey(key string) bool {
+	tab := m.activeTabPtr()
+	content := tab.textarea.Value()
+	pos := textareaCursorOffset(tab.textarea)
+
+	if vimMotions[key] {
+		moveCursorTo(&tab.textarea, vimMotion(content, pos, key, 0))
+		return true
+	}
+	start, end := tab.selection.Range(pos)
+	// The character under the cursor is selected too, as in vim
+	if end < len(content) {
+		_, size := utf8.DecodeRuneInString(content[end:])
+		end += size
+	}
+	switch key {
+	case "esc", "v":
+		tab.selection = nil
+	case "y":
+		tab.selection = nil
+		m.vimYank(start, end, false)
+		moveCursorTo(&tab.textarea, start)
+	case "d", "x":
+		tab.selection = nil
+		m.vimDelete(start, end, false)
+	case "c":
+		tab.selection = nil
+		m.vimChange(start, end, false)
+	default:
+		// Ctrl+R runs the selection; other keys that would type do nothing
+		return len(key) == 1
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package main
+
+import "testing"
+
+// TestVimMotion tests where vim's normal-mode motions move the cursor
+func TestVimMotion(t *testing.T) {
+	content := "SELECT id, name FROM users;\n  WHERE x = 1;\n\nSELECT 2;"
+	tests := []struct {
+		name   string
+		pos    int
+		motion string
+		count  int
+		want   int
+	}{
+		{"h at line start", 28, "h", 0, 28},
+		{"l", 0, "l", 0, 1},
+		{"l stops on the last character", 25, "l", 3, 26},
+		{"j keeps the column", 3, "j", 0, 31},
+		{"j onto an empty line", 3, "j", 2, 43},
+		{"k", 31, "k", 0, 3},
+		{"w over punctuation", 7, "w", 0, 9},
+		{"w to the next line", 21, "w", 2, 30},
+		{"b", 11, "b", 0, 9},
+		{"b to the previous line", 30, "b", 0, 26},
+		{"e", 0, "e", 0, 5},
+		{"e from the end of a word", 5, "e", 0, 8},
+		{"0", 35, "0", 0, 28},
+		{"^", 28, "^", 0, 30},
+		{"$", 0, "$", 0, 26},
+		{"G", 0, "G", 0, 44},
+		{"G with a count", 0, "G", 2, 30},
+		{"gg", 44, "gg", 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vimMotion(content, tc.pos, tc.motion, tc.count); got != tc.want {
+				t.Errorf("vimMotion(%d, %q, %d) = %d, want %d", tc.pos, tc.motion, tc.count, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestVimOperatorRange tests the text an operator acts on with each motion
+func TestVimOperatorRange(t *testing.T) {
+	content := "SELECT id, name FROM users;\n  WHERE x = 1;\nSELECT 2;"
+	tests := []struct {
+		name         string
+		pos          int
+		motion       string
+		count        int
+		want         string
+		wantLinewise bool
+	}{
+		{"w", 0, "w", 0, "SELECT ", false},
+		{"w stops at the line end", 26, "w", 0, ";", false},
+		{"e", 0, "e", 0, "SELECT", false},
+		{"e to the end of the text", 45, "e", 3, "LECT 2;", false},
+		{"b", 9, "b", 0, "id", false},
+		{"$", 16, "$", 0, "FROM users;", false},
+		{"0", 7, "0", 0, "SELECT ", false},
+		{"l", 0, "l", 3, "SEL", false},
+		{"j", 3, "j", 0, "SELECT id, name FROM users;\n  WHERE x = 1;\n", true},
+		{"k on the last line", 45, "k", 0, "  WHERE x = 1;\nSELECT 2;", true},
+		{"G", 30, "G", 0, "  WHERE x = 1;\nSELECT 2;", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, linewise := vimOperatorRange(content, tc.pos, tc.motion, tc.count)
+			if got := content[start:end]; got != tc.want || linewise != tc.wantLinewise {
+				t.Errorf("vimOperatorRange(%d, %q, %d) = %q (linewise %v), want %q (linewise %v)", tc.pos, tc.motion, tc.count, got, linewise, tc.want, tc.wantLinewise)
+			}
+		})
+	}
+}
+
+// TestVimPutPosition tests where p and P put the register
+func TestVimPutPosition(t *testing.T) {
+	content := "SELECT 1;\nSELECT 2;"
+	tests := []struct {
+		name     string
+		content  string
+		pos      int
+		text     string
+		linewise bool
+		after    bool
+		wantAt   int
+		wantText string
+	}{
+		{"p after the cursor", content, 0, "x", false, true, 1, "x"},
+		{"P before the cursor", content, 3, "x", false, false, 3, "x"},
+		{"p on an empty line", "\nSELECT 1;", 0, "x", false, true, 0, "x"},
+		{"p lines below", content, 2, "SELECT 3;\n", true, true, 10, "SELECT 3;\n"},
+		{"P lines above", content, 12, "SELECT 3;\n", true, false, 10, "SELECT 3;\n"},
+		{"p lines below the last line", content, 12, "SELECT 3;\n", true, true, 19, "\nSELECT 3;"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			at, text := vimPutPosition(tc.content, tc.pos, tc.text, tc.linewise, tc.after)
+			if at != tc.wantAt || text != tc.wantText {
+				t.Errorf("vimPutPosition(%d) = (%d, %q), want (%d, %q)", tc.pos, at, text, tc.wantAt, tc.wantText)
+			}
+		})
+	}
+}
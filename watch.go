@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultWatchInterval is how often a watched query runs when no interval
+// is typed
+const defaultWatchInterval = 5 * time.Second
+
+// watchState is a query a tab runs again every interval, like watch(1),
+// until it's stopped, fails or another statement runs in the tab
+type watchState struct {
+	query    string
+	interval time.Duration
+	runs     int // finished so far
+}
+
+// watchTickMsg is sent when a watched query is due to run again
+type watchTickMsg struct {
+	tab   *Tab
+	watch *watchState
+}
+
+// parseWatchInterval reads the seconds typed into the watch prompt; empty
+// means defaultWatchInterval
+func parseWatchInterval(text string) (time.Duration, error) {
+	text = strings.TrimSuffix(strings.TrimSpace(text), "s")
+	if text == "" {
+		return defaultWatchInterval, nil
+	}
+	secs, err := strconv.ParseFloat(text, 64)
+	if err != nil || secs < 0.5 {
+		return 0, fmt.Errorf("invalid interval %q: expected a number of seconds, at least 0.5", text)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// toggleWatch stops the active tab's watch, or asks how often to run the
+// statement under the cursor
+func (m *Model) toggleWatch() {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return
+	}
+	if tab.watch != nil {
+		tab.watch = nil
+		m.statusMessage = "Stopped watching"
+		return
+	}
+	stmt := strings.TrimSpace(m.getQueryUnderCursor())
+	switch {
+	case stmt == "":
+		m.statusMessage = "No query under cursor to watch"
+		return
+	case writesData(stmt):
+		m.statusMessage = "Only a read can be watched; this statement writes"
+		return
+	case len(findQueryVariables(stmt, tab.dbType)) > 0:
+		m.statusMessage = "A watched query can't have variables"
+		return
+	}
+	ti := textinput.New()
+	ti.Prompt = "Watch every (seconds): "
+	ti.Placeholder = strconv.Itoa(int(defaultWatchInterval.Seconds()))
+	ti.CharLimit = 6
+	ti.Width = 8
+	ti.Focus()
+	m.watchPrompt = &ti
+	m.focus = focusWatch
+	tab.completion = nil
+	m.statusMessage = ""
+}
+
+// startWatch runs the statement under the cursor now and then every
+// interval
+func (m *Model) startWatch(interval time.Duration) tea.Cmd {
+	tab := m.activeTabPtr()
+	if tab == nil {
+		return nil
+	}
+	if tab.running != "" {
+		m.statusMessage = "A query is already running in this tab"
+		return nil
+	}
+	query := strings.TrimSpace(m.getQueryUnderCursor())
+	tab.watch = &watchState{query: query, interval: interval}
+	return m.startQuery(tab, query)
+}
+
+// nextWatchTick schedules the tab's watched query to run again
+func (tab *Tab) nextWatchTick() tea.Cmd {
+	watch := tab.watch
+	return tea.Tick(watch.interval, func(time.Time) tea.Msg {
+		return watchTickMsg{tab: tab, watch: watch}
+	})
+}
+
+// runWatched runs a watched query again when its tick comes, comparing its
+// rows with the last run's so the changes are highlighted
+func (m *Model) runWatched(msg watchTickMsg) tea.Cmd {
+	tab := msg.tab
+	if !slices.Contains(m.tabs, tab) || tab.watch != msg.watch {
+		return nil // closed or stopped since
+	}
+	if tab.running != "" || m.writeConfirm != nil {
+		return tab.nextWatchTick() // try again next time
+	}
+	if n := len(tab.results); n > 0 && !tab.results[n-1].Result.Executed && tab.results[n-1].Result.Error == nil {
+		tab.diffBase = tab.results[n-1].Result
+	}
+	return m.launchQuery(tab, tab.watch.query)
+}
+
+// status is the note added to the status bar after a watched run
+func (w *watchState) status() string {
+	return fmt.Sprintf("watching every %s, Alt+W to stop", w.interval)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseWatchInterval tests reading the interval typed into the watch prompt
+func TestParseWatchInterval(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", defaultWatchInterval, false},
+		{"10", 10 * time.Second, false},
+		{" 2s ", 2 * time.Second, false},
+		{"1.5", 1500 * time.Millisecond, false},
+		{"0", 0, true},
+		{"0.1", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseWatchInterval(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWatchInterval(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseWatchInterval(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
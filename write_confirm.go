@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// WriteConfirm is the dialog asking the user to type YES before an UPDATE
+// or DELETE without a WHERE clause runs
+type WriteConfirm struct {
+	tab         *Tab
+	query       string
+	args        []any
+	verb        string // UPDATE or DELETE
+	input       textinput.Model
+	mismatch    bool       // Enter was pressed without YES typed
+	returnFocus focusState // focus to restore when the dialog closes
+}
+
+// newWriteConfirm creates the dialog for query, about to run in tab
+func newWriteConfirm(tab *Tab, query string, args []any, verb string, returnFocus focusState) *WriteConfirm {
+	ti := textinput.New()
+	ti.Placeholder = "YES"
+	ti.CharLimit = 8
+	ti.Width = 10
+	ti.Focus()
+	return &WriteConfirm{tab: tab, query: query, args: args, verb: verb, input: ti, returnFocus: returnFocus}
+}
+
+// isProduction reports whether a tab's connection is tagged as production
+// with the production theme
+func (t *Tab) isProduction() bool {
+	return t.theme.Name == "production"
+}
+
+// confirmWrite opens the dialog if query changes every row of a table,
+// returning false if it may run straight away. Dry runs change nothing, so
+// they're never held up.
+func (m *Model) confirmWrite(tab *Tab, query string, args []any) bool {
+	verb := unscopedWrite(query)
+	if verb == "" || tab.dryRun {
+		return false
+	}
+	if m.writeConfirm != nil {
+		// Another tab's script is already waiting; stop this one rather
+		// than stack the dialogs
+		tab.script = nil
+		m.statusMessage = fmt.Sprintf("%s: %s without WHERE not run", m.tabDisplayName(slices.Index(m.tabs, tab)), verb)
+		return true
+	}
+	m.writeConfirm = newWriteConfirm(tab, query, args, verb, m.focus)
+	m.focus = focusConfirmWrite
+	if active := m.activeTabPtr(); active != nil {
+		active.textarea.Blur()
+	}
+	m.statusMessage = ""
+	return true
+}
+
+// table returns the table the statement changes, or "" if it can't tell
+func (c *WriteConfirm) table() string {
+	fields := strings.Fields(skipLeadingComments(c.query, hashCommentsAllowed(c.tab.dbType)))
+	for i := 0; i+1 < len(fields); i++ {
+		if strings.EqualFold(fields[i], "UPDATE") || strings.EqualFold(fields[i], "FROM") {
+			return strings.TrimSuffix(fields[i+1], ";")
+		}
+	}
+	return ""
+}